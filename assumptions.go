@@ -0,0 +1,297 @@
+package gosymbol
+
+/*
+A Property is a set of facts about the value of an expression,
+e.g. that it is a positive integer. Properties are attached to
+variables when they are declared,
+
+	x := Var("x", Positive, Integer)
+
+and queried for arbitrary expressions with Ask. The properties
+of compound expressions are derived from the properties of their
+operands, so Ask(Pow(x, Int(2)), IsPositive) is true for the
+variable above.
+
+A property that can not be derived is treated as unknown, never
+as false: Ask(expr, IsNegative) being false does not imply that
+expr is non-negative.
+*/
+type Property uint16
+
+const (
+	IsReal Property = 1 << iota
+	IsInteger
+	IsPositive
+	IsNegative
+	IsNonnegative
+	IsNonpositive
+	IsNonzero
+)
+
+// The union of all properties, so other bits are no properties.
+const allProperties = IsNonzero<<1 - 1
+
+// Aliases reading naturally as assumptions in Var(name, ...).
+// Real variables are declared with Real(symbol).
+const (
+	Integer     = IsInteger
+	Positive    = IsPositive
+	Negative    = IsNegative
+	Nonnegative = IsNonnegative
+	Nonpositive = IsNonpositive
+	Nonzero     = IsNonzero
+)
+
+/*
+Returns true if every property in query can be derived for expr
+from the assumptions on the variables in it. Several properties
+can be queried at once, e.g. Ask(expr, IsPositive|IsInteger).
+*/
+func Ask(expr Expr, query Property) bool {
+	return properties(expr)&query == query
+}
+
+// Returns the properties of the variable v.
+func (v variable) Assumptions() Property {
+	return v.assumptions
+}
+
+/*
+Adds the properties implied by the ones in p, e.g. a positive
+value is also non-negative, non-zero and real.
+*/
+func (p Property) closure() Property {
+	for {
+		q := p
+		if q&IsPositive != 0 {
+			q |= IsNonnegative | IsNonzero
+		}
+		if q&IsNegative != 0 {
+			q |= IsNonpositive | IsNonzero
+		}
+		if q&(IsNonnegative|IsNonpositive|IsInteger) != 0 {
+			q |= IsReal
+		}
+		if q&IsNonzero != 0 && q&IsNonnegative != 0 {
+			q |= IsPositive
+		}
+		if q&IsNonzero != 0 && q&IsNonpositive != 0 {
+			q |= IsNegative
+		}
+		if q == p {
+			return p
+		}
+		p = q
+	}
+}
+
+// Returns false if p implies contradicting properties,
+// e.g. both positive and non-positive.
+func (p Property) consistent() bool {
+	p = p.closure()
+	return p&(IsPositive|IsNonpositive) != IsPositive|IsNonpositive &&
+		p&(IsNegative|IsNonnegative) != IsNegative|IsNonnegative
+}
+
+// Derives the properties of expr from the properties of its operands.
+func properties(expr Expr) Property {
+	switch e := expr.(type) {
+	case integer:
+		return rationalProperties(e) | IsInteger
+	case fraction:
+		return rationalProperties(e)
+	case variable:
+		return e.assumptions.closure()
+	case add:
+		return addProperties(e.Operands)
+	case mul:
+		return mulProperties(e.Operands)
+	case pow:
+		return powProperties(e.Base, e.Exponent)
+	case exp:
+		if properties(e.Arg)&IsReal != 0 {
+			return IsPositive.closure()
+		}
+		return IsNonzero
+	case log:
+		return logProperties(e.Arg)
+	case sqrt:
+		arg := properties(e.Arg)
+		if arg&IsPositive != 0 {
+			return IsPositive.closure()
+		} else if arg&IsNonnegative != 0 {
+			return IsNonnegative.closure()
+		}
+	case sin:
+		return properties(e.Arg) & IsReal
+	case cos:
+		return properties(e.Arg) & IsReal
+	case FunctionApplication:
+		return functionProperties(e)
+	}
+	return 0
+}
+
+func rationalProperties(r rational) Property {
+	switch value := r.approx(); {
+	case value > 0:
+		return IsPositive.closure()
+	case value < 0:
+		return IsNegative.closure()
+	}
+	return (IsNonnegative | IsNonpositive).closure()
+}
+
+/*
+A sum is non-negative if all its terms are, and positive if
+additionally one of them is. The non-positive case is symmetric.
+*/
+func addProperties(terms []Expr) Property {
+	result := IsReal | IsInteger
+	nonnegative, nonpositive := true, true
+	positive, negative := false, false
+	for _, term := range terms {
+		p := properties(term)
+		result &= p
+		nonnegative = nonnegative && p&IsNonnegative != 0
+		nonpositive = nonpositive && p&IsNonpositive != 0
+		positive = positive || p&IsPositive != 0
+		negative = negative || p&IsNegative != 0
+	}
+	if nonnegative {
+		result |= IsNonnegative
+		if positive {
+			result |= IsPositive
+		}
+	}
+	if nonpositive {
+		result |= IsNonpositive
+		if negative {
+			result |= IsNegative
+		}
+	}
+	return result.closure()
+}
+
+/*
+The sign of a product is known if the sign of every factor is,
+and is negative if an odd number of the factors are.
+*/
+func mulProperties(factors []Expr) Property {
+	result := IsReal | IsInteger | IsNonzero
+	signed, strict := true, true
+	negatives := 0
+	for _, factor := range factors {
+		p := properties(factor)
+		result &= p
+		switch {
+		case p&IsPositive != 0:
+		case p&IsNegative != 0:
+			negatives++
+		case p&IsNonnegative != 0:
+			strict = false
+		case p&IsNonpositive != 0:
+			strict = false
+			negatives++
+		default:
+			signed = false
+		}
+	}
+	if signed {
+		sign := IsNonnegative
+		if negatives%2 != 0 {
+			sign = IsNonpositive
+		}
+		if strict {
+			sign |= IsNonzero
+		}
+		result |= sign
+	}
+	return result.closure()
+}
+
+func powProperties(base, exponent Expr) Property {
+	b := properties(base)
+	n, ok := exponent.(integer)
+	if !ok {
+		e := properties(exponent)
+		switch {
+		case b&IsPositive != 0 && e&IsReal != 0:
+			return IsPositive.closure()
+		case b&IsNonnegative != 0 && e&IsPositive != 0:
+			return IsNonnegative.closure()
+		}
+		return 0
+	}
+
+	// Negative powers are undefined when the base is zero
+	if n.value < 0 && b&IsNonzero == 0 {
+		return 0
+	}
+
+	// Integer powers keep realness and, for non-negative exponents,
+	// integrality, while the sign is decided by the parity of n
+	result := b & (IsReal | IsNonzero)
+	if n.value >= 0 {
+		result |= b & IsInteger
+	}
+	if n.value%2 == 0 {
+		if b&IsReal != 0 {
+			result |= IsNonnegative
+		}
+	} else {
+		result |= b & (IsPositive | IsNegative | IsNonnegative | IsNonpositive)
+	}
+	return result.closure()
+}
+
+func logProperties(arg Expr) Property {
+	if r, ok := arg.(rational); ok {
+		switch value := r.approx(); {
+		case value > 1:
+			return IsPositive.closure()
+		case value == 1:
+			return (IsNonnegative | IsNonpositive).closure()
+		case value > 0:
+			return IsNegative.closure()
+		}
+		return 0
+	}
+	if properties(arg)&IsPositive != 0 {
+		return IsReal
+	}
+	return 0
+}
+
+func functionProperties(f FunctionApplication) Property {
+	args := make([]Property, len(f.Args))
+	for ix, arg := range f.Args {
+		args[ix] = properties(arg)
+	}
+	nonnegativeInteger := IsNonnegative | IsInteger
+	switch {
+	case f.Name == factorialName && len(args) == 1 && args[0]&nonnegativeInteger == nonnegativeInteger:
+		return (IsPositive | IsInteger).closure()
+	case f.Name == gammaName && len(args) == 1 && args[0]&IsPositive != 0:
+		return IsPositive.closure()
+	case f.Name == binomialName && len(args) == 2 && args[0]&args[1]&IsInteger != 0:
+		return IsInteger.closure()
+	case f.Name == heavisideName && len(args) == 1 && args[0]&IsReal != 0:
+		return IsNonnegative.closure()
+	case f.Name == absName && len(args) == 1:
+		return (IsNonnegative | args[0]&IsNonzero).closure()
+	case f.Name == reName || f.Name == imName || f.Name == argName:
+		return IsReal
+	case f.Name == conjugateName && len(args) == 1 && args[0]&IsReal != 0:
+		return args[0]
+	case f.Name == nextPrimeName || f.Name == eulerPhiName:
+		return (IsPositive | IsInteger).closure()
+	case f.Name == lcmName || f.Name == modularInverseName:
+		return (IsNonnegative | IsInteger).closure()
+	case f.Name == moebiusMuName || f.Name == jacobiSymbolName:
+		return IsInteger.closure()
+	case f.Name == rootOfName:
+		return rootOfProperties(f)
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+package gosymbol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAsk(t *testing.T) {
+	x := Var("x", Positive)
+	y := Var("y", Negative)
+	n := Var("n", Nonnegative, Integer)
+	r := Real("r")
+	z := Var("z")
+
+	tests := []struct {
+		name     string
+		input    Expr
+		query    Property
+		expected bool
+	}{
+		{name: "positive variable", input: x, query: IsPositive | IsNonzero | IsReal, expected: true},
+		{name: "unknown variable", input: z, query: IsReal, expected: false},
+		{name: "negative constant", input: Int(-3), query: IsNegative | IsInteger, expected: true},
+		{name: "fraction is not integer", input: Div(Int(1), Int(2)), query: IsInteger, expected: false},
+		{name: "zero is nonnegative", input: Int(0), query: IsNonnegative | IsNonpositive, expected: true},
+		{name: "zero is not positive", input: Int(0), query: IsPositive, expected: false},
+		{name: "sum of positives", input: Add(x, n, Int(1)), query: IsPositive, expected: true},
+		{name: "sum of mixed signs", input: Add(x, y), query: IsPositive, expected: false},
+		{name: "sum of mixed signs is real", input: Add(x, y), query: IsReal, expected: true},
+		{name: "product of two negatives", input: Mul(y, y, x), query: IsPositive, expected: true},
+		{name: "product with odd number of negatives", input: Mul(Int(2), y), query: IsNegative, expected: true},
+		{name: "product with nonnegative factor", input: Mul(n, y), query: IsNonpositive, expected: true},
+		{name: "product with nonnegative factor may be zero", input: Mul(n, y), query: IsNegative, expected: false},
+		{name: "even power of real", input: Pow(r, Int(2)), query: IsNonnegative, expected: true},
+		{name: "even power of unknown", input: Pow(z, Int(2)), query: IsNonnegative, expected: false},
+		{name: "odd power keeps sign", input: Pow(y, Int(3)), query: IsNegative, expected: true},
+		{name: "negative power of integer", input: Pow(n, Int(-1)), query: IsInteger, expected: false},
+		{name: "negative power of zero", input: Pow(Int(0), Int(-1)), query: IsReal, expected: false},
+		{name: "negative power of a possible zero", input: Pow(r, Int(-2)), query: IsReal, expected: false},
+		{name: "negative power of nonzero", input: Pow(y, Int(-1)), query: IsNegative, expected: true},
+		{name: "real power of positive", input: Pow(x, r), query: IsPositive, expected: true},
+		{name: "exp of real", input: Exp(r), query: IsPositive, expected: true},
+		{name: "exp of unknown", input: Exp(z), query: IsNonzero, expected: true},
+		{name: "log of positive", input: Log(Add(x, Int(1))), query: IsReal, expected: true},
+		{name: "log of small constant", input: Log(Div(Int(1), Int(2))), query: IsNegative, expected: true},
+		{name: "log of unknown", input: Log(z), query: IsReal, expected: false},
+		{name: "sin of real", input: Sin(r), query: IsReal, expected: true},
+		{name: "factorial", input: Factorial(n), query: IsPositive | IsInteger, expected: true},
+		{name: "pi", input: PI, query: IsPositive, expected: true},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := Ask(test.input, test.query)
+			if result != test.expected {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expected, result)
+			}
+		})
+	}
+}
+
+func TestContradictoryAssumptions(t *testing.T) {
+	tests := [][]Property{
+		{Positive, Negative},
+		{Positive, Nonpositive},
+		{Negative, Nonnegative},
+		{Nonnegative, Nonpositive, Nonzero},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Following test failed: contradictory assumptions\nInput: %v\nExpected: panic\nGot: no panic", test)
+				}
+			}()
+			Var("x", test...)
+		})
+	}
+}
+
+func TestAssumptionSimplification(t *testing.T) {
+	x := Var("x", Nonnegative)
+	r := Real("r")
+	z := Var("z")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput Expr
+	}{
+		{
+			name:           "sqrt(x^2) = x for x >= 0",
+			input:          Sqrt(Pow(x, Int(2))),
+			expectedOutput: x,
+		},
+		{
+			name:           "(x^2)^(1/2) = x for x >= 0",
+			input:          Pow(Pow(x, Int(2)), Div(Int(1), Int(2))),
+			expectedOutput: x,
+		},
+		{
+			name:           "(z^(1/2))^2 = z",
+			input:          Pow(Pow(z, Div(Int(1), Int(2))), Int(2)),
+			expectedOutput: z,
+		},
+		{
+			name:           "log(exp(r)) = r for real r",
+			input:          Log(Exp(r)),
+			expectedOutput: r,
+		},
+		{
+			name:           "log(exp(2*x + 1)) = 2*x + 1",
+			input:          Log(Exp(Add(Mul(Int(2), x), Int(1)))),
+			expectedOutput: Add(Int(1), Mul(Int(2), x)),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := test.input.Simplify()
+			if !Equal(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+
+	// Without assumptions the identities do not hold
+	if result := Log(Exp(z)).Simplify(); Equal(result, z) {
+		t.Errorf("Following test failed: log(exp(z)) is not simplified for complex z\nExpected: %v\nGot: %v", Log(Exp(z)), result)
+	}
+	if result := Sqrt(Pow(z, Int(2))).Simplify(); Equal(result, z) {
+		t.Errorf("Following test failed: sqrt(z^2) is not simplified for complex z\nGot: %v", result)
+	}
+}
+
+func TestEvalIgnoresAssumptions(t *testing.T) {
+	x := Var("x", Positive)
+	args := Arguments{}
+	args.AddArgument(Var("x"), Int(3))
+	result := Mul(Int(2), x).Eval()(args)
+	if !Equal(result, Int(6)) {
+		t.Errorf("Following test failed: evaluation by variable name\nInput: %v\nExpected: %v\nGot: %v", Mul(Int(2), x), Int(6), result)
+	}
+}
+
+func TestDifferentiateIgnoresAssumptions(t *testing.T) {
+	x := Var("x", Positive)
+	result := Pow(x, Int(2)).D(Var("x"))
+	expected := Mul(Int(2), x)
+	if !Equal(result, expected) {
+		t.Errorf("Following test failed: differentiation by variable name\nInput: %v\nExpected: %v\nGot: %v", Pow(x, Int(2)), expected, result)
+	}
+}
@@ -0,0 +1,456 @@
+/*
+Package codegen emits source code of functions computing gosymbol
+expressions in Go, C and Python, e.g.
+
+	x, y := gosymbol.Var("x"), gosymbol.Var("y")
+	expr := gosymbol.Add(gosymbol.Pow(gosymbol.Sin(x), gosymbol.Int(2)), gosymbol.Mul(y, gosymbol.Sin(x)))
+	src, err := codegen.Go(expr, "f", []gosymbol.VarName{"x", "y"})
+
+gives
+
+	func f(x, y float64) float64 {
+		t0 := math.Sin(x)
+		return t0*t0 + y*t0
+	}
+
+Subexpressions occurring more than once are computed once into
+temporaries, and small integer powers are computed by repeated
+multiplication instead of by the power function of the language.
+The emitted functions use the standard math library of the language,
+which the surrounding source has to import, see each function.
+
+Variables not listed in vars, except PI, and undefined functions
+other than Abs, Gamma and Factorial give an UnboundVariableError.
+Unevaluated derivatives give a NotNumericError. A function or
+parameter name which is a keyword or predeclared identifier of the
+language, such as float64 in Go, or a name the generated code refers
+to, such as math, gives an InvalidArgumentError.
+*/
+package codegen
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/victorbrun/gosymbol"
+)
+
+// The options of the generated code, see GoWith.
+type Options struct {
+	// Also emit a function computing the gradient of the
+	// expression w.r.t. vars, in their order. It is omitted
+	// when there are no variables.
+	Gradient bool
+}
+
+/*
+Returns a Go function funcName of the float64 parameters vars
+computing expr, using the package math. With Options.Gradient, a
+function funcName + "Gradient" returning an array follows.
+*/
+func Go(expr gosymbol.Expr, funcName string, vars []gosymbol.VarName) (string, error) {
+	return GoWith(expr, funcName, vars, Options{})
+}
+
+func GoWith(expr gosymbol.Expr, funcName string, vars []gosymbol.VarName, opts Options) (string, error) {
+	return generate(goLanguage, expr, funcName, vars, opts)
+}
+
+/*
+Returns a C function funcName of the double parameters vars computing
+expr, using <math.h>. With Options.Gradient, a function funcName +
+"_gradient" storing the gradient in its last parameter follows.
+*/
+func C(expr gosymbol.Expr, funcName string, vars []gosymbol.VarName) (string, error) {
+	return CWith(expr, funcName, vars, Options{})
+}
+
+func CWith(expr gosymbol.Expr, funcName string, vars []gosymbol.VarName, opts Options) (string, error) {
+	return generate(cLanguage, expr, funcName, vars, opts)
+}
+
+/*
+Returns a Python function funcName of the parameters vars computing
+expr, using the module math. With Options.Gradient, a function
+funcName + "_gradient" returning a list follows.
+*/
+func Python(expr gosymbol.Expr, funcName string, vars []gosymbol.VarName) (string, error) {
+	return PythonWith(expr, funcName, vars, Options{})
+}
+
+func PythonWith(expr gosymbol.Expr, funcName string, vars []gosymbol.VarName, opts Options) (string, error) {
+	return generate(pythonLanguage, expr, funcName, vars, opts)
+}
+
+/*
+Returns a Python function like Python, but using NumPy imported as
+np, so it can be applied to arrays elementwise. Gamma and Factorial
+use scipy.special.gamma.
+*/
+func NumPy(expr gosymbol.Expr, funcName string, vars []gosymbol.VarName) (string, error) {
+	return NumPyWith(expr, funcName, vars, Options{})
+}
+
+func NumPyWith(expr gosymbol.Expr, funcName string, vars []gosymbol.VarName, opts Options) (string, error) {
+	return generate(numPyLanguage, expr, funcName, vars, opts)
+}
+
+// The syntax of a target language.
+type language struct {
+	// The names of the constants pi, e and nan, and of the elementary functions
+	names map[string]string
+	// Formats a call of the power function, or nil if ** is used
+	pow func(base, exponent string) string
+	// Whether identifiers may contain non-ASCII letters
+	unicode bool
+	// The keywords, and the names the generated code refers to, which cannot name a function or parameter
+	reserved map[string]bool
+	// The suffix of the name of the gradient function
+	gradientSuffix string
+
+	// The function header, the definition of a temporary, and the return statements
+	header        func(name string, params []string) string
+	gradient      func(name string, params []string) string
+	temporary     string
+	returnValue   func(value string) string
+	returnVector  func(components []string) string
+	footer        string
+	functionBreak string
+}
+
+const goKeywords = `break case chan const continue default defer else fallthrough for func go goto if
+	import interface map package range return select struct switch type var`
+
+// The predeclared identifiers of Go, which a parameter would shadow, e.g. float64.
+const goPredeclared = `any bool byte comparable complex64 complex128 error float32 float64 int int8 int16
+	int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr true false iota nil append cap clear
+	close complex copy delete imag len make max min new panic print println real recover`
+
+var goLanguage = language{
+	names: map[string]string{
+		"pi": "math.Pi", "e": "math.E", "nan": "math.NaN()",
+		"exp": "math.Exp", "log": "math.Log", "sqrt": "math.Sqrt", "sin": "math.Sin", "cos": "math.Cos",
+		"abs": "math.Abs", "gamma": "math.Gamma",
+	},
+	pow:            func(base, exponent string) string { return "math.Pow(" + base + ", " + exponent + ")" },
+	unicode:        true,
+	reserved:       words(goKeywords + " " + goPredeclared + " math"),
+	gradientSuffix: "Gradient",
+	header: func(name string, params []string) string {
+		return fmt.Sprintf("func %s(%s) float64 {\n", name, goParams(params))
+	},
+	gradient: func(name string, params []string) string {
+		return fmt.Sprintf("func %s(%s) [%d]float64 {\n", name, goParams(params), len(params))
+	},
+	temporary:   "\t%s := %s\n",
+	returnValue: func(value string) string { return "\treturn " + value + "\n" },
+	returnVector: func(components []string) string {
+		return fmt.Sprintf("\treturn [%d]float64{%s}\n", len(components), strings.Join(components, ", "))
+	},
+	footer:        "}\n",
+	functionBreak: "\n",
+}
+
+func goParams(params []string) string {
+	if len(params) == 0 {
+		return ""
+	}
+	return strings.Join(params, ", ") + " float64"
+}
+
+const cKeywords = `auto break case char const continue default do double else enum extern float for goto if
+	inline int long register restrict return short signed sizeof static struct switch typedef union unsigned
+	void volatile while _Bool _Complex _Imaginary`
+
+var cLanguage = language{
+	names: map[string]string{
+		"pi": formatFloat(math.Pi), "e": formatFloat(math.E), "nan": "NAN",
+		"exp": "exp", "log": "log", "sqrt": "sqrt", "sin": "sin", "cos": "cos",
+		"abs": "fabs", "gamma": "tgamma",
+	},
+	pow:            func(base, exponent string) string { return "pow(" + base + ", " + exponent + ")" },
+	reserved:       words(cKeywords + " exp log sqrt sin cos fabs tgamma pow NAN grad"),
+	gradientSuffix: "_gradient",
+	header: func(name string, params []string) string {
+		return fmt.Sprintf("double %s(%s) {\n", name, cParams(params))
+	},
+	gradient: func(name string, params []string) string {
+		return fmt.Sprintf("void %s(%s, double grad[%d]) {\n", name, cParams(params), len(params))
+	},
+	temporary:   "\tconst double %s = %s;\n",
+	returnValue: func(value string) string { return "\treturn " + value + ";\n" },
+	returnVector: func(components []string) string {
+		var sb strings.Builder
+		for ix, component := range components {
+			fmt.Fprintf(&sb, "\tgrad[%d] = %s;\n", ix, component)
+		}
+		return sb.String()
+	},
+	footer:        "}\n",
+	functionBreak: "\n",
+}
+
+func cParams(params []string) string {
+	if len(params) == 0 {
+		return "void"
+	}
+	return "double " + strings.Join(params, ", double ")
+}
+
+const pythonKeywords = `False None True and as assert async await break class continue def del elif else
+	except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield`
+
+var pythonLanguage = language{
+	names: map[string]string{
+		"pi": "math.pi", "e": "math.e", "nan": "math.nan",
+		"exp": "math.exp", "log": "math.log", "sqrt": "math.sqrt", "sin": "math.sin", "cos": "math.cos",
+		"abs": "abs", "gamma": "math.gamma",
+	},
+	unicode:        true,
+	reserved:       words(pythonKeywords + " math abs"),
+	gradientSuffix: "_gradient",
+	header: func(name string, params []string) string {
+		return fmt.Sprintf("def %s(%s):\n", name, strings.Join(params, ", "))
+	},
+	gradient: func(name string, params []string) string {
+		return fmt.Sprintf("def %s(%s):\n", name, strings.Join(params, ", "))
+	},
+	temporary:   "    %s = %s\n",
+	returnValue: func(value string) string { return "    return " + value + "\n" },
+	returnVector: func(components []string) string {
+		return "    return [" + strings.Join(components, ", ") + "]\n"
+	},
+	functionBreak: "\n\n",
+}
+
+var numPyLanguage = func() language {
+	numPy := pythonLanguage
+	numPy.names = map[string]string{
+		"pi": "np.pi", "e": "np.e", "nan": "np.nan",
+		"exp": "np.exp", "log": "np.log", "sqrt": "np.sqrt", "sin": "np.sin", "cos": "np.cos",
+		"abs": "np.abs", "gamma": "scipy.special.gamma",
+	}
+	numPy.reserved = words(pythonKeywords + " np scipy")
+	return numPy
+}()
+
+// Returns the set of the words in list.
+func words(list string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.Fields(list) {
+		set[word] = true
+	}
+	return set
+}
+
+func generate(lang language, expr gosymbol.Expr, funcName string, vars []gosymbol.VarName, opts Options) (string, error) {
+	params := make([]string, len(vars))
+	seen := map[gosymbol.VarName]bool{}
+	for ix, v := range vars {
+		if seen[v] {
+			return "", &gosymbol.DuplicateArgumentError{}
+		}
+		seen[v] = true
+		params[ix] = string(v)
+	}
+	for _, name := range append([]string{funcName}, params...) {
+		if !isIdentifier(name, lang.unicode) || lang.reserved[name] {
+			return "", &gosymbol.InvalidArgumentError{Function: "code generation", Arg: gosymbol.Var(gosymbol.VarName(name))}
+		}
+	}
+
+	var sb strings.Builder
+	g := newGraph(vars)
+	root, err := g.build(expr)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(lang.header(funcName, params))
+	p := newPrinter(lang, g, []int{root}, params)
+	sb.WriteString(p.temporaries())
+	sb.WriteString(lang.returnValue(p.string(root)))
+	sb.WriteString(lang.footer)
+
+	if !opts.Gradient || len(vars) == 0 {
+		return sb.String(), nil
+	}
+	g = newGraph(vars)
+	roots := make([]int, len(vars))
+	for ix, v := range vars {
+		if roots[ix], err = g.build(expr.D(gosymbol.Var(v)).Simplify()); err != nil {
+			return "", err
+		}
+	}
+	sb.WriteString(lang.functionBreak)
+	sb.WriteString(lang.gradient(funcName+lang.gradientSuffix, params))
+	p = newPrinter(lang, g, roots, params)
+	sb.WriteString(p.temporaries())
+	components := make([]string, len(roots))
+	for ix, root := range roots {
+		components[ix] = p.string(root)
+	}
+	sb.WriteString(lang.returnVector(components))
+	sb.WriteString(lang.footer)
+	return sb.String(), nil
+}
+
+func isIdentifier(name string, allowUnicode bool) bool {
+	for ix, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && ix > 0:
+		case allowUnicode && r > unicode.MaxASCII && (unicode.IsLetter(r) || ix > 0 && unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// The precedence levels of the printed operations.
+const (
+	precedenceSum = iota
+	precedenceProduct
+	precedenceUnary
+	precedencePower
+	precedenceAtom
+)
+
+type printer struct {
+	lang  language
+	g     *graph
+	temps map[int]string
+	order []int
+}
+
+/*
+Returns a printer of the nodes of g computing roots, naming the
+temporaries with a prefix that none of params starts with.
+*/
+func newPrinter(lang language, g *graph, roots []int, params []string) printer {
+	g.countUses(roots)
+	prefix := "t"
+	for collides := true; collides; {
+		collides = false
+		for _, param := range params {
+			if strings.HasPrefix(param, prefix) {
+				prefix += "_"
+				collides = true
+				break
+			}
+		}
+	}
+
+	p := printer{lang: lang, g: g, temps: map[int]string{}, order: g.temporaries()}
+	for ix, id := range p.order {
+		p.temps[id] = prefix + strconv.Itoa(ix)
+	}
+	return p
+}
+
+// Returns the definitions of the temporaries.
+func (p printer) temporaries() string {
+	var sb strings.Builder
+	for _, id := range p.order {
+		str, _ := p.print(id)
+		fmt.Fprintf(&sb, p.lang.temporary, p.temps[id], str)
+	}
+	return sb.String()
+}
+
+func (p printer) string(id int) string {
+	str, _ := p.wrap(id, precedenceSum)
+	return str
+}
+
+// Prints the node id, or its temporary, and parenthesizes it if it binds weaker than precedence.
+func (p printer) wrap(id int, precedence int) (string, int) {
+	if name, ok := p.temps[id]; ok {
+		return name, precedenceAtom
+	}
+	str, prec := p.print(id)
+	if prec < precedence {
+		return "(" + str + ")", precedenceAtom
+	}
+	return str, prec
+}
+
+func (p printer) operand(id int, precedence int) string {
+	str, _ := p.wrap(id, precedence)
+	return str
+}
+
+// Returns the node id together with its precedence.
+func (p printer) print(id int) (string, int) {
+	n := p.g.nodes[id]
+	switch n.op {
+	case opConst:
+		if n.symbol != "" {
+			return p.lang.names[n.symbol], precedenceAtom
+		}
+		return formatFloat(n.value), precedenceAtom
+	case opVar:
+		return n.symbol, precedenceAtom
+	case opAdd:
+		var sb strings.Builder
+		for ix, arg := range n.args {
+			switch {
+			case n.negated[ix] && ix == 0:
+				sb.WriteString("-" + p.operand(arg, precedenceProduct))
+			case n.negated[ix]:
+				sb.WriteString(" - " + p.operand(arg, precedenceProduct))
+			case ix == 0:
+				sb.WriteString(p.operand(arg, precedenceSum))
+			default:
+				sb.WriteString(" + " + p.operand(arg, precedenceSum))
+			}
+		}
+		return sb.String(), precedenceSum
+	case opMul:
+		factors := make([]string, len(n.args))
+		for ix, arg := range n.args {
+			factors[ix] = p.operand(arg, precedenceProduct)
+		}
+		str := strings.Join(factors, "*")
+		if len(factors) == 0 {
+			str = "1.0"
+		}
+		if len(n.den) == 1 {
+			str += "/" + p.operand(n.den[0], precedenceUnary)
+		} else if len(n.den) > 1 {
+			divisors := make([]string, len(n.den))
+			for ix, arg := range n.den {
+				divisors[ix] = p.operand(arg, precedenceProduct)
+			}
+			str += "/(" + strings.Join(divisors, "*") + ")"
+		}
+		return str, precedenceProduct
+	case opNeg:
+		return "-" + p.operand(n.args[0], precedenceProduct), precedenceUnary
+	case opPowInt:
+		base := p.operand(n.args[0], precedenceAtom)
+		return strings.TrimSuffix(strings.Repeat(base+"*", int(n.n)), "*"), precedenceProduct
+	case opPow:
+		if p.lang.pow != nil {
+			return p.lang.pow(p.string(n.args[0]), p.string(n.args[1])), precedenceAtom
+		}
+		return p.operand(n.args[0], precedenceAtom) + "**" + p.operand(n.args[1], precedenceUnary), precedencePower
+	}
+	args := make([]string, len(n.args))
+	for ix, arg := range n.args {
+		args[ix] = p.string(arg)
+	}
+	return p.lang.names[n.symbol] + "(" + strings.Join(args, ", ") + ")", precedenceAtom
+}
+
+// Formats value as a floating point literal, so integers are not divided as integers.
+func formatFloat(value float64) string {
+	str := strconv.FormatFloat(value, 'g', -1, 64)
+	if !strings.ContainsAny(str, ".e") {
+		str += ".0"
+	}
+	return str
+}
@@ -0,0 +1,351 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/victorbrun/gosymbol"
+)
+
+var (
+	x, y = gosymbol.Var("x"), gosymbol.Var("y")
+	vars = []gosymbol.VarName{"x", "y"}
+)
+
+func TestGo(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          gosymbol.Expr
+		expectedOutput []string
+	}{
+		{
+			name:  "Example from the documentation",
+			input: gosymbol.Add(gosymbol.Pow(gosymbol.Sin(x), gosymbol.Int(2)), gosymbol.Mul(y, gosymbol.Sin(x))),
+			expectedOutput: []string{
+				"func f(x, y float64) float64 {",
+				"\tt0 := math.Sin(x)",
+				"\treturn t0*t0 + y*t0",
+				"}",
+			},
+		},
+		{
+			name:  "Quotient and rational coefficient",
+			input: gosymbol.Sub(gosymbol.Div(gosymbol.Exp(gosymbol.Neg(x)), gosymbol.Add(gosymbol.Pow(x, gosymbol.Int(2)), gosymbol.Int(1))), gosymbol.Mul(gosymbol.Div(gosymbol.Int(3), gosymbol.Int(2)), y)),
+			expectedOutput: []string{
+				"func f(x, y float64) float64 {",
+				"\treturn math.Exp(-x)/(x*x + 1.0) - 3.0*y/2.0",
+				"}",
+			},
+		},
+		{
+			name:  "Powers",
+			input: gosymbol.Add(gosymbol.Pow(x, gosymbol.Int(-3)), gosymbol.Pow(x, gosymbol.Int(7)), gosymbol.Pow(y, gosymbol.Div(gosymbol.Int(-1), gosymbol.Int(2)))),
+			expectedOutput: []string{
+				"func f(x, y float64) float64 {",
+				"\treturn 1.0/(x*x*x) + math.Pow(x, 7.0) + 1.0/math.Sqrt(y)",
+				"}",
+			},
+		},
+		{
+			name:  "Power of a subexpression",
+			input: gosymbol.Pow(gosymbol.Add(x, y), gosymbol.Int(3)),
+			expectedOutput: []string{
+				"func f(x, y float64) float64 {",
+				"\tt0 := x + y",
+				"\treturn t0*t0*t0",
+				"}",
+			},
+		},
+		{
+			name:  "Constants and special functions",
+			input: gosymbol.Mul(gosymbol.PI, gosymbol.E, gosymbol.Abs(x), gosymbol.Factorial(y)),
+			expectedOutput: []string{
+				"func f(x, y float64) float64 {",
+				"\treturn math.Pi*math.E*math.Abs(x)*math.Gamma(y + 1.0)",
+				"}",
+			},
+		},
+		{
+			name:  "Negation",
+			input: gosymbol.Neg(gosymbol.Mul(x, gosymbol.Log(y))),
+			expectedOutput: []string{
+				"func f(x, y float64) float64 {",
+				"\treturn -x*math.Log(y)",
+				"}",
+			},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := Go(test.input, "f", vars)
+			if expected := strings.Join(test.expectedOutput, "\n") + "\n"; err != nil || result != expected {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected:\n%v\nGot:\n%v (error %v)", test.name, test.input, expected, result, err)
+			}
+		})
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	input := gosymbol.Add(gosymbol.Mul(gosymbol.Int(3), gosymbol.Pow(x, gosymbol.Int(2))), gosymbol.Pow(y, gosymbol.Div(gosymbol.Int(5), gosymbol.Int(2))))
+	opts := Options{Gradient: true}
+
+	tests := []struct {
+		name           string
+		generate       func(gosymbol.Expr, string, []gosymbol.VarName, Options) (string, error)
+		expectedOutput []string
+	}{
+		{
+			name:     "Go",
+			generate: GoWith,
+			expectedOutput: []string{
+				"func f(x, y float64) float64 {",
+				"\treturn 3.0*x*x + math.Pow(y, 2.5)",
+				"}",
+				"",
+				"func fGradient(x, y float64) [2]float64 {",
+				"\treturn [2]float64{6.0*x, 5.0*math.Pow(y, 1.5)/2.0}",
+				"}",
+			},
+		},
+		{
+			name:     "C",
+			generate: CWith,
+			expectedOutput: []string{
+				"double f(double x, double y) {",
+				"\treturn 3.0*x*x + pow(y, 2.5);",
+				"}",
+				"",
+				"void f_gradient(double x, double y, double grad[2]) {",
+				"\tgrad[0] = 6.0*x;",
+				"\tgrad[1] = 5.0*pow(y, 1.5)/2.0;",
+				"}",
+			},
+		},
+		{
+			name:     "Python",
+			generate: PythonWith,
+			expectedOutput: []string{
+				"def f(x, y):",
+				"    return 3.0*x*x + y**2.5",
+				"",
+				"",
+				"def f_gradient(x, y):",
+				"    return [6.0*x, 5.0*y**1.5/2.0]",
+			},
+		},
+		{
+			name:     "NumPy",
+			generate: NumPyWith,
+			expectedOutput: []string{
+				"def f(x, y):",
+				"    return 3.0*x*x + y**2.5",
+				"",
+				"",
+				"def f_gradient(x, y):",
+				"    return [6.0*x, 5.0*y**1.5/2.0]",
+			},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := test.generate(input, "f", vars, opts)
+			if expected := strings.Join(test.expectedOutput, "\n") + "\n"; err != nil || result != expected {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected:\n%v\nGot:\n%v (error %v)", test.name, input, expected, result, err)
+			}
+		})
+	}
+}
+
+func TestLanguageNames(t *testing.T) {
+	input := gosymbol.Add(gosymbol.Exp(x), gosymbol.Mul(gosymbol.PI, gosymbol.Gamma(x)), gosymbol.Undefined())
+
+	tests := []struct {
+		name           string
+		generate       func(gosymbol.Expr, string, []gosymbol.VarName) (string, error)
+		expectedOutput string
+	}{
+		{name: "C", generate: C, expectedOutput: "exp(x) + 3.141592653589793*tgamma(x) + NAN"},
+		{name: "Python", generate: Python, expectedOutput: "math.exp(x) + math.pi*math.gamma(x) + math.nan"},
+		{name: "NumPy", generate: NumPy, expectedOutput: "np.exp(x) + np.pi*scipy.special.gamma(x) + np.nan"},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := test.generate(input, "f", []gosymbol.VarName{"x"})
+			if err != nil || !strings.Contains(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot:\n%v (error %v)", test.name, input, test.expectedOutput, result, err)
+			}
+		})
+	}
+}
+
+func TestGradient(t *testing.T) {
+	input := gosymbol.Add(gosymbol.Pow(x, y), gosymbol.Abs(gosymbol.Sub(x, y)))
+	expected := []string{
+		"void f_gradient(double x, double y, double grad[2]) {",
+		"\tconst double t0 = pow(x, y);",
+		"\tconst double t1 = x - y;",
+		"\tconst double t2 = t1/fabs(t1);",
+		"\tgrad[0] = t0*y/x + t2;",
+		"\tgrad[1] = t0*log(x) - t2;",
+		"}",
+	}
+	result, err := CWith(input, "f", vars, Options{Gradient: true})
+	if err != nil || !strings.Contains(result, strings.Join(expected, "\n")) {
+		t.Errorf("Following test failed: gradient\nInput: %v\nExpected:\n%v\nGot:\n%v (error %v)", input, strings.Join(expected, "\n"), result, err)
+	}
+}
+
+// Type checks the Go source src, which uses the package math.
+func typeCheckGo(src string) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "generated.go", "package generated\n\nimport \"math\"\n\nvar _ = math.Pi\n\n"+src, 0)
+	if err != nil {
+		return err
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("generated", fset, []*ast.File{file}, nil)
+	return err
+}
+
+func TestGoCompiles(t *testing.T) {
+	input := gosymbol.Add(gosymbol.Pow(x, y), gosymbol.Mul(gosymbol.PI, gosymbol.Abs(gosymbol.Sub(x, y))), gosymbol.Pow(gosymbol.Sin(x), gosymbol.Int(2)))
+	for _, name := range []gosymbol.VarName{"x", "t0", "θ", "float", "f64"} {
+		v := gosymbol.Var(name)
+		expr := gosymbol.Substitute(input, x, v)
+		src, err := GoWith(expr, "f", []gosymbol.VarName{name, "y"}, Options{Gradient: true})
+		if err == nil {
+			err = typeCheckGo(src)
+		}
+		if err != nil {
+			t.Errorf("Following test failed: Go code for the variable %s\nGot:\n%v\nError: %v", name, src, err)
+		}
+	}
+
+	var argErr *gosymbol.InvalidArgumentError
+	for _, name := range []gosymbol.VarName{"float64", "len", "int", "nil", "true", "math"} {
+		if src, err := GoWith(gosymbol.Var(name), "f", []gosymbol.VarName{name}, Options{Gradient: true}); !errors.As(err, &argErr) {
+			t.Errorf("Following test failed: Go code for the variable %s\nExpected: InvalidArgumentError\nGot:\n%v (error %v)", name, src, err)
+		}
+	}
+}
+
+func TestTemporaryNames(t *testing.T) {
+	input := gosymbol.Mul(gosymbol.Sin(gosymbol.Var("t0")), gosymbol.Sin(gosymbol.Var("t0")))
+	expected := "func f(t0 float64) float64 {\n\tt_0 := math.Sin(t0)\n\treturn t_0*t_0\n}\n"
+	if result, err := Go(input, "f", []gosymbol.VarName{"t0"}); err != nil || result != expected {
+		t.Errorf("Following test failed: temporary names\nInput: %v\nExpected:\n%v\nGot:\n%v (error %v)", input, expected, result, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var unboundErr *gosymbol.UnboundVariableError
+	if _, err := Go(gosymbol.Add(x, gosymbol.Var("z")), "f", vars); !errors.As(err, &unboundErr) || unboundErr.Name != "z" {
+		t.Errorf("Following test failed: unbound variable\nExpected: UnboundVariableError\nGot: %v", err)
+	}
+	if _, err := C(gosymbol.Function("g", x), "f", vars); !errors.As(err, &unboundErr) || unboundErr.Name != "g" {
+		t.Errorf("Following test failed: undefined function\nExpected: UnboundVariableError\nGot: %v", err)
+	}
+	var notNumericErr *gosymbol.NotNumericError
+	if _, err := Python(gosymbol.Derivative(gosymbol.Function("g", x), x), "f", vars); !errors.As(err, &notNumericErr) {
+		t.Errorf("Following test failed: derivative\nExpected: NotNumericError\nGot: %v", err)
+	}
+	var duplicateErr *gosymbol.DuplicateArgumentError
+	if _, err := Go(x, "f", []gosymbol.VarName{"x", "x"}); !errors.As(err, &duplicateErr) {
+		t.Errorf("Following test failed: duplicate variables\nExpected: DuplicateArgumentError\nGot: %v", err)
+	}
+	var argErr *gosymbol.InvalidArgumentError
+	if _, err := C(gosymbol.Var("θ"), "f", []gosymbol.VarName{"θ"}); !errors.As(err, &argErr) {
+		t.Errorf("Following test failed: non-ASCII name in C\nExpected: InvalidArgumentError\nGot: %v", err)
+	}
+	if _, err := Go(x, "2f", vars); !errors.As(err, &argErr) {
+		t.Errorf("Following test failed: invalid function name\nExpected: InvalidArgumentError\nGot: %v", err)
+	}
+	if _, err := Go(x, "func", vars); !errors.As(err, &argErr) {
+		t.Errorf("Following test failed: keyword as function name\nExpected: InvalidArgumentError\nGot: %v", err)
+	}
+	if _, err := Python(gosymbol.Var("lambda"), "f", []gosymbol.VarName{"lambda"}); !errors.As(err, &argErr) {
+		t.Errorf("Following test failed: keyword as parameter\nExpected: InvalidArgumentError\nGot: %v", err)
+	}
+	if _, err := C(gosymbol.Var("sin"), "f", []gosymbol.VarName{"sin"}); !errors.As(err, &argErr) {
+		t.Errorf("Following test failed: parameter shadowing a library function\nExpected: InvalidArgumentError\nGot: %v", err)
+	}
+}
+
+// Evaluates the node id of g, to check the graph against EvalFloat.
+func evalNode(g *graph, id int, args map[gosymbol.VarName]float64) float64 {
+	n := g.nodes[id]
+	values := make([]float64, len(n.args))
+	for ix, arg := range n.args {
+		values[ix] = evalNode(g, arg, args)
+	}
+	switch n.op {
+	case opConst:
+		return map[string]float64{"": n.value, "pi": math.Pi, "e": math.E, "nan": math.NaN()}[n.symbol]
+	case opVar:
+		return args[gosymbol.VarName(n.symbol)]
+	case opAdd:
+		sum := 0.0
+		for ix, value := range values {
+			if n.negated[ix] {
+				value = -value
+			}
+			sum += value
+		}
+		return sum
+	case opMul:
+		prod := 1.0
+		for _, value := range values {
+			prod *= value
+		}
+		for _, arg := range n.den {
+			prod /= evalNode(g, arg, args)
+		}
+		return prod
+	case opNeg:
+		return -values[0]
+	case opPowInt:
+		return math.Pow(values[0], float64(n.n))
+	case opPow:
+		return math.Pow(values[0], values[1])
+	}
+	return map[string]func(float64) float64{
+		"exp": math.Exp, "log": math.Log, "sqrt": math.Sqrt, "sin": math.Sin, "cos": math.Cos,
+		"abs": math.Abs, "gamma": math.Gamma,
+	}[n.symbol](values[0])
+}
+
+func TestGraph(t *testing.T) {
+	tests := []gosymbol.Expr{
+		gosymbol.Sub(gosymbol.Div(gosymbol.Exp(gosymbol.Neg(x)), gosymbol.Add(gosymbol.Pow(x, gosymbol.Int(2)), gosymbol.Int(1))), gosymbol.Mul(gosymbol.Div(gosymbol.Int(-3), gosymbol.Int(2)), gosymbol.Pow(y, gosymbol.Div(gosymbol.Int(5), gosymbol.Int(2))))),
+		gosymbol.Mul(gosymbol.Div(gosymbol.Int(-2), gosymbol.Int(3)), gosymbol.Pow(gosymbol.Add(x, y), gosymbol.Int(-4)), gosymbol.Cos(gosymbol.Mul(x, y))),
+		gosymbol.Pow(gosymbol.Sqrt(x), gosymbol.Div(gosymbol.Int(-3), gosymbol.Int(2))),
+		gosymbol.Add(gosymbol.Int(-7), gosymbol.Neg(gosymbol.Log(gosymbol.Factorial(x))), gosymbol.Mul(gosymbol.PI, gosymbol.Abs(gosymbol.Sub(y, x)))),
+		gosymbol.Expand(gosymbol.Pow(gosymbol.Add(x, gosymbol.Sqrt(y)), gosymbol.Int(5))),
+	}
+	args := map[gosymbol.VarName]float64{"x": 1.3, "y": 0.7}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			g := newGraph(vars)
+			root, err := g.build(test)
+			if err != nil {
+				t.Fatalf("Following test failed: graph\nInput: %v\nUnexpected error: %v", test, err)
+			}
+			expected, _ := gosymbol.EvalFloat(test, args)
+			if result := evalNode(g, root, args); math.Abs(result-expected) > 1e-12*math.Max(1, math.Abs(expected)) {
+				t.Errorf("Following test failed: graph\nInput: %v\nExpected: %v\nGot: %v", test, expected, result)
+			}
+		})
+	}
+}
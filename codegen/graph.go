@@ -0,0 +1,323 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/victorbrun/gosymbol"
+)
+
+// The operations of the nodes of a graph.
+const (
+	opConst  = iota // a number, or the constant in symbol
+	opVar           // the variable in symbol
+	opAdd           // args summed, with the negated ones subtracted
+	opMul           // the product of args divided by the product of den
+	opNeg           // the negation of args[0]
+	opPowInt        // args[0] to the power of n, as repeated multiplication
+	opPow           // args[0] to the power of args[1]
+	opCall          // the elementary function in symbol applied to args
+)
+
+type node struct {
+	op      int
+	value   float64
+	symbol  string
+	args    []int
+	negated []bool
+	den     []int
+	n       int64
+}
+
+/*
+A graph holds the numeric operations computing one or more
+expressions. Equal subexpressions are only added once, so a node
+referred to more than once is a common subexpression, which is
+computed once into a temporary.
+*/
+type graph struct {
+	nodes []node
+	ids   map[string]int
+	vars  map[gosymbol.VarName]bool
+	uses  []int
+}
+
+func newGraph(vars []gosymbol.VarName) *graph {
+	g := &graph{ids: map[string]int{}, vars: map[gosymbol.VarName]bool{}}
+	for _, v := range vars {
+		g.vars[v] = true
+	}
+	return g
+}
+
+// Adds n unless an equal node exists, and returns its id.
+func (g *graph) add(n node) int {
+	key := fmt.Sprint(n.op, n.value, n.symbol, n.args, n.negated, n.den, n.n)
+	if id, ok := g.ids[key]; ok {
+		return id
+	}
+	g.nodes = append(g.nodes, n)
+	g.ids[key] = len(g.nodes) - 1
+	return len(g.nodes) - 1
+}
+
+func (g *graph) constant(value float64) int {
+	return g.add(node{op: opConst, value: value})
+}
+
+func (g *graph) call(function string, args ...int) int {
+	return g.add(node{op: opCall, symbol: function, args: args})
+}
+
+// Adds the operations computing expr and returns the id of its value.
+func (g *graph) build(expr gosymbol.Expr) (int, error) {
+	switch gosymbol.KindOf(expr) {
+	case gosymbol.KindUndefined:
+		return g.add(node{op: opConst, symbol: "nan"}), nil
+	case gosymbol.KindInteger, gosymbol.KindFraction:
+		num, den, _ := rationalValue(expr)
+		if num < 0 {
+			return g.add(node{op: opNeg, args: []int{g.constant(float64(-num) / float64(den))}}), nil
+		}
+		return g.constant(float64(num) / float64(den)), nil
+	case gosymbol.KindVariable:
+		name := gosymbol.NameOf(expr)
+		if g.vars[name] {
+			return g.add(node{op: opVar, symbol: string(name)}), nil
+		} else if name == gosymbol.NameOf(gosymbol.PI) {
+			return g.add(node{op: opConst, symbol: "pi"}), nil
+		}
+		return 0, &gosymbol.UnboundVariableError{Name: name}
+	case gosymbol.KindAdd:
+		return g.buildSum(expr)
+	case gosymbol.KindMul:
+		return g.buildProduct(operands(expr))
+	case gosymbol.KindPow:
+		return g.buildPower(expr)
+	case gosymbol.KindExp:
+		if gosymbol.Equal(expr, gosymbol.E) {
+			return g.add(node{op: opConst, symbol: "e"}), nil
+		}
+		return g.buildCall("exp", operands(expr)...)
+	case gosymbol.KindLog:
+		return g.buildCall("log", operands(expr)...)
+	case gosymbol.KindSqrt:
+		return g.buildCall("sqrt", operands(expr)...)
+	case gosymbol.KindSin:
+		return g.buildCall("sin", operands(expr)...)
+	case gosymbol.KindCos:
+		return g.buildCall("cos", operands(expr)...)
+	case gosymbol.KindFunction:
+		args := operands(expr)
+		if len(args) == 1 {
+			switch gosymbol.NameOf(expr) {
+			case "Abs":
+				return g.buildCall("abs", args...)
+			case "Gamma":
+				return g.buildCall("gamma", args...)
+			case "Factorial":
+				return g.buildCall("gamma", gosymbol.Add(args[0], gosymbol.Int(1)))
+			}
+		}
+		return 0, &gosymbol.UnboundVariableError{Name: gosymbol.NameOf(expr)}
+	}
+	return 0, &gosymbol.NotNumericError{Expr: expr}
+}
+
+func operands(expr gosymbol.Expr) []gosymbol.Expr {
+	ops := make([]gosymbol.Expr, gosymbol.NumberOfOperands(expr))
+	for ix := range ops {
+		ops[ix] = gosymbol.Operand(expr, ix+1)
+	}
+	return ops
+}
+
+func (g *graph) buildCall(function string, args ...gosymbol.Expr) (int, error) {
+	ids := make([]int, len(args))
+	for ix, arg := range args {
+		id, err := g.build(arg)
+		if err != nil {
+			return 0, err
+		}
+		ids[ix] = id
+	}
+	return g.call(function, ids...), nil
+}
+
+// Returns true if expr is a negative number or a product with a negative coefficient.
+func isNegative(expr gosymbol.Expr) bool {
+	if gosymbol.KindOf(expr) == gosymbol.KindMul {
+		expr = gosymbol.Operand(expr, 1)
+	}
+	num, _, ok := rationalValue(expr)
+	return ok && num < 0
+}
+
+// Adds a sum, where the terms with a negative coefficient are subtracted.
+func (g *graph) buildSum(expr gosymbol.Expr) (int, error) {
+	n := node{op: opAdd}
+	for _, term := range operands(expr) {
+		negated := isNegative(term)
+		if negated {
+			term = gosymbol.Neg(term)
+		}
+		id, err := g.build(term)
+		if err != nil {
+			return 0, err
+		}
+		n.args = append(n.args, id)
+		n.negated = append(n.negated, negated)
+	}
+	return g.add(n), nil
+}
+
+/*
+Adds a product as a quotient, where the factors with a negative
+exponent, and the denominators of the coefficients, are divided by.
+A negative coefficient becomes a negation of the quotient.
+*/
+func (g *graph) buildProduct(factors []gosymbol.Expr) (int, error) {
+	negative := false
+	n := node{op: opMul}
+	for _, factor := range factors {
+		if num, den, ok := rationalValue(factor); ok {
+			if num < 0 {
+				negative, num = !negative, -num
+			}
+			if num != 1 {
+				n.args = append(n.args, g.constant(float64(num)))
+			}
+			if den != 1 {
+				n.den = append(n.den, g.constant(float64(den)))
+			}
+			continue
+		}
+
+		if gosymbol.KindOf(factor) == gosymbol.KindPow {
+			base, exponent := gosymbol.Operand(factor, 1), gosymbol.Operand(factor, 2)
+			if num, den, ok := rationalValue(exponent); ok && num < 0 {
+				divisor := base
+				if num != -1 || den != 1 {
+					divisor = gosymbol.Pow(base, gosymbol.Div(gosymbol.Int(-num), gosymbol.Int(den)))
+				}
+				id, err := g.build(divisor)
+				if err != nil {
+					return 0, err
+				}
+				n.den = append(n.den, id)
+				continue
+			}
+		}
+		id, err := g.build(factor)
+		if err != nil {
+			return 0, err
+		}
+		n.args = append(n.args, id)
+	}
+
+	var id int
+	switch {
+	case len(n.args) == 0 && len(n.den) == 0:
+		id = g.constant(1)
+	case len(n.args) == 1 && len(n.den) == 0:
+		id = n.args[0]
+	default:
+		id = g.add(n)
+	}
+	if negative {
+		return g.add(node{op: opNeg, args: []int{id}}), nil
+	}
+	return id, nil
+}
+
+/*
+Adds a power, where small integer exponents become repeated
+multiplication and the exponents 1/2 and -1/2 square roots.
+*/
+func (g *graph) buildPower(expr gosymbol.Expr) (int, error) {
+	base, exponent := gosymbol.Operand(expr, 1), gosymbol.Operand(expr, 2)
+	num, den, isRational := rationalValue(exponent)
+	switch {
+	case isRational && num < 0:
+		return g.buildProduct([]gosymbol.Expr{expr})
+	case isRational && num == 0:
+		return g.constant(1), nil
+	case isRational && den == 1 && num == 1:
+		return g.build(base)
+	case isRational && den == 2 && num == 1:
+		return g.buildCall("sqrt", base)
+	}
+
+	b, err := g.build(base)
+	if err != nil {
+		return 0, err
+	}
+	if isRational && den == 1 && num <= maxIntegerPower {
+		return g.add(node{op: opPowInt, args: []int{b}, n: num}), nil
+	}
+	e, err := g.build(exponent)
+	if err != nil {
+		return 0, err
+	}
+	return g.add(node{op: opPow, args: []int{b, e}}), nil
+}
+
+// Integer powers up to this exponent are computed by repeated multiplication.
+const maxIntegerPower = 4
+
+/*
+Counts the references to the nodes computing roots. The operands of
+a node are only counted once however often the node is referred to,
+since it is computed once. The base of an integer power is referred
+to once per factor.
+*/
+func (g *graph) countUses(roots []int) {
+	g.uses = make([]int, len(g.nodes))
+	var visit func(id int, references int64)
+	visit = func(id int, references int64) {
+		visited := g.uses[id] > 0
+		g.uses[id] += int(references)
+		if visited {
+			return
+		}
+		n := g.nodes[id]
+		for _, arg := range n.args {
+			if n.op == opPowInt {
+				visit(arg, n.n)
+			} else {
+				visit(arg, 1)
+			}
+		}
+		for _, arg := range n.den {
+			visit(arg, 1)
+		}
+	}
+	for _, root := range roots {
+		visit(root, 1)
+	}
+}
+
+// Returns the ids of the nodes computed into temporaries, in an order where operands come first.
+func (g *graph) temporaries() []int {
+	var temps []int
+	for id := range g.nodes {
+		if g.uses[id] > 1 && !g.isAtomic(id) {
+			temps = append(temps, id)
+		}
+	}
+	return temps
+}
+
+// Returns true for numbers and variables, which are never computed into temporaries.
+func (g *graph) isAtomic(id int) bool {
+	n := g.nodes[id]
+	return n.op == opConst || n.op == opVar || n.op == opNeg && g.nodes[n.args[0]].op == opConst
+}
+
+// Returns the value of a rational number with a positive denominator.
+func rationalValue(expr gosymbol.Expr) (num, den int64, ok bool) {
+	num, den, ok = gosymbol.RationalValue(expr)
+	if den < 0 {
+		num, den = -num, -den
+	}
+	return num, den, ok
+}
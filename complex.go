@@ -0,0 +1,210 @@
+package gosymbol
+
+/*
+Constructs the complex number re + im*I, where re and im are
+typically rationals. The imaginary unit I is the principal square
+root of -1, so complex numbers are ordinary expressions that the
+simplifier, the polynomial routines and CompileComplex understand.
+*/
+func Complex(re, im Expr) Expr {
+	switch {
+	case Equal(im, Int(0)):
+		return re
+	case Equal(re, Int(0)):
+		return Mul(im, I)
+	}
+	return Add(re, Mul(im, I))
+}
+
+/*
+Returns the real and imaginary parts of z if it is of the form
+re + im*I where re and im can be shown to be real, e.g. by the
+assumptions on the variables in them. Only sums of terms that
+are real or real multiples of I are split, which is the form
+the simplifier leaves complex numbers in.
+*/
+func complexParts(z Expr) (Expr, Expr, bool) {
+	terms := []Expr{z}
+	if sum, ok := z.(add); ok {
+		terms = sum.Operands
+	}
+	re, im := []Expr{}, []Expr{}
+	for _, term := range terms {
+		if c, ok := imaginaryCoefficient(term); ok {
+			im = append(im, c)
+		} else {
+			re = append(re, term)
+		}
+	}
+	reExpr, imExpr := sumOf(re), sumOf(im)
+	if !Ask(reExpr, IsReal) || !Ask(imExpr, IsReal) {
+		return nil, nil, false
+	}
+	return reExpr, imExpr, true
+}
+
+// Returns c if term is c*I.
+func imaginaryCoefficient(term Expr) (Expr, bool) {
+	if Equal(term, I) {
+		return Int(1), true
+	}
+	product, ok := term.(mul)
+	if !ok {
+		return nil, false
+	}
+	for ix, factor := range product.Operands {
+		if Equal(factor, I) {
+			rest := append(append([]Expr{}, product.Operands[:ix]...), product.Operands[ix+1:]...)
+			if len(rest) == 1 {
+				return rest[0], true
+			}
+			return Mul(rest...), true
+		}
+	}
+	return nil, false
+}
+
+func sumOf(terms []Expr) Expr {
+	switch len(terms) {
+	case 0:
+		return Int(0)
+	case 1:
+		return terms[0]
+	}
+	return Add(terms...)
+}
+
+/*
+Evaluates Re, Im, Conjugate, Arg and Abs at arguments with known
+real and imaginary parts. The argument is only found for numbers
+on the axes and the diagonals, i.e. for multiples of π/4.
+*/
+func complexPartFunction(f FunctionApplication) (Expr, bool) {
+	if len(f.Args) != 1 {
+		return nil, false
+	}
+	re, im, ok := complexParts(f.Args[0])
+	if !ok {
+		return nil, false
+	}
+	switch f.Name {
+	case reName:
+		return re, true
+	case imName:
+		return im, true
+	case conjugateName:
+		if Equal(im, Int(0)) {
+			return re, true
+		}
+		return Sub(re, Mul(im, I)), true
+	case absName:
+		if !Equal(im, Int(0)) {
+			return Pow(Add(Pow(re, Int(2)), Pow(im, Int(2))), Div(Int(1), Int(2))), true
+		} else if Ask(re, IsNonnegative) {
+			return re, true
+		} else if Ask(re, IsNonpositive) {
+			return Neg(re), true
+		}
+	case argName:
+		return complexArg(re, im)
+	}
+	return nil, false
+}
+
+func complexArg(re, im Expr) (Expr, bool) {
+	quarter := func(n int64) Expr { return Mul(Div(Int(n), Int(4)), PI) }
+	switch {
+	case Equal(im, Int(0)) && Ask(re, IsPositive):
+		return Int(0), true
+	case Equal(im, Int(0)) && Ask(re, IsNegative):
+		return PI, true
+	case Equal(re, Int(0)) && Ask(im, IsPositive):
+		return quarter(2), true
+	case Equal(re, Int(0)) && Ask(im, IsNegative):
+		return quarter(-2), true
+	}
+
+	// On the diagonals re = ±im
+	a, okRe := re.(rational)
+	b, okIm := im.(rational)
+	if !okRe || !okIm || ratAbs(a).approx() != ratAbs(b).approx() {
+		return nil, false
+	}
+	n := int64(1)
+	if a.approx() < 0 {
+		n = 3
+	}
+	if b.approx() < 0 {
+		n = -n
+	}
+	return quarter(n), true
+}
+
+// Returns the real and imaginary parts of z if both are rationals.
+func gaussianParts(z Expr) (rational, rational, bool) {
+	re, im, ok := complexParts(z)
+	if !ok {
+		return nil, nil, false
+	}
+	a, okRe := re.(rational)
+	b, okIm := im.(rational)
+	return a, b, okRe && okIm
+}
+
+/*
+Multiplies the factors that are complex numbers with rational parts,
+and returns the real and imaginary parts of their product together with
+the other factors. The last return value is false unless there are at
+least two such factors, one of which is a sum a + b*I, so that products
+such as 2*I*x are left as they are.
+*/
+func gaussianProduct(factors []Expr) (rational, rational, []Expr, bool) {
+	var re, im rational = Int(1), Int(0)
+	count, sums := 0, 0
+	others := []Expr{}
+	for _, factor := range factors {
+		a, b, ok := gaussianParts(factor)
+		if !ok {
+			others = append(others, factor)
+			continue
+		}
+		if _, ok := factor.(add); ok {
+			sums++
+		}
+		count++
+		re, im = ratSubtract(ratMul(re, a), ratMul(im, b)), ratAdd(ratMul(re, b), ratMul(im, a))
+	}
+	return re, im, others, count > 1 && sums > 0
+}
+
+/*
+Returns the real and imaginary parts of (a + b*I)^n by repeated
+squaring, where negative powers are powers of the inverse
+(a - b*I)/(a^2 + b^2). The last return value is false if the
+parts might not fit in an int64.
+*/
+func gaussianPow(a, b rational, n integer) (rational, rational, bool) {
+	// The parts are bounded by (|a| + |b|)^n, and their
+	// denominators by (a^2 + b^2)^-n for negative n
+	bound := intAbs(n)
+	if n.value < 0 {
+		bound = intMul(bound, Int(2))
+	}
+	if ratPowOverflows(ratAdd(ratAbs(a), ratAbs(b)), bound) {
+		return nil, nil, false
+	}
+	if n.value < 0 {
+		norm := ratAdd(ratMul(a, a), ratMul(b, b))
+		a, b, n = ratDiv(a, norm), ratDiv(ratMinus(b), norm), intNeg(n)
+	}
+	var re, im rational = Int(1), Int(0)
+	for k := n.value; k > 0; k /= 2 {
+		if k%2 == 1 {
+			re, im = ratSubtract(ratMul(re, a), ratMul(im, b)), ratAdd(ratMul(re, b), ratMul(im, a))
+		}
+		if k > 1 {
+			a, b = ratSubtract(ratMul(a, a), ratMul(b, b)), ratMul(Int(2), ratMul(a, b))
+		}
+	}
+	return re, im, true
+}
@@ -0,0 +1,147 @@
+package gosymbol
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestComplexSimplify(t *testing.T) {
+	half := Div(Int(1), Int(2))
+	x := Real("x")
+	p := Var("p", Positive)
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput Expr
+	}{
+		{name: "I^2 = -1", input: Pow(I, Int(2)), expectedOutput: Int(-1)},
+		{name: "I*I = -1", input: Mul(I, I), expectedOutput: Int(-1)},
+		{name: "I^3 = -I", input: Pow(I, Int(3)), expectedOutput: Neg(I)},
+		{name: "I^-1 = -I", input: Pow(I, Int(-1)), expectedOutput: Neg(I)},
+		{name: "I^4 = 1", input: Pow(I, Int(4)), expectedOutput: Int(1)},
+		{name: "sqrt(-1) = I", input: Sqrt(Int(-1)), expectedOutput: I},
+		{name: "(-2)^(1/2) = 2^(1/2)*I", input: Pow(Int(-2), half), expectedOutput: Mul(I, Pow(Int(2), half))},
+		{name: "(-4)^(1/2) = 2*I", input: Pow(Int(-4), half), expectedOutput: Mul(Int(2), I)},
+		{name: "(-4)^(3/2) = -8*I", input: Pow(Int(-4), Div(Int(3), Int(2))), expectedOutput: Mul(Int(-8), I)},
+		{name: "(1 + 2I)*(3 - I) = 5 + 5I", input: Mul(Complex(Int(1), Int(2)), Complex(Int(3), Int(-1))), expectedOutput: Complex(Int(5), Int(5))},
+		{name: "2*(1 + I)*I = -2 + 2I", input: Mul(Int(2), Complex(Int(1), Int(1)), I), expectedOutput: Complex(Int(-2), Int(2))},
+		{name: "(1 + I)*x*(1 - I) = 2x", input: Mul(Complex(Int(1), Int(1)), x, Complex(Int(1), Int(-1))), expectedOutput: Mul(Int(2), x)},
+		{name: "2*I*x is kept", input: Mul(Int(2), I, x), expectedOutput: Mul(Int(2), I, x).Simplify()},
+		{name: "(1 + I)^2 = 2I", input: Pow(Complex(Int(1), Int(1)), Int(2)), expectedOutput: Mul(Int(2), I)},
+		{name: "(1 + I)^5 = -4 - 4I", input: Pow(Complex(Int(1), Int(1)), Int(5)), expectedOutput: Complex(Int(-4), Int(-4))},
+		{name: "1/(1 + I) = 1/2 - I/2", input: Div(Int(1), Complex(Int(1), Int(1))), expectedOutput: Complex(half, Div(Int(-1), Int(2)))},
+		{name: "(3 + 4I)/(1 - 2I) = -1 + 2I", input: Div(Complex(Int(3), Int(4)), Complex(Int(1), Int(-2))), expectedOutput: Complex(Int(-1), Int(2))},
+		{name: "exp(I*π) = -1", input: Exp(Mul(I, PI)), expectedOutput: Int(-1)},
+		{name: "exp(I*π/2) = I", input: Exp(Mul(half, I, PI)), expectedOutput: I},
+		{name: "exp(3*π*I) = -1", input: Exp(Mul(Int(3), PI, I)), expectedOutput: Int(-1)},
+		{name: "log(-1) = I*π", input: Log(Int(-1)), expectedOutput: Mul(I, PI)},
+		{name: "log(-2) = log(2) + I*π", input: Log(Int(-2)), expectedOutput: Add(Log(Int(2)), Mul(I, PI))},
+		{name: "log(I) = I*π/2", input: Log(I), expectedOutput: Mul(half, I, PI)},
+		{name: "Re(3 + 4I)", input: Re(Complex(Int(3), Int(4))), expectedOutput: Int(3)},
+		{name: "Im(3 + 4I)", input: Im(Complex(Int(3), Int(4))), expectedOutput: Int(4)},
+		{name: "Im(x + 2xI) for real x", input: Im(Add(x, Mul(Int(2), x, I))), expectedOutput: Mul(Int(2), x)},
+		{name: "Re((3 - I)*(1 + 2I))", input: Re(Mul(Complex(Int(3), Int(-1)), Complex(Int(1), Int(2)))), expectedOutput: Int(5)},
+		{name: "Im((1 + I)^2)", input: Im(Pow(Complex(Int(1), Int(1)), Int(2))), expectedOutput: Int(2)},
+		{name: "Abs((2 + I)*(2 - I))", input: Abs(Mul(Complex(Int(2), Int(1)), Complex(Int(2), Int(-1)))), expectedOutput: Int(5)},
+		{name: "Abs(1/(3 + 4I))", input: Abs(Div(Int(1), Complex(Int(3), Int(4)))), expectedOutput: Div(Int(1), Int(5))},
+		{name: "Re(z) for complex z", input: Re(Var("z")), expectedOutput: Re(Var("z"))},
+		{name: "Conjugate(3 + 4I)", input: Conjugate(Complex(Int(3), Int(4))), expectedOutput: Add(Int(3), Mul(Int(-4), I))},
+		{name: "Conjugate(x) for real x", input: Conjugate(x), expectedOutput: x},
+		{name: "Abs(3 + 4I)", input: Abs(Complex(Int(3), Int(4))), expectedOutput: Int(5)},
+		{name: "Abs(-3)", input: Abs(Int(-3)), expectedOutput: Int(3)},
+		{name: "Abs(-2p) for positive p", input: Abs(Mul(Int(-2), p)), expectedOutput: Mul(Int(2), p)},
+		{name: "Abs(x) for real x", input: Abs(x), expectedOutput: Abs(x)},
+		{name: "Arg(-2)", input: Arg(Int(-2)), expectedOutput: PI},
+		{name: "Arg(I)", input: Arg(I), expectedOutput: Mul(half, PI)},
+		{name: "Arg(-1 - I)", input: Arg(Complex(Int(-1), Int(-1))), expectedOutput: Mul(Div(Int(-3), Int(4)), PI)},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := test.input.Simplify()
+			if !Equal(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestSimplifyKeepsPrincipalBranch(t *testing.T) {
+	x, y, n := Var("x"), Var("y"), Var("n", Negative)
+
+	tests := []struct {
+		input Expr
+		args  map[VarName]complex128
+	}{
+		{input: Sqrt(Neg(x)), args: map[VarName]complex128{"x": -4}},
+		{input: Sqrt(Mul(Int(-2), x)), args: map[VarName]complex128{"x": -8}},
+		{input: Sqrt(Neg(n)), args: map[VarName]complex128{"n": -4}},
+		{input: Sqrt(Mul(x, y)), args: map[VarName]complex128{"x": -1, "y": -9}},
+		{input: Pow(Mul(Int(-8), x), Div(Int(1), Int(3))), args: map[VarName]complex128{"x": -1}},
+		{input: Pow(Mul(Int(-3), x, y), Div(Int(3), Int(2))), args: map[VarName]complex128{"x": 2, "y": -5}},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			simplified := test.input.Simplify()
+			expected, err1 := EvalComplex(test.input, test.args)
+			result, err2 := EvalComplex(simplified, test.args)
+			if err1 != nil || err2 != nil || cmplx.Abs(result-expected) > 1e-9*math.Max(1, cmplx.Abs(expected)) {
+				t.Errorf("Following test failed: %d\nInput: %v\nExpected: %v\nGot: %v = %v (errors %v, %v)", ix+1, test.input, expected, simplified, result, err1, err2)
+			}
+		})
+	}
+}
+
+func TestComplexArithmetic(t *testing.T) {
+	// (1 + 2I)*(3 - 2I) = 7 + 4I
+	result := Expand(Mul(Complex(Int(1), Int(2)), Complex(Int(3), Int(-2))))
+	expected := Complex(Int(7), Int(4))
+	if !Equal(result, expected) {
+		t.Errorf("Following test failed: complex product\nExpected: %v\nGot: %v", expected, result)
+	}
+}
+
+func TestCompileComplex(t *testing.T) {
+	x := Var("x")
+	z := Var("z")
+	tol := 1e-12
+
+	tests := []struct {
+		name     string
+		input    Expr
+		args     []complex128
+		expected complex128
+	}{
+		{name: "I", input: I, args: []complex128{0, 0}, expected: 1i},
+		{name: "exp(I*x) at π", input: Exp(Mul(I, x)), args: []complex128{math.Pi, 0}, expected: -1},
+		{name: "sqrt(x) at -4", input: Sqrt(x), args: []complex128{-4, 0}, expected: 2i},
+		{name: "log(x) at -1", input: Log(x), args: []complex128{-1, 0}, expected: complex(0, math.Pi)},
+		{name: "z^2 + 1 at I", input: Add(Pow(z, Int(2)), Int(1)), args: []complex128{0, 1i}, expected: 0},
+		{name: "Abs(z) at 3 + 4i", input: Abs(z), args: []complex128{0, 3 + 4i}, expected: 5},
+		{name: "Arg(z) at -1 - i", input: Arg(z), args: []complex128{0, -1 - 1i}, expected: complex(-3*math.Pi/4, 0)},
+		{name: "Conjugate(z)*z at 1 + 2i", input: Mul(Conjugate(z), z), args: []complex128{0, 1 + 2i}, expected: 5},
+		{name: "x^(1/3) at -8 is principal", input: Pow(x, Div(Int(1), Int(3))), args: []complex128{-8, 0}, expected: complex(1, math.Sqrt(3))},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			f, err := CompileComplex(test.input, []variable{x, z})
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nInput: %v\nUnexpected error: %v", test.name, test.input, err)
+			}
+			result := f(test.args)
+			if cmplx.Abs(result-test.expected) > tol {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expected, result)
+			}
+		})
+	}
+
+	value, err := EvalComplex(Add(Re(z), Im(z)), map[VarName]complex128{"z": 2 + 3i})
+	if err != nil || value != 5 {
+		t.Errorf("Following test failed: EvalComplex\nExpected: %v\nGot: %v (%v)", 5, value, err)
+	}
+}
@@ -3,6 +3,7 @@ package gosymbol
 import (
 	"errors"
 	"fmt"
+	"math/big"
 )
 
 func (u integer) String() string {
@@ -56,6 +57,15 @@ func intAbs(a integer) integer {
 	return a
 }
 
+var errIntOverflow = errors.New("integer overflow")
+
+/*
+Computes a^b by exponentiation by squaring. The intermediate powers
+are arbitrary precision integers, so an overflow is detected and
+returned as errIntOverflow instead of silently wrapping around. The
+loop stops as soon as the result can no longer fit in an int64,
+which keeps e.g. 2^(10^18) from being computed in full.
+*/
 func intPow(a integer, b integer) (integer, error) {
 	if a == Int(0) && b == Int(0) {
 		return integer{}, errors.New("Undefined 0^0")
@@ -63,17 +73,30 @@ func intPow(a integer, b integer) (integer, error) {
 	if b.value < 0 {
 		return integer{}, errors.New("exponent in intPow must be non negative")
 	}
-	if a == Int(0) {
-		return Int(0), nil
-	}
-	if b == Int(0) {
+	switch {
+	case a == Int(0) || a == Int(1):
+		return a, nil
+	case a == Int(-1) && b.value%2 == 0:
 		return Int(1), nil
+	case a == Int(-1):
+		return a, nil
 	}
-	partialPow, err := intPow(a, intSubtract(b, Int(1)))
-	if err != nil {
-		return integer{}, err
+
+	result, base := big.NewInt(1), big.NewInt(a.value)
+	for n := b.value; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			if result.Mul(result, base); !result.IsInt64() {
+				return integer{}, errIntOverflow
+			}
+		}
+		// With |a| > 1 the base is used again as long as n > 1
+		if n > 1 {
+			if base.Mul(base, base); !base.IsInt64() {
+				return integer{}, errIntOverflow
+			}
+		}
 	}
-	return intMul(partialPow, a), nil
+	return Int(result.Int64()), nil
 }
 
 func intSubtract(a integer, b integer) integer {
@@ -1,6 +1,7 @@
 package gosymbol
 
 import (
+	"errors"
 	"fmt"
 	"math"
 )
@@ -46,7 +47,7 @@ func (u fraction) simplifyRational() rational {
 		}
 		return q
 	}
-	gcd, err := gcd(u.numerator(), u.denominator())
+	gcd, err := gcd(intAbs(u.numerator()), u.denominator())
 	if err != nil {
 		return Undefined()
 	}
@@ -59,9 +60,6 @@ func (u fraction) simplifyRational() rational {
 }
 
 func ratMul(u rational, w rational) rational {
-	if w.numerator() == Int(0) {
-		return Undefined()
-	}
 	return Div(
 		intMul(u.numerator(), w.numerator()),
 		intMul(u.denominator(), w.denominator()),
@@ -72,6 +70,14 @@ func ratDiv(u rational, w rational) rational {
 	return ratMul(u, ratInv(w))
 }
 
+// Returns true if the numerator or denominator of u^n does not fit in an int64.
+func ratPowOverflows(u rational, n integer) bool {
+	exponent := intAbs(n)
+	_, err1 := intPow(u.numerator(), exponent)
+	_, err2 := intPow(u.denominator(), exponent)
+	return errors.Is(err1, errIntOverflow) || errors.Is(err2, errIntOverflow)
+}
+
 func ratPow(u rational, n integer) rational {
 	u = u.simplifyRational()
 	if n.value < 0 {
@@ -115,6 +115,16 @@ func TestSimplifyRational(t *testing.T) {
 			input:          Div(Int(0), Int(1)).(rational),
 			expectedOutput: Int(0),
 		},
+		{
+			name:           "simplify fraction 4/-6",
+			input:          Div(Int(4), Int(-6)).(rational),
+			expectedOutput: Div(Int(-2), Int(3)).(rational),
+		},
+		{
+			name:           "simplify fraction -2/6",
+			input:          Div(Int(-2), Int(6)).(rational),
+			expectedOutput: Div(Int(-1), Int(3)).(rational),
+		},
 		{
 			name:           "simplify empty fraction",
 			input:          Undefined(),
@@ -152,6 +162,24 @@ func TestRatMul(t *testing.T) {
 			input2:         Int(3),
 			expectedOutput: Int(6),
 		},
+		{
+			name:           "multiply by zero 3 * 0",
+			input1:         Int(3),
+			input2:         Int(0),
+			expectedOutput: Int(0),
+		},
+		{
+			name:           "multiply zero by a fraction 0 * 1/3",
+			input1:         Int(0),
+			input2:         Div(Int(1), Int(3)).(rational),
+			expectedOutput: Int(0),
+		},
+		{
+			name:           "multiply negative integer and fraction -1 * 1/3",
+			input1:         Int(-1),
+			input2:         Div(Int(1), Int(3)).(rational),
+			expectedOutput: Div(Int(-1), Int(3)).(rational),
+		},
 		{
 			name:           "multiply by empty fraction",
 			input1:         Div(Int(1), Int(2)).(rational),
@@ -503,6 +531,41 @@ func TestIntPow(t *testing.T) {
 			expectedOutput: integer{}, // Since negative exponents are not handled here, assuming an error is returned
 			expectedErr:    errors.New("exponent in intPow must be non negative"),
 		},
+		{
+			name:           "largest power of 3 in int64",
+			base:           Int(3),
+			exponent:       Int(39),
+			expectedOutput: Int(4052555153018976267),
+			expectedErr:    nil,
+		},
+		{
+			name:           "overflowing power 3^40",
+			base:           Int(3),
+			exponent:       Int(40),
+			expectedOutput: integer{},
+			expectedErr:    errIntOverflow,
+		},
+		{
+			name:           "smallest int64 (-2)^63",
+			base:           Int(-2),
+			exponent:       Int(63),
+			expectedOutput: Int(math.MinInt64),
+			expectedErr:    nil,
+		},
+		{
+			name:           "huge exponent overflows early",
+			base:           Int(2),
+			exponent:       Int(1000000000000000000),
+			expectedOutput: integer{},
+			expectedErr:    errIntOverflow,
+		},
+		{
+			name:           "huge odd power of -1",
+			base:           Int(-1),
+			exponent:       Int(1000000000000000001),
+			expectedOutput: Int(-1),
+			expectedErr:    nil,
+		},
 	}
 
 	for ix, test := range tests {
@@ -0,0 +1,245 @@
+package gosymbol
+
+/*
+Computes the LU decomposition of m with partial pivoting, i.e. returns a
+permutation matrix p, a unit lower triangular l and an upper triangular u
+such that p*m = l*u. Rows are only swapped when a pivot is zero, so for
+matrices with non-zero leading principal minors p is the identity. Singular
+matrices are decomposed as well, in which case u has zeros on the diagonal.
+*/
+func (m Matrix) LU() (Matrix, Matrix, Matrix, error) {
+	if m.rows != m.cols {
+		return Matrix{}, Matrix{}, Matrix{}, &NonSquareMatrixError{Rows: m.rows, Cols: m.cols}
+	}
+	kt := &kernelTable{}
+	u, ok := m.toRatFuncs(kt)
+	if !ok {
+		return Matrix{}, Matrix{}, Matrix{}, &NotNumericError{Expr: Undefined()}
+	}
+
+	n := m.rows
+	l := identityRatFuncs(n)
+	perm := make([]int, n)
+	for ix := range perm {
+		perm[ix] = ix
+	}
+
+	for k := 0; k < n; k++ {
+		pivot := k
+		for pivot < n && u[pivot][k].isZero() {
+			pivot++
+		}
+		if pivot == n {
+			// Nothing to eliminate in this column
+			continue
+		}
+		if pivot != k {
+			u[k], u[pivot] = u[pivot], u[k]
+			perm[k], perm[pivot] = perm[pivot], perm[k]
+			// The already computed multipliers follow their rows
+			for j := 0; j < k; j++ {
+				l[k][j], l[pivot][j] = l[pivot][j], l[k][j]
+			}
+		}
+
+		for i := k + 1; i < n; i++ {
+			factor, _ := u[i][k].div(u[k][k])
+			l[i][k] = factor
+			for j := k; j < n; j++ {
+				u[i][j] = u[i][j].sub(factor.mul(u[k][j]))
+			}
+		}
+	}
+
+	p := Zeros(n, n)
+	for ix, row := range perm {
+		p.entries[ix*n+row] = Int(1)
+	}
+	return p, matrixFromRatFuncs(l, n, kt), matrixFromRatFuncs(u, n, kt), nil
+}
+
+/*
+Computes the QR decomposition of m with the Gram-Schmidt process, i.e.
+returns q with orthonormal columns and an upper triangular r with positive
+diagonal such that m = q*r. The entries are assumed to be real, so no
+complex conjugates are taken, and the norms are exact square roots. The
+columns of m must be linearly independent, otherwise a SingularMatrixError
+is returned.
+*/
+func (m Matrix) QR() (Matrix, Matrix, error) {
+	kt := &kernelTable{}
+	a, ok := m.Transpose().toRatFuncs(kt)
+	if !ok {
+		return Matrix{}, Matrix{}, &NotNumericError{Expr: Undefined()}
+	}
+	if m.cols > m.rows {
+		return Matrix{}, Matrix{}, &SingularMatrixError{}
+	}
+
+	// The orthogonal (but not normalised) columns are computed first
+	// so that no square roots are involved in the projections
+	n := m.cols
+	orthogonal := make([][]ratFunc, n)
+	squaredNorms := make([]ratFunc, n)
+	r := zeroRatFuncs(n, n)
+	for j := 0; j < n; j++ {
+		u := append([]ratFunc{}, a[j]...)
+		for i := 0; i < j; i++ {
+			r[i][j] = dotRatFuncs(orthogonal[i], a[j])
+			coeff, _ := r[i][j].div(squaredNorms[i])
+			for k := range u {
+				u[k] = u[k].sub(coeff.mul(orthogonal[i][k]))
+			}
+		}
+		orthogonal[j] = u
+		squaredNorms[j] = dotRatFuncs(u, u)
+		if squaredNorms[j].isZero() {
+			return Matrix{}, Matrix{}, &SingularMatrixError{}
+		}
+	}
+
+	q := zeroRatFuncs(m.rows, n)
+	for j := 0; j < n; j++ {
+		norm := ratFuncSqrt(squaredNorms[j], kt)
+		for k := 0; k < m.rows; k++ {
+			q[k][j], _ = orthogonal[j][k].div(norm)
+		}
+		for i := j + 1; i < n; i++ {
+			r[j][i], _ = r[j][i].div(norm)
+		}
+		r[j][j] = norm
+	}
+	return matrixFromRatFuncs(q, n, kt), matrixFromRatFuncs(r, n, kt), nil
+}
+
+/*
+Computes the Cholesky decomposition of the symmetric positive definite m,
+i.e. returns a lower triangular l with positive diagonal such that
+m = l*l^T. Symbolic pivots are assumed to be positive, while a numeric
+pivot that is not results in a NotPositiveDefiniteError.
+*/
+func (m Matrix) Cholesky() (Matrix, error) {
+	kt, a, err := m.symmetricRatFuncs()
+	if err != nil {
+		return Matrix{}, err
+	}
+
+	n := m.rows
+	l := zeroRatFuncs(n, n)
+	for j := 0; j < n; j++ {
+		d := a[j][j]
+		for k := 0; k < j; k++ {
+			d = d.sub(l[j][k].mul(l[j][k]))
+		}
+		if c, ok := d.constant(); ok && !(c.approx() > 0) {
+			return Matrix{}, &NotPositiveDefiniteError{}
+		}
+		l[j][j] = ratFuncSqrt(d, kt)
+
+		for i := j + 1; i < n; i++ {
+			s := a[i][j]
+			for k := 0; k < j; k++ {
+				s = s.sub(l[i][k].mul(l[j][k]))
+			}
+			l[i][j], _ = s.div(l[j][j])
+		}
+	}
+	return matrixFromRatFuncs(l, n, kt), nil
+}
+
+/*
+Computes the LDL^T decomposition of the symmetric m, i.e. returns a unit
+lower triangular l and a diagonal d such that m = l*d*l^T. Unlike the
+Cholesky decomposition no square roots are needed and m may be indefinite,
+but a ZeroPivotError is returned if a pivot vanishes.
+*/
+func (m Matrix) LDL() (Matrix, Matrix, error) {
+	kt, a, err := m.symmetricRatFuncs()
+	if err != nil {
+		return Matrix{}, Matrix{}, err
+	}
+
+	n := m.rows
+	l := identityRatFuncs(n)
+	d := zeroRatFuncs(n, n)
+	for j := 0; j < n; j++ {
+		pivot := a[j][j]
+		for k := 0; k < j; k++ {
+			pivot = pivot.sub(l[j][k].mul(l[j][k]).mul(d[k][k]))
+		}
+		if pivot.isZero() {
+			return Matrix{}, Matrix{}, &ZeroPivotError{Index: j}
+		}
+		d[j][j] = pivot
+
+		for i := j + 1; i < n; i++ {
+			s := a[i][j]
+			for k := 0; k < j; k++ {
+				s = s.sub(l[i][k].mul(l[j][k]).mul(d[k][k]))
+			}
+			l[i][j], _ = s.div(pivot)
+		}
+	}
+	return matrixFromRatFuncs(l, n, kt), matrixFromRatFuncs(d, n, kt), nil
+}
+
+// Converts the entries of m into rational functions and
+// checks that m is square and symmetric.
+func (m Matrix) symmetricRatFuncs() (*kernelTable, [][]ratFunc, error) {
+	if m.rows != m.cols {
+		return nil, nil, &NonSquareMatrixError{Rows: m.rows, Cols: m.cols}
+	}
+	kt := &kernelTable{}
+	a, ok := m.toRatFuncs(kt)
+	if !ok {
+		return nil, nil, &NotNumericError{Expr: Undefined()}
+	}
+	for i := range a {
+		for j := 0; j < i; j++ {
+			if !a[i][j].equal(a[j][i]) {
+				return nil, nil, &NonSymmetricMatrixError{}
+			}
+		}
+	}
+	return kt, a, nil
+}
+
+// Returns the value of r and true if r is constant.
+func (r ratFunc) constant() (rational, bool) {
+	num, ok := r.num.constant()
+	if !ok {
+		return nil, false
+	}
+	den, ok := r.den.constant()
+	if !ok {
+		return nil, false
+	}
+	return ratDiv(num, den), true
+}
+
+func dotRatFuncs(u, v []ratFunc) ratFunc {
+	sum := constRatFunc(Int(0))
+	for ix := range u {
+		sum = sum.add(u[ix].mul(v[ix]))
+	}
+	return sum
+}
+
+func zeroRatFuncs(rows, cols int) [][]ratFunc {
+	a := make([][]ratFunc, rows)
+	for i := range a {
+		a[i] = make([]ratFunc, cols)
+		for j := range a[i] {
+			a[i][j] = constRatFunc(Int(0))
+		}
+	}
+	return a
+}
+
+func identityRatFuncs(n int) [][]ratFunc {
+	a := zeroRatFuncs(n, n)
+	for ix := range a {
+		a[ix][ix] = constRatFunc(Int(1))
+	}
+	return a
+}
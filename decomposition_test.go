@@ -0,0 +1,192 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMatrixLU(t *testing.T) {
+	a, b, c, d := Var("a"), Var("b"), Var("c"), Var("d")
+
+	tests := []struct {
+		name      string
+		input     [][]Expr
+		expectedP [][]Expr
+		expectedL [][]Expr
+		expectedU [][]Expr
+	}{
+		{
+			name:      "Integer 3x3 without pivoting",
+			input:     [][]Expr{{Int(4), Int(12), Int(-16)}, {Int(12), Int(37), Int(-43)}, {Int(-16), Int(-43), Int(98)}},
+			expectedP: [][]Expr{{Int(1), Int(0), Int(0)}, {Int(0), Int(1), Int(0)}, {Int(0), Int(0), Int(1)}},
+			expectedL: [][]Expr{{Int(1), Int(0), Int(0)}, {Int(3), Int(1), Int(0)}, {Int(-4), Int(5), Int(1)}},
+			expectedU: [][]Expr{{Int(4), Int(12), Int(-16)}, {Int(0), Int(1), Int(5)}, {Int(0), Int(0), Int(9)}},
+		},
+		{
+			name:      "Zero pivot requiring a row swap",
+			input:     [][]Expr{{Int(0), Int(1)}, {Int(1), Int(1)}},
+			expectedP: [][]Expr{{Int(0), Int(1)}, {Int(1), Int(0)}},
+			expectedL: [][]Expr{{Int(1), Int(0)}, {Int(0), Int(1)}},
+			expectedU: [][]Expr{{Int(1), Int(1)}, {Int(0), Int(1)}},
+		},
+		{
+			name:      "Symbolic 2x2",
+			input:     [][]Expr{{a, b}, {c, d}},
+			expectedP: [][]Expr{{Int(1), Int(0)}, {Int(0), Int(1)}},
+			expectedL: [][]Expr{{Int(1), Int(0)}, {Div(c, a), Int(1)}},
+			expectedU: [][]Expr{{a, b}, {Int(0), Sub(d, Div(Mul(b, c), a))}},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			expectedP, _ := NewMatrix(test.expectedP)
+			expectedL, _ := NewMatrix(test.expectedL)
+			expectedU, _ := NewMatrix(test.expectedU)
+			p, l, u, err := m.LU()
+			if err != nil || !matrixIsZero(t, p, expectedP) || !matrixIsZero(t, l, expectedL) || !matrixIsZero(t, u, expectedU) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: P = %v, L = %v, U = %v\nGot: P = %v, L = %v, U = %v, error: %v", test.name, m, expectedP, expectedL, expectedU, p, l, u, err)
+			}
+		})
+	}
+}
+
+func TestMatrixQR(t *testing.T) {
+	a, b := Var("a"), Var("b")
+
+	tests := []struct {
+		name  string
+		input [][]Expr
+	}{
+		{
+			name:  "Integer 3x3",
+			input: [][]Expr{{Int(12), Int(-51), Int(4)}, {Int(6), Int(167), Int(-68)}, {Int(-4), Int(24), Int(-41)}},
+		},
+		{
+			name:  "Irrational norms",
+			input: [][]Expr{{Int(1), Int(1)}, {Int(1), Int(0)}, {Int(0), Int(1)}},
+		},
+		{
+			name:  "Symbolic 2x2",
+			input: [][]Expr{{a, b}, {b, a}},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			q, r, err := m.QR()
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nInput: %v\nGot error: %v", test.name, m, err)
+			}
+			qr, _ := q.Mul(r)
+			qtq, _ := q.Transpose().Mul(q)
+			if !matrixIsZero(t, qr, m) || !matrixIsZero(t, qtq, Identity(m.Cols())) {
+				t.Errorf("Following test failed: %s\nInput: %v\nGot: Q = %v, R = %v", test.name, m, q, r)
+			}
+			for i := 0; i < r.Rows(); i++ {
+				for j := 0; j < i; j++ {
+					if !isZero(r.At(i, j)) {
+						t.Errorf("Following test failed: %s\nInput: %v\nR is not upper triangular: %v", test.name, m, r)
+					}
+				}
+			}
+		})
+	}
+
+	m, _ := NewMatrix([][]Expr{{Int(1), Int(2)}, {Int(2), Int(4)}})
+	if _, _, err := m.QR(); !errors.As(err, new(*SingularMatrixError)) {
+		t.Errorf("Expected SingularMatrixError for linearly dependent columns, got: %v", err)
+	}
+}
+
+func TestMatrixCholesky(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          [][]Expr
+		expectedOutput [][]Expr
+	}{
+		{
+			name:           "Integer 3x3",
+			input:          [][]Expr{{Int(4), Int(12), Int(-16)}, {Int(12), Int(37), Int(-43)}, {Int(-16), Int(-43), Int(98)}},
+			expectedOutput: [][]Expr{{Int(2), Int(0), Int(0)}, {Int(6), Int(1), Int(0)}, {Int(-8), Int(5), Int(3)}},
+		},
+		{
+			name:  "Irrational diagonal",
+			input: [][]Expr{{Int(2), Int(1)}, {Int(1), Int(2)}},
+			expectedOutput: [][]Expr{
+				{Pow(Int(2), Div(Int(1), Int(2))), Int(0)},
+				{Div(Int(1), Pow(Int(2), Div(Int(1), Int(2)))), Mul(Div(Int(1), Int(2)), Pow(Int(6), Div(Int(1), Int(2))))},
+			},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			expected, _ := NewMatrix(test.expectedOutput)
+			l, err := m.Cholesky()
+			if err != nil || !matrixIsZero(t, l, expected) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v, error: %v", test.name, m, expected, l, err)
+			}
+		})
+	}
+
+	indefinite, _ := NewMatrix([][]Expr{{Int(1), Int(2)}, {Int(2), Int(1)}})
+	if _, err := indefinite.Cholesky(); !errors.As(err, new(*NotPositiveDefiniteError)) {
+		t.Errorf("Expected NotPositiveDefiniteError, got: %v", err)
+	}
+	nonSymmetric, _ := NewMatrix([][]Expr{{Int(1), Int(2)}, {Int(3), Int(1)}})
+	if _, err := nonSymmetric.Cholesky(); !errors.As(err, new(*NonSymmetricMatrixError)) {
+		t.Errorf("Expected NonSymmetricMatrixError, got: %v", err)
+	}
+}
+
+func TestMatrixLDL(t *testing.T) {
+	a, b := Var("a"), Var("b")
+
+	tests := []struct {
+		name      string
+		input     [][]Expr
+		expectedL [][]Expr
+		expectedD [][]Expr
+	}{
+		{
+			name:      "Integer 3x3",
+			input:     [][]Expr{{Int(4), Int(12), Int(-16)}, {Int(12), Int(37), Int(-43)}, {Int(-16), Int(-43), Int(98)}},
+			expectedL: [][]Expr{{Int(1), Int(0), Int(0)}, {Int(3), Int(1), Int(0)}, {Int(-4), Int(5), Int(1)}},
+			expectedD: [][]Expr{{Int(4), Int(0), Int(0)}, {Int(0), Int(1), Int(0)}, {Int(0), Int(0), Int(9)}},
+		},
+		{
+			name:      "Indefinite",
+			input:     [][]Expr{{Int(1), Int(2)}, {Int(2), Int(1)}},
+			expectedL: [][]Expr{{Int(1), Int(0)}, {Int(2), Int(1)}},
+			expectedD: [][]Expr{{Int(1), Int(0)}, {Int(0), Int(-3)}},
+		},
+		{
+			name:      "Symbolic 2x2",
+			input:     [][]Expr{{a, b}, {b, a}},
+			expectedL: [][]Expr{{Int(1), Int(0)}, {Div(b, a), Int(1)}},
+			expectedD: [][]Expr{{a, Int(0)}, {Int(0), Sub(a, Div(Pow(b, Int(2)), a))}},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			expectedL, _ := NewMatrix(test.expectedL)
+			expectedD, _ := NewMatrix(test.expectedD)
+			l, d, err := m.LDL()
+			if err != nil || !matrixIsZero(t, l, expectedL) || !matrixIsZero(t, d, expectedD) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: L = %v, D = %v\nGot: L = %v, D = %v, error: %v", test.name, m, expectedL, expectedD, l, d, err)
+			}
+		})
+	}
+
+	zeroPivot, _ := NewMatrix([][]Expr{{Int(0), Int(1)}, {Int(1), Int(0)}})
+	if _, _, err := zeroPivot.LDL(); !errors.As(err, new(*ZeroPivotError)) {
+		t.Errorf("Expected ZeroPivotError, got: %v", err)
+	}
+}
@@ -34,6 +34,22 @@ func (e pow) D(v variable) Expr {
 	return differentiate(e, v).Simplify()
 }
 
+func (e sin) D(v variable) Expr {
+	return differentiate(e, v).Simplify()
+}
+
+func (e cos) D(v variable) Expr {
+	return differentiate(e, v).Simplify()
+}
+
+func (e FunctionApplication) D(v variable) Expr {
+	return differentiate(e, v).Simplify()
+}
+
+func (e derivative) D(v variable) Expr {
+	return differentiate(e, v).Simplify()
+}
+
 // D(sqrt(f)) = (1/2)*(1/sqrt(f))*D(f)
 func (e sqrt) D(v variable) Expr {
 	return differentiate(e, v).Simplify()
@@ -49,7 +65,7 @@ func differentiate(expr Expr, v variable) Expr {
 	case fraction:
 		return Int(0)
 	case variable:
-		if v == e {
+		if v.Name == e.Name {
 			return Int(1)
 		} else {
 			return Int(0)
@@ -75,7 +91,7 @@ func differentiate(expr Expr, v variable) Expr {
 
 	case pow:
 		// IF EXPONENT IS CONSTANT: Power rule: D(x^a) = ax^(a-1)
-		// IF EXPONENT IS NOT CONSTANT: Exponential deriv: D(f^g) = D(exp(g*log(f))) = exp(g*log(f))*D(g*log(f))
+		// IF EXPONENT IS NOT CONSTANT: Exponential deriv: D(f^g) = D(exp(g*log(f))) = f^g*D(g*log(f))
 		switch exponentTyped := e.Exponent.(type) {
 		case integer:
 			return Mul(e.Exponent, Pow(e.Base, intAdd(exponentTyped, Int(-1))), differentiate(e.Base, v))
@@ -83,7 +99,7 @@ func differentiate(expr Expr, v variable) Expr {
 			return Mul(e.Exponent, Pow(e.Base, ratAdd(exponentTyped, Int(-1))), differentiate(e.Base, v))
 		default:
 			exponentLogBaseProd := Mul(e.Exponent, Log(e.Base))
-			return Mul(Exp(exponentLogBaseProd), differentiate(exponentLogBaseProd, v))
+			return Mul(e, differentiate(exponentLogBaseProd, v))
 		}
 	case exp:
 		return Mul(e, differentiate(e.Arg, v))
@@ -91,6 +107,33 @@ func differentiate(expr Expr, v variable) Expr {
 	case log:
 		return Mul(Pow(e.Arg, Int(-1)), differentiate(e.Arg, v))
 
+	case sqrt:
+		return Mul(Div(Int(1), Int(2)), Pow(e, Int(-1)), differentiate(e.Arg, v))
+
+	case sin:
+		return Mul(Cos(e.Arg), differentiate(e.Arg, v))
+
+	case cos:
+		return Mul(Int(-1), Sin(e.Arg), differentiate(e.Arg, v))
+
+	case FunctionApplication:
+		if !RecContains(e, v) {
+			return Int(0)
+		}
+		// D(|f|) = f/|f|*D(f), undefined where f = 0
+		if e.Name == absName && len(e.Args) == 1 {
+			return Mul(e.Args[0], Pow(e, Int(-1)), differentiate(e.Args[0], v))
+		}
+		// Nothing is known about the function, so
+		// the derivative is kept unevaluated
+		return Derivative(e, v)
+
+	case derivative:
+		if !RecContains(e.Arg, v) {
+			return Int(0)
+		}
+		return Derivative(e, v)
+
 	default:
 		errMsg := fmt.Errorf("ERROR: expression %#v have no differentiation pattern case implemented", e)
 		panic(errMsg)
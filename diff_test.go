@@ -65,6 +65,30 @@ func TestD(t *testing.T) {
 			},
 			expectedOutput: Mul(Int(2), Var("X")),
 		},
+		{ // Test 7
+			name: "Diff of square root",
+			input: inputArgs{
+				expr:    Sqrt(Var("X")),
+				diffVar: Var("X"),
+			},
+			expectedOutput: Mul(Div(Int(1), Int(2)), Pow(Var("X"), Div(Int(-1), Int(2)))),
+		},
+		{ // Test 8
+			name: "Diff of power with variable exponent",
+			input: inputArgs{
+				expr:    Pow(Var("X"), Var("Y")),
+				diffVar: Var("Y"),
+			},
+			expectedOutput: Mul(Pow(Var("X"), Var("Y")), Log(Var("X"))).Simplify(),
+		},
+		{ // Test 9
+			name: "Diff of absolute value",
+			input: inputArgs{
+				expr:    Abs(Var("X")),
+				diffVar: Var("X"),
+			},
+			expectedOutput: Div(Var("X"), Abs(Var("X"))).Simplify(),
+		},
 	}
 
 	for ix, test := range tests {
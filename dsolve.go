@@ -0,0 +1,891 @@
+package gosymbol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An initial condition D^Order f(At) = Value for DSolve, e.g.
+// InitialCondition{At: Int(0), Order: 1, Value: Int(2)} for f'(0) = 2.
+type InitialCondition struct {
+	At    Expr
+	Order int
+	Value Expr
+}
+
+/*
+Solves the ordinary differential equation eq = 0 for f, which must be an
+undefined function applied to x alone. The derivatives of f are written as
+Derivative(f, x), nested for higher orders, and eq may contain them in any
+form the chain rule can reduce, e.g. D(f^2, x).
+
+The following kinds of equations are solved:
+
+  - linear equations with constant coefficients of any order, through the
+    roots of the characteristic polynomial, where the particular solution
+    is found by undetermined coefficients or variation of parameters,
+  - first order linear equations f' + P(x)*f = Q(x), with the integrating
+    factor exp(∫P),
+  - Bernoulli equations f' + P(x)*f = Q(x)*f^n, through v = f^(1-n),
+  - separable equations f' = g(x)*h(f), and
+  - exact equations M(x, f) + N(x, f)*f' = 0 with D(M, f) = D(N, x).
+
+The general solution contains the integration constants C1, C2, ... which
+are determined by the initial conditions, if given. Constants that are not
+determined by the conditions are kept.
+
+If the solution of a separable or exact equation can not be solved for f
+an ImplicitSolutionError holding the solution F(x, f(x)) = 0 is returned,
+and if eq is of none of the kinds above an UnsupportedODEError is returned.
+*/
+func DSolve(eq Expr, f FunctionApplication, x variable, conditions ...InitialCondition) (Expr, error) {
+	if len(f.Args) != 1 || !Equal(f.Args[0], x) {
+		return nil, &UnsupportedODEError{Eq: eq}
+	}
+	o := &odeProblem{eq: eq, f: f, x: x}
+	reduced, ok := o.replaceDerivatives(eq)
+	if !ok || len(o.ys) < 2 {
+		return nil, &UnsupportedODEError{Eq: eq}
+	}
+
+	var solution Expr
+	var err error
+	if a, g, ok := o.constantCoefficients(reduced); ok {
+		for _, c := range conditions {
+			if c.Order < 0 || c.Order >= len(a)-1 {
+				return nil, &InvalidInitialConditionError{Order: c.Order, ODEOrder: len(a) - 1}
+			}
+		}
+		solution, err = o.solveConstantCoefficients(a, g)
+	} else if len(o.ys) == 2 {
+		for _, c := range conditions {
+			if c.Order != 0 {
+				return nil, &InvalidInitialConditionError{Order: c.Order, ODEOrder: 1}
+			}
+		}
+		solution, err = o.solveFirstOrder(reduced, conditions)
+	} else {
+		return nil, &UnsupportedODEError{Eq: eq}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	solution, err = o.applyConditions(solution, conditions)
+	if err != nil {
+		return nil, err
+	}
+	return tidy(solution), nil
+}
+
+// An ordinary differential equation where f and its derivatives
+// have been replaced by the variables ys[0], ys[1], ...
+type odeProblem struct {
+	eq        Expr
+	f         FunctionApplication
+	x         variable
+	ys        []variable
+	constants []variable
+}
+
+// Returns the variable standing in for the k:th derivative of f.
+func (o *odeProblem) y(k int) variable {
+	for len(o.ys) <= k {
+		name := VarName(string(o.f.Name) + strings.Repeat("'", len(o.ys)))
+		o.ys = append(o.ys, freshVariable(name, o.eq))
+	}
+	return o.ys[k]
+}
+
+// Returns the k:th integration constant, counting from one.
+func (o *odeProblem) constant(k int) variable {
+	for len(o.constants) < k {
+		name := VarName(fmt.Sprintf("C%d", len(o.constants)+1))
+		o.constants = append(o.constants, freshVariable(name, o.eq))
+	}
+	return o.constants[k-1]
+}
+
+// Replaces f and its derivatives in expr by the variables of o. False is
+// returned if expr contains any other undefined function or derivative.
+func (o *odeProblem) replaceDerivatives(expr Expr) (Expr, bool) {
+	switch e := expr.(type) {
+	case FunctionApplication:
+		if Equal(e, o.f) {
+			return o.y(0), true
+		}
+		return nil, false
+	case derivative:
+		if !Equal(e.Var, o.x) {
+			return nil, false
+		}
+		order, arg := 1, e.Arg
+		for {
+			d, ok := arg.(derivative)
+			if !ok || !Equal(d.Var, o.x) {
+				break
+			}
+			order, arg = order+1, d.Arg
+		}
+		if Equal(arg, o.f) {
+			return o.y(order), true
+		}
+		// Derivatives of other expressions are
+		// expanded with the chain rule
+		return o.replaceDerivatives(differentiate(e.Arg, o.x))
+	}
+
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		op, ok := o.replaceDerivatives(Operand(expr, ix))
+		if !ok {
+			return nil, false
+		}
+		expr = replaceOperand(expr, ix, op)
+	}
+	return expr, true
+}
+
+/*
+Returns the coefficients a_0, ..., a_n and the right hand side g if eq is
+a_n*y^(n) + ... + a_0*y = g with constant a_k and a_n != 0, where n is
+the highest order whose coefficient does not vanish.
+*/
+func (o *odeProblem) constantCoefficients(eq Expr) ([]Expr, Expr, bool) {
+	expanded := Expand(expandExp(eq))
+	a := make([]Expr, len(o.ys))
+	g := expanded
+	for k, y := range o.ys {
+		a[k] = Cancel(differentiate(expanded, y))
+		if RecContains(a[k], o.x) {
+			return nil, nil, false
+		}
+		for _, w := range o.ys {
+			if RecContains(a[k], w) {
+				return nil, nil, false
+			}
+		}
+		g = Substitute(g, y, Int(0))
+	}
+
+	n := len(a) - 1
+	for n > 0 && isZero(a[n]) {
+		n--
+	}
+	if n == 0 {
+		return nil, nil, false
+	}
+	return a[:n+1], Cancel(Neg(g)), true
+}
+
+/* Linear equations with constant coefficients */
+
+// A root re + im*i of a characteristic polynomial.
+type characteristicRoot struct {
+	re, im       Expr
+	multiplicity int
+}
+
+/*
+Solves sum_k a_k*y^(k) = g. Each real root r of multiplicity m of the
+characteristic polynomial gives the solutions x^j*exp(r*x) for j < m,
+and each pair of complex roots re ± im*i gives x^j*exp(re*x)*cos(im*x)
+and x^j*exp(re*x)*sin(im*x).
+*/
+func (o *odeProblem) solveConstantCoefficients(a []Expr, g Expr) (Expr, error) {
+	lambda := freshVariable("λ", o.eq)
+	terms := make([]Expr, len(a))
+	for k, c := range a {
+		terms[k] = Mul(c, Pow(lambda, Int(int64(k))))
+	}
+	roots, err := polynomialRoots(Add(terms...), lambda, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	charRoots := make([]characteristicRoot, len(roots))
+	basis := []Expr{}
+	for ix, r := range roots {
+		re, im, ok := splitComplex(r.value)
+		if !ok {
+			return nil, &UnsupportedODEError{Eq: o.eq}
+		}
+		charRoots[ix] = characteristicRoot{re: re, im: im, multiplicity: r.multiplicity}
+
+		growth := expandExp(Exp(Mul(re, o.x))).Simplify()
+		for j := 0; j < r.multiplicity; j++ {
+			power := Pow(o.x, Int(int64(j)))
+			if isZero(im) {
+				basis = append(basis, Mul(power, growth).Simplify())
+			} else if !isNegativeForm(Expand(im)) {
+				// Only one root of each conjugate pair is used
+				basis = append(basis,
+					Mul(power, growth, Cos(Mul(im, o.x))).Simplify(),
+					Mul(power, growth, Sin(Mul(im, o.x))).Simplify())
+			}
+		}
+	}
+
+	terms = make([]Expr, len(basis))
+	for ix, y := range basis {
+		terms[ix] = Mul(o.constant(ix+1), y)
+	}
+	homogeneous := Add(terms...)
+	if isZero(g) {
+		return homogeneous, nil
+	}
+
+	particular, ok := o.undeterminedCoefficients(a, g, charRoots)
+	if !ok {
+		particular, ok = o.variationOfParameters(a, g, basis)
+	}
+	if !ok {
+		return nil, &UnsupportedODEError{Eq: o.eq}
+	}
+	return Add(homogeneous, particular), nil
+}
+
+/*
+Returns re and im such that r = re + im*i, where the imaginary unit
+comes from the square root of a negative number, as in the complex
+roots returned by polynomialRoots. False is returned if r contains
+more than one such square root.
+*/
+func splitComplex(r Expr) (Expr, Expr, bool) {
+	kt := &kernelTable{}
+	rf, ok := toRatFunc(r, kt)
+	if !ok {
+		return nil, nil, false
+	}
+	imaginary, scale := -1, Expr(nil)
+	for ix, rad := range kt.radicals {
+		c, ok := rad.base.constant()
+		if !ok || rad.degree != 2 || c.numerator().value >= 0 {
+			continue
+		} else if imaginary >= 0 {
+			return nil, nil, false
+		}
+		imaginary, scale = ix, Pow(ratMinus(c), Div(Int(1), Int(2)))
+	}
+	if imaginary < 0 {
+		return r, Int(0), true
+	} else if rf.den.degreeIn(imaginary) > 0 {
+		return nil, nil, false
+	}
+	re := newRatFunc(rf.num.coefficientIn(imaginary, 0), rf.den, kt).toExpr(kt)
+	im := newRatFunc(rf.num.coefficientIn(imaginary, 1), rf.den, kt).toExpr(kt)
+	return re, Cancel(Mul(im, scale)), true
+}
+
+// A term of the right hand side of the form x^k * exp(rate*x) * trig(arg)
+// with trig sin or cos, or without the trigonometric factor if arg is nil.
+type forcingTerm struct {
+	degree    int
+	rate, arg Expr
+}
+
+/*
+Finds a particular solution of sum_k a_k*y^(k) = g by the method of
+undetermined coefficients. This requires every term of g to be of the form
+c*x^k*exp(a*x)*sin(b*x + d), or with cos instead of sin or without the
+trigonometric factor. For each such family the trial solution is
+
+	x^s * exp(a*x) * sum_{j<=k} x^j*(A_j*cos(b*x + d) + B_j*sin(b*x + d))
+
+where s is the multiplicity of a + b*i as a root of the characteristic
+polynomial. The unknown coefficients are found by equating coefficients.
+*/
+func (o *odeProblem) undeterminedCoefficients(a []Expr, g Expr, roots []characteristicRoot) (Expr, bool) {
+	expanded := Expand(linearizeTrig(expandExp(g)))
+	terms := []Expr{expanded}
+	if sum, ok := expanded.(add); ok {
+		terms = sum.Operands
+	}
+
+	// The families of forcing terms, keeping the highest degree
+	families := []forcingTerm{}
+	for _, t := range terms {
+		ft, ok := o.forcingTerm(t)
+		if !ok {
+			return nil, false
+		}
+		found := false
+		for ix, family := range families {
+			if isZero(Sub(family.rate, ft.rate)) && ((family.arg == nil && ft.arg == nil) ||
+				(family.arg != nil && ft.arg != nil && isZero(Sub(family.arg, ft.arg)))) {
+				families[ix].degree = max(family.degree, ft.degree)
+				found = true
+			}
+		}
+		if !found {
+			families = append(families, ft)
+		}
+	}
+
+	unknowns := []variable{}
+	unknown := func() variable {
+		u := freshVariable(VarName(fmt.Sprintf("A%d", len(unknowns))), o.eq, g)
+		unknowns = append(unknowns, u)
+		return u
+	}
+	trial := []Expr{}
+	for _, family := range families {
+		var b Expr = Int(0)
+		if family.arg != nil {
+			b, _ = linearCoefficient(family.arg, o.x)
+		}
+		s := 0
+		for _, r := range roots {
+			if isZero(Sub(r.re, family.rate)) && (isZero(Sub(r.im, b)) || isZero(Add(r.im, b))) {
+				s = r.multiplicity
+			}
+		}
+		growth := expandExp(Exp(Mul(family.rate, o.x)))
+		for j := 0; j <= family.degree; j++ {
+			power := Pow(o.x, Int(int64(j+s)))
+			if family.arg == nil {
+				trial = append(trial, Mul(unknown(), power, growth))
+			} else {
+				trial = append(trial,
+					Mul(unknown(), power, growth, Cos(family.arg)),
+					Mul(unknown(), power, growth, Sin(family.arg)))
+			}
+		}
+	}
+	var ansatz Expr = Add(trial...)
+
+	// Applies the differential operator to the ansatz
+	lhs := []Expr{}
+	derivative := Expand(ansatz)
+	for k, c := range a {
+		if k > 0 {
+			derivative = Expand(expandExp(differentiate(derivative, o.x)))
+		}
+		lhs = append(lhs, Mul(c, derivative))
+	}
+	residual := Sub(Add(lhs...), expanded)
+
+	values, ok := solveIdentity(residual, unknowns, o.x)
+	if !ok {
+		return nil, false
+	}
+	for ix, u := range unknowns {
+		ansatz = Substitute(ansatz, u, values[ix])
+	}
+	return Expand(ansatz), true
+}
+
+// Splits an expanded term into the form c*x^k*exp(rate*x)*trig(arg).
+func (o *odeProblem) forcingTerm(term Expr) (forcingTerm, bool) {
+	_, factors := splitConstantFactors(term, o.x)
+	ft := forcingTerm{rate: Int(0)}
+	for _, f := range factors {
+		if k, ok := monomialDegree(f, o.x); ok && k > 0 {
+			ft.degree += k
+		} else if rate, ok := exponentialRate(f, o.x); ok {
+			ft.rate = Add(ft.rate, rate)
+		} else if trig, ok := f.(sin); ok && ft.arg == nil {
+			ft.arg = trig.Arg
+		} else if trig, ok := f.(cos); ok && ft.arg == nil {
+			ft.arg = trig.Arg
+		} else {
+			return forcingTerm{}, false
+		}
+	}
+	if ft.arg != nil {
+		if _, ok := linearCoefficient(ft.arg, o.x); !ok {
+			return forcingTerm{}, false
+		}
+	}
+	ft.rate = Cancel(ft.rate)
+	return ft, true
+}
+
+/*
+Returns values of the unknowns making residual identically zero in x,
+where residual is linear in the unknowns. The coefficients of the distinct
+products of powers of x, exp, sin and cos of x are equated to zero, and
+the resulting linear system is solved with unknowns that are not determined
+set to zero. False is returned if the system has no solution.
+*/
+func solveIdentity(residual Expr, unknowns []variable, x variable) ([]Expr, bool) {
+	kt := &kernelTable{}
+	p, ok := toPolynomial(residual, kt)
+	if !ok {
+		return nil, false
+	}
+	p = kt.reduce(p)
+
+	column := map[int]int{}
+	for ix, u := range unknowns {
+		column[kt.index(u)] = ix
+	}
+
+	// Each distinct monomial in the kernels depending on x
+	// gives an equation, with one column per unknown and the
+	// constant term in the last column
+	rows := map[string]int{}
+	entries := [][]polynomial{}
+	for _, t := range p {
+		key := monomial{}
+		rest := monomial{}
+		col := len(unknowns)
+		for kx, e := range t.mono {
+			if e == 0 {
+				continue
+			}
+			if c, ok := column[kx]; ok {
+				if e != 1 || col != len(unknowns) {
+					return nil, false
+				}
+				col = c
+			} else if RecContains(kt.kernels[kx], x) {
+				key = append(key, make(monomial, kx+1-len(key))...)
+				key[kx] = e
+			} else {
+				rest = append(rest, make(monomial, kx+1-len(rest))...)
+				rest[kx] = e
+			}
+		}
+		row, ok := rows[key.trim().key()]
+		if !ok {
+			row = len(entries)
+			rows[key.trim().key()] = row
+			entries = append(entries, make([]polynomial, len(unknowns)+1))
+		}
+		if entries[row][col] == nil {
+			entries[row][col] = polynomial{}
+		}
+		entries[row][col].addTerm(polyTerm{coeff: t.coeff, mono: rest.trim()})
+	}
+
+	augmented := make([][]Expr, len(entries))
+	for ix, row := range entries {
+		augmented[ix] = make([]Expr, len(row))
+		for jx, e := range row {
+			if e == nil {
+				augmented[ix][jx] = Int(0)
+			} else if jx == len(unknowns) {
+				augmented[ix][jx] = e.neg().toExpr(kt)
+			} else {
+				augmented[ix][jx] = e.toExpr(kt)
+			}
+		}
+	}
+	return solveLinearSystem(augmented, len(unknowns))
+}
+
+// Solves the linear system with the given augmented matrix, where
+// undetermined unknowns are set to zero. False is returned if the
+// system is inconsistent.
+func solveLinearSystem(augmented [][]Expr, n int) ([]Expr, bool) {
+	values := make([]Expr, n)
+	for ix := range values {
+		values[ix] = Int(0)
+	}
+	if len(augmented) == 0 {
+		return values, true
+	}
+	m, err := NewMatrix(augmented)
+	if err != nil {
+		return nil, false
+	}
+	reduced, pivots := m.RREF()
+	for row, p := range pivots {
+		if p == n {
+			return nil, false
+		}
+		values[p] = reduced.At(row, n)
+	}
+	return values, true
+}
+
+/*
+Finds a particular solution of sum_k a_k*y^(k) = g by variation of
+parameters, i.e. as sum_j u_j*y_j over the basis y_j of solutions of the
+homogeneous equation, where the derivatives u_j' solve W*u' = (0, ..., 0,
+g/a_n) with W the Wronskian matrix of the basis.
+*/
+func (o *odeProblem) variationOfParameters(a []Expr, g Expr, basis []Expr) (Expr, bool) {
+	n := len(basis)
+	rows := make([][]Expr, n)
+	derivatives := append([]Expr{}, basis...)
+	for i := range rows {
+		rows[i] = append([]Expr{}, derivatives...)
+		for j := range derivatives {
+			derivatives[j] = Expand(expandExp(differentiate(derivatives[j], o.x)))
+		}
+	}
+	wronskian, err := NewMatrix(rows)
+	if err != nil {
+		return nil, false
+	}
+	inv, err := wronskian.Inverse()
+	if err != nil {
+		return nil, false
+	}
+
+	terms := make([]Expr, n)
+	for j, y := range basis {
+		du := Cancel(expandExp(Div(Mul(inv.At(j, n-1), g), a[n])))
+		u, ok := integrate(du, o.x)
+		if !ok {
+			return nil, false
+		}
+		terms[j] = Mul(u, y)
+	}
+	return Add(terms...), true
+}
+
+/* First order equations */
+
+/*
+Solves the first order equation eq = A(x, y)*y' + B(x, y) = 0 by trying the
+linear, Bernoulli, separable and exact methods in turn, unless the initial
+condition is an equilibrium. Separable and exact
+equations give an implicit solution F(x, y) = C1, where C1 is determined
+directly by the initial condition if there is one.
+*/
+func (o *odeProblem) solveFirstOrder(eq Expr, conditions []InitialCondition) (Expr, error) {
+	y, dy := o.y(0), o.y(1)
+	A := Cancel(differentiate(eq, dy))
+	if RecContains(A, dy) || isZero(A) {
+		return nil, &UnsupportedODEError{Eq: o.eq}
+	}
+	B := Cancel(Substitute(eq, dy, Int(0)))
+	F := Cancel(Div(Neg(B), A))
+
+	// An initial value y0 with F(x, y0) = 0 for all x gives the equilibrium
+	// y = y0, which the general solution may only reach in a limit, e.g.
+	// y = 0 of y' = y^2 with the general solution 1/(C1 - x)
+	if len(conditions) > 0 {
+		y0 := conditions[0].Value
+		if !RecContains(y0, o.x) && isZero(expandExp(Cancel(Substitute(F, y, y0)))) {
+			return y0, nil
+		}
+	}
+
+	// Linear: y' = -P*y + Q
+	if dF := Cancel(differentiate(F, y)); !RecContains(dF, y) {
+		if solution, ok := o.linearFirstOrder(Neg(dF), Cancel(Substitute(F, y, Int(0)))); ok {
+			return solution, nil
+		}
+	}
+
+	// Bernoulli: y' = -P*y + Q*y^n
+	if P, Q, n, ok := o.bernoulli(F); ok {
+		exponent := ratSubtract(Int(1), n)
+		if v, ok := o.linearFirstOrder(Mul(exponent, P), Mul(exponent, Q)); ok {
+			return Pow(v, ratInv(exponent)), nil
+		}
+	}
+
+	relation, ok := o.separable(F)
+	if !ok {
+		relation, ok = o.exact(B, A)
+	}
+	if !ok {
+		return nil, &UnsupportedODEError{Eq: o.eq}
+	}
+
+	// The implicit solution relation = C1
+	c := o.constant(1)
+	if len(conditions) > 0 {
+		value := Cancel(Substitute(Substitute(relation, o.x, conditions[0].At), y, conditions[0].Value))
+		relation, conditions = Sub(relation, value), conditions[1:]
+	} else {
+		relation = Sub(relation, c)
+	}
+	solution, ok := isolate(relation, y)
+	if !ok {
+		return nil, &ImplicitSolutionError{Solution: Substitute(tidy(relation), y, o.f)}
+	}
+	if len(conditions) == 0 && RecContains(relation, c) {
+		solution = absorbConstant(solution, c)
+	}
+	return solution, nil
+}
+
+// Solves y' + P*y = Q as y = (∫mu*Q + C1)/mu with mu = exp(∫P).
+func (o *odeProblem) linearFirstOrder(P, Q Expr) (Expr, bool) {
+	integralP, ok := integrate(P, o.x)
+	if !ok {
+		return nil, false
+	}
+	mu := expandExp(Exp(integralP)).Simplify()
+	integral, ok := integrate(Mul(mu, Q), o.x)
+	if !ok {
+		return nil, false
+	}
+	return Mul(Add(integral, o.constant(1)), Pow(mu, Int(-1))), true
+}
+
+// Returns P, Q and n if y' = F is the Bernoulli equation
+// y' = -P*y + Q*y^n with n not zero or one.
+func (o *odeProblem) bernoulli(F Expr) (Expr, Expr, rational, bool) {
+	y := o.y(0)
+	expanded := Expand(expandExp(F))
+	terms := []Expr{expanded}
+	if sum, ok := expanded.(add); ok {
+		terms = sum.Operands
+	}
+
+	var P, Q Expr = Int(0), Int(0)
+	var n rational
+	for _, t := range terms {
+		e, ok := Cancel(Div(Mul(y, differentiate(t, y)), t)).(rational)
+		if !ok {
+			return nil, nil, nil, false
+		}
+		coeff := Cancel(Div(t, Pow(y, e)))
+		if RecContains(coeff, y) {
+			return nil, nil, nil, false
+		}
+		switch {
+		case e == Int(1):
+			P = Sub(P, coeff)
+		case e == Int(0) || (n != nil && ratSubtract(e, n).numerator().value != 0):
+			return nil, nil, nil, false
+		default:
+			n = e
+			Q = Add(Q, coeff)
+		}
+	}
+	return P, Q, n, n != nil
+}
+
+// Returns G(y) - H(x) if y' = F = g(x)*h(y) is separable,
+// where G and H are antiderivatives of 1/h and g.
+func (o *odeProblem) separable(F Expr) (Expr, bool) {
+	x, y := o.x, o.y(0)
+	Fx, Fy := differentiate(F, x), differentiate(F, y)
+	if !isZero(expandExp(Sub(Mul(F, differentiate(Fx, y)), Mul(Fx, Fy)))) {
+		return nil, false
+	}
+
+	// h(y) = F(x0, y) for some x0 where it is defined and non-zero
+	for x0 := int64(0); x0 < 4; x0++ {
+		h := Cancel(Substitute(F, x, Int(x0)))
+		if _, ok := h.(undefined); ok || isZero(h) {
+			continue
+		}
+		g := Cancel(expandExp(Div(F, h)))
+		if RecContains(g, y) {
+			return nil, false
+		}
+		G, ok := integrate(Pow(h, Int(-1)), y)
+		if !ok {
+			return nil, false
+		}
+		H, ok := integrate(g, x)
+		if !ok {
+			return nil, false
+		}
+		return Sub(G, H), true
+	}
+	return nil, false
+}
+
+// Returns the potential Φ(x, y) with D(Φ, x) = M and D(Φ, y) = N
+// if M + N*y' = 0 is exact.
+func (o *odeProblem) exact(M, N Expr) (Expr, bool) {
+	x, y := o.x, o.y(0)
+	if !isZero(expandExp(Sub(differentiate(M, y), differentiate(N, x)))) {
+		return nil, false
+	}
+	phi, ok := integrate(M, x)
+	if !ok {
+		return nil, false
+	}
+	rest := Cancel(expandExp(Sub(N, differentiate(phi, y))))
+	if RecContains(rest, x) {
+		return nil, false
+	}
+	psi, ok := integrate(rest, y)
+	if !ok {
+		return nil, false
+	}
+	return Add(phi, psi), true
+}
+
+/*
+Solves expr = 0 for y by peeling off the functions around y, i.e. by
+inverting log, exp and powers, after combining integer multiples of
+logarithms into one. What remains must be a rational function whose
+numerator is linear in y.
+*/
+func isolate(expr Expr, y variable) (Expr, bool) {
+	for depth := 0; depth < maxIntegrationDepth; depth++ {
+		expanded := Expand(expr)
+		terms := []Expr{expanded}
+		if sum, ok := expanded.(add); ok {
+			terms = sum.Operands
+		}
+		dependent, rest := []Expr{}, []Expr{Int(0)}
+		for _, t := range terms {
+			if RecContains(t, y) {
+				dependent = append(dependent, t)
+			} else {
+				rest = append(rest, t)
+			}
+		}
+		var rhs Expr = Neg(Add(rest...))
+
+		if logs, ok := combineLogs(dependent, y); ok {
+			expr = Sub(logs, Exp(rhs))
+			continue
+		} else if len(dependent) != 1 {
+			break
+		}
+		c, factors := splitConstantFactors(dependent[0], y)
+		if len(factors) != 1 {
+			break
+		}
+		rhs = Div(rhs, c)
+		switch f := factors[0].(type) {
+		case variable:
+			return Cancel(rhs), true
+		case log:
+			expr = Sub(f.Arg, Exp(rhs))
+			continue
+		case exp:
+			expr = Sub(f.Arg, Log(rhs))
+			continue
+		case pow:
+			if !RecContains(f.Exponent, y) {
+				expr = Sub(f.Base, Pow(rhs, Div(Int(1), f.Exponent)))
+				continue
+			}
+		}
+		break
+	}
+
+	// What remains must have a numerator linear in y
+	kt := &kernelTable{}
+	ix := kt.index(y)
+	r, ok := toRatFunc(expr, kt)
+	if !ok || r.num.degreeIn(ix) != 1 {
+		return nil, false
+	}
+	c0, c1 := r.num.coefficientIn(ix, 0).toExpr(kt), r.num.coefficientIn(ix, 1).toExpr(kt)
+	if RecContains(c0, y) || RecContains(c1, y) {
+		return nil, false
+	}
+	return Cancel(Div(Neg(c0), c1)), true
+}
+
+// Returns log(u_1^k_1 * ... * u_m^k_m) if the terms are k_1*log(u_1), ...,
+// k_m*log(u_m) with integers k_i, and there are at least two of them.
+func combineLogs(terms []Expr, y variable) (Expr, bool) {
+	if len(terms) < 2 {
+		return nil, false
+	}
+	factors := make([]Expr, len(terms))
+	for ix, t := range terms {
+		c, fs := splitConstantFactors(t, y)
+		n, ok := c.(integer)
+		if !ok || len(fs) != 1 {
+			return nil, false
+		}
+		l, ok := fs[0].(log)
+		if !ok {
+			return nil, false
+		}
+		factors[ix] = Pow(l.Arg, n)
+	}
+	return Log(Cancel(Mul(factors...))), true
+}
+
+// Renames exp(c) to c in solution, which is valid when c is an arbitrary
+// constant only appearing in that form, e.g. exp(x + C1) becomes C1*exp(x).
+func absorbConstant(solution Expr, c variable) Expr {
+	k := freshVariable("K", solution)
+	renamed := Substitute(expandExp(solution), Exp(c), k)
+	if RecContains(renamed, c) {
+		return solution
+	}
+	return Substitute(renamed, k, c)
+}
+
+/*
+Determines the integration constants from the initial conditions. The
+conditions give equations in the constants that are solved as a linear
+system if they are linear, leaving undetermined constants as they are,
+and otherwise by isolating the single constant.
+*/
+func (o *odeProblem) applyConditions(solution Expr, conditions []InitialCondition) (Expr, error) {
+	if len(conditions) == 0 {
+		return solution, nil
+	}
+	equations := make([]Expr, len(conditions))
+	for ix, c := range conditions {
+		d := solution
+		for k := 0; k < c.Order; k++ {
+			d = differentiate(d, o.x)
+		}
+		equations[ix] = Sub(Substitute(d, o.x, c.At), c.Value)
+	}
+
+	solved, linear, err := substituteConstants(solution, equations, o.constants)
+	if err != nil || linear {
+		return solved, err
+	}
+	if len(o.constants) != 1 || len(equations) != 1 {
+		return nil, &UnsupportedODEError{Eq: o.eq}
+	}
+	value, ok := isolate(equations[0], o.constants[0])
+	if !ok {
+		return nil, &UnsupportedODEError{Eq: o.eq}
+	}
+	return Substitute(solution, o.constants[0], value), nil
+}
+
+/*
+Solves the equations = 0 for the constants and substitutes the values
+into solution, where constants that are not determined by the equations
+are kept. False is returned if the equations are not linear in the
+constants, and an InconsistentInitialConditionsError if they have no
+solution.
+*/
+func substituteConstants(solution Expr, equations []Expr, constants []variable) (Expr, bool, error) {
+	n := len(constants)
+	augmented := make([][]Expr, len(equations))
+	for ix, e := range equations {
+		augmented[ix] = make([]Expr, n+1)
+		rest := e
+		for jx, c := range constants {
+			coeff := Cancel(expandExp(differentiate(e, c)))
+			for _, other := range constants {
+				if RecContains(coeff, other) {
+					return nil, false, nil
+				}
+			}
+			augmented[ix][jx] = coeff
+			rest = Substitute(rest, c, Int(0))
+		}
+		augmented[ix][n] = Cancel(expandExp(Neg(rest)))
+	}
+
+	m, err := NewMatrix(augmented)
+	if err != nil {
+		return nil, true, err
+	}
+	reduced, pivots := m.RREF()
+	for row, p := range pivots {
+		if p == n {
+			return nil, true, &InconsistentInitialConditionsError{}
+		}
+		// Constants without pivot stay free
+		terms := []Expr{reduced.At(row, n)}
+		for jx := p + 1; jx < n; jx++ {
+			terms = append(terms, Neg(Mul(reduced.At(row, jx), constants[jx])))
+		}
+		solution = Substitute(solution, constants[p], Add(terms...))
+	}
+	return solution, true, nil
+}
+
+// Writes a solution as an expanded sum with the exponentials
+// in each term combined.
+func tidy(solution Expr) Expr {
+	return combineExp(Expand(expandExp(solution))).Simplify()
+}
@@ -0,0 +1,170 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDSolve(t *testing.T) {
+	x := Var("x")
+	f := Function("f", x)
+	d1 := Derivative(f, x)
+	d2 := Derivative(d1, x)
+
+	tests := []struct {
+		name       string
+		input      Expr
+		conditions []InitialCondition
+		constants  int
+	}{
+		{
+			name:      "Exponential growth",
+			input:     Sub(d1, f),
+			constants: 1,
+		},
+		{
+			name:      "First order linear",
+			input:     Sub(Add(d1, Mul(Int(2), x, f)), x),
+			constants: 1,
+		},
+		{
+			name:      "Bernoulli",
+			input:     Sub(Add(d1, f), Pow(f, Int(2))),
+			constants: 1,
+		},
+		{
+			name:      "Separable with exponential",
+			input:     Sub(d1, Mul(x, Exp(Neg(f)))),
+			constants: 1,
+		},
+		{
+			name:      "Separable with power",
+			input:     Sub(d1, Mul(x, Pow(f, Int(2)))),
+			constants: 1,
+		},
+		{
+			name:      "Harmonic oscillator",
+			input:     Add(d2, f),
+			constants: 2,
+		},
+		{
+			name:      "Exponential forcing",
+			input:     Sub(Add(d2, Mul(Int(-3), d1), Mul(Int(2), f)), Exp(Mul(Int(3), x))),
+			constants: 2,
+		},
+		{
+			name:      "Polynomial forcing",
+			input:     Sub(Add(d2, Mul(Int(-3), d1), Mul(Int(2), f)), x),
+			constants: 2,
+		},
+		{
+			name:      "Resonant exponential forcing",
+			input:     Sub(Add(d2, Mul(Int(-3), d1), Mul(Int(2), f)), Exp(x)),
+			constants: 2,
+		},
+		{
+			name:      "Resonant trigonometric forcing",
+			input:     Sub(Add(d2, f), Sin(x)),
+			constants: 2,
+		},
+		{
+			name:      "Variation of parameters",
+			input:     Sub(Add(d2, Mul(Int(-2), d1), f), Div(Exp(x), Pow(x, Int(2)))),
+			constants: 2,
+		},
+		{
+			name:      "Third order",
+			input:     Sub(Derivative(d2, x), d1),
+			constants: 3,
+		},
+		{
+			name:  "Harmonic oscillator with initial conditions",
+			input: Add(d2, f),
+			conditions: []InitialCondition{
+				{At: Int(0), Order: 0, Value: Int(1)},
+				{At: Int(0), Order: 1, Value: Int(2)},
+			},
+		},
+		{
+			name:       "Separable with initial condition",
+			input:      Sub(d1, Mul(x, Pow(f, Int(2)))),
+			conditions: []InitialCondition{{At: Int(0), Order: 0, Value: Int(1)}},
+		},
+		{
+			name:       "Equilibrium of a Bernoulli equation",
+			input:      Sub(d1, Pow(f, Int(2))),
+			conditions: []InitialCondition{{At: Int(0), Order: 0, Value: Int(0)}},
+		},
+		{
+			name:       "Equilibrium of a separable equation",
+			input:      Sub(d1, Mul(x, Sub(f, Int(2)), Sub(f, Int(3)))),
+			conditions: []InitialCondition{{At: Int(1), Order: 0, Value: Int(3)}},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := DSolve(test.input, f, x, test.conditions...)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nInput: %v\nGot error: %v", test.name, test.input, err)
+			}
+
+			// The solution must satisfy the equation and the conditions
+			residual := Substitute(test.input, f, result).Simplify()
+			if !isZero(expandExp(residual)) {
+				t.Errorf("Following test failed: %s\nInput: %v\nSolution: %v\nResidual: %v", test.name, test.input, result, residual)
+			}
+			for _, c := range test.conditions {
+				value := result
+				for k := 0; k < c.Order; k++ {
+					value = value.D(x)
+				}
+				if !isZero(Sub(Substitute(value, x, c.At), c.Value)) {
+					t.Errorf("Following test failed: %s\nSolution %v does not satisfy %v", test.name, result, c)
+				}
+			}
+
+			// The general solution has one constant per order
+			for k := 1; k <= 3; k++ {
+				contains := RecContains(result, Var(VarName(fmt.Sprintf("C%d", k))))
+				if contains != (k <= test.constants) {
+					t.Errorf("Following test failed: %s\nExpected %d integration constants in %v", test.name, test.constants, result)
+				}
+			}
+		})
+	}
+}
+
+func TestDSolveErrors(t *testing.T) {
+	x, y := Var("x"), Var("y")
+	f := Function("f", x)
+	d1 := Derivative(f, x)
+
+	var unsupported *UnsupportedODEError
+	if _, err := DSolve(Sub(d1, f), Function("f", x, y), x); !errors.As(err, &unsupported) {
+		t.Errorf("Expected UnsupportedODEError for a function of several variables, got %v", err)
+	}
+	if _, err := DSolve(Sub(d1, Sin(Mul(x, f))), f, x); !errors.As(err, &unsupported) {
+		t.Errorf("Expected UnsupportedODEError for a non-linear equation, got %v", err)
+	}
+
+	var implicit *ImplicitSolutionError
+	exact := Add(Mul(Int(2), x, f), Mul(Add(Pow(x, Int(2)), Mul(Int(2), f)), d1))
+	if _, err := DSolve(exact, f, x); !errors.As(err, &implicit) {
+		t.Errorf("Expected ImplicitSolutionError, got %v", err)
+	} else if !RecContains(implicit.Solution, Var("C1")) {
+		t.Errorf("Expected the implicit solution %v to contain C1", implicit.Solution)
+	}
+
+	var invalid *InvalidInitialConditionError
+	if _, err := DSolve(Sub(d1, f), f, x, InitialCondition{At: Int(0), Order: 1, Value: Int(1)}); !errors.As(err, &invalid) {
+		t.Errorf("Expected InvalidInitialConditionError, got %v", err)
+	}
+
+	var inconsistent *InconsistentInitialConditionsError
+	conditions := []InitialCondition{{At: Int(0), Value: Int(1)}, {At: Int(0), Value: Int(2)}}
+	if _, err := DSolve(Sub(d1, f), f, x, conditions...); !errors.As(err, &inconsistent) {
+		t.Errorf("Expected InconsistentInitialConditionsError, got %v", err)
+	}
+}
@@ -0,0 +1,333 @@
+package gosymbol
+
+// An eigenvalue together with its algebraic multiplicity.
+type Eigenvalue struct {
+	Value        Expr
+	Multiplicity int
+}
+
+// An eigenvalue together with a basis of its eigenspace. The
+// geometric multiplicity of the eigenvalue is len(Basis).
+type Eigenspace struct {
+	Eigenvalue   Expr
+	Multiplicity int      // Algebraic multiplicity
+	Basis        []Matrix // Column vectors
+}
+
+/*
+Returns the characteristic polynomial det(lambda*I - m) of m
+as an expanded polynomial in lambda. The polynomial is monic
+and of degree m.Rows().
+*/
+func (m Matrix) CharPoly(lambda variable) (Expr, error) {
+	if m.rows != m.cols {
+		return nil, &NonSquareMatrixError{Rows: m.rows, Cols: m.cols}
+	}
+	shifted, _ := Identity(m.rows).Scale(lambda).Sub(m)
+	det, err := shifted.Det()
+	if err != nil {
+		return nil, err
+	}
+	return Expand(det), nil
+}
+
+/*
+Returns the eigenvalues of m with their algebraic multiplicities,
+found as the exact roots of the characteristic polynomial. Linear and
+quadratic factors are always solved, and for matrices with numeric
+entries so are all factors with rational roots. The diagonal entries
+are tried as roots as well, which covers triangular matrices with
+symbolic entries. If the characteristic polynomial has a factor that
+can not be solved an UnsolvablePolynomialError is returned.
+
+Numeric eigenvalues are returned in increasing order.
+*/
+func (m Matrix) Eigenvalues() ([]Eigenvalue, error) {
+	lambda := m.freshVariable()
+	charPoly, err := m.CharPoly(lambda)
+	if err != nil {
+		return nil, err
+	}
+
+	diagonal := make([]Expr, m.rows)
+	for ix := range diagonal {
+		diagonal[ix] = m.At(ix, ix)
+	}
+	roots, err := polynomialRoots(charPoly, lambda, diagonal)
+	if err != nil {
+		return nil, err
+	}
+
+	eigenvalues := make([]Eigenvalue, len(roots))
+	for ix, r := range roots {
+		eigenvalues[ix] = Eigenvalue{Value: r.value, Multiplicity: r.multiplicity}
+	}
+	return eigenvalues, nil
+}
+
+// Returns the eigenvalues of m together with bases of their
+// eigenspaces, which are the null spaces of m - lambda*I.
+func (m Matrix) Eigenvectors() ([]Eigenspace, error) {
+	eigenvalues, err := m.Eigenvalues()
+	if err != nil {
+		return nil, err
+	}
+	spaces := make([]Eigenspace, len(eigenvalues))
+	for ix, e := range eigenvalues {
+		spaces[ix] = Eigenspace{
+			Eigenvalue:   e.Value,
+			Multiplicity: e.Multiplicity,
+			Basis:        m.shift(e.Value).NullSpace(),
+		}
+	}
+	return spaces, nil
+}
+
+/*
+Diagonalises m, i.e. returns an invertible p and a diagonal d such that
+m = p*d*p^-1. The columns of p are eigenvectors of m and the diagonal of
+d holds the corresponding eigenvalues. A NotDiagonalizableError is
+returned if m does not have a basis of eigenvectors.
+*/
+func (m Matrix) Diagonalize() (Matrix, Matrix, error) {
+	spaces, err := m.Eigenvectors()
+	if err != nil {
+		return Matrix{}, Matrix{}, err
+	}
+
+	columns := []Matrix{}
+	eigenvalues := []Expr{}
+	for _, s := range spaces {
+		if len(s.Basis) < s.Multiplicity {
+			return Matrix{}, Matrix{}, &NotDiagonalizableError{}
+		}
+		for _, v := range s.Basis {
+			columns = append(columns, v)
+			eigenvalues = append(eigenvalues, s.Eigenvalue)
+		}
+	}
+
+	d := Zeros(m.rows, m.rows)
+	for ix, e := range eigenvalues {
+		d.entries[ix*m.rows+ix] = e
+	}
+	return matrixFromColumns(columns, m.rows), d, nil
+}
+
+/*
+Returns the Jordan normal form j of m together with an invertible p such
+that m = p*j*p^-1. The Jordan blocks are ordered by eigenvalue, as given
+by Eigenvalues, and by decreasing size for each eigenvalue, with the ones
+on the superdiagonal.
+
+For each eigenvalue lambda the columns of p are Jordan chains v_k, ...,
+v_1 with v_{i-1} = (m - lambda*I) v_i, where the chain heads are chosen
+from the null spaces of the powers of m - lambda*I, starting with the
+longest chains. All of this is done exactly, so it is only suitable for
+small matrices.
+*/
+func (m Matrix) JordanForm() (Matrix, Matrix, error) {
+	eigenvalues, err := m.Eigenvalues()
+	if err != nil {
+		return Matrix{}, Matrix{}, err
+	}
+
+	n := m.rows
+	columns := []Matrix{}
+	j := Zeros(n, n)
+	for _, e := range eigenvalues {
+		for _, chain := range m.jordanChains(e) {
+			start := len(columns)
+			// The chain is stored from its head, while the columns
+			// of p start with the eigenvector at the end of the chain
+			for ix := len(chain) - 1; ix >= 0; ix-- {
+				columns = append(columns, chain[ix])
+			}
+			for ix := start; ix < len(columns); ix++ {
+				j.entries[ix*n+ix] = e.Value
+				if ix > start {
+					j.entries[(ix-1)*n+ix] = Int(1)
+				}
+			}
+		}
+	}
+	return matrixFromColumns(columns, n), j, nil
+}
+
+/*
+Returns the Jordan chains of the eigenvalue e. Each chain starts with its
+head v and continues with N*v, N^2*v, ... where N = m - e.Value*I, and the
+chains are ordered by decreasing length.
+*/
+func (m Matrix) jordanChains(e Eigenvalue) [][]Matrix {
+	shifted := m.shift(e.Value)
+
+	// kernels[k] is a basis of the null space of N^k, the dimensions
+	// of which grow until they reach the algebraic multiplicity
+	kernels := [][]Matrix{{}}
+	power := Identity(m.rows)
+	for len(kernels[len(kernels)-1]) < e.Multiplicity && len(kernels) <= e.Multiplicity {
+		power, _ = power.Mul(shifted)
+		power = power.mapEntries(Cancel)
+		kernels = append(kernels, power.NullSpace())
+	}
+
+	apply := func(v Matrix) Matrix {
+		result, _ := shifted.Mul(v)
+		return result.mapEntries(Cancel)
+	}
+
+	chains := [][]Matrix{}
+	for level := len(kernels) - 1; level >= 1; level-- {
+		// The vectors on this level of the longer chains, which
+		// together with the null space of N^(level-1) must stay
+		// linearly independent when adding new chain heads
+		independent := append([]Matrix{}, kernels[level-1]...)
+		for _, chain := range chains {
+			independent = append(independent, chain[len(chain)-level])
+		}
+
+		for _, candidate := range kernels[level] {
+			if len(independent) == len(kernels[level]) {
+				break
+			}
+			rank := matrixFromColumns(append(independent, candidate), m.rows).Rank()
+			if rank < len(independent)+1 {
+				continue
+			}
+			independent = append(independent, candidate)
+			chain := []Matrix{candidate}
+			for len(chain) < level {
+				chain = append(chain, apply(chain[len(chain)-1]))
+			}
+			chains = append(chains, chain)
+		}
+	}
+	return chains
+}
+
+// Returns m - e*I.
+func (m Matrix) shift(e Expr) Matrix {
+	result, _ := m.Sub(Identity(m.rows).Scale(e))
+	return result
+}
+
+// Returns a variable that does not occur in m.
+func (m Matrix) freshVariable() variable {
+	return freshVariable("λ", m.entries...)
+}
+
+// Creates a matrix with the given column vectors of length rows.
+func matrixFromColumns(columns []Matrix, rows int) Matrix {
+	result := Zeros(rows, len(columns))
+	for jx, c := range columns {
+		for ix := 0; ix < rows; ix++ {
+			result.entries[ix*len(columns)+jx] = c.At(ix, 0)
+		}
+	}
+	return result
+}
+
+/*
+Returns the matrix exponential exp(m*t). It is computed from the Jordan
+form m = p*j*p^-1 as p*exp(j*t)*p^-1, where exp(j*t) is block diagonal
+with exp(lambda*t)*t^k/k! on the k:th superdiagonal of the block with
+eigenvalue lambda. Use t = 1 for exp(m) itself.
+
+The solution of the linear system x' = m*x with x(0) = x0 is exp(m*t)*x0.
+*/
+func (m Matrix) Exp(t Expr) (Matrix, error) {
+	return m.jordanFunction(func(lambda Expr, k int) Expr {
+		return Mul(Exp(Mul(lambda, t)), Pow(t, Int(int64(k))), Div(Int(1), Int(factorial(k))))
+	})
+}
+
+/*
+Returns m^n where n may be symbolic. Integer powers are computed by
+repeated squaring, of the inverse of m if n is negative. For any other n
+the power is computed from the Jordan form like in Exp, with the entries
+binomial(n, k)*lambda^(n-k) on the k:th superdiagonal of each block. In
+that case the result is only valid for integers n at least as large as
+the Jordan blocks with eigenvalue zero.
+*/
+func (m Matrix) Pow(n Expr) (Matrix, error) {
+	if m.rows != m.cols {
+		return Matrix{}, &NonSquareMatrixError{Rows: m.rows, Cols: m.cols}
+	}
+
+	if k, ok := n.(integer); ok {
+		base := m
+		if k.value < 0 {
+			inv, err := m.Inverse()
+			if err != nil {
+				return Matrix{}, err
+			}
+			base, k = inv, intNeg(k)
+		}
+		result := Identity(m.rows)
+		for ; k.value > 0; k.value /= 2 {
+			if k.value%2 == 1 {
+				result, _ = result.Mul(base)
+				result = result.mapEntries(Cancel)
+			}
+			base, _ = base.Mul(base)
+			base = base.mapEntries(Cancel)
+		}
+		return result, nil
+	}
+
+	return m.jordanFunction(func(lambda Expr, k int) Expr {
+		factors := []Expr{Pow(lambda, Sub(n, Int(int64(k)))), Div(Int(1), Int(factorial(k)))}
+		for ix := 0; ix < k; ix++ {
+			factors = append(factors, Sub(n, Int(int64(ix))))
+		}
+		return Mul(factors...)
+	})
+}
+
+/*
+Applies a matrix function to m via its Jordan form, where f(lambda, k)
+gives the entries on the k:th superdiagonal of the Jordan block with
+eigenvalue lambda, i.e. the k:th derivative of the function at lambda
+divided by k!.
+*/
+func (m Matrix) jordanFunction(f func(lambda Expr, k int) Expr) (Matrix, error) {
+	if m.rows != m.cols {
+		return Matrix{}, &NonSquareMatrixError{Rows: m.rows, Cols: m.cols}
+	}
+	p, j, err := m.JordanForm()
+	if err != nil {
+		return Matrix{}, err
+	}
+
+	n := m.rows
+	fj := Zeros(n, n)
+	for start := 0; start < n; {
+		end := start + 1
+		for end < n && Equal(j.At(end-1, end), Int(1)) {
+			end++
+		}
+		for i := start; i < end; i++ {
+			for k := 0; i+k < end; k++ {
+				fj.entries[i*n+i+k] = f(j.At(start, start), k)
+			}
+		}
+		start = end
+	}
+
+	pInv, err := p.Inverse()
+	if err != nil {
+		return Matrix{}, err
+	}
+	pfj, _ := p.Mul(fj)
+	result, _ := pfj.Mul(pInv)
+	return result.mapEntries(Cancel), nil
+}
+
+func factorial(n int) int64 {
+	result := int64(1)
+	for ix := 2; ix <= n; ix++ {
+		result *= int64(ix)
+	}
+	return result
+}
@@ -0,0 +1,385 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMatrixCharPoly(t *testing.T) {
+	a, b, c, d := Var("a"), Var("b"), Var("c"), Var("d")
+	lambda := Var("λ")
+
+	tests := []struct {
+		name           string
+		input          [][]Expr
+		expectedOutput Expr
+	}{
+		{
+			name:           "Integer 2x2",
+			input:          [][]Expr{{Int(1), Int(2)}, {Int(3), Int(4)}},
+			expectedOutput: Add(Pow(lambda, Int(2)), Mul(Int(-5), lambda), Int(-2)),
+		},
+		{
+			name:           "Symbolic 2x2",
+			input:          [][]Expr{{a, b}, {c, d}},
+			expectedOutput: Add(Pow(lambda, Int(2)), Mul(Int(-1), Add(a, d), lambda), Mul(a, d), Mul(Int(-1), b, c)),
+		},
+		{
+			name:           "Diagonal 3x3",
+			input:          [][]Expr{{Int(1), Int(0), Int(0)}, {Int(0), Int(2), Int(0)}, {Int(0), Int(0), Int(3)}},
+			expectedOutput: Mul(Sub(lambda, Int(1)), Sub(lambda, Int(2)), Sub(lambda, Int(3))),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			result, err := m.CharPoly(lambda)
+			if err != nil || !isZero(Sub(result, test.expectedOutput)) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v, error: %v", test.name, m, test.expectedOutput, result, err)
+			}
+		})
+	}
+}
+
+func TestMatrixEigenvalues(t *testing.T) {
+	a, b := Var("a"), Var("b")
+	sqrt5 := Pow(Int(5), Div(Int(1), Int(2)))
+
+	tests := []struct {
+		name           string
+		input          [][]Expr
+		expectedOutput []Eigenvalue
+	}{
+		{
+			name:           "Symmetric integer 2x2",
+			input:          [][]Expr{{Int(1), Int(2)}, {Int(2), Int(1)}},
+			expectedOutput: []Eigenvalue{{Int(-1), 1}, {Int(3), 1}},
+		},
+		{
+			name:  "Irrational eigenvalues",
+			input: [][]Expr{{Int(1), Int(1)}, {Int(1), Int(0)}},
+			expectedOutput: []Eigenvalue{
+				{Mul(Div(Int(1), Int(2)), Sub(Int(1), sqrt5)), 1},
+				{Mul(Div(Int(1), Int(2)), Add(Int(1), sqrt5)), 1},
+			},
+		},
+		{
+			name:           "Repeated eigenvalue",
+			input:          [][]Expr{{Int(2), Int(1)}, {Int(0), Int(2)}},
+			expectedOutput: []Eigenvalue{{Int(2), 2}},
+		},
+		{
+			name:           "Rational roots of a quartic",
+			input:          [][]Expr{{Int(5), Int(4), Int(2), Int(1)}, {Int(0), Int(1), Int(-1), Int(-1)}, {Int(-1), Int(-1), Int(3), Int(0)}, {Int(1), Int(1), Int(-1), Int(2)}},
+			expectedOutput: []Eigenvalue{{Int(1), 1}, {Int(2), 1}, {Int(4), 2}},
+		},
+		{
+			name:           "Symbolic symmetric 2x2",
+			input:          [][]Expr{{a, b}, {b, a}},
+			expectedOutput: []Eigenvalue{{Sub(a, b), 1}, {Add(a, b), 1}},
+		},
+		{
+			name:           "Symbolic upper triangular 3x3",
+			input:          [][]Expr{{a, Int(1), Int(2)}, {Int(0), b, Int(3)}, {Int(0), Int(0), Add(a, b)}},
+			expectedOutput: []Eigenvalue{{a, 1}, {b, 1}, {Add(a, b), 1}},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			result, err := m.Eigenvalues()
+			if err != nil || len(result) != len(test.expectedOutput) {
+				t.Fatalf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v, error: %v", test.name, m, test.expectedOutput, result, err)
+			}
+			for jx, e := range test.expectedOutput {
+				if !isZero(Sub(result[jx].Value, e.Value)) || result[jx].Multiplicity != e.Multiplicity {
+					t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, m, test.expectedOutput, result)
+				}
+			}
+		})
+	}
+}
+
+func TestMatrixEigenvaluesUnsolvable(t *testing.T) {
+	m, _ := NewMatrix([][]Expr{{Int(1), Int(2), Int(3)}, {Int(4), Int(5), Int(6)}, {Int(7), Int(8), Int(10)}})
+	_, err := m.Eigenvalues()
+	var unsolvable *UnsolvablePolynomialError
+	if !errors.As(err, &unsolvable) {
+		t.Errorf("Expected an UnsolvablePolynomialError for an irreducible cubic, got: %v", err)
+	}
+}
+
+func TestMatrixEigenvectors(t *testing.T) {
+	a, b := Var("a"), Var("b")
+
+	tests := []struct {
+		name               string
+		input              [][]Expr
+		expectedDimensions []int
+	}{
+		{
+			name:               "Irrational eigenvalues",
+			input:              [][]Expr{{Int(1), Int(1)}, {Int(1), Int(0)}},
+			expectedDimensions: []int{1, 1},
+		},
+		{
+			name:               "Defective matrix",
+			input:              [][]Expr{{Int(2), Int(1)}, {Int(0), Int(2)}},
+			expectedDimensions: []int{1},
+		},
+		{
+			name:               "Two dimensional eigenspace",
+			input:              [][]Expr{{Int(2), Int(0), Int(0)}, {Int(0), Int(3), Int(1)}, {Int(0), Int(0), Int(2)}},
+			expectedDimensions: []int{2, 1},
+		},
+		{
+			name:               "Symbolic eigenvalues with radicals",
+			input:              [][]Expr{{a, b}, {Int(1), Int(0)}},
+			expectedDimensions: []int{1, 1},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			result, err := m.Eigenvectors()
+			if err != nil || len(result) != len(test.expectedDimensions) {
+				t.Fatalf("Following test failed: %s\nInput: %v\nGot: %v, error: %v", test.name, m, result, err)
+			}
+			for jx, space := range result {
+				if len(space.Basis) != test.expectedDimensions[jx] {
+					t.Errorf("Following test failed: %s\nInput: %v\nExpected dimensions: %v\nGot: %v", test.name, m, test.expectedDimensions, result)
+				}
+				for _, v := range space.Basis {
+					mv, _ := m.Mul(v)
+					if !matrixIsZero(t, mv, v.Scale(space.Eigenvalue)) {
+						t.Errorf("Following test failed: %s\nInput: %v\n%v is not an eigenvector with eigenvalue %v", test.name, m, v, space.Eigenvalue)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMatrixDiagonalize(t *testing.T) {
+	a, b := Var("a"), Var("b")
+
+	tests := []struct {
+		name                 string
+		input                [][]Expr
+		expectDiagonalizable bool
+	}{
+		{
+			name:                 "Symmetric integer 2x2",
+			input:                [][]Expr{{Int(1), Int(2)}, {Int(2), Int(1)}},
+			expectDiagonalizable: true,
+		},
+		{
+			name:                 "Irrational eigenvalues",
+			input:                [][]Expr{{Int(1), Int(1)}, {Int(1), Int(0)}},
+			expectDiagonalizable: true,
+		},
+		{
+			name:                 "Symbolic symmetric 2x2",
+			input:                [][]Expr{{a, b}, {b, a}},
+			expectDiagonalizable: true,
+		},
+		{
+			name:                 "Defective matrix",
+			input:                [][]Expr{{Int(2), Int(1)}, {Int(0), Int(2)}},
+			expectDiagonalizable: false,
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			p, d, err := m.Diagonalize()
+			if !test.expectDiagonalizable {
+				var notDiagonalizable *NotDiagonalizableError
+				if !errors.As(err, &notDiagonalizable) {
+					t.Errorf("Following test failed: %s\nInput: %v\nExpected NotDiagonalizableError, got: %v", test.name, m, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nInput: %v\nGot error: %v", test.name, m, err)
+			}
+			pInv, err := p.Inverse()
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nInput: %v\nP is not invertible: %v", test.name, m, p)
+			}
+			pd, _ := p.Mul(d)
+			pdpInv, _ := pd.Mul(pInv)
+			if !matrixIsZero(t, pdpInv, m) {
+				t.Errorf("Following test failed: %s\nInput: %v\nGot P: %v, D: %v", test.name, m, p, d)
+			}
+		})
+	}
+}
+
+func TestMatrixJordanForm(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          [][]Expr
+		expectedOutput [][]Expr
+	}{
+		{
+			name:           "Single Jordan block",
+			input:          [][]Expr{{Int(2), Int(1)}, {Int(0), Int(2)}},
+			expectedOutput: [][]Expr{{Int(2), Int(1)}, {Int(0), Int(2)}},
+		},
+		{
+			name:           "Diagonalizable",
+			input:          [][]Expr{{Int(1), Int(2)}, {Int(2), Int(1)}},
+			expectedOutput: [][]Expr{{Int(-1), Int(0)}, {Int(0), Int(3)}},
+		},
+		{
+			name:  "Mixed block sizes",
+			input: [][]Expr{{Int(5), Int(4), Int(2), Int(1)}, {Int(0), Int(1), Int(-1), Int(-1)}, {Int(-1), Int(-1), Int(3), Int(0)}, {Int(1), Int(1), Int(-1), Int(2)}},
+			expectedOutput: [][]Expr{
+				{Int(1), Int(0), Int(0), Int(0)},
+				{Int(0), Int(2), Int(0), Int(0)},
+				{Int(0), Int(0), Int(4), Int(1)},
+				{Int(0), Int(0), Int(0), Int(4)},
+			},
+		},
+		{
+			name:  "Nilpotent with blocks of size two and one",
+			input: [][]Expr{{Int(0), Int(1), Int(0)}, {Int(0), Int(0), Int(0)}, {Int(0), Int(0), Int(0)}},
+			expectedOutput: [][]Expr{
+				{Int(0), Int(1), Int(0)},
+				{Int(0), Int(0), Int(0)},
+				{Int(0), Int(0), Int(0)},
+			},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			expected, _ := NewMatrix(test.expectedOutput)
+			p, j, err := m.JordanForm()
+			if err != nil || !matrixIsZero(t, j, expected) {
+				t.Fatalf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v, error: %v", test.name, m, expected, j, err)
+			}
+			pInv, err := p.Inverse()
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nInput: %v\nP is not invertible: %v", test.name, m, p)
+			}
+			pj, _ := p.Mul(j)
+			pjpInv, _ := pj.Mul(pInv)
+			if !matrixIsZero(t, pjpInv, m) {
+				t.Errorf("Following test failed: %s\nInput: %v\nGot P: %v, J: %v", test.name, m, p, j)
+			}
+		})
+	}
+}
+
+func TestMatrixExp(t *testing.T) {
+	tt := Var("t")
+	e2t := Exp(Mul(Int(2), tt))
+
+	tests := []struct {
+		name           string
+		input          [][]Expr
+		expectedOutput [][]Expr
+	}{
+		{
+			name:           "Diagonal",
+			input:          [][]Expr{{Int(1), Int(0)}, {Int(0), Int(-1)}},
+			expectedOutput: [][]Expr{{Exp(tt), Int(0)}, {Int(0), Exp(Neg(tt))}},
+		},
+		{
+			name:           "Nilpotent",
+			input:          [][]Expr{{Int(0), Int(1)}, {Int(0), Int(0)}},
+			expectedOutput: [][]Expr{{Int(1), tt}, {Int(0), Int(1)}},
+		},
+		{
+			name:           "Jordan block",
+			input:          [][]Expr{{Int(2), Int(1)}, {Int(0), Int(2)}},
+			expectedOutput: [][]Expr{{e2t, Mul(tt, e2t)}, {Int(0), e2t}},
+		},
+		{
+			name:  "Symmetric",
+			input: [][]Expr{{Int(1), Int(2)}, {Int(2), Int(1)}},
+			expectedOutput: [][]Expr{
+				{Mul(Div(Int(1), Int(2)), Add(Exp(Mul(Int(3), tt)), Exp(Neg(tt)))), Mul(Div(Int(1), Int(2)), Sub(Exp(Mul(Int(3), tt)), Exp(Neg(tt))))},
+				{Mul(Div(Int(1), Int(2)), Sub(Exp(Mul(Int(3), tt)), Exp(Neg(tt)))), Mul(Div(Int(1), Int(2)), Add(Exp(Mul(Int(3), tt)), Exp(Neg(tt))))},
+			},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			expected, _ := NewMatrix(test.expectedOutput)
+			result, err := m.Exp(tt)
+			if err != nil || !matrixIsZero(t, result, expected) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v, error: %v", test.name, m, expected, result, err)
+			}
+		})
+	}
+}
+
+func TestMatrixPow(t *testing.T) {
+	n := Var("n")
+	fibonacci, _ := NewMatrix([][]Expr{{Int(1), Int(1)}, {Int(1), Int(0)}})
+	jordan, _ := NewMatrix([][]Expr{{Int(2), Int(1)}, {Int(0), Int(2)}})
+
+	tests := []struct {
+		name           string
+		input          Matrix
+		exponent       Expr
+		expectedOutput [][]Expr
+	}{
+		{
+			name:           "Positive integer power",
+			input:          fibonacci,
+			exponent:       Int(10),
+			expectedOutput: [][]Expr{{Int(89), Int(55)}, {Int(55), Int(34)}},
+		},
+		{
+			name:           "Negative integer power",
+			input:          fibonacci,
+			exponent:       Int(-2),
+			expectedOutput: [][]Expr{{Int(1), Int(-1)}, {Int(-1), Int(2)}},
+		},
+		{
+			name:           "Zeroth power",
+			input:          jordan,
+			exponent:       Int(0),
+			expectedOutput: [][]Expr{{Int(1), Int(0)}, {Int(0), Int(1)}},
+		},
+		{
+			name:           "Symbolic power of a Jordan block",
+			input:          jordan,
+			exponent:       n,
+			expectedOutput: [][]Expr{{Pow(Int(2), n), Mul(n, Pow(Int(2), Sub(n, Int(1))))}, {Int(0), Pow(Int(2), n)}},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			expected, _ := NewMatrix(test.expectedOutput)
+			result, err := test.input.Pow(test.exponent)
+			if err != nil || !matrixIsZero(t, result, expected) {
+				t.Errorf("Following test failed: %s\nInput: %v^%v\nExpected: %v\nGot: %v, error: %v", test.name, test.input, test.exponent, expected, result, err)
+			}
+		})
+	}
+
+	// The symbolic power of the Fibonacci matrix is Binet's formula,
+	// which must agree with the integer powers
+	symbolic, err := fibonacci.Pow(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	integer, _ := fibonacci.Pow(Int(7))
+	if !matrixIsZero(t, symbolic.Substitute(n, Int(7)), integer) {
+		t.Errorf("Symbolic power %v does not agree with %v for n = 7", symbolic, integer)
+	}
+}
@@ -1,5 +1,292 @@
 package gosymbol
 
+import "fmt"
+
 type DuplicateArgumentError struct{}
 
 func (e *DuplicateArgumentError) Error() string { return "multiple variables have the same name" }
+
+type UnboundVariableError struct {
+	Name VarName
+}
+
+func (e *UnboundVariableError) Error() string {
+	return fmt.Sprintf("variable %v has no value bound to it", e.Name)
+}
+
+type NotPolynomialError struct {
+	Expr Expr
+	Var  variable
+}
+
+func (e *NotPolynomialError) Error() string {
+	return fmt.Sprintf("%v is not a polynomial in %v", e.Expr, e.Var)
+}
+
+type BracketError struct {
+	A, B float64
+}
+
+func (e *BracketError) Error() string {
+	return fmt.Sprintf("function does not change sign on [%v, %v]", e.A, e.B)
+}
+
+type ConvergenceError struct {
+	Method     string
+	Iterations int
+}
+
+func (e *ConvergenceError) Error() string {
+	return fmt.Sprintf("%s did not converge within %d iterations", e.Method, e.Iterations)
+}
+
+type NotNumericError struct {
+	Expr Expr
+}
+
+func (e *NotNumericError) Error() string {
+	return fmt.Sprintf("%v does not evaluate to a finite number", e.Expr)
+}
+
+type DimensionMismatchError struct {
+	Expected, Got int
+}
+
+func (e *DimensionMismatchError) Error() string {
+	return fmt.Sprintf("dimension mismatch: expected %d but got %d", e.Expected, e.Got)
+}
+
+type NonSquareMatrixError struct {
+	Rows, Cols int
+}
+
+func (e *NonSquareMatrixError) Error() string {
+	return fmt.Sprintf("matrix is not square: %d x %d", e.Rows, e.Cols)
+}
+
+type SingularMatrixError struct{}
+
+func (e *SingularMatrixError) Error() string { return "matrix is singular" }
+
+type UnsolvablePolynomialError struct {
+	Expr Expr
+	Var  variable
+}
+
+func (e *UnsolvablePolynomialError) Error() string {
+	return fmt.Sprintf("could not solve %v = 0 exactly for %v", e.Expr, e.Var)
+}
+
+type NotDiagonalizableError struct{}
+
+func (e *NotDiagonalizableError) Error() string { return "matrix is not diagonalizable" }
+
+type NonSymmetricMatrixError struct{}
+
+func (e *NonSymmetricMatrixError) Error() string { return "matrix is not symmetric" }
+
+type NotPositiveDefiniteError struct{}
+
+func (e *NotPositiveDefiniteError) Error() string { return "matrix is not positive definite" }
+
+type ZeroPivotError struct {
+	Index int
+}
+
+func (e *ZeroPivotError) Error() string {
+	return fmt.Sprintf("pivot %d is zero", e.Index)
+}
+
+type UnsupportedODEError struct {
+	Eq Expr
+}
+
+func (e *UnsupportedODEError) Error() string {
+	return fmt.Sprintf("no method for solving the differential equation %v = 0", e.Eq)
+}
+
+// Returned by DSolve when the solution could not be
+// solved for the unknown function.
+type ImplicitSolutionError struct {
+	Solution Expr
+}
+
+func (e *ImplicitSolutionError) Error() string {
+	return fmt.Sprintf("only found the implicit solution %v = 0", e.Solution)
+}
+
+type InvalidInitialConditionError struct {
+	Order, ODEOrder int
+}
+
+func (e *InvalidInitialConditionError) Error() string {
+	return fmt.Sprintf("initial condition on derivative %d of a differential equation of order %d", e.Order, e.ODEOrder)
+}
+
+type InconsistentInitialConditionsError struct{}
+
+func (e *InconsistentInitialConditionsError) Error() string {
+	return "initial conditions are inconsistent"
+}
+
+type StepSizeError struct {
+	T float64
+}
+
+func (e *StepSizeError) Error() string {
+	return fmt.Sprintf("step size became too small at t = %v", e.T)
+}
+
+type OutOfRangeError struct {
+	T, Start, End float64
+}
+
+func (e *OutOfRangeError) Error() string {
+	return fmt.Sprintf("%v is outside of the interval [%v, %v]", e.T, e.Start, e.End)
+}
+
+type NonFiniteStateError struct {
+	T float64
+}
+
+func (e *NonFiniteStateError) Error() string {
+	return fmt.Sprintf("solution is not finite at t = %v", e.T)
+}
+
+type UnknownMethodError struct {
+	Method ODEMethod
+}
+
+func (e *UnknownMethodError) Error() string {
+	return fmt.Sprintf("NDSolve is not implemented for method %v", e.Method)
+}
+
+type UnsupportedTransformError struct {
+	Transform string
+	Expr      Expr
+}
+
+func (e *UnsupportedTransformError) Error() string {
+	return fmt.Sprintf("no rule for the %s transform of %v", e.Transform, e.Expr)
+}
+
+type NoClosedFormError struct {
+	Operation string
+	Expr      Expr
+}
+
+func (e *NoClosedFormError) Error() string {
+	return fmt.Sprintf("found no closed form for the %s of %v", e.Operation, e.Expr)
+}
+
+type UnsupportedRecurrenceError struct {
+	Recurrence Expr
+}
+
+func (e *UnsupportedRecurrenceError) Error() string {
+	return fmt.Sprintf("no method for solving the recurrence %v = 0", e.Recurrence)
+}
+
+type RootIndexError struct {
+	Expr         Expr
+	Index, Count int
+}
+
+func (e *RootIndexError) Error() string {
+	return fmt.Sprintf("%v has %d distinct roots, so there is no root number %d", e.Expr, e.Count, e.Index)
+}
+
+type NotRealRootError struct {
+	Expr Expr
+}
+
+func (e *NotRealRootError) Error() string {
+	return fmt.Sprintf("%v is not a real root of a polynomial", e.Expr)
+}
+
+type InvalidModulusError struct {
+	Modulus int64
+}
+
+func (e *InvalidModulusError) Error() string {
+	return fmt.Sprintf("modulus %d is not positive", e.Modulus)
+}
+
+type ModulusMismatchError struct {
+	A, B int64
+}
+
+func (e *ModulusMismatchError) Error() string {
+	return fmt.Sprintf("modulus mismatch: %d and %d", e.A, e.B)
+}
+
+type NotInvertibleError struct {
+	Value, Modulus int64
+}
+
+func (e *NotInvertibleError) Error() string {
+	return fmt.Sprintf("%d is not invertible modulo %d", e.Value, e.Modulus)
+}
+
+type NoSquareRootError struct {
+	Value, Modulus int64
+}
+
+func (e *NoSquareRootError) Error() string {
+	return fmt.Sprintf("%d is not a square modulo %d", e.Value, e.Modulus)
+}
+
+type NotPrimeError struct {
+	N int64
+}
+
+func (e *NotPrimeError) Error() string {
+	return fmt.Sprintf("%d is not prime", e.N)
+}
+
+type InconsistentCongruencesError struct{}
+
+func (e *InconsistentCongruencesError) Error() string { return "congruences are inconsistent" }
+
+type ModulusOverflowError struct{}
+
+func (e *ModulusOverflowError) Error() string { return "modulus does not fit in int64" }
+
+type DivisionByZeroError struct{}
+
+func (e *DivisionByZeroError) Error() string { return "division by zero" }
+
+type NegativeExponentError struct {
+	Exponent int64
+}
+
+func (e *NegativeExponentError) Error() string {
+	return fmt.Sprintf("exponent %d is negative", e.Exponent)
+}
+
+type InvalidArgumentError struct {
+	Function string
+	Arg      Expr
+}
+
+func (e *InvalidArgumentError) Error() string {
+	return fmt.Sprintf("%s is not defined for %v", e.Function, e.Arg)
+}
+
+type SyntaxError struct {
+	Line, Column int
+	Msg          string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+type InvalidNotationError struct {
+	Format string
+	Msg    string
+}
+
+func (e *InvalidNotationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Format, e.Msg)
+}
@@ -24,6 +24,12 @@ func (e variable) Eval() Func {
 		if ok {
 			return value.Simplify()
 		}
+		// Variables are identified by name, whatever their assumptions
+		for arg, value := range args {
+			if arg.Name == e.Name {
+				return value.Simplify()
+			}
+		}
 		return e
 	}
 }
@@ -56,6 +62,47 @@ func (e log) Eval() Func {
 	return func(args Arguments) Expr { return Log(e.Arg.Eval()(args)).Simplify().Simplify() }
 }
 
+func (e sqrt) Eval() Func {
+	return func(args Arguments) Expr { return Sqrt(e.Arg.Eval()(args)).Simplify() }
+}
+
+func (e sin) Eval() Func {
+	return func(args Arguments) Expr { return Sin(e.Arg.Eval()(args)).Simplify() }
+}
+
+func (e cos) Eval() Func {
+	return func(args Arguments) Expr { return Cos(e.Arg.Eval()(args)).Simplify() }
+}
+
+func (e FunctionApplication) Eval() Func {
+	return func(args Arguments) Expr {
+		evaluatedArgs := make([]Expr, len(e.Args))
+		for ix, arg := range e.Args {
+			evaluatedArgs[ix] = arg.Eval()(args)
+		}
+		return Function(e.Name, evaluatedArgs...).Simplify()
+	}
+}
+
+// The variable of differentiation is only bound once the
+// derivative can be evaluated, since there is no way to
+// represent the derivative of an undefined function at a point.
+func (e derivative) Eval() Func {
+	return func(args Arguments) Expr {
+		inner := make(Arguments, len(args))
+		for v, value := range args {
+			if v.Name != e.Var.Name {
+				inner[v] = value
+			}
+		}
+		result := Derivative(e.Arg.Eval()(inner), e.Var).Simplify()
+		if _, ok := result.(derivative); ok {
+			return result
+		}
+		return result.Eval()(args)
+	}
+}
+
 func (e pow) Eval() Func {
 	return func(args Arguments) Expr {
 		return Pow(e.Base.Eval()(args), e.Exponent.Eval()(args)).Simplify()
@@ -77,31 +124,41 @@ func (e constrainedVariable) String() string {
 }
 
 func (e add) String() string {
-	str := fmt.Sprintf("( %v", e.Operands[0])
-	for ix := 1; ix < len(e.Operands); ix++ {
-		str += fmt.Sprintf(" + %v", e.Operands[ix])
-	}
-	str += " )"
-	return str
+	return Format(e, CompactFormat)
 }
 
 func (e mul) String() string {
-	str := fmt.Sprintf("( %v", e.Operands[0])
-	for ix := 1; ix < len(e.Operands); ix++ {
-		str += fmt.Sprintf(" * %v", e.Operands[ix])
-	}
-	str += " )"
-	return str
+	return Format(e, CompactFormat)
 }
 
 func (e exp) String() string {
-	return fmt.Sprintf("exp( %v )", e.Arg)
+	return Format(e, CompactFormat)
 }
 
 func (e log) String() string {
-	return fmt.Sprintf("log( %v )", e.Arg)
+	return Format(e, CompactFormat)
+}
+
+func (e sqrt) String() string {
+	return Format(e, CompactFormat)
+}
+
+func (e sin) String() string {
+	return Format(e, CompactFormat)
+}
+
+func (e cos) String() string {
+	return Format(e, CompactFormat)
+}
+
+func (e FunctionApplication) String() string {
+	return Format(e, CompactFormat)
+}
+
+func (e derivative) String() string {
+	return Format(e, CompactFormat)
 }
 
 func (e pow) String() string {
-	return fmt.Sprintf("( %v^%v )", e.Base, e.Exponent)
+	return Format(e, CompactFormat)
 }
@@ -95,54 +95,51 @@ func TestExprEval(t *testing.T) {
 }
 
 func TestExprString(t *testing.T) {
+	x, y := Var("X"), Var("Y")
+
 	tests := []struct {
 		input          Expr
 		expectedOutput string
+		debugOutput    string
 	}{
-		{
-			input:          Var("X"),
-			expectedOutput: "X",
-		},
-		{
-			input:          Int(-1),
-			expectedOutput: "-1",
-		},
-		{
-			input:          Int(10),
-			expectedOutput: "10",
-		},
-		{
-			input:          Add(Var("X"), Var("Y")),
-			expectedOutput: "( X + Y )",
-		},
-		{
-			input:          Sub(Var("X"), Var("Y")),
-			expectedOutput: "( X + ( -1 * Y ) )",
-		},
-		{
-			input:          Mul(Var("X"), Var("Y")),
-			expectedOutput: "( X * Y )",
-		},
-		{
-			input:          Div(Var("X"), Var("Y")),
-			expectedOutput: "( X * ( Y^-1 ) )",
-		},
-		{
-			input:          Exp(Var("X")),
-			expectedOutput: "exp( X )",
-		},
-		{
-			input:          Log(Var("X")),
-			expectedOutput: "log( X )",
-		},
-		{
-			input:          Pow(Var("X"), Int(9)),
-			expectedOutput: "( X^9 )",
-		},
+		{input: x, expectedOutput: "X", debugOutput: "X"},
+		{input: Int(-1), expectedOutput: "-1", debugOutput: "-1"},
+		{input: Int(10), expectedOutput: "10", debugOutput: "10"},
+		{input: Add(x, y), expectedOutput: "X + Y", debugOutput: "( X + Y )"},
+		{input: Sub(x, y), expectedOutput: "X - Y", debugOutput: "( X + ( -1 * Y ) )"},
+		{input: Mul(x, y), expectedOutput: "X*Y", debugOutput: "( X * Y )"},
+		{input: Div(x, y), expectedOutput: "X/Y", debugOutput: "( X * ( Y^-1 ) )"},
+		{input: Exp(x), expectedOutput: "exp(X)", debugOutput: "exp( X )"},
+		{input: Log(x), expectedOutput: "log(X)", debugOutput: "log( X )"},
+		{input: Pow(x, Int(9)), expectedOutput: "X^9", debugOutput: "( X^9 )"},
+		{input: Mul(Int(2), Pow(x, Int(2))), expectedOutput: "2*X^2", debugOutput: "( 2 * ( X^2 ) )"},
+		{input: Neg(x), expectedOutput: "-X", debugOutput: "( -1 * X )"},
+		{input: Mul(Div(Int(3), Int(2)), x), expectedOutput: "3/2*X", debugOutput: "( 3/2 * X )"},
+		{input: Sub(x, Mul(Int(2), y)), expectedOutput: "X - 2*Y", debugOutput: "( X + ( -1 * 2 * Y ) )"},
+		{input: Sub(x, Add(y, Int(1))), expectedOutput: "X - (Y + 1)", debugOutput: "( X + ( -1 * ( Y + 1 ) ) )"},
+		{input: Neg(Add(x, y)), expectedOutput: "-(X + Y)", debugOutput: "( -1 * ( X + Y ) )"},
+		{input: Mul(Add(x, Int(1)), Pow(x, Int(-1)), Pow(y, Int(-2))), expectedOutput: "(X + 1)/(X*Y^2)", debugOutput: "( ( X + 1 ) * ( X^-1 ) * ( Y^-2 ) )"},
+		{input: Pow(x, Int(-2)), expectedOutput: "1/X^2", debugOutput: "( X^-2 )"},
+		{input: Pow(Pow(x, y), Int(2)), expectedOutput: "(X^Y)^2", debugOutput: "( ( X^Y )^2 )"},
+		{input: Pow(x, Pow(y, Int(2))), expectedOutput: "X^Y^2", debugOutput: "( X^( Y^2 ) )"},
+		{input: Pow(x, Div(Int(1), Int(2))), expectedOutput: "X^(1/2)", debugOutput: "( X^1/2 )"},
+		{input: Pow(Int(-2), x), expectedOutput: "(-2)^X", debugOutput: "( (-2)^X )"},
+		{input: Pow(Neg(x), Neg(y)), expectedOutput: "(-X)^(-Y)", debugOutput: "( ( -1 * X )^( -1 * Y ) )"},
+		{input: Mul(Int(3), I), expectedOutput: "3*I", debugOutput: "( 3 * ( (-1)^1/2 ) )"},
+		{input: Mul(Int(2), Factorial(Add(x, Int(1)))), expectedOutput: "2*(X + 1)!", debugOutput: "( 2 * Factorial( ( X + 1 ) ) )"},
+		{input: Function("f", x, Sin(y)), expectedOutput: "f(X, sin(Y))", debugOutput: "f( X, sin( Y ) )"},
+		{input: Derivative(Function("f", x), x), expectedOutput: "D(f(X), X)", debugOutput: "D( f( X ), X )"},
+		{input: Neg(Neg(x)), expectedOutput: "X", debugOutput: "( -1 * -1 * X )"},
+		{input: Neg(Neg(Neg(x))), expectedOutput: "-X", debugOutput: "( -1 * -1 * -1 * X )"},
+		{input: Neg(Mul(Int(-3), x)), expectedOutput: "3*X", debugOutput: "( -1 * -3 * X )"},
+		{input: Sub(y, Neg(x)), expectedOutput: "Y + X", debugOutput: "( Y + ( -1 * -1 * X ) )"},
+		{input: Mul(Int(3), Pow(Int(2), Int(-1))), expectedOutput: "3/2", debugOutput: "( 3 * ( 2^-1 ) )"},
 	}
 
 	for ix, test := range tests {
 		result := fmt.Sprint(test.input)
 		correctnesCheck(t, strconv.Itoa(ix+1), test.input, test.expectedOutput, result)
+		result = Format(test.input, DebugFormat)
+		correctnesCheck(t, strconv.Itoa(ix+1)+" (debug)", test.input, test.debugOutput, result)
 	}
 }
@@ -12,4 +12,7 @@ func main() {
 
 	fmt.Println("f(x) = ", f)
 	fmt.Println("f'(x) = ", f.D(x))
+
+	g := gosymbol.Div(gosymbol.Sin(x), gosymbol.Add(gosymbol.Pow(x, gosymbol.Int(2)), gosymbol.Int(1)))
+	fmt.Printf("\ng(x) =\n\n%s\n\ng'(x) =\n\n%s\n", gosymbol.Pretty(g), gosymbol.Pretty(g.D(x).Simplify()))
 }
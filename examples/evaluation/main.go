@@ -26,8 +26,7 @@ func main() {
 
 	a, err := gosymbol.ParseLatex("(2+2)*6")
 	if err != nil {
-		println(err)
+		fmt.Println(err)
 	}
-
-	println("%p", &a)
+	fmt.Printf("%v = %v\n", a, a.Simplify())
 }
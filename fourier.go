@@ -0,0 +1,550 @@
+package gosymbol
+
+import (
+	"math"
+)
+
+/*
+A normalization convention (a, b) of the Fourier transform, which is
+
+	F(ω) = sqrt(|b|/(2π)^(1-a)) * ∫ f(t)*exp(i*b*ω*t) dt
+
+with the inverse
+
+	f(t) = sqrt(|b|/(2π)^(1+a)) * ∫ F(ω)*exp(-i*b*ω*t) dω
+
+where b must evaluate to a non-zero number.
+*/
+type FourierConvention struct {
+	A, B Expr
+}
+
+var (
+	// F(ω) = 1/sqrt(2π) * ∫ f(t)*exp(i*ω*t) dt
+	UnitaryAngularFourier = FourierConvention{A: Int(0), B: Int(1)}
+	// F(ω) = ∫ f(t)*exp(-i*ω*t) dt
+	NonUnitaryAngularFourier = FourierConvention{A: Int(1), B: Int(-1)}
+	// F(ν) = ∫ f(t)*exp(-2π*i*ν*t) dt
+	OrdinaryFrequencyFourier = FourierConvention{A: Int(0), B: Mul(Int(-2), PI)}
+)
+
+/*
+Returns the first terms of the Fourier series of expr w.r.t. x over the
+period [-period/2, period/2], i.e.
+
+	a_0/2 + sum_{n=1}^{nTerms} a_n*cos(n*ω*x) + b_n*sin(n*ω*x)
+
+with ω = 2π/period, where the coefficients are computed by symbolic
+definite integration. An InvalidArgumentError is returned for a zero
+period or a negative nTerms, and an UnsupportedTransformError if the
+integrals could not be computed.
+*/
+func FourierSeries(expr Expr, x variable, period Expr, nTerms int) (Expr, error) {
+	if isZero(period) {
+		return nil, &InvalidArgumentError{Function: "FourierSeries", Arg: period}
+	} else if nTerms < 0 {
+		return nil, &InvalidArgumentError{Function: "FourierSeries", Arg: Int(int64(nTerms))}
+	}
+	omega := Div(Mul(Int(2), PI), period)
+	lower, upper := Div(period, Int(-2)), Div(period, Int(2))
+	coefficient := func(trig Expr) (Expr, bool) {
+		integral, ok := definiteIntegral(Mul(expr, trig), x, lower, upper)
+		if !ok {
+			return nil, false
+		}
+		return Cancel(Div(Mul(Int(2), integral), period)), true
+	}
+
+	a0, ok := coefficient(Int(1))
+	if !ok {
+		return nil, &UnsupportedTransformError{Transform: "Fourier series", Expr: expr}
+	}
+	terms := []Expr{Div(a0, Int(2))}
+	for n := 1; n <= nTerms; n++ {
+		arg := Mul(Cancel(Mul(Int(int64(n)), omega)), x)
+		a, ok := coefficient(Cos(arg))
+		if !ok {
+			return nil, &UnsupportedTransformError{Transform: "Fourier series", Expr: expr}
+		}
+		b, ok := coefficient(Sin(arg))
+		if !ok {
+			return nil, &UnsupportedTransformError{Transform: "Fourier series", Expr: expr}
+		}
+		terms = append(terms, Mul(a, Cos(arg)), Mul(b, Sin(arg)))
+	}
+	return Add(terms...).Simplify(), nil
+}
+
+/*
+Returns the Fourier transform of expr w.r.t. t as an expression in w,
+normalized according to convention.
+
+The transform is computed term by term for products of polynomials,
+rational functions, exponentials of polynomials of degree at most two
+(which includes Gaussians), sin, cos, Sinc, and at most one Heaviside or
+Rect window or DiracDelta, where the arguments of the trigonometric and
+special functions must be linear in t. Transforms that only exist as
+distributions are written with DiracDelta and its derivatives.
+
+An UnsupportedTransformError is returned if a term is of none of these
+forms or if its transform does not converge.
+*/
+func FourierTransform(expr Expr, t, w variable, convention FourierConvention) (Expr, error) {
+	scale, b, err := convention.scale(1)
+	if err != nil {
+		return nil, err
+	}
+	k := freshVariable("k", expr, w)
+	transform, ok := fourierIntegral(expr, t, k)
+	if !ok {
+		return nil, &UnsupportedTransformError{Transform: "Fourier", Expr: expr}
+	}
+	return tidyFourier(Mul(scale, Substitute(transform, k, Neg(Mul(b, w)))), w), nil
+}
+
+/*
+Returns the inverse Fourier transform of expr w.r.t. w as an expression
+in t, normalized according to convention. The same classes of functions
+as for FourierTransform are supported.
+*/
+func InverseFourierTransform(expr Expr, w, t variable, convention FourierConvention) (Expr, error) {
+	scale, b, err := convention.scale(-1)
+	if err != nil {
+		return nil, err
+	}
+	k := freshVariable("k", expr, t)
+	transform, ok := fourierIntegral(expr, w, k)
+	if !ok {
+		return nil, &UnsupportedTransformError{Transform: "inverse Fourier", Expr: expr}
+	}
+	return tidyFourier(Mul(scale, Substitute(transform, k, Mul(b, t))), t), nil
+}
+
+// Returns sqrt(|b|/(2π)^(1-sign*a)) and b.
+func (c FourierConvention) scale(sign int64) (Expr, Expr, error) {
+	bSign, ok := numericSign(c.B)
+	if !ok || bSign == 0 {
+		return nil, nil, &NotNumericError{Expr: c.B}
+	}
+	absB := Mul(Int(int64(bSign)), c.B)
+	power := Sub(Int(1), Mul(Int(sign), c.A))
+	return Pow(Div(absB, Pow(Mul(Int(2), PI), power)), Div(Int(1), Int(2))).Simplify(), c.B, nil
+}
+
+// Returns the sign of expr if it evaluates to a number.
+func numericSign(expr Expr) (int, bool) {
+	value, err := EvalFloat(expr, nil)
+	if err != nil || math.IsNaN(value) {
+		return 0, false
+	}
+	switch {
+	case math.Abs(value) < 1e-12:
+		return 0, true
+	case value > 0:
+		return 1, true
+	}
+	return -1, true
+}
+
+/*
+Returns ∫ expr*exp(-i*k*t) dt over the real line, and false if no rule
+applies. The terms of the expanded expression are transformed separately.
+*/
+func fourierIntegral(expr Expr, t, k variable) (Expr, bool) {
+	terms, ok := expandedTerms(expandExp(expandSinc(expr)))
+	if !ok {
+		return nil, false
+	}
+
+	result := make([]Expr, len(terms))
+	for ix, term := range terms {
+		constant, factors := splitConstantFactors(term, t)
+		transform, ok := fourierTerm(factors, t, k)
+		if !ok {
+			return nil, false
+		}
+		result[ix] = Mul(constant, transform)
+	}
+	return Add(result...), true
+}
+
+// Replaces Sinc(u) by sin(u)/u in expr.
+func expandSinc(expr Expr) Expr {
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		expr = replaceOperand(expr, ix, expandSinc(Operand(expr, ix)))
+	}
+	if f, ok := expr.(FunctionApplication); ok && f.Name == sincName && len(f.Args) == 1 {
+		return Div(Sin(f.Args[0]), f.Args[0])
+	}
+	return expr
+}
+
+/*
+Transforms the product of factors. A DiracDelta factor is removed by the
+sifting property, and sin and cos are written as complex exponentials,
+which leaves terms of the form R(t)*exp(p(t)) with a rational R and a
+polynomial p that are transformed by fourierExponential.
+*/
+func fourierTerm(factors []Expr, t, k variable) (Expr, bool) {
+	for ix, f := range factors {
+		if delta, ok := f.(FunctionApplication); ok && delta.Name == diracDeltaName {
+			others := append(append([]Expr{}, factors[:ix]...), factors[ix+1:]...)
+			return siftDelta(delta, others, t, k)
+		}
+	}
+
+	var exponent Expr = Int(0)
+	var window Expr
+	rest := []Expr{}
+	trig := []Expr{}
+	for _, f := range factors {
+		if u, ok := exponentOf(f, t); ok {
+			exponent = Add(exponent, u)
+			continue
+		}
+		switch e := f.(type) {
+		case sin:
+			if isLinearIn(e.Arg, t) {
+				trig = append(trig, e)
+				continue
+			}
+		case cos:
+			if isLinearIn(e.Arg, t) {
+				trig = append(trig, e)
+				continue
+			}
+		case FunctionApplication:
+			if (e.Name != heavisideName && e.Name != rectName) || window != nil ||
+				len(e.Args) != 1 || !isLinearIn(e.Args[0], t) {
+				return nil, false
+			}
+			window = e
+			continue
+		}
+		rest = append(rest, f)
+	}
+	R := Mul(rest...).Simplify()
+	if !isRationalIn(R, t) {
+		return nil, false
+	}
+
+	// Writes cos(u) = (exp(i*u) + exp(-i*u))/2 and
+	// sin(u) = (exp(i*u) - exp(-i*u))/(2*i)
+	type exponential struct{ coeff, exponent Expr }
+	exponentials := []exponential{{coeff: Int(1), exponent: exponent}}
+	for _, f := range trig {
+		arg := Mul(I, Operand(f, 1))
+		plus, minus := Div(Int(1), Int(2)), Div(Int(1), Int(2))
+		if _, ok := f.(sin); ok {
+			plus, minus = Div(Int(1), Mul(Int(2), I)), Div(Int(-1), Mul(Int(2), I))
+		}
+		next := []exponential{}
+		for _, e := range exponentials {
+			next = append(next,
+				exponential{coeff: Mul(e.coeff, plus), exponent: Add(e.exponent, arg)},
+				exponential{coeff: Mul(e.coeff, minus), exponent: Sub(e.exponent, arg)})
+		}
+		exponentials = next
+	}
+
+	terms := make([]Expr, len(exponentials))
+	for ix, e := range exponentials {
+		transform, ok := fourierExponential(R, e.exponent, window, t, k)
+		if !ok {
+			return nil, false
+		}
+		terms[ix] = Mul(e.coeff, transform)
+	}
+	return Add(terms...), true
+}
+
+// Returns ∫ g(t)*δ(a*t + b)*exp(-i*k*t) dt = g(t0)*exp(-i*k*t0)/|a| with
+// t0 = -b/a, where g is the product of the other factors.
+func siftDelta(delta FunctionApplication, others []Expr, t, k variable) (Expr, bool) {
+	if len(delta.Args) != 1 || !isLinearIn(delta.Args[0], t) {
+		return nil, false
+	}
+	a, b := linearCoefficients(delta.Args[0], t)
+	sign, ok := numericSign(a)
+	if !ok {
+		return nil, false
+	}
+	t0 := Cancel(Div(Neg(b), a))
+	g := Mul(append(others, Exp(Neg(Mul(I, k, t))))...)
+	return Div(Substitute(g, t, t0), Mul(Int(int64(sign)), a)), true
+}
+
+// Returns u if f is exp(u) or exp(u)^n, where u = n*u is
+// a polynomial in t of degree at most two.
+func exponentOf(f Expr, t variable) (Expr, bool) {
+	var u Expr
+	switch e := f.(type) {
+	case exp:
+		u = e.Arg
+	case pow:
+		base, ok := e.Base.(exp)
+		if !ok || RecContains(e.Exponent, t) {
+			return nil, false
+		}
+		u = Mul(e.Exponent, base.Arg)
+	default:
+		return nil, false
+	}
+	coeffs, err := polynomialCoefficients(Expand(u), t)
+	if err != nil || len(coeffs) > 3 {
+		return nil, false
+	}
+	return u, true
+}
+
+/*
+Returns ∫ R(t)*exp(p(t))*w(t)*exp(-i*k*t) dt where p is a polynomial of
+degree at most two and w an optional Heaviside or Rect window. The
+polynomial part of R is handled by the differentiation theorem, i.e.
+multiplying by t^n corresponds to i^n*D^n(transform, k).
+*/
+func fourierExponential(R, p, window Expr, t, k variable) (Expr, bool) {
+	coeffs, err := polynomialCoefficients(Expand(p), t)
+	if err != nil {
+		return nil, false
+	}
+	for len(coeffs) < 3 {
+		coeffs = append(coeffs, Int(0))
+	}
+	p0, p1, p2 := coeffs[0], coeffs[1], coeffs[2]
+
+	var transform Expr
+	var polynomial []Expr
+	switch {
+	case window != nil:
+		if !isZero(p2) {
+			return nil, false
+		}
+		if polynomial, err = polynomialCoefficients(R, t); err != nil {
+			return nil, false
+		}
+		var ok bool
+		if transform, ok = windowIntegral(window, p1, t, k); !ok {
+			return nil, false
+		}
+
+	case !isZero(p2):
+		// ∫ exp(p2*t^2 + c*t) dt = sqrt(-π/p2)*exp(-c^2/(4*p2)) if Re(p2) < 0
+		re, _, ok := splitComplex(p2)
+		if sign, known := numericSign(re); !ok || !known || sign >= 0 {
+			return nil, false
+		}
+		if polynomial, err = polynomialCoefficients(R, t); err != nil {
+			return nil, false
+		}
+		c := Sub(p1, Mul(I, k))
+		transform = Mul(Pow(Div(Neg(PI), p2), Div(Int(1), Int(2))), Exp(Div(Neg(Pow(c, Int(2))), Mul(Int(4), p2))))
+
+	default:
+		// exp(i*ω0*t) shifts the transform of R by ω0
+		re, im, ok := splitComplex(p1)
+		if !ok || !isZero(re) {
+			return nil, false
+		}
+		shifted := freshVariable("κ", R, k)
+		rational, ok := rationalFourier(R, t, shifted)
+		if !ok {
+			return nil, false
+		}
+		transform = Substitute(rational, shifted, Sub(k, im))
+		polynomial = []Expr{Int(1)}
+	}
+
+	terms := make([]Expr, len(polynomial))
+	derivative := transform
+	for n, c := range polynomial {
+		terms[n] = Mul(c, Pow(I, Int(int64(n))), derivative)
+		derivative = differentiate(derivative, k)
+	}
+	return Mul(Exp(p0), Add(terms...)), true
+}
+
+/*
+Returns ∫ exp(c*t) dt with c = p1 - i*k over the support of the
+Heaviside or Rect window. Infinite ends require Re(p1) <= 0 at +∞ and
+Re(p1) >= 0 at -∞, where Re(p1) = 0 gives a DiracDelta term.
+*/
+func windowIntegral(window, p1 Expr, t, k variable) (Expr, bool) {
+	f := window.(FunctionApplication)
+	a, b := linearCoefficients(f.Args[0], t)
+	aSign, ok := numericSign(a)
+	if !ok {
+		return nil, false
+	}
+	c := Sub(p1, Mul(I, k))
+	antiderivative := func(x Expr) Expr { return Div(Exp(Mul(c, x)), c) }
+
+	if f.Name == rectName {
+		lo, hi := Div(Sub(Div(Int(-1), Int(2)), b), a), Div(Sub(Div(Int(1), Int(2)), b), a)
+		if aSign < 0 {
+			lo, hi = hi, lo
+		}
+		return Sub(antiderivative(hi), antiderivative(lo)), true
+	}
+
+	// The window is [t0, ∞) for a > 0 and (-∞, t0] for a < 0
+	t0 := Div(Neg(b), a)
+	re, _, ok := splitComplex(p1)
+	if !ok {
+		return nil, false
+	}
+	reSign, ok := numericSign(re)
+	if !ok || reSign*aSign > 0 {
+		return nil, false
+	}
+	var transform Expr = Neg(antiderivative(t0))
+	if aSign < 0 {
+		transform = antiderivative(t0)
+	}
+	if reSign == 0 {
+		delta := DiracDelta(Expand(Mul(I, c)))
+		transform = Add(transform, Mul(PI, delta))
+	}
+	return transform, true
+}
+
+/*
+Returns ∫ R(t)*exp(-i*k*t) dt for a rational function R, through the
+partial fractions of R. The polynomial part gives derivatives of DiracDelta,
+and with G_1 the transform of 1/(t - r),
+
+	G_1 = 2π*i*exp(-i*k*r)*Heaviside(-k)          if Im(r) > 0
+	G_1 = -2π*i*exp(-i*k*r)*Heaviside(k)          if Im(r) < 0
+	G_1 = -π*i*exp(-i*k*r)*(2*Heaviside(k) - 1)   if Im(r) = 0
+
+the transform of 1/(t - r)^m is (-i*k)^(m-1)/(m-1)! * G_1, where real poles
+are taken in the principal value sense.
+*/
+func rationalFourier(R Expr, t, k variable) (Expr, bool) {
+	quotient, fractions, ok := partialFractions(R, t)
+	if !ok {
+		return nil, false
+	}
+	coeffs, err := polynomialCoefficients(quotient, t)
+	if err != nil {
+		return nil, false
+	}
+
+	terms := []Expr{}
+	var delta Expr = Mul(Int(2), PI, DiracDelta(k))
+	for n, c := range coeffs {
+		terms = append(terms, Mul(c, Pow(I, Int(int64(n))), delta))
+		delta = differentiate(delta, k)
+	}
+	for _, f := range fractions {
+		_, im, ok := splitComplex(f.root)
+		if !ok {
+			return nil, false
+		}
+		sign, ok := numericSign(im)
+		if !ok {
+			return nil, false
+		}
+		phase := Exp(Neg(Mul(I, k, f.root)))
+		var g Expr
+		switch sign {
+		case 1:
+			g = Mul(Int(2), PI, I, phase, Heaviside(Neg(k)))
+		case -1:
+			g = Mul(Int(-2), PI, I, phase, Heaviside(k))
+		default:
+			g = Mul(Neg(PI), I, phase, Sub(Mul(Int(2), Heaviside(k)), Int(1)))
+		}
+		power := Div(Pow(Neg(Mul(I, k)), Int(int64(f.power-1))), Int(factorial(f.power-1)))
+		terms = append(terms, Mul(f.coeff, power, g))
+	}
+	return Add(terms...), true
+}
+
+/*
+Writes a transform without the imaginary unit in the exponentials where
+possible, i.e. exp(a + i*b) becomes exp(a)*(cos(b) + i*sin(b)), so that
+the imaginary parts of real transforms cancel. The signs of the arguments
+of cos and DiracDelta, which are even, and sin, which is odd, are
+normalized as well, and the arguments of Heaviside are scaled to have
+v with coefficient ±1.
+*/
+func tidyFourier(expr Expr, v variable) Expr {
+	result := tidy(normalizeFourier(tidy(expr), v))
+	if isRationalIn(result, v) {
+		return Cancel(result)
+	}
+	return result
+}
+
+func normalizeFourier(expr Expr, v variable) Expr {
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		expr = replaceOperand(expr, ix, normalizeFourier(Operand(expr, ix), v))
+	}
+	switch e := expr.(type) {
+	case exp:
+		re, im, ok := splitComplex(Expand(e.Arg))
+		if !ok || isZero(im) {
+			return expr
+		}
+		return Mul(Exp(re), Add(trigOf(false, im), Mul(I, trigOf(true, im))))
+	case sin:
+		return trigOf(true, Expand(e.Arg))
+	case cos:
+		return trigOf(false, Expand(e.Arg))
+	case FunctionApplication:
+		if e.Name == heavisideName && len(e.Args) == 1 && isLinearIn(e.Args[0], v) {
+			// Heaviside(a*v + b) = Heaviside(sign(a)*(v + b/a))
+			a, b := linearCoefficients(e.Args[0], v)
+			if sign, ok := numericSign(a); ok {
+				return Heaviside(Expand(Mul(Int(int64(sign)), Add(v, Div(b, a)))))
+			}
+		}
+		if e.Name != diracDeltaName || len(e.Args) != 1 {
+			break
+		}
+		// The sign is chosen from the first non-constant term
+		arg := Expand(e.Args[0])
+		terms := []Expr{arg}
+		if sum, ok := arg.(add); ok {
+			terms = sum.Operands
+		}
+		for _, t := range terms {
+			if _, ok := t.(rational); !ok {
+				if isNegativeForm(t) {
+					return DiracDelta(Expand(Neg(arg)))
+				}
+				break
+			}
+		}
+	}
+	return expr
+}
+
+/*
+Returns ∫ expr dv from a to b as F(b) - F(a) for an antiderivative F,
+where the arguments of sin and cos are simplified after the substitution
+so that e.g. sin(n*π) is recognised as zero.
+*/
+func definiteIntegral(expr Expr, v variable, a, b Expr) (Expr, bool) {
+	antiderivative, ok := integrate(expr, v)
+	if !ok {
+		return nil, false
+	}
+	upper := simplifyTrigArguments(Substitute(antiderivative, v, b))
+	lower := simplifyTrigArguments(Substitute(antiderivative, v, a))
+	return Sub(upper, lower).Simplify(), true
+}
+
+func simplifyTrigArguments(expr Expr) Expr {
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		expr = replaceOperand(expr, ix, simplifyTrigArguments(Operand(expr, ix)))
+	}
+	switch e := expr.(type) {
+	case sin:
+		return Sin(Cancel(e.Arg)).Simplify()
+	case cos:
+		return Cos(Cancel(e.Arg)).Simplify()
+	}
+	return expr.Simplify()
+}
@@ -0,0 +1,201 @@
+package gosymbol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFourierSeries(t *testing.T) {
+	x := Var("x")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		period         Expr
+		nTerms         int
+		expectedOutput Expr
+	}{
+		{
+			name:   "Sawtooth",
+			input:  x,
+			period: Mul(Int(2), PI),
+			nTerms: 3,
+			expectedOutput: Add(
+				Mul(Int(2), Sin(x)),
+				Neg(Sin(Mul(Int(2), x))),
+				Mul(Div(Int(2), Int(3)), Sin(Mul(Int(3), x))),
+			),
+		},
+		{
+			name:   "Parabola",
+			input:  Pow(x, Int(2)),
+			period: Int(2),
+			nTerms: 2,
+			expectedOutput: Add(
+				Div(Int(1), Int(3)),
+				Mul(Int(-4), Pow(PI, Int(-2)), Cos(Mul(PI, x))),
+				Mul(Pow(PI, Int(-2)), Cos(Mul(Int(2), PI, x))),
+			),
+		},
+		{
+			name:           "Trigonometric polynomial",
+			input:          Add(Int(1), Sin(Mul(Int(2), x)), Pow(Cos(x), Int(2))),
+			period:         Mul(Int(2), PI),
+			nTerms:         3,
+			expectedOutput: Add(Div(Int(3), Int(2)), Sin(Mul(Int(2), x)), Div(Cos(Mul(Int(2), x)), Int(2))),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := FourierSeries(test.input, x, test.period, test.nTerms)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+			if !isZero(Sub(result, test.expectedOutput)) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestFourierSeriesErrors(t *testing.T) {
+	x := Var("x")
+	tests := []struct {
+		name   string
+		period Expr
+		nTerms int
+	}{
+		{name: "Zero period", period: Int(0), nTerms: 2},
+		{name: "Period simplifying to zero", period: Sub(PI, PI), nTerms: 2},
+		{name: "Negative number of terms", period: PI, nTerms: -1},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			_, err := FourierSeries(x, x, test.period, test.nTerms)
+			if _, ok := err.(*InvalidArgumentError); !ok {
+				t.Errorf("Following test failed: %s\nExpected: InvalidArgumentError\nGot: %v", test.name, err)
+			}
+		})
+	}
+}
+
+func TestFourierTransform(t *testing.T) {
+	x, w := Var("t"), Var("w")
+	i := I
+
+	tests := []struct {
+		name           string
+		input          Expr
+		convention     FourierConvention
+		expectedOutput Expr
+		// The inverse transform of expectedOutput if it is
+		// written differently from input
+		expectedInverse Expr
+	}{
+		{
+			name:           "Gaussian",
+			input:          Exp(Neg(Pow(x, Int(2)))),
+			convention:     NonUnitaryAngularFourier,
+			expectedOutput: Mul(Sqrt(PI), Exp(Div(Pow(w, Int(2)), Int(-4)))),
+		},
+		{
+			name:           "Unitary Gaussian",
+			input:          Exp(Div(Pow(x, Int(2)), Int(-2))),
+			convention:     UnitaryAngularFourier,
+			expectedOutput: Exp(Div(Pow(w, Int(2)), Int(-2))),
+		},
+		{
+			name:           "One-sided exponential",
+			input:          Mul(Exp(Mul(Int(-2), x)), Heaviside(x)),
+			convention:     NonUnitaryAngularFourier,
+			expectedOutput: Div(Int(1), Add(Int(2), Mul(i, w))),
+		},
+		{
+			name:           "Two-sided exponential",
+			input:          Div(Int(1), Add(Pow(x, Int(2)), Int(1))),
+			convention:     NonUnitaryAngularFourier,
+			expectedOutput: Mul(PI, Add(Mul(Exp(Neg(w)), Heaviside(w)), Mul(Exp(w), Heaviside(Neg(w))))),
+		},
+		{
+			name:            "Rect",
+			input:           Rect(x),
+			convention:      NonUnitaryAngularFourier,
+			expectedOutput:  Div(Mul(Int(2), Sin(Div(w, Int(2)))), w),
+			expectedInverse: Sub(Heaviside(Sub(Div(Int(1), Int(2)), x)), Heaviside(Sub(Div(Int(-1), Int(2)), x))),
+		},
+		{
+			name:            "Rect in ordinary frequency",
+			input:           Rect(x),
+			convention:      OrdinaryFrequencyFourier,
+			expectedOutput:  Div(Sin(Mul(PI, w)), Mul(PI, w)),
+			expectedInverse: Sub(Heaviside(Sub(Div(Int(1), Int(2)), x)), Heaviside(Sub(Div(Int(-1), Int(2)), x))),
+		},
+		{
+			name:           "Sinc",
+			input:          Sinc(x),
+			convention:     NonUnitaryAngularFourier,
+			expectedOutput: Mul(PI, Sub(Heaviside(Add(w, Int(1))), Heaviside(Sub(w, Int(1))))),
+		},
+		{
+			name:           "Shifted Dirac delta",
+			input:          DiracDelta(Sub(x, Int(2))),
+			convention:     NonUnitaryAngularFourier,
+			expectedOutput: Sub(Cos(Mul(Int(2), w)), Mul(i, Sin(Mul(Int(2), w)))),
+		},
+		{
+			name:           "Constant",
+			input:          Int(1),
+			convention:     NonUnitaryAngularFourier,
+			expectedOutput: Mul(Int(2), PI, DiracDelta(w)),
+		},
+		{
+			name:           "Cosine",
+			input:          Cos(Mul(Int(3), x)),
+			convention:     NonUnitaryAngularFourier,
+			expectedOutput: Mul(PI, Add(DiracDelta(Sub(w, Int(3))), DiracDelta(Add(w, Int(3))))),
+		},
+		{
+			name:           "Modulated Gaussian",
+			input:          Mul(x, Exp(Neg(Pow(x, Int(2))))),
+			convention:     NonUnitaryAngularFourier,
+			expectedOutput: Mul(Div(Int(-1), Int(2)), i, w, Sqrt(PI), Exp(Div(Pow(w, Int(2)), Int(-4)))),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := FourierTransform(test.input, x, w, test.convention)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+			if !isZero(expandExp(Sub(result, test.expectedOutput))) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+
+			// The inverse transform must give back the input
+			expectedInverse := test.expectedInverse
+			if expectedInverse == nil {
+				expectedInverse = expandSinc(test.input)
+			}
+			inverse, err := InverseFourierTransform(result, w, x, test.convention)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error in inverse: %v", test.name, err)
+			}
+			if !isZero(expandExp(Sub(inverse, expectedInverse))) {
+				t.Errorf("Following test failed: %s\nInput: %v\nGot inverse: %v", test.name, test.input, inverse)
+			}
+		})
+	}
+}
+
+func TestFourierTransformUnsupported(t *testing.T) {
+	x, w := Var("t"), Var("w")
+	for _, input := range []Expr{Exp(Pow(x, Int(2))), Exp(x), Log(x)} {
+		_, err := FourierTransform(input, x, w, NonUnitaryAngularFourier)
+		if _, ok := err.(*UnsupportedTransformError); !ok {
+			t.Errorf("Expected UnsupportedTransformError for %v but got: %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package gosymbol
+
+import "fmt"
+
+/*
+A GFPoly is a univariate polynomial with coefficients in the prime
+field GF(p). The coefficients are stored in increasing degree with
+the leading coefficient last, and the zero polynomial has no
+coefficients. As for ModInt, arithmetic between polynomials over
+different fields is an error.
+
+Polynomials over finite fields are what modular algorithms for the
+GCD and factorization of integer polynomials reduce to.
+*/
+type GFPoly struct {
+	coeffs []int64
+	p      int64
+}
+
+/*
+Returns the polynomial c_0 + c_1*x + ... + c_n*x^n over f from the
+coefficients in increasing degree, e.g. F.Poly(1, 0, 1) = x^2 + 1.
+*/
+func (f FiniteField) Poly(coeffs ...int64) GFPoly {
+	reduced := make([]int64, len(coeffs))
+	for ix, c := range coeffs {
+		reduced[ix] = reduceMod(c, f.p)
+	}
+	return GFPoly{coeffs: reduced, p: f.p}.trim()
+}
+
+/*
+Returns the image over f of the polynomial expr in x with rational
+coefficients. NotPolynomialError is returned if expr is not such a
+polynomial and NotInvertibleError if a denominator is divisible by
+the characteristic.
+*/
+func (f FiniteField) PolyFromExpr(expr Expr, x variable) (GFPoly, error) {
+	coeffs, err := polynomialCoefficients(Expand(expr), x)
+	if err != nil {
+		return GFPoly{}, err
+	}
+	reduced := make([]int64, len(coeffs))
+	for ix, c := range coeffs {
+		r, ok := c.(rational)
+		if !ok {
+			return GFPoly{}, &NotPolynomialError{Expr: expr, Var: x}
+		}
+		den := r.denominator().value
+		inv, ok := invMod(den, f.p)
+		if !ok {
+			return GFPoly{}, &NotInvertibleError{Value: reduceMod(den, f.p), Modulus: f.p}
+		}
+		reduced[ix] = mulMod(reduceMod(r.numerator().value, f.p), inv, f.p)
+	}
+	return GFPoly{coeffs: reduced, p: f.p}.trim(), nil
+}
+
+func (a GFPoly) trim() GFPoly {
+	n := len(a.coeffs)
+	for n > 0 && a.coeffs[n-1] == 0 {
+		n--
+	}
+	a.coeffs = a.coeffs[:n]
+	return a
+}
+
+func (a GFPoly) Field() FiniteField { return FiniteField{p: a.p} }
+
+// The degree of a, where the zero polynomial has degree -1.
+func (a GFPoly) Degree() int { return len(a.coeffs) - 1 }
+
+func (a GFPoly) IsZero() bool { return len(a.coeffs) == 0 }
+
+// Returns the coefficient of x^n.
+func (a GFPoly) Coeff(n int) ModInt {
+	if n < 0 || n >= len(a.coeffs) {
+		return ModInt{value: 0, modulus: a.p}
+	}
+	return ModInt{value: a.coeffs[n], modulus: a.p}
+}
+
+func (a GFPoly) LeadingCoeff() ModInt {
+	return a.Coeff(a.Degree())
+}
+
+func (a GFPoly) Equal(b GFPoly) bool {
+	if a.p != b.p || len(a.coeffs) != len(b.coeffs) {
+		return false
+	}
+	for ix := range a.coeffs {
+		if a.coeffs[ix] != b.coeffs[ix] {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns a as an expression in x with coefficients in [0, p).
+func (a GFPoly) ToExpr(x variable) Expr {
+	if a.IsZero() {
+		return Int(0)
+	}
+	terms := []Expr{}
+	for n := a.Degree(); n >= 0; n-- {
+		c := a.coeffs[n]
+		switch {
+		case c == 0:
+			continue
+		case n == 0:
+			terms = append(terms, Int(c))
+		case n == 1 && c == 1:
+			terms = append(terms, x)
+		case n == 1:
+			terms = append(terms, Mul(Int(c), x))
+		case c == 1:
+			terms = append(terms, Pow(x, Int(int64(n))))
+		default:
+			terms = append(terms, Mul(Int(c), Pow(x, Int(int64(n)))))
+		}
+	}
+	return sumOf(terms)
+}
+
+func (a GFPoly) String() string {
+	return fmt.Sprintf("%v over GF(%d)", a.ToExpr(Var("x")), a.p)
+}
+
+func (a GFPoly) Add(b GFPoly) (GFPoly, error) {
+	if a.p != b.p {
+		return GFPoly{}, &ModulusMismatchError{A: a.p, B: b.p}
+	}
+	n := max(len(a.coeffs), len(b.coeffs))
+	sum := make([]int64, n)
+	for ix := range sum {
+		sum[ix] = addMod(a.Coeff(ix).value, b.Coeff(ix).value, a.p)
+	}
+	return GFPoly{coeffs: sum, p: a.p}.trim(), nil
+}
+
+func (a GFPoly) Sub(b GFPoly) (GFPoly, error) {
+	return a.Add(b.Neg())
+}
+
+func (a GFPoly) Neg() GFPoly {
+	neg := make([]int64, len(a.coeffs))
+	for ix, c := range a.coeffs {
+		neg[ix] = ModInt{value: c, modulus: a.p}.Neg().value
+	}
+	return GFPoly{coeffs: neg, p: a.p}
+}
+
+func (a GFPoly) Mul(b GFPoly) (GFPoly, error) {
+	if a.p != b.p {
+		return GFPoly{}, &ModulusMismatchError{A: a.p, B: b.p}
+	}
+	if a.IsZero() || b.IsZero() {
+		return GFPoly{p: a.p}, nil
+	}
+	product := make([]int64, len(a.coeffs)+len(b.coeffs)-1)
+	for ix, c := range a.coeffs {
+		for jx, d := range b.coeffs {
+			product[ix+jx] = addMod(product[ix+jx], mulMod(c, d, a.p), a.p)
+		}
+	}
+	return GFPoly{coeffs: product, p: a.p}.trim(), nil
+}
+
+// Returns c*a for a constant c in the field of a.
+func (a GFPoly) Scale(c ModInt) (GFPoly, error) {
+	if a.p != c.modulus {
+		return GFPoly{}, &ModulusMismatchError{A: a.p, B: c.modulus}
+	}
+	scaled := make([]int64, len(a.coeffs))
+	for ix, d := range a.coeffs {
+		scaled[ix] = mulMod(c.value, d, a.p)
+	}
+	return GFPoly{coeffs: scaled, p: a.p}.trim(), nil
+}
+
+// Returns a divided by its leading coefficient. The zero polynomial is returned as is.
+func (a GFPoly) Monic() GFPoly {
+	if a.IsZero() {
+		return a
+	}
+	// The leading coefficient of a non-zero polynomial over a field is invertible
+	inv, _ := a.LeadingCoeff().Inv()
+	monic, _ := a.Scale(inv)
+	return monic
+}
+
+/*
+Returns the quotient q and remainder r of the division of a by d,
+so that a = q*d + r with deg r < deg d.
+*/
+func (a GFPoly) QuoRem(d GFPoly) (GFPoly, GFPoly, error) {
+	if a.p != d.p {
+		return GFPoly{}, GFPoly{}, &ModulusMismatchError{A: a.p, B: d.p}
+	}
+	if d.IsZero() {
+		return GFPoly{}, GFPoly{}, &DivisionByZeroError{}
+	}
+	inv, _ := d.LeadingCoeff().Inv()
+	rem := append([]int64{}, a.coeffs...)
+	if len(rem) < len(d.coeffs) {
+		return GFPoly{p: a.p}, a, nil
+	}
+	quo := make([]int64, len(rem)-len(d.coeffs)+1)
+	for n := len(quo) - 1; n >= 0; n-- {
+		c := mulMod(rem[n+d.Degree()], inv.value, a.p)
+		quo[n] = c
+		if c == 0 {
+			continue
+		}
+		for ix, e := range d.coeffs {
+			rem[n+ix] = addMod(rem[n+ix], a.p-mulMod(c, e, a.p), a.p)
+		}
+	}
+	return GFPoly{coeffs: quo, p: a.p}.trim(), GFPoly{coeffs: rem, p: a.p}.trim(), nil
+}
+
+// Returns the monic greatest common divisor of a and b by the Euclidean algorithm.
+func (a GFPoly) GCD(b GFPoly) (GFPoly, error) {
+	if a.p != b.p {
+		return GFPoly{}, &ModulusMismatchError{A: a.p, B: b.p}
+	}
+	for !b.IsZero() {
+		_, r, _ := a.QuoRem(b)
+		a, b = b, r
+	}
+	return a.Monic(), nil
+}
+
+/*
+Returns a^n mod m by square-and-multiply, reducing after every
+step so that the degree stays below that of m. This is the basic
+operation of the distinct and equal degree factorization
+algorithms, where n is a power of p.
+*/
+func (a GFPoly) PowMod(n int64, m GFPoly) (GFPoly, error) {
+	if n < 0 {
+		return GFPoly{}, &NegativeExponentError{Exponent: n}
+	}
+	_, base, err := a.QuoRem(m)
+	if err != nil {
+		return GFPoly{}, err
+	}
+	_, result, _ := a.Field().Poly(1).QuoRem(m)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result, _ = result.Mul(base)
+			_, result, _ = result.QuoRem(m)
+		}
+		base, _ = base.Mul(base)
+		_, base, _ = base.QuoRem(m)
+	}
+	return result, nil
+}
+
+func (a GFPoly) Derivative() GFPoly {
+	if a.Degree() < 1 {
+		return GFPoly{p: a.p}
+	}
+	derivative := make([]int64, len(a.coeffs)-1)
+	for n := 1; n < len(a.coeffs); n++ {
+		derivative[n-1] = mulMod(reduceMod(int64(n), a.p), a.coeffs[n], a.p)
+	}
+	return GFPoly{coeffs: derivative, p: a.p}.trim()
+}
+
+// Evaluates a at x by Horner's scheme.
+func (a GFPoly) Eval(x ModInt) (ModInt, error) {
+	if a.p != x.modulus {
+		return ModInt{}, &ModulusMismatchError{A: a.p, B: x.modulus}
+	}
+	var value int64
+	for n := a.Degree(); n >= 0; n-- {
+		value = addMod(mulMod(value, x.value, a.p), a.coeffs[n], a.p)
+	}
+	return ModInt{value: value, modulus: a.p}, nil
+}
@@ -1,5 +1,3 @@
 module github.com/victorbrun/gosymbol
 
 go 1.23.4
-
-require github.com/SimoneChiominto/goalgebra v0.0.0-20250124195534-9632c6a2f69d
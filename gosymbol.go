@@ -1,13 +1,26 @@
 package gosymbol
 
+import "fmt"
+
 /* Factories */
 
 func Undefined() undefined {
 	return undefined{}
 }
 
-func Var(name VarName) variable {
-	return variable{Name: name, isPattern: false}
+// Declares a variable with optional assumptions on its value,
+// e.g. Var("n", Positive, Integer). Var panics if the assumptions
+// contradict each other, e.g. Positive and Negative.
+func Var(name VarName, assumptions ...Property) variable {
+	var p Property
+	for _, assumption := range assumptions {
+		p |= assumption
+	}
+	if !p.consistent() {
+		errMsg := fmt.Sprintf("ERROR: contradictory assumptions %v on variable %s", assumptions, name)
+		panic(errMsg)
+	}
+	return variable{Name: name, assumptions: p.closure(), isPattern: false}
 }
 
 func patternVar(name VarName) variable {
@@ -100,6 +113,113 @@ func Sqrt(arg Expr) sqrt {
 	return sqrt{Arg: arg}
 }
 
+func Sin(arg Expr) sin {
+	return sin{Arg: arg}
+}
+
+func Cos(arg Expr) cos {
+	return cos{Arg: arg}
+}
+
+func Function(name VarName, args ...Expr) FunctionApplication {
+	return FunctionApplication{Name: name, Args: args}
+}
+
+func Derivative(arg Expr, v variable) derivative {
+	return derivative{Arg: arg, Var: v}
+}
+
+// The names of the undefined functions that are known to
+// the simplifier, the integral transforms and CompileNumeric.
+const (
+	diracDeltaName VarName = "DiracDelta"
+	heavisideName  VarName = "Heaviside"
+	rectName       VarName = "Rect"
+	sincName       VarName = "Sinc"
+	factorialName  VarName = "Factorial"
+	binomialName   VarName = "Binomial"
+	gammaName      VarName = "Gamma"
+	reName         VarName = "Re"
+	imName         VarName = "Im"
+	conjugateName  VarName = "Conjugate"
+	argName        VarName = "Arg"
+	absName        VarName = "Abs"
+	rootOfName     VarName = "RootOf"
+)
+
+// Returns true if name is one of the functions known to the simplifier,
+// as opposed to an undefined function such as f(t).
+func isKnownFunction(name VarName) bool {
+	switch name {
+	case diracDeltaName, heavisideName, rectName, sincName, factorialName, binomialName,
+		gammaName, reName, imName, conjugateName, argName, absName, rootOfName:
+		return true
+	}
+	return false
+}
+
+// The Dirac delta distribution δ(arg).
+func DiracDelta(arg Expr) FunctionApplication {
+	return Function(diracDeltaName, arg)
+}
+
+// The unit step function, which is 1/2 at zero.
+func Heaviside(arg Expr) FunctionApplication {
+	return Function(heavisideName, arg)
+}
+
+// The rectangular function, which is one on (-1/2, 1/2),
+// 1/2 at ±1/2 and zero elsewhere.
+func Rect(arg Expr) FunctionApplication {
+	return Function(rectName, arg)
+}
+
+// The unnormalized sinc function sin(arg)/arg, which is one at zero.
+func Sinc(arg Expr) FunctionApplication {
+	return Function(sincName, arg)
+}
+
+// The factorial arg!, which is evaluated when arg is a non-negative integer.
+func Factorial(arg Expr) FunctionApplication {
+	return Function(factorialName, arg)
+}
+
+// The binomial coefficient n!/(k!*(n - k)!), which is evaluated
+// when n and k are integers with n non-negative.
+func Binomial(n, k Expr) FunctionApplication {
+	return Function(binomialName, n, k)
+}
+
+// The gamma function, with Gamma(n) = (n - 1)! for positive integers n.
+func Gamma(arg Expr) FunctionApplication {
+	return Function(gammaName, arg)
+}
+
+// The real part of z.
+func Re(z Expr) FunctionApplication {
+	return Function(reName, z)
+}
+
+// The imaginary part of z.
+func Im(z Expr) FunctionApplication {
+	return Function(imName, z)
+}
+
+// The complex conjugate of z.
+func Conjugate(z Expr) FunctionApplication {
+	return Function(conjugateName, z)
+}
+
+// The argument of z, i.e. the angle in (-π, π] of z in the complex plane.
+func Arg(z Expr) FunctionApplication {
+	return Function(argName, z)
+}
+
+// The absolute value |z|.
+func Abs(z Expr) FunctionApplication {
+	return Function(absName, z)
+}
+
 func TransformationRule(pattern Expr, transform func(Expr) Expr) transformationRule {
 	return transformationRule{pattern: pattern, transform: transform}
 }
@@ -143,8 +263,12 @@ func Int(value int64) integer {
 }
 
 func Real(symbol string) variable {
-	return variable{Name: VarName(symbol), isPattern: false}
+	return Var(VarName(symbol), IsReal)
 }
 
-var PI = Real("π")
+var PI = Var("π", Positive)
 var E = Exp(Int(1))
+
+// The imaginary unit as the principal square root of -1,
+// which is also how polynomialRoots writes complex roots.
+var I = Pow(Int(-1), Div(Int(1), Int(2)))
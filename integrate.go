@@ -0,0 +1,790 @@
+package gosymbol
+
+// Bounds the recursion of integrate, since integration by
+// parts and substitution may otherwise go on forever.
+const maxIntegrationDepth = 8
+
+/*
+Returns an antiderivative of expr w.r.t. v, without integration constant,
+and false if none is found. The integrand is expanded into a sum of terms
+that are integrated one by one using
+
+  - antiderivatives of powers, exp, sin, cos and log of expressions
+    that are linear in v,
+  - partial fractions for rational functions whose denominators split
+    into linear factors,
+  - product to sum formulas for products of sines and cosines,
+  - integration by parts for polynomials times exp, sin or cos, for exp
+    times sin or cos, and for logarithms, and
+  - substitution of u when the integrand is f(u)*D(u, v) up to a constant.
+
+This is a heuristic rather than a decision procedure, so false does not
+mean that expr has no elementary antiderivative.
+*/
+func integrate(expr Expr, v variable) (Expr, bool) {
+	return integrateSum(expr, v, 0)
+}
+
+func integrateSum(expr Expr, v variable, depth int) (Expr, bool) {
+	if depth > maxIntegrationDepth {
+		return nil, false
+	}
+	expanded := Expand(expandExp(expr))
+	if _, ok := expanded.(undefined); ok {
+		return nil, false
+	}
+
+	terms := []Expr{expanded}
+	if sum, ok := expanded.(add); ok {
+		terms = sum.Operands
+	}
+	result := make([]Expr, len(terms))
+	for ix, t := range terms {
+		integral, ok := integrateTerm(t, v, depth)
+		if !ok {
+			return nil, false
+		}
+		result[ix] = integral
+	}
+	return Add(result...).Simplify(), true
+}
+
+// Integrates a single term of an expanded sum.
+func integrateTerm(term Expr, v variable, depth int) (Expr, bool) {
+	constant, factors := splitConstantFactors(term, v)
+	if len(factors) == 0 {
+		return Mul(constant, v), true
+	}
+	integral, ok := integrateFactors(factors, v, depth)
+	if !ok {
+		return nil, false
+	}
+	return Mul(constant, integral), true
+}
+
+// Splits term into the product of its factors that are free of v,
+// and the factors that depend on v.
+func splitConstantFactors(term Expr, v variable) (Expr, []Expr) {
+	ops := []Expr{term}
+	if product, ok := term.(mul); ok {
+		ops = product.Operands
+	}
+	constant := []Expr{Int(1)}
+	factors := []Expr{}
+	for _, op := range ops {
+		if RecContains(op, v) {
+			factors = append(factors, op)
+		} else {
+			constant = append(constant, op)
+		}
+	}
+	return Mul(constant...).Simplify(), factors
+}
+
+func integrateFactors(factors []Expr, v variable, depth int) (Expr, bool) {
+	// Exponentials are merged into one, e.g. exp(x)*exp(2*y*x) = exp((1 + 2*y)*x)
+	exponentials, others := []Expr{}, []Expr{}
+	for _, f := range factors {
+		if _, ok := exponentialRate(f, v); ok {
+			exponentials = append(exponentials, f)
+		} else {
+			others = append(others, f)
+		}
+	}
+	if len(exponentials) > 1 {
+		factors = append(others, combineExp(Mul(exponentials...)))
+	}
+
+	if len(factors) == 1 {
+		if integral, ok := integrateElementary(factors[0], v); ok {
+			return integral, true
+		}
+	}
+	integrand := Mul(factors...)
+
+	if isRationalIn(integrand, v) {
+		return integrateRational(integrand, v)
+	}
+
+	if trigDegree(factors) >= 2 {
+		return integrateSum(linearizeTrig(integrand), v, depth+1)
+	}
+
+	if len(factors) == 2 {
+		if integral, ok := integrateExpTrig(factors[0], factors[1], v); ok {
+			return integral, true
+		} else if integral, ok := integrateExpTrig(factors[1], factors[0], v); ok {
+			return integral, true
+		}
+	}
+
+	if integral, ok := integrateByParts(factors, v, depth); ok {
+		return integral, true
+	} else if integral, ok := integrateBySubstitution(integrand, v, depth); ok {
+		return integral, true
+	}
+	return integrateLogByParts(factors, v, depth)
+}
+
+/*
+Integrates f(u) for a single factor f that is v, exp(u), sin(u), cos(u),
+log(u) or u^n, where u is linear in v and n is free of v. Powers of
+exp(u) are integrated as well since exp(u)^n = exp(n*u).
+*/
+func integrateElementary(f Expr, v variable) (Expr, bool) {
+	switch e := f.(type) {
+	case variable:
+		return Mul(Div(Int(1), Int(2)), Pow(v, Int(2))), true
+	case exp:
+		if a, ok := linearCoefficient(e.Arg, v); ok {
+			return Div(e, a), true
+		}
+	case sin:
+		if a, ok := linearCoefficient(e.Arg, v); ok {
+			return Div(Neg(Cos(e.Arg)), a), true
+		}
+	case cos:
+		if a, ok := linearCoefficient(e.Arg, v); ok {
+			return Div(Sin(e.Arg), a), true
+		}
+	case log:
+		if a, ok := linearCoefficient(e.Arg, v); ok {
+			return Div(Sub(Mul(e.Arg, e), e.Arg), a), true
+		}
+	case sqrt:
+		return integrateElementary(Pow(e.Arg, Div(Int(1), Int(2))), v)
+	case pow:
+		if RecContains(e.Exponent, v) {
+			// b^u = exp(u*log(b)) for a constant base b
+			if a, ok := linearCoefficient(e.Exponent, v); ok && !RecContains(e.Base, v) {
+				return Div(e, Mul(a, Log(e.Base))), true
+			}
+			break
+		}
+		if base, ok := e.Base.(exp); ok {
+			if a, ok := linearCoefficient(base.Arg, v); ok {
+				return Div(e, Mul(e.Exponent, a)), true
+			}
+			break
+		}
+		if a, ok := linearCoefficient(e.Base, v); ok {
+			n := Add(e.Exponent, Int(1)).Simplify()
+			if Equal(n, Int(0)) {
+				return Div(Log(e.Base), a), true
+			}
+			return Div(Pow(e.Base, n), Mul(n, a)), true
+		}
+	}
+	return nil, false
+}
+
+// Returns a if u = a*v + b with a non-zero a free of v.
+func linearCoefficient(u Expr, v variable) (Expr, bool) {
+	coeffs, err := polynomialCoefficients(Expand(u), v)
+	if err != nil || len(coeffs) != 2 {
+		return nil, false
+	}
+	return coeffs[1], true
+}
+
+// Returns true if expr is a rational function of v with
+// coefficients that are free of v.
+func isRationalIn(expr Expr, v variable) bool {
+	kt := &kernelTable{}
+	x := kt.index(v)
+	if _, ok := toRatFunc(expr, kt); !ok {
+		return false
+	}
+	for ix, k := range kt.kernels {
+		if ix != x && RecContains(k, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Integrates a rational function of v through its partial fractions.
+// Denominators with irreducible quadratic factors are not supported.
+func integrateRational(expr Expr, v variable) (Expr, bool) {
+	fractions, ok := apart(expr, v)
+	if !ok || containsImaginaryRadical(fractions) {
+		return nil, false
+	}
+	terms := []Expr{fractions}
+	if sum, ok := fractions.(add); ok {
+		terms = sum.Operands
+	}
+	result := make([]Expr, len(terms))
+	for ix, t := range terms {
+		constant, factors := splitConstantFactors(t, v)
+		switch len(factors) {
+		case 0:
+			result[ix] = Mul(constant, v)
+		case 1:
+			integral, ok := integrateElementary(factors[0], v)
+			if !ok {
+				return nil, false
+			}
+			result[ix] = Mul(constant, integral)
+		default:
+			return nil, false
+		}
+	}
+	return Add(result...).Simplify(), true
+}
+
+// Returns true if expr contains a square root of a negative number,
+// as produced by polynomialRoots for complex roots.
+func containsImaginaryRadical(expr Expr) bool {
+	if p, ok := expr.(pow); ok {
+		if base, ok := p.Base.(rational); ok && base.numerator().value < 0 {
+			if _, ok := p.Exponent.(fraction); ok {
+				return true
+			}
+		}
+	}
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		if containsImaginaryRadical(Operand(expr, ix)) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Returns the partial fraction decomposition of the rational function expr
+in v, i.e. a polynomial plus a sum of terms c/(v - r)^k over the roots r
+of the denominator, and false if the denominator can not be split into
+linear factors by polynomialRoots.
+*/
+func apart(expr Expr, v variable) (Expr, bool) {
+	quotient, fractions, ok := partialFractions(expr, v)
+	if !ok {
+		return nil, false
+	}
+	terms := []Expr{quotient}
+	for _, f := range fractions {
+		terms = append(terms, Mul(f.coeff, Pow(Sub(v, f.root), Int(int64(-f.power)))))
+	}
+	return Add(terms...).Simplify(), true
+}
+
+// A term coeff/(v - root)^power of a partial fraction decomposition.
+type partialFraction struct {
+	coeff, root Expr
+	power       int
+}
+
+/*
+Returns the polynomial part and the fractions of the partial fraction
+decomposition of expr in v. The coefficients of the terms for a root r
+of multiplicity m are read off from the Taylor expansion of
+(v - r)^m * expr around r, which is computed exactly also when r
+contains radicals.
+*/
+func partialFractions(expr Expr, v variable) (Expr, []partialFraction, bool) {
+	kt := &kernelTable{}
+	x := kt.index(v)
+	r, ok := toRatFunc(expr, kt)
+	if !ok {
+		return nil, nil, false
+	}
+	if r.den.degreeIn(x) <= 0 {
+		return r.toExpr(kt), nil, true
+	}
+
+	num, den := univariateRatFuncPoly(r.num, x, kt), univariateRatFuncPoly(r.den, x, kt)
+	quo, rem := num.quoRem(den)
+
+	roots, err := polynomialRoots(r.den.toExpr(kt), v, nil)
+	if err != nil {
+		return nil, nil, false
+	}
+	fractions := []partialFraction{}
+	for _, root := range roots {
+		c, ok := toRatFunc(root.value, kt)
+		if !ok {
+			return nil, nil, false
+		}
+		// The cofactor of (v - c)^m in the denominator
+		cofactor := den
+		linear := ratFuncPoly{c.neg(), constRatFunc(Int(1))}
+		for ix := 0; ix < root.multiplicity; ix++ {
+			cofactor, _ = cofactor.quoRem(linear)
+		}
+
+		series, ok := seriesQuo(rem.taylorShift(c), cofactor.taylorShift(c), root.multiplicity)
+		if !ok {
+			return nil, nil, false
+		}
+		for k, coeff := range series {
+			if coeff.isZero() {
+				continue
+			}
+			fractions = append(fractions, partialFraction{
+				coeff: coeff.toExpr(kt),
+				root:  root.value,
+				power: root.multiplicity - k,
+			})
+		}
+	}
+	return quo.toExpr(v, kt), fractions, true
+}
+
+// Returns p seen as a univariate polynomial in kernel x.
+func univariateRatFuncPoly(p polynomial, x int, kt *kernelTable) ratFuncPoly {
+	result := make(ratFuncPoly, p.degreeIn(x)+1)
+	for ix := range result {
+		result[ix] = newRatFunc(p.coefficientIn(x, ix), constPoly(Int(1)), kt)
+	}
+	return result
+}
+
+func (p ratFuncPoly) toExpr(v variable, kt *kernelTable) Expr {
+	terms := make([]Expr, len(p))
+	for ix, c := range p {
+		terms[ix] = Mul(c.toExpr(kt), Pow(v, Int(int64(ix))))
+	}
+	return Add(terms...).Simplify()
+}
+
+// Returns the coefficients of p(c + t) as a polynomial in t,
+// computed by repeated division by x - c.
+func (p ratFuncPoly) taylorShift(c ratFunc) ratFuncPoly {
+	linear := ratFuncPoly{c.neg(), constRatFunc(Int(1))}
+	result := ratFuncPoly{}
+	for p.degree() >= 0 {
+		quo, rem := p.quoRem(linear)
+		if len(rem) == 0 {
+			result = append(result, constRatFunc(Int(0)))
+		} else {
+			result = append(result, rem[0])
+		}
+		p = quo
+	}
+	return result
+}
+
+// Returns the first n coefficients of the power series a/b,
+// and false if b has a zero constant term.
+func seriesQuo(a, b ratFuncPoly, n int) ([]ratFunc, bool) {
+	if len(b) == 0 || b[0].isZero() {
+		return nil, false
+	}
+	coeff := func(p ratFuncPoly, ix int) ratFunc {
+		if ix < len(p) {
+			return p[ix]
+		}
+		return constRatFunc(Int(0))
+	}
+	result := make([]ratFunc, n)
+	for k := range result {
+		s := coeff(a, k)
+		for j := 1; j <= k; j++ {
+			s = s.sub(coeff(b, j).mul(result[k-j]))
+		}
+		result[k], _ = s.div(b[0])
+	}
+	return result, true
+}
+
+// The total degree of the sine and cosine factors.
+func trigDegree(factors []Expr) int {
+	degree := 0
+	for _, f := range factors {
+		switch e := f.(type) {
+		case sin, cos:
+			degree++
+		case pow:
+			n, ok := e.Exponent.(integer)
+			if !ok || n.value < 0 {
+				continue
+			}
+			switch e.Base.(type) {
+			case sin, cos:
+				degree += int(n.value)
+			}
+		}
+	}
+	return degree
+}
+
+/*
+Integrates exp(a*v + b)^n * sin(c*v + d), or with cos instead of sin,
+using that the antiderivatives are
+
+	exp(...)^n * (n*a*sin(...) - c*cos(...)) / ((n*a)^2 + c^2)
+	exp(...)^n * (n*a*cos(...) + c*sin(...)) / ((n*a)^2 + c^2)
+*/
+func integrateExpTrig(e, trig Expr, v variable) (Expr, bool) {
+	alpha, ok := exponentialRate(e, v)
+	if !ok {
+		return nil, false
+	}
+	var arg Expr
+	switch t := trig.(type) {
+	case sin:
+		arg = t.Arg
+	case cos:
+		arg = t.Arg
+	default:
+		return nil, false
+	}
+	beta, ok := linearCoefficient(arg, v)
+	if !ok {
+		return nil, false
+	}
+
+	den := Add(Pow(alpha, Int(2)), Pow(beta, Int(2)))
+	if _, ok := trig.(sin); ok {
+		return Div(Mul(e, Sub(Mul(alpha, Sin(arg)), Mul(beta, Cos(arg)))), den), true
+	}
+	return Div(Mul(e, Add(Mul(alpha, Cos(arg)), Mul(beta, Sin(arg)))), den), true
+}
+
+// Returns a for e = exp(u) or e = exp(u)^n such that e = exp(a*v + b).
+func exponentialRate(e Expr, v variable) (Expr, bool) {
+	switch f := e.(type) {
+	case exp:
+		return linearCoefficient(f.Arg, v)
+	case pow:
+		if base, ok := f.Base.(exp); ok && !RecContains(f.Exponent, v) {
+			if a, ok := linearCoefficient(base.Arg, v); ok {
+				return Mul(f.Exponent, a), true
+			}
+		}
+	}
+	return nil, false
+}
+
+/*
+Integrates by parts as
+
+	∫ v^k * g = v^k * G - k * ∫ v^(k-1) * G
+
+where g is a product of exp, sin and cos factors with antiderivative G.
+*/
+func integrateByParts(factors []Expr, v variable, depth int) (Expr, bool) {
+	for ix, f := range factors {
+		rest := append(append([]Expr{}, factors[:ix]...), factors[ix+1:]...)
+		if k, ok := monomialDegree(f, v); ok && k > 0 && len(rest) > 0 && isExponentialOrTrig(rest, v) {
+			g, ok := integrateFactors(rest, v, depth+1)
+			if !ok {
+				return nil, false
+			}
+			remaining, ok := integrateSum(Mul(Int(int64(k)), Pow(v, Int(int64(k-1))), g), v, depth+1)
+			if !ok {
+				return nil, false
+			}
+			return Sub(Mul(f, g), remaining), true
+		}
+	}
+	return nil, false
+}
+
+/*
+Integrates by parts as
+
+	∫ log(u) * g = log(u) * G - ∫ G * D(u, v)/u
+
+where g is the product of the other factors with antiderivative G.
+*/
+func integrateLogByParts(factors []Expr, v variable, depth int) (Expr, bool) {
+	for ix, f := range factors {
+		l, ok := f.(log)
+		if !ok {
+			continue
+		}
+		rest := append(append([]Expr{}, factors[:ix]...), factors[ix+1:]...)
+		var g Expr = v
+		if len(rest) > 0 {
+			if g, ok = integrateSum(Mul(rest...), v, depth+1); !ok {
+				return nil, false
+			}
+		}
+		remaining, ok := integrateSum(Cancel(Mul(g, differentiate(l.Arg, v), Pow(l.Arg, Int(-1)))), v, depth+1)
+		if !ok {
+			return nil, false
+		}
+		return Sub(Mul(l, g), remaining), true
+	}
+	return nil, false
+}
+
+// Returns k if f is v^k for an integer k.
+func monomialDegree(f Expr, v variable) (int, bool) {
+	switch e := f.(type) {
+	case variable:
+		return 1, Equal(e, v)
+	case pow:
+		n, ok := e.Exponent.(integer)
+		return int(n.value), ok && Equal(e.Base, v)
+	}
+	return 0, false
+}
+
+// Returns true if every factor is exp, sin or cos of something linear in
+// v, or a power of such an exp, so that their product integrates into
+// an expression of the same kind.
+func isExponentialOrTrig(factors []Expr, v variable) bool {
+	for _, f := range factors {
+		switch e := f.(type) {
+		case sin:
+			if _, ok := linearCoefficient(e.Arg, v); !ok {
+				return false
+			}
+		case cos:
+			if _, ok := linearCoefficient(e.Arg, v); !ok {
+				return false
+			}
+		default:
+			if _, ok := exponentialRate(f, v); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+/*
+Tries the substitution t = u for every operand u of a function or power in
+expr. It succeeds if expr/D(u, v) can be written in t alone, in which case
+the antiderivative in t is computed and u is substituted back.
+*/
+func integrateBySubstitution(expr Expr, v variable, depth int) (Expr, bool) {
+	t := freshVariable("t", expr)
+	for _, u := range substitutionCandidates(expr, v) {
+		du := differentiate(u, v).Simplify()
+		if isZero(du) {
+			continue
+		}
+		q := Substitute(Cancel(Div(expr, du)), Cancel(u), t)
+		if RecContains(q, v) {
+			continue
+		}
+		if integral, ok := integrateSum(q, t, depth+1); ok {
+			return Substitute(integral, t, u), true
+		}
+	}
+	return nil, false
+}
+
+// Returns the operands of functions and the bases of powers in expr that
+// depend on v but are not v itself, followed by the functions themselves.
+func substitutionCandidates(expr Expr, v variable) []Expr {
+	operands, functions := []Expr{}, []Expr{}
+	var collect func(Expr)
+	collect = func(e Expr) {
+		switch f := e.(type) {
+		case exp, log, sin, cos, sqrt:
+			if arg := Operand(f, 1); RecContains(arg, v) && !Equal(arg, v) {
+				operands = append(operands, arg)
+			}
+			functions = append(functions, f)
+		case pow:
+			if RecContains(f.Base, v) && !Equal(f.Base, v) {
+				operands = append(operands, f.Base)
+			}
+		}
+		for ix := 1; ix <= NumberOfOperands(e); ix++ {
+			collect(Operand(e, ix))
+		}
+	}
+	collect(expr)
+	return append(operands, functions...)
+}
+
+/* Rewriting exponentials and trigonometric functions */
+
+/*
+Splits the exponentials in expr into products according to their expanded
+arguments, where integer multiples become powers, e.g. exp(2*x - y + 1)
+becomes exp(x)^2 * exp(y)^-1 * exp(1). This way the kernels of products of
+exponentials are related, so that e.g. exp(x)*exp(-x) cancels to one.
+*/
+func expandExp(expr Expr) Expr {
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		expr = replaceOperand(expr, ix, expandExp(Operand(expr, ix)))
+	}
+	e, ok := expr.(exp)
+	if !ok {
+		return expr
+	}
+
+	arg := Expand(e.Arg)
+	terms := []Expr{arg}
+	if sum, ok := arg.(add); ok {
+		terms = sum.Operands
+	}
+	factors := make([]Expr, len(terms))
+	for ix, t := range terms {
+		c, rest := splitRationalCoefficient(t)
+		if n, ok := c.(integer); ok && rest != nil {
+			factors[ix] = Pow(Exp(rest), n)
+		} else {
+			factors[ix] = Exp(t)
+		}
+	}
+	return Mul(factors...).Simplify()
+}
+
+// Splits term into c*rest where c is its rational coefficient.
+// Rest is nil if term is a number.
+func splitRationalCoefficient(term Expr) (rational, Expr) {
+	switch t := term.(type) {
+	case rational:
+		return t, nil
+	case mul:
+		if c, ok := t.Operands[0].(rational); ok {
+			return c, Mul(t.Operands[1:]...).Simplify()
+		}
+	}
+	return Int(1), term
+}
+
+// Combines the exponentials in each product of expr into a single
+// exponential, which reverses expandExp.
+func combineExp(expr Expr) Expr {
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		expr = replaceOperand(expr, ix, combineExp(Operand(expr, ix)))
+	}
+
+	ops := []Expr{expr}
+	if product, ok := expr.(mul); ok {
+		ops = product.Operands
+	}
+	others := []Expr{}
+	args := []Expr{}
+	powers := 0
+	for _, op := range ops {
+		switch e := op.(type) {
+		case exp:
+			args = append(args, e.Arg)
+			continue
+		case pow:
+			if base, ok := e.Base.(exp); ok {
+				args = append(args, Mul(e.Exponent, base.Arg))
+				powers++
+				continue
+			}
+		}
+		others = append(others, op)
+	}
+	if len(args) == 0 || (len(args) == 1 && powers == 0) {
+		return expr
+	}
+	return Mul(append(others, Exp(Expand(Add(args...))))...).Simplify()
+}
+
+/*
+Rewrites all products of sines and cosines in expr into sums using
+
+	sin(a)*sin(b) = (cos(a-b) - cos(a+b))/2
+	cos(a)*cos(b) = (cos(a-b) + cos(a+b))/2
+	sin(a)*cos(b) = (sin(a+b) + sin(a-b))/2
+
+so that the result is linear in the trigonometric functions.
+*/
+func linearizeTrig(expr Expr) Expr {
+	expanded := Expand(expr)
+	terms := []Expr{expanded}
+	if sum, ok := expanded.(add); ok {
+		terms = sum.Operands
+	}
+
+	changed := false
+	result := make([]Expr, len(terms))
+	for ix, t := range terms {
+		others, trig := splitTrigFactors(t)
+		if len(trig) < 2 {
+			result[ix] = t
+			continue
+		}
+		changed = true
+		result[ix] = Mul(append(append(others, productToSum(trig[0], trig[1])), trig[2:]...)...)
+	}
+	if !changed {
+		return expanded
+	}
+	return linearizeTrig(Add(result...))
+}
+
+// Splits term into its non-trigonometric factors and a list of
+// sin and cos factors, where powers are written out.
+func splitTrigFactors(term Expr) ([]Expr, []Expr) {
+	ops := []Expr{term}
+	if product, ok := term.(mul); ok {
+		ops = product.Operands
+	}
+	others, trig := []Expr{}, []Expr{}
+	for _, op := range ops {
+		switch e := op.(type) {
+		case sin, cos:
+			trig = append(trig, e)
+			continue
+		case pow:
+			if n, ok := e.Exponent.(integer); ok && n.value > 0 {
+				switch e.Base.(type) {
+				case sin, cos:
+					for jx := int64(0); jx < n.value; jx++ {
+						trig = append(trig, e.Base)
+					}
+					continue
+				}
+			}
+		}
+		others = append(others, op)
+	}
+	return others, trig
+}
+
+func productToSum(f, g Expr) Expr {
+	half := Div(Int(1), Int(2))
+	a, b := Operand(f, 1), Operand(g, 1)
+	_, fSin := f.(sin)
+	_, gSin := g.(sin)
+	switch {
+	case fSin && gSin:
+		return Mul(half, Sub(trigOf(false, Sub(a, b)), trigOf(false, Add(a, b))))
+	case !fSin && !gSin:
+		return Mul(half, Add(trigOf(false, Sub(a, b)), trigOf(false, Add(a, b))))
+	case fSin:
+		return Mul(half, Add(trigOf(true, Add(a, b)), trigOf(true, Sub(a, b))))
+	default:
+		return Mul(half, Add(trigOf(true, Add(b, a)), trigOf(true, Sub(b, a))))
+	}
+}
+
+// Returns sin(arg) or cos(arg) with the expanded argument normalised
+// using sin(-u) = -sin(u) and cos(-u) = cos(u), so that equal
+// functions are recognised as such.
+func trigOf(sine bool, arg Expr) Expr {
+	arg = Expand(arg)
+	negative := isNegativeForm(arg)
+	if negative {
+		arg = Expand(Neg(arg))
+	}
+	if sine {
+		if negative {
+			return Neg(Sin(arg)).Simplify()
+		}
+		return Sin(arg).Simplify()
+	}
+	return Cos(arg).Simplify()
+}
+
+// Returns true if the expanded expression e starts with a negative
+// coefficient, which is flipped when e is negated.
+func isNegativeForm(e Expr) bool {
+	switch t := e.(type) {
+	case rational:
+		return t.numerator().value < 0
+	case mul:
+		if c, ok := t.Operands[0].(rational); ok {
+			return c.numerator().value < 0
+		}
+	case add:
+		return isNegativeForm(t.Operands[0])
+	}
+	return false
+}
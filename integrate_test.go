@@ -0,0 +1,44 @@
+package gosymbol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIntegrate(t *testing.T) {
+	x := Var("x")
+
+	tests := []struct {
+		name  string
+		input Expr
+	}{
+		{name: "Power", input: Pow(x, Int(3))},
+		{name: "Reciprocal", input: Div(Int(1), x)},
+		{name: "Exponential", input: Exp(Mul(Int(2), x))},
+		{name: "Logarithm", input: Log(x)},
+		{name: "Square root", input: Sqrt(x)},
+		{name: "By parts with exponential", input: Mul(x, Exp(x))},
+		{name: "By parts with sine", input: Mul(Pow(x, Int(2)), Sin(x))},
+		{name: "By parts with logarithm", input: Mul(x, Log(x))},
+		{name: "Squared sine", input: Pow(Sin(x), Int(2))},
+		{name: "Product of trigonometric functions", input: Mul(Sin(x), Cos(Mul(Int(3), x)))},
+		{name: "Exponential times cosine", input: Mul(Exp(x), Cos(x))},
+		{name: "Partial fractions", input: Div(Int(1), Mul(x, Sub(Int(1), x)))},
+		{name: "Repeated pole", input: Div(x, Pow(Add(x, Int(1)), Int(2)))},
+		{name: "Substitution", input: Mul(x, Exp(Pow(x, Int(2))))},
+		{name: "Substitution of sine", input: Mul(Cos(x), Exp(Sin(x)))},
+		{name: "Substitution of logarithm", input: Div(Log(x), x)},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, ok := integrate(test.input, x)
+			if !ok {
+				t.Fatalf("Following test failed: %s\nInput: %v\nNo antiderivative found", test.name, test.input)
+			}
+			if !isZero(linearizeTrig(expandExp(Sub(differentiate(result, x), test.input)))) {
+				t.Errorf("Following test failed: %s\nInput: %v\nGot: %v", test.name, test.input, result)
+			}
+		})
+	}
+}
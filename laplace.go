@@ -0,0 +1,370 @@
+package gosymbol
+
+import (
+	"strings"
+)
+
+// The name of unevaluated Laplace transforms
+// LaplaceTransform(f(t), t, s) of undefined functions.
+const laplaceTransformName VarName = "LaplaceTransform"
+
+/*
+Returns the Laplace transform of expr w.r.t. t as an expression in s.
+
+The transform is computed term by term with constant factors moved out,
+using the frequency shifting theorem L{exp(a*t)*f(t)} = F(s - a) and the
+differentiation theorem L{t^n*f(t)} = (-1)^n * D^n(F(s), s) to reduce
+each term to an entry in the table of laplaceRules. Products of sin and
+cos are first rewritten as sums.
+
+The transform of an undefined function f(t) is kept unevaluated as
+LaplaceTransform(f(t), t, s), and the transform of its n:th derivative
+is s^n*F(s) minus the initial values f(0), f'(0), ..., which are written
+as undefined functions with primes in their names. The transform of an
+ODE in f is thereby linear in F(s), which gives the transfer function.
+
+Terms with a factor Heaviside(t - a) are transformed by the second
+shifting theorem, and terms with a factor DiracDelta(t - a) by the
+sifting property, as implemented by laplaceOfStep.
+
+An UnsupportedTransformError is returned if a term matches no rule.
+*/
+func LaplaceTransform(expr Expr, t, s variable) (Expr, error) {
+	terms, ok := expandedTerms(linearizeTrig(expandExp(expr)))
+	if !ok {
+		return nil, &UnsupportedTransformError{Transform: "Laplace", Expr: expr}
+	}
+
+	rules := laplaceRules(t, s)
+	result := make([]Expr, len(terms))
+	for ix, term := range terms {
+		constant, factors := splitConstantFactors(term, t)
+		if transform, ok, err := laplaceOfStep(constant, factors, t, s); ok {
+			if err != nil {
+				return nil, err
+			}
+			result[ix] = transform
+			continue
+		}
+
+		// Splits off the factors handled by the theorems
+		var rate Expr = Int(0)
+		degree := 0
+		rest := []Expr{}
+		for _, f := range factors {
+			if a, ok := exponentialRate(f, t); ok {
+				rate = Add(rate, a)
+			} else if k, ok := monomialDegree(f, t); ok && k > 0 {
+				degree += k
+			} else {
+				rest = append(rest, f)
+			}
+		}
+		if len(rest) == 0 {
+			rest, degree = []Expr{Pow(t, Int(int64(degree)))}, 0
+		}
+
+		transform, ok := applyFirstRule(rules, Mul(rest...).Simplify())
+		if !ok {
+			return nil, &UnsupportedTransformError{Transform: "Laplace", Expr: term}
+		}
+		for k := 0; k < degree; k++ {
+			transform = Neg(differentiate(transform, s))
+		}
+		if rate = Cancel(rate); !isZero(rate) {
+			transform = shiftVariable(transform, s, Neg(rate))
+		}
+		result[ix] = Mul(constant, transform)
+	}
+	return Cancel(Add(result...)), nil
+}
+
+/*
+Transforms constant times the product of factors if one of the factors
+is a Heaviside step or a Dirac delta, by the second shifting theorem
+
+	L{H(t - a)*g(t)} = exp(-a*s)*L{g(t + a)}
+
+and the sifting property L{δ(c*(t - a))*g(t)} = g(a)*exp(-a*s)/c, where
+c must be positive and the sign of a known. The second return value is
+false if no factor is a step or a delta.
+*/
+func laplaceOfStep(constant Expr, factors []Expr, t, s variable) (Expr, bool, error) {
+	for ix, f := range factors {
+		step, ok := f.(FunctionApplication)
+		if !ok || (step.Name != heavisideName && step.Name != diracDeltaName) || len(step.Args) != 1 {
+			continue
+		}
+		unsupported := &UnsupportedTransformError{Transform: "Laplace", Expr: Mul(append([]Expr{constant}, factors...)...)}
+		if !isLinearIn(step.Args[0], t) {
+			return nil, true, unsupported
+		}
+		c, b := linearCoefficients(step.Args[0], t)
+		a := Cancel(Div(Neg(b), c))
+		g := Mul(append(append([]Expr{constant}, factors[:ix]...), factors[ix+1:]...)...)
+		if !Ask(c, IsPositive) {
+			return nil, true, unsupported
+		} else if Ask(a, IsNegative) {
+			// The step is one and the delta zero for t > 0
+			if step.Name == diracDeltaName {
+				return Int(0), true, nil
+			}
+			transform, err := LaplaceTransform(g, t, s)
+			return transform, true, err
+		} else if !Ask(a, IsNonnegative) {
+			return nil, true, unsupported
+		}
+
+		delay := Exp(Neg(Mul(a, s)))
+		if step.Name == diracDeltaName {
+			return Cancel(Div(Mul(Substitute(g, t, a), delay), c)), true, nil
+		}
+		transform, err := LaplaceTransform(shiftVariable(g, t, a), t, s)
+		if err != nil {
+			return nil, true, err
+		}
+		return Mul(delay, transform), true, nil
+	}
+	return nil, false, nil
+}
+
+/*
+Returns the inverse Laplace transform of expr w.r.t. s as an expression
+in t.
+
+The terms that are rational in s are inverted through their partial
+fractions, where c/(s - a)^k is the transform of c*t^(k-1)*exp(a*t)/(k-1)!.
+The terms of conjugate complex roots a ± b*i are combined into real
+exponentials times sin(b*t) and cos(b*t), and a constant c is the
+transform of c*DiracDelta(t). Terms with a factor exp(-a*s) are inverted
+by the second shifting theorem. Other terms must match one of the
+inverseLaplaceRules.
+
+An UnsupportedTransformError is returned if the polynomial part of the
+rational terms is not constant, i.e. if its inverse would contain
+derivatives of the Dirac delta, if the denominator can not be factored,
+or if a term matches no rule.
+*/
+func InverseLaplaceTransform(expr Expr, s, t variable) (Expr, error) {
+	terms, ok := expandedTerms(expr)
+	if !ok {
+		return nil, &UnsupportedTransformError{Transform: "inverse Laplace", Expr: expr}
+	}
+
+	rules := inverseLaplaceRules(s, t)
+	rational := []Expr{Int(0)}
+	result := []Expr{}
+	for _, term := range terms {
+		if isRationalIn(term, s) {
+			rational = append(rational, term)
+			continue
+		}
+		constant, factors := splitConstantFactors(term, s)
+		if inverse, ok, err := inverseLaplaceOfDelay(constant, factors, s, t); ok {
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, inverse)
+			continue
+		}
+		inverse, ok := applyFirstRule(rules, Mul(factors...).Simplify())
+		if !ok {
+			return nil, &UnsupportedTransformError{Transform: "inverse Laplace", Expr: term}
+		}
+		result = append(result, Mul(constant, inverse))
+	}
+
+	F := Cancel(Add(rational...))
+	quotient, fractions, ok := partialFractions(F, s)
+	if !ok || RecContains(quotient, s) {
+		return nil, &UnsupportedTransformError{Transform: "inverse Laplace", Expr: F}
+	} else if !isZero(quotient) {
+		result = append(result, Mul(quotient, DiracDelta(t)))
+	}
+	for _, f := range fractions {
+		re, im, ok := splitComplex(f.root)
+		if !ok {
+			return nil, &UnsupportedTransformError{Transform: "inverse Laplace", Expr: F}
+		}
+		power := Div(Pow(t, Int(int64(f.power-1))), Int(factorial(f.power-1)))
+		if isZero(im) {
+			result = append(result, Mul(f.coeff, power, Exp(Mul(f.root, t))))
+			continue
+		} else if isNegativeForm(Expand(im)) {
+			// Included together with the conjugate root
+			continue
+		}
+
+		// c*exp((a + b*i)*t) plus its conjugate
+		cRe, cIm, ok := splitComplex(f.coeff)
+		if !ok {
+			return nil, &UnsupportedTransformError{Transform: "inverse Laplace", Expr: F}
+		}
+		oscillation := Sub(Mul(cRe, Cos(Mul(im, t))), Mul(cIm, Sin(Mul(im, t))))
+		result = append(result, Mul(Int(2), power, Exp(Mul(re, t)), oscillation))
+	}
+	return tidy(Add(result...)), nil
+}
+
+/*
+Inverts constant times the product of factors if one of the factors is
+exp(-a*s) with a non-negative a, as H(t - a)*f(t - a) where f is the
+inverse transform of the other factors, or as c*δ(t - a) if the other
+factors are a constant c. The second return value is false if there is
+no such factor.
+*/
+func inverseLaplaceOfDelay(constant Expr, factors []Expr, s, t variable) (Expr, bool, error) {
+	for ix, f := range factors {
+		rate, ok := exponentialRate(f, s)
+		if !ok {
+			continue
+		}
+		a := Cancel(Neg(rate))
+		if !Ask(a, IsNonnegative) {
+			continue
+		}
+		F := Mul(append(append([]Expr{constant}, factors[:ix]...), factors[ix+1:]...)...)
+		if !RecContains(F, s) {
+			return Mul(F, DiracDelta(Sub(t, a))), true, nil
+		}
+		inverse, err := InverseLaplaceTransform(F, s, t)
+		if err != nil {
+			return nil, true, err
+		}
+		return Mul(Heaviside(Sub(t, a)), shiftVariable(inverse, t, Neg(a))), true, nil
+	}
+	return nil, false, nil
+}
+
+// Returns the transform of expr by the first rule matching it.
+func applyFirstRule(rules []transformationRule, expr Expr) (Expr, bool) {
+	for _, rule := range rules {
+		if transformed, ok := rule.apply(expr); ok {
+			return transformed, true
+		}
+	}
+	return nil, false
+}
+
+/*
+The table of Laplace transforms of the expressions that remain after the
+constant factors, exponentials and powers of t have been split off by
+LaplaceTransform.
+*/
+func laplaceRules(t, s variable) []transformationRule {
+	return []transformationRule{
+		{ // L{t^n} = n!/s^(n+1)
+			patternFunction: func(expr Expr) bool {
+				if Equal(expr, Int(1)) {
+					return true
+				}
+				n, ok := monomialDegree(expr, t)
+				return ok && n > 0
+			},
+			transform: func(expr Expr) Expr {
+				n, _ := monomialDegree(expr, t)
+				return Div(Int(factorial(n)), Pow(s, Int(int64(n+1))))
+			},
+		},
+		{ // L{sin(a*t + b)} = (a*cos(b) + s*sin(b))/(s^2 + a^2)
+			patternFunction: func(expr Expr) bool {
+				f, ok := expr.(sin)
+				return ok && isLinearIn(f.Arg, t)
+			},
+			transform: func(expr Expr) Expr {
+				arg := Operand(expr, 1)
+				a, b := linearCoefficients(arg, t)
+				return Div(Add(Mul(a, Cos(b)), Mul(s, Sin(b))), Add(Pow(s, Int(2)), Pow(a, Int(2))))
+			},
+		},
+		{ // L{cos(a*t + b)} = (s*cos(b) - a*sin(b))/(s^2 + a^2)
+			patternFunction: func(expr Expr) bool {
+				f, ok := expr.(cos)
+				return ok && isLinearIn(f.Arg, t)
+			},
+			transform: func(expr Expr) Expr {
+				arg := Operand(expr, 1)
+				a, b := linearCoefficients(arg, t)
+				return Div(Sub(Mul(s, Cos(b)), Mul(a, Sin(b))), Add(Pow(s, Int(2)), Pow(a, Int(2))))
+			},
+		},
+		{ // L{f(t)} = LaplaceTransform(f(t), t, s)
+			patternFunction: func(expr Expr) bool {
+				f, ok := expr.(FunctionApplication)
+				return ok && !isKnownFunction(f.Name) && len(f.Args) == 1 && Equal(f.Args[0], t)
+			},
+			transform: func(expr Expr) Expr {
+				return Function(laplaceTransformName, expr, t, s)
+			},
+		},
+		{ // L{D^n(f(t), t)} = s^n*F(s) - s^(n-1)*f(0) - ... - f^(n-1)(0)
+			patternFunction: func(expr Expr) bool {
+				f, n := derivativeOrder(expr, t)
+				return n > 0 && !isKnownFunction(f.Name) && len(f.Args) == 1 && Equal(f.Args[0], t)
+			},
+			transform: func(expr Expr) Expr {
+				f, n := derivativeOrder(expr, t)
+				terms := []Expr{Mul(Pow(s, Int(int64(n))), Function(laplaceTransformName, f, t, s))}
+				for k := 0; k < n; k++ {
+					initial := Function(VarName(string(f.Name)+strings.Repeat("'", k)), Int(0))
+					terms = append(terms, Neg(Mul(Pow(s, Int(int64(n-1-k))), initial)))
+				}
+				return Add(terms...)
+			},
+		},
+	}
+}
+
+// The table of inverse Laplace transforms of the
+// terms that are not rational functions of s.
+func inverseLaplaceRules(s, t variable) []transformationRule {
+	return []transformationRule{
+		{ // L^-1{LaplaceTransform(f(u), u, s)} = f(t)
+			patternFunction: func(expr Expr) bool {
+				f, ok := expr.(FunctionApplication)
+				if !ok || f.Name != laplaceTransformName || len(f.Args) != 3 || !Equal(f.Args[2], s) {
+					return false
+				}
+				_, ok = f.Args[1].(variable)
+				return ok
+			},
+			transform: func(expr Expr) Expr {
+				f := expr.(FunctionApplication)
+				if Equal(f.Args[1], t) {
+					return f.Args[0]
+				}
+				return Substitute(f.Args[0], f.Args[1], t)
+			},
+		},
+	}
+}
+
+// Returns f and n if expr is the n:th derivative of f w.r.t. v,
+// where n is zero if expr is not a derivative of an undefined function.
+func derivativeOrder(expr Expr, v variable) (FunctionApplication, int) {
+	n := 0
+	for {
+		d, ok := expr.(derivative)
+		if !ok || !Equal(d.Var, v) {
+			break
+		}
+		expr, n = d.Arg, n+1
+	}
+	f, ok := expr.(FunctionApplication)
+	if !ok {
+		return FunctionApplication{}, 0
+	}
+	return f, n
+}
+
+// Returns true if u = a*v + b with a non-zero a free of v.
+func isLinearIn(u Expr, v variable) bool {
+	_, ok := linearCoefficient(u, v)
+	return ok
+}
+
+// Returns a and b such that u = a*v + b, where u must be linear in v.
+func linearCoefficients(u Expr, v variable) (Expr, Expr) {
+	a, _ := linearCoefficient(u, v)
+	return a, Cancel(Substitute(u, v, Int(0)))
+}
@@ -0,0 +1,243 @@
+package gosymbol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLaplaceTransform(t *testing.T) {
+	x, s := Var("t"), Var("s")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput Expr
+	}{
+		{
+			name:           "Constant",
+			input:          Int(3),
+			expectedOutput: Div(Int(3), s),
+		},
+		{
+			name:           "Power",
+			input:          Pow(x, Int(3)),
+			expectedOutput: Div(Int(6), Pow(s, Int(4))),
+		},
+		{
+			name:           "Exponential",
+			input:          Exp(Mul(Int(-2), x)),
+			expectedOutput: Div(Int(1), Add(s, Int(2))),
+		},
+		{
+			name:           "Sine",
+			input:          Sin(Mul(Int(3), x)),
+			expectedOutput: Div(Int(3), Add(Pow(s, Int(2)), Int(9))),
+		},
+		{
+			name:           "Shifted cosine",
+			input:          Mul(Exp(Neg(x)), Cos(Mul(Int(2), x))),
+			expectedOutput: Div(Add(s, Int(1)), Add(Pow(Add(s, Int(1)), Int(2)), Int(4))),
+		},
+		{
+			name:           "Multiplication by t",
+			input:          Mul(x, Sin(x)),
+			expectedOutput: Div(Mul(Int(2), s), Pow(Add(Pow(s, Int(2)), Int(1)), Int(2))),
+		},
+		{
+			name:           "Squared sine",
+			input:          Pow(Sin(x), Int(2)),
+			expectedOutput: Div(Int(2), Mul(s, Add(Pow(s, Int(2)), Int(4)))),
+		},
+		{
+			name:           "Linear combination",
+			input:          Add(Mul(Int(2), Pow(x, Int(2)), Exp(x)), Neg(Cos(x))),
+			expectedOutput: Sub(Div(Int(4), Pow(Sub(s, Int(1)), Int(3))), Div(s, Add(Pow(s, Int(2)), Int(1)))),
+		},
+		{
+			name:           "Dirac delta",
+			input:          DiracDelta(x),
+			expectedOutput: Int(1),
+		},
+		{
+			name:           "Shifted Dirac delta",
+			input:          Mul(Pow(x, Int(2)), DiracDelta(Sub(Mul(Int(2), x), Int(6)))),
+			expectedOutput: Div(Mul(Int(9), Exp(Mul(Int(-3), s))), Int(2)),
+		},
+		{
+			name:           "Unit step",
+			input:          Heaviside(x),
+			expectedOutput: Div(Int(1), s),
+		},
+		{
+			name:           "Unit step before zero",
+			input:          Mul(Heaviside(Add(x, Int(1))), x),
+			expectedOutput: Div(Int(1), Pow(s, Int(2))),
+		},
+		{
+			name:           "Delayed step",
+			input:          Heaviside(Sub(x, Var("a", Positive))),
+			expectedOutput: Div(Exp(Neg(Mul(Var("a", Positive), s))), s),
+		},
+		{
+			name:           "Delayed sine",
+			input:          Mul(Heaviside(Sub(x, Int(1))), Sin(Sub(x, Int(1)))),
+			expectedOutput: Div(Exp(Neg(s)), Add(Pow(s, Int(2)), Int(1))),
+		},
+		{
+			name:           "Step times t",
+			input:          Mul(Heaviside(Sub(x, Int(1))), x),
+			expectedOutput: Mul(Exp(Neg(s)), Add(Div(Int(1), s), Div(Int(1), Pow(s, Int(2))))),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := LaplaceTransform(test.input, x, s)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+			if !isZero(Sub(result, test.expectedOutput)) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestLaplaceTransformOfODE(t *testing.T) {
+	x, s := Var("t"), Var("s")
+	y, u := Function("y", x), Function("u", x)
+	Y, U := Var("Y"), Var("U")
+
+	// y'' + 3y' + 2y = u with zero initial values
+	// has the transfer function 1/(s^2 + 3s + 2)
+	eq := Sub(Add(Derivative(Derivative(y, x), x), Mul(Int(3), Derivative(y, x)), Mul(Int(2), y)), u)
+	result, err := LaplaceTransform(eq, x, s)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result = Substitute(result, Function(laplaceTransformName, y, x, s), Y)
+	result = Substitute(result, Function(laplaceTransformName, u, x, s), U)
+	for _, initial := range []Expr{Function("y", Int(0)), Function("y'", Int(0))} {
+		if !RecContains(result, initial) {
+			t.Errorf("Expected the initial value %v in %v", initial, result)
+		}
+		result = Substitute(result, initial, Int(0))
+	}
+
+	// The transform is linear in Y, so Y = -result(Y = 0)/D(result, Y)
+	transfer := Cancel(Div(Neg(Substitute(result, Y, Int(0))), Mul(U, differentiate(result, Y))))
+	expected := Div(Int(1), Add(Pow(s, Int(2)), Mul(Int(3), s), Int(2)))
+	if !isZero(Sub(transfer, expected)) {
+		t.Errorf("Expected the transfer function %v but got %v", expected, transfer)
+	}
+}
+
+func TestInverseLaplaceTransform(t *testing.T) {
+	x, s := Var("t"), Var("s")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput Expr
+	}{
+		{
+			name:           "Distinct real poles",
+			input:          Div(Int(1), Mul(s, Add(s, Int(1)), Add(s, Int(2)))),
+			expectedOutput: Add(Div(Int(1), Int(2)), Neg(Exp(Neg(x))), Div(Exp(Mul(Int(-2), x)), Int(2))),
+		},
+		{
+			name:           "Repeated pole",
+			input:          Div(Int(2), Pow(Sub(s, Int(1)), Int(3))),
+			expectedOutput: Mul(Pow(x, Int(2)), Exp(x)),
+		},
+		{
+			name:           "Complex poles",
+			input:          Div(Add(s, Int(1)), Add(Pow(s, Int(2)), Mul(Int(2), s), Int(5))),
+			expectedOutput: Mul(Exp(Neg(x)), Cos(Mul(Int(2), x))),
+		},
+		{
+			name:           "Repeated complex poles",
+			input:          Div(Int(1), Pow(Add(Pow(s, Int(2)), Int(1)), Int(2))),
+			expectedOutput: Sub(Div(Sin(x), Int(2)), Div(Mul(x, Cos(x)), Int(2))),
+		},
+		{
+			name:           "Irrational frequency",
+			input:          Div(Int(1), Add(Pow(s, Int(2)), Int(2))),
+			expectedOutput: Div(Sin(Mul(Sqrt(Int(2)), x)), Sqrt(Int(2))),
+		},
+		{
+			name:           "Unevaluated transform",
+			input:          Add(Function(laplaceTransformName, Function("f", Var("u")), Var("u"), s), Div(Int(1), s)),
+			expectedOutput: Add(Function("f", x), Int(1)),
+		},
+		{
+			name:           "Unevaluated transform in t",
+			input:          Function(laplaceTransformName, Function("x", x), x, s),
+			expectedOutput: Function("x", x),
+		},
+		{
+			name:           "Constant",
+			input:          Int(3),
+			expectedOutput: Mul(Int(3), DiracDelta(x)),
+		},
+		{
+			name:           "Delayed constant",
+			input:          Mul(Int(3), Exp(Mul(Int(-2), s))),
+			expectedOutput: Mul(Int(3), DiracDelta(Sub(x, Int(2)))),
+		},
+		{
+			name:           "Delayed step",
+			input:          Div(Exp(Mul(Int(-2), s)), s),
+			expectedOutput: Heaviside(Sub(x, Int(2))),
+		},
+		{
+			name:           "Delayed sine",
+			input:          Div(Exp(Neg(s)), Add(Pow(s, Int(2)), Int(1))),
+			expectedOutput: Mul(Heaviside(Sub(x, Int(1))), Sin(Sub(x, Int(1)))),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := InverseLaplaceTransform(test.input, s, x)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+			if !isZero(expandExp(Sub(result, test.expectedOutput))) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestInverseLaplaceTransformUnsupported(t *testing.T) {
+	s, x := Var("s"), Var("t")
+	for _, input := range []Expr{Pow(s, Int(2)), Log(s)} {
+		_, err := InverseLaplaceTransform(input, s, x)
+		if _, ok := err.(*UnsupportedTransformError); !ok {
+			t.Errorf("Expected UnsupportedTransformError for %v but got: %v", input, err)
+		}
+	}
+}
+
+func TestLaplaceTransformRoundTrip(t *testing.T) {
+	x, s := Var("t"), Var("s")
+	input := Function("x", x)
+	transform, err := LaplaceTransform(input, x, s)
+	if err == nil {
+		transform, err = InverseLaplaceTransform(transform, s, x)
+	}
+	if err != nil || !Equal(transform, input) {
+		t.Errorf("Following test failed: round trip\nInput: %v\nExpected: %v\nGot: %v (error %v)", input, input, transform, err)
+	}
+}
+
+func TestLaplaceTransformUnsupported(t *testing.T) {
+	x, s := Var("t"), Var("s")
+	for _, input := range []Expr{Abs(x), Factorial(x), Heaviside(Sub(x, Var("a"))), DiracDelta(Pow(x, Int(2)))} {
+		_, err := LaplaceTransform(input, x, s)
+		if _, ok := err.(*UnsupportedTransformError); !ok {
+			t.Errorf("Expected UnsupportedTransformError for %v but got: %v", input, err)
+		}
+	}
+}
@@ -1,80 +1,900 @@
 package gosymbol
 
 import (
-	"errors"
+	"fmt"
+	"math/big"
 	"slices"
 	"strings"
+	"unicode/utf8"
 )
 
-type treeParser struct {
-	operator string
-	sons     []treeParser
-}
-
-func findMatchingBrackets(formula string) (int, error) {
-	var matchingBracket rune
-	bracket := rune(formula[0])
-	switch bracket {
-	case '(':
-		matchingBracket = ')'
-	case '[':
-		matchingBracket = ']'
-	case '{':
-		matchingBracket = '}'
-	}
-	count := 0
-	for i, c := range formula[1:] {
-		if c == bracket {
-			count++
-		}
-		if c == matchingBracket && count > 0 {
-			count--
-		}
-		if c == matchingBracket && count == 0 {
-			return i + 1, nil
-		}
-	}
-	return 0, errors.New("no matching bracket")
-}
-
-func ParseLatex(formula string) (treeParser, error) {
-	strings.ReplaceAll(formula, " ", "")
-	var parseTree treeParser
-	operators := []byte{'+', '-', '*', '/', '^'}
-	openBrackets := []byte{'(', '[', '{'}
-	i := 0
-	var variable string
-	for i < len(formula) {
-		if slices.Contains(operators, formula[i]) {
-			if variable != string("") {
-				parseTree.sons = append(parseTree.sons, treeParser{variable, nil})
-				variable = string("")
+// The names of the unevaluated integrals, sums and products
+// that ParseLatex returns for \int, \sum and \prod.
+const (
+	integrateName VarName = "Integrate"
+	sumName       VarName = "Sum"
+	productName   VarName = "Product"
+)
+
+// The Greek letters that are understood as variables, e.g. \alpha is Var("α").
+var greekLetters = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ε",
+	"varepsilon": "ε", "zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ",
+	"iota": "ι", "kappa": "κ", "lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ",
+	"rho": "ρ", "sigma": "σ", "tau": "τ", "upsilon": "υ", "phi": "φ",
+	"varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω", "Gamma": "Γ",
+	"Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+}
+
+// The functions written as commands, e.g. \sin x.
+var latexFunctions = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "exp": true, "log": true, "ln": true,
+}
+
+// Commands that only produce spacing and are skipped.
+var latexSpacing = map[string]bool{
+	",": true, ";": true, ":": true, "!": true, " ": true, "quad": true, "qquad": true,
+}
+
+/*
+Parses a LaTeX math formula into an expression. The usual notation
+is understood:
+
+  - +, -, \cdot, \times, * and / with the usual precedence, and
+    implicit multiplication as in 2x\sin y,
+  - powers x^2 and x^{n+1}, \frac{a}{b}, \sqrt{x} and \sqrt[n]{x},
+  - parentheses, brackets, braces, \left( ... \right) and |x|,
+  - \sin, \cos, \tan, \exp, \log, \ln and \log_b, where the argument
+    may be given without parentheses as in \sin 2x, and powers of
+    functions such as \sin^2 x,
+  - \operatorname{f}(x, y) for other functions,
+  - single letter variables, where subscripted ones like x_1 and
+    x_{max} are variables of their own, Greek letters, \pi, e
+    and i or \mathrm{i}, which is the imaginary unit unless it is the
+    index of an enclosing sum or product,
+  - decimal numbers, which are converted to exact rationals,
+  - \int f\,dx, \int_a^b f\,dx, \sum_{k=a}^{b} f and \prod_{k=a}^{b} f,
+    where the bounds of sums and products may be left out as in \sum_k f.
+
+Integrals, sums and products are returned unevaluated as the functions
+Integrate(f, x), Integrate(f, x, a, b), Sum(f, k, a, b), Sum(f, k) and
+the same forms of Product. The result is not simplified. There is no
+expression for infinity, so \infty gives a SyntaxError.
+
+A SyntaxError with the line and column of the offending input is
+returned if formula can not be parsed.
+*/
+func ParseLatex(formula string) (Expr, error) {
+	p := &latexParser{src: formula}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != 0 {
+		return nil, p.errorf("unexpected %q", p.peekToken())
+	}
+	return expr, nil
+}
+
+type latexParser struct {
+	src string
+	pos int
+
+	// The number of enclosing integrals, within which "d x" ends the integrand
+	integrals int
+	// The number of enclosing |...|, within which | ends the absolute value
+	absolutes int
+	// The indices of the enclosing sums and products
+	indices []string
+}
+
+func (p *latexParser) errorf(format string, args ...any) error {
+	return newSyntaxError(p.src, p.pos, fmt.Sprintf(format, args...))
+}
+
+// Skips white space and spacing commands.
+func (p *latexParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '~':
+			p.pos++
+		case c == '\\' && latexSpacing[p.commandAt(p.pos)]:
+			p.pos += 1 + len(p.commandAt(p.pos))
+		default:
+			return
+		}
+	}
+}
+
+// Returns the next character after any white space, and 0 at the end of input.
+func (p *latexParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// Returns the command name if the next token is a command.
+func (p *latexParser) peekCommand() string {
+	if p.peek() != '\\' {
+		return ""
+	}
+	return p.commandAt(p.pos)
+}
+
+// Returns the name of the command starting with the backslash at pos.
+func (p *latexParser) commandAt(pos int) string {
+	end := pos + 1
+	for end < len(p.src) && isLetter(p.src[end]) {
+		end++
+	}
+	if end == pos+1 && end < len(p.src) {
+		// Commands like \, and \{ consist of a single non-letter
+		end++
+	}
+	return p.src[pos+1 : end]
+}
+
+// Returns the next token as text for error messages.
+func (p *latexParser) peekToken() string {
+	if command := p.peekCommand(); command != "" {
+		return "\\" + command
+	}
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	return string(r)
+}
+
+func (p *latexParser) consumeCommand() string {
+	command := p.peekCommand()
+	p.pos += 1 + len(command)
+	return command
+}
+
+func (p *latexParser) expect(c byte) error {
+	if p.peek() != c {
+		if p.peek() == 0 {
+			return p.errorf("expected %q but found the end of input", c)
+		}
+		return p.errorf("expected %q but found %q", c, p.peekToken())
+	}
+	p.pos++
+	return nil
+}
+
+func (p *latexParser) parseExpr() (Expr, error) {
+	lhs, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return lhs, nil
+		}
+		p.pos++
+		rhs, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		if op == '+' {
+			lhs = Add(lhs, rhs)
+		} else {
+			lhs = Sub(lhs, rhs)
+		}
+	}
+}
+
+func (p *latexParser) parseTerm() (Expr, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var rhs Expr
+		switch command := p.peekCommand(); {
+		case command == "cdot" || command == "times":
+			p.consumeCommand()
+			if rhs, err = p.parseUnary(); err != nil {
+				return nil, err
+			}
+			lhs = Mul(lhs, rhs)
+		case p.peek() == '*':
+			p.pos++
+			if rhs, err = p.parseUnary(); err != nil {
+				return nil, err
 			}
-			parseTree.operator = string(formula[i])
-			variable = string("")
-		} else if slices.Contains(openBrackets, formula[i]) {
-			if variable != string("") {
-				parseTree.sons = append(parseTree.sons, treeParser{variable, nil})
-				variable = string("")
+			lhs = Mul(lhs, rhs)
+		case p.peek() == '/':
+			p.pos++
+			if rhs, err = p.parseUnary(); err != nil {
+				return nil, err
 			}
-			variable = string("")
-			finalIndex, err := findMatchingBrackets(formula[i:])
-			if err != nil {
-				return treeParser{}, err
+			lhs = Div(lhs, rhs)
+		case p.startsFactor():
+			if rhs, err = p.parsePower(); err != nil {
+				return nil, err
 			}
-			parsedSon, err := ParseLatex(formula[i+1 : i+finalIndex])
-			if err != nil {
-				return treeParser{}, err
+			lhs = Mul(lhs, rhs)
+		default:
+			return lhs, nil
+		}
+	}
+}
+
+func (p *latexParser) parseUnary() (Expr, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if n, ok := operand.(integer); ok {
+			return intNeg(n), nil
+		}
+		return Neg(operand), nil
+	case '+':
+		p.pos++
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+func (p *latexParser) parsePower() (Expr, error) {
+	base, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == '!' {
+		p.pos++
+		base = Factorial(base)
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+	exponent, err := p.parseScript()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() == '^' {
+		return nil, p.errorf("double superscript, use braces to group exponents")
+	}
+	if Equal(base, E) {
+		return Exp(exponent), nil
+	}
+	return Pow(base, exponent), nil
+}
+
+/*
+Parses the argument of ^, _ or \sqrt, which is a group in braces or
+a single digit, letter or command, so that x^23 is x^2*3.
+*/
+func (p *latexParser) parseScript() (Expr, error) {
+	switch c := p.peek(); {
+	case c == '{':
+		return p.parseGroup()
+	case isDigit(c):
+		p.pos++
+		return Int(int64(c - '0')), nil
+	case isLetter(c):
+		p.pos++
+		return p.letterVariable(string(c)), nil
+	case c == '-':
+		// Not proper LaTeX, but x^-1 is common enough to be accepted
+		p.pos++
+		operand, err := p.parseScript()
+		if err != nil {
+			return nil, err
+		}
+		if n, ok := operand.(integer); ok {
+			return intNeg(n), nil
+		}
+		return Neg(operand), nil
+	case c == '\\':
+		return p.parseAtom()
+	case c == 0:
+		return nil, p.errorf("missing argument at the end of input")
+	}
+	return nil, p.errorf("unexpected %q", p.peekToken())
+}
+
+func (p *latexParser) parseGroup() (Expr, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	// Absolute values do not continue into groups
+	absolutes := p.absolutes
+	p.absolutes = 0
+	expr, err := p.parseExpr()
+	p.absolutes = absolutes
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect('}'); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// Returns true if the next token can start a factor of an implicit product.
+func (p *latexParser) startsFactor() bool {
+	switch c := p.peek(); {
+	case isDigit(c) || c == '.' || c == '(' || c == '[' || c == '{':
+		return true
+	case c == '|':
+		return p.absolutes == 0
+	case isLetter(c):
+		return !p.atDifferential()
+	case c == '\\':
+		command := p.peekCommand()
+		if command == "mathrm" {
+			return !p.atDifferential()
+		}
+		switch command {
+		case "frac", "dfrac", "tfrac", "sqrt", "left", "pi", "infty", "operatorname", "int", "sum", "prod":
+			return true
+		}
+		return latexFunctions[command] || greekLetters[command] != ""
+	}
+	return false
+}
+
+// Returns true if the next tokens are the differential d x of an integral.
+func (p *latexParser) atDifferential() bool {
+	if p.integrals == 0 {
+		return false
+	}
+	start := p.pos
+	defer func() { p.pos = start }()
+	if !p.consumeDifferentialD() {
+		return false
+	}
+	c := p.peek()
+	return isLetter(c) || (c == '\\' && greekLetters[p.peekCommand()] != "")
+}
+
+// Consumes the d of a differential, written as d or \mathrm{d}.
+func (p *latexParser) consumeDifferentialD() bool {
+	switch {
+	case p.peek() == 'd':
+		p.pos++
+		return true
+	case strings.HasPrefix(p.src[p.pos:], "\\mathrm{d}"):
+		p.pos += len("\\mathrm{d}")
+		return true
+	}
+	return false
+}
+
+func (p *latexParser) parseAtom() (Expr, error) {
+	switch c := p.peek(); {
+	case isDigit(c) || c == '.':
+		return p.parseNumber()
+	case isLetter(c):
+		return p.parseName()
+	case c == '(':
+		return p.parseDelimited('(', ')')
+	case c == '[':
+		return p.parseDelimited('[', ']')
+	case c == '{':
+		return p.parseGroup()
+	case c == '|':
+		p.pos++
+		p.absolutes++
+		arg, err := p.parseExpr()
+		p.absolutes--
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect('|'); err != nil {
+			return nil, err
+		}
+		return Abs(arg), nil
+	case c == '\\':
+		return p.parseCommand()
+	case c == 0:
+		return nil, p.errorf("unexpected end of input")
+	}
+	return nil, p.errorf("unexpected %q", p.peekToken())
+}
+
+func (p *latexParser) parseDelimited(open, close byte) (Expr, error) {
+	if err := p.expect(open); err != nil {
+		return nil, err
+	}
+	absolutes := p.absolutes
+	p.absolutes = 0
+	expr, err := p.parseExpr()
+	p.absolutes = absolutes
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(close); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+/*
+Parses a decimal number into an exact rational, e.g. 0.25 is 1/4.
+*/
+func (p *latexParser) parseNumber() (Expr, error) {
+	start := p.pos
+	digits, decimals := "", 0
+	for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+		digits += string(p.src[p.pos])
+		p.pos++
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '.' {
+		p.pos++
+		for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+			digits += string(p.src[p.pos])
+			decimals++
+			p.pos++
+		}
+	}
+	if digits == "" {
+		p.pos = start
+		return nil, p.errorf("malformed number")
+	}
+	value, ok := parseDecimal(digits, decimals)
+	if !ok {
+		literal := p.src[start:p.pos]
+		p.pos = start
+		return nil, p.errorf("number %s does not fit in an int64", literal)
+	}
+	return value, nil
+}
+
+// Returns the rational digits/10^decimals, or false if it does not fit in int64.
+func parseDecimal(digits string, decimals int) (rational, bool) {
+	num, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, false
+	}
+	den := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return bigRatToRational(new(big.Rat).SetFrac(num, den))
+}
+
+// Parses a letter with an optional subscript, where a lone e is Euler's number.
+func (p *latexParser) parseName() (Expr, error) {
+	letter := string(p.src[p.pos])
+	p.pos++
+	name, err := p.parseSubscript(letter)
+	if err != nil {
+		return nil, err
+	}
+	return p.letterVariable(name), nil
+}
+
+// Returns the variable name, where e is Euler's number and i the
+// imaginary unit unless it is the index of an enclosing sum or product.
+func (p *latexParser) letterVariable(name string) Expr {
+	switch name {
+	case "e":
+		return E
+	case "i":
+		if !slices.Contains(p.indices, name) {
+			return I
+		}
+	}
+	return Var(VarName(name))
+}
+
+/*
+Appends an optional subscript to name, so that x_1 and x_{12} are
+the variables x_1 and x_12. Subscripts that are not alphanumeric
+keep their braces, as in x_{i+1}.
+*/
+func (p *latexParser) parseSubscript(name string) (string, error) {
+	if p.peek() != '_' {
+		return name, nil
+	}
+	p.pos++
+	switch c := p.peek(); {
+	case isLetter(c) || isDigit(c):
+		p.pos++
+		return name + "_" + string(c), nil
+	case c == '{':
+		content, err := p.rawGroup()
+		if err != nil {
+			return "", err
+		}
+		if isAlphanumeric(content) {
+			return name + "_" + content, nil
+		}
+		return name + "_{" + content + "}", nil
+	}
+	return "", p.errorf("expected a subscript but found %q", p.peekToken())
+}
+
+// Returns the text inside the group in braces with white space removed.
+func (p *latexParser) rawGroup() (string, error) {
+	start := p.pos
+	if err := p.expect('{'); err != nil {
+		return "", err
+	}
+	depth := 1
+	for p.pos < len(p.src) && depth > 0 {
+		switch p.src[p.pos] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+		p.pos++
+	}
+	if depth > 0 {
+		p.pos = start
+		return "", p.errorf("unmatched '{'")
+	}
+	return strings.Join(strings.Fields(p.src[start+1:p.pos-1]), ""), nil
+}
+
+func (p *latexParser) parseCommand() (Expr, error) {
+	start := p.pos
+	command := p.consumeCommand()
+	switch {
+	case command == "frac" || command == "dfrac" || command == "tfrac":
+		num, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		den, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		return Div(num, den), nil
+	case command == "sqrt":
+		return p.parseSqrt()
+	case command == "left":
+		return p.parseLeftRight()
+	case command == "pi":
+		return PI, nil
+	case command == "infty":
+		p.pos = start
+		return nil, p.errorf("\\infty is not supported")
+	case command == "mathrm":
+		name, err := p.rawGroup()
+		if err != nil {
+			return nil, err
+		}
+		if name == "i" {
+			return I, nil
+		}
+		return p.letterVariable(name), nil
+	case command == "operatorname":
+		return p.parseOperatorName()
+	case command == "int":
+		return p.parseIntegral()
+	case command == "sum":
+		return p.parseSumOrProduct(sumName)
+	case command == "prod":
+		return p.parseSumOrProduct(productName)
+	case latexFunctions[command]:
+		return p.parseFunction(command)
+	case greekLetters[command] != "":
+		name, err := p.parseSubscript(greekLetters[command])
+		if err != nil {
+			return nil, err
+		}
+		return Var(VarName(name)), nil
+	}
+	p.pos = start
+	return nil, p.errorf("unknown command \\%s", command)
+}
+
+func (p *latexParser) parseSqrt() (Expr, error) {
+	var index Expr
+	if p.peek() == '[' {
+		var err error
+		if index, err = p.parseDelimited('[', ']'); err != nil {
+			return nil, err
+		}
+	}
+	arg, err := p.parseScript()
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		return Sqrt(arg), nil
+	}
+	return Pow(arg, Div(Int(1), index)), nil
+}
+
+// The delimiters allowed after \left and \right, with . for none.
+var latexDelimiters = map[string]string{
+	"(": ")", "[": "]", "\\{": "\\}", "|": "|", ".": ".",
+}
+
+func (p *latexParser) parseDelimiter() (string, error) {
+	p.skipSpace()
+	for _, delimiter := range []string{"(", ")", "[", "]", "\\{", "\\}", "|", "."} {
+		if strings.HasPrefix(p.src[p.pos:], delimiter) {
+			p.pos += len(delimiter)
+			return delimiter, nil
+		}
+	}
+	return "", p.errorf("expected a delimiter but found %q", p.peekToken())
+}
+
+func (p *latexParser) parseLeftRight() (Expr, error) {
+	open, err := p.parseDelimiter()
+	if err != nil {
+		return nil, err
+	}
+	close, ok := latexDelimiters[open]
+	if !ok {
+		return nil, p.errorf("%q can not open a \\left delimiter", open)
+	}
+	absolutes := p.absolutes
+	p.absolutes = 0
+	expr, err := p.parseExpr()
+	p.absolutes = absolutes
+	if err != nil {
+		return nil, err
+	}
+	if p.peekCommand() != "right" {
+		return nil, p.errorf("expected \\right but found %q", p.peekToken())
+	}
+	p.consumeCommand()
+	if found, err := p.parseDelimiter(); err != nil {
+		return nil, err
+	} else if found != close && close != "." && found != "." {
+		return nil, p.errorf("\\left%s is closed by \\right%s", open, found)
+	}
+	if open == "|" {
+		return Abs(expr), nil
+	}
+	return expr, nil
+}
+
+/*
+Parses the argument of \sin, \log etc. An argument in parentheses
+is taken as is, and otherwise the implicit product that follows is
+the argument up to the next function, so that \sin 2x\cos x is
+sin(2x)*cos(x). A superscript on the function, as in \sin^2 x,
+is a power of the function value.
+*/
+func (p *latexParser) parseFunction(name string) (Expr, error) {
+	var power, base Expr
+	var err error
+	for p.peek() == '^' || (p.peek() == '_' && name == "log") {
+		script := p.peek()
+		p.pos++
+		if script == '^' {
+			power, err = p.parseScript()
+		} else {
+			base, err = p.parseScript()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var arg Expr
+	if c := p.peek(); c == '(' || c == '[' || p.peekCommand() == "left" {
+		arg, err = p.parseAtom()
+	} else {
+		arg, err = p.parsePower()
+		for err == nil && p.startsFactor() && !latexFunctions[p.peekCommand()] {
+			var factor Expr
+			if factor, err = p.parsePower(); err == nil {
+				arg = Mul(arg, factor)
 			}
-			parseTree.sons = append(parseTree.sons, parsedSon)
-			i = finalIndex
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var value Expr
+	switch name {
+	case "sin":
+		value = Sin(arg)
+	case "cos":
+		value = Cos(arg)
+	case "tan":
+		value = Div(Sin(arg), Cos(arg))
+	case "exp":
+		value = Exp(arg)
+	default:
+		value = Log(arg)
+		if base != nil {
+			value = Div(value, Log(base))
+		}
+	}
+	if power != nil {
+		value = Pow(value, power)
+	}
+	return value, nil
+}
+
+// Parses \operatorname{f}(a, b, ...) into the function application f(a, b, ...).
+func (p *latexParser) parseOperatorName() (Expr, error) {
+	name, err := p.rawGroup()
+	if err != nil {
+		return nil, err
+	}
+	if p.peekCommand() == "left" {
+		p.consumeCommand()
+	}
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	args := []Expr{}
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if p.peekCommand() == "right" {
+		p.consumeCommand()
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return Function(VarName(name), args...), nil
+}
+
+// Parses the optional bounds _a^b of an integral in any order.
+func (p *latexParser) parseBounds() (Expr, Expr, error) {
+	var lower, upper Expr
+	for p.peek() == '_' || p.peek() == '^' {
+		script := p.peek()
+		p.pos++
+		bound, err := p.parseScript()
+		if err != nil {
+			return nil, nil, err
+		}
+		if script == '_' {
+			lower = bound
 		} else {
-			variable = variable + string(formula[i])
+			upper = bound
 		}
-		i++
 	}
-	if variable != string("") {
-		parseTree.sons = append(parseTree.sons, treeParser{variable, nil})
+	if (lower == nil) != (upper == nil) {
+		return nil, nil, p.errorf("an integral needs both a lower and an upper bound")
+	}
+	return lower, upper, nil
+}
+
+func (p *latexParser) parseIntegral() (Expr, error) {
+	lower, upper, err := p.parseBounds()
+	if err != nil {
+		return nil, err
+	}
+
+	p.integrals++
+	var integrand Expr = Int(1)
+	if !p.atDifferential() {
+		integrand, err = p.parseExpr()
+	}
+	p.integrals--
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.consumeDifferentialD() {
+		return nil, p.errorf("expected the differential of the integral")
+	}
+	x, err := p.parseVariable()
+	if err != nil {
+		return nil, err
+	}
+	if lower == nil {
+		return Function(integrateName, integrand, x), nil
+	}
+	return Function(integrateName, integrand, x, lower, upper), nil
+}
+
+// Parses a letter or a Greek letter, with an optional subscript, as a variable.
+func (p *latexParser) parseVariable() (variable, error) {
+	var letter string
+	switch c := p.peek(); {
+	case isLetter(c):
+		letter = string(c)
+		p.pos++
+	case c == '\\' && greekLetters[p.peekCommand()] != "":
+		letter = greekLetters[p.consumeCommand()]
+	default:
+		return variable{}, p.errorf("expected a variable but found %q", p.peekToken())
+	}
+	name, err := p.parseSubscript(letter)
+	if err != nil {
+		return variable{}, err
+	}
+	return Var(VarName(name)), nil
+}
+
+/*
+Parses \sum_{k=a}^{b} f and \prod_{k=a}^{b} f, where the summand
+extends over the following product, so that \sum_{k=1}^n k^2 + 1
+is one plus the sum. Without bounds, as in \sum_k f or \sum_{k} f,
+the index is the only subscript.
+*/
+func (p *latexParser) parseSumOrProduct(name VarName) (Expr, error) {
+	if err := p.expect('_'); err != nil {
+		return nil, err
+	}
+	braced := p.peek() == '{'
+	if braced {
+		p.pos++
+	}
+	k, err := p.parseVariable()
+	if err != nil {
+		return nil, err
+	}
+	if braced && p.peek() == '}' {
+		p.pos++
+		braced = false
+	}
+	if !braced {
+		summand, err := p.parseSummand(k)
+		if err != nil {
+			return nil, err
+		}
+		return Function(name, summand, k), nil
+	}
+	if err := p.expect('='); err != nil {
+		return nil, err
+	}
+	lower, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect('}'); err != nil {
+		return nil, err
+	}
+	if err := p.expect('^'); err != nil {
+		return nil, err
+	}
+	upper, err := p.parseScript()
+	if err != nil {
+		return nil, err
+	}
+	summand, err := p.parseSummand(k)
+	if err != nil {
+		return nil, err
+	}
+	return Function(name, summand, k, lower, upper), nil
+}
+
+// Parses the summand of a sum or product with index k.
+func (p *latexParser) parseSummand(k variable) (Expr, error) {
+	p.indices = append(p.indices, string(k.Name))
+	summand, err := p.parseTerm()
+	p.indices = p.indices[:len(p.indices)-1]
+	return summand, err
+}
+
+// Returns a SyntaxError at the byte offset pos in src.
+func newSyntaxError(src string, pos int, msg string) error {
+	pos = min(pos, len(src))
+	line := 1 + strings.Count(src[:pos], "\n")
+	lineStart := strings.LastIndex(src[:pos], "\n") + 1
+	column := 1 + utf8.RuneCountInString(src[lineStart:pos])
+	return &SyntaxError{Line: line, Column: column, Msg: msg}
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isLetter(c byte) bool { return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') }
+
+func isAlphanumeric(s string) bool {
+	for ix := 0; ix < len(s); ix++ {
+		if !isDigit(s[ix]) && !isLetter(s[ix]) {
+			return false
+		}
 	}
-	return parseTree, nil
+	return s != ""
 }
@@ -0,0 +1,114 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseLatex(t *testing.T) {
+	x, y, n, k := Var("x"), Var("y"), Var("n"), Var("k")
+	a, b, c := Var("a"), Var("b"), Var("c")
+
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput Expr
+	}{
+		{name: "Parentheses", input: "(2+2)*6", expectedOutput: Mul(Add(Int(2), Int(2)), Int(6))},
+		{name: "Precedence of \\cdot over +", input: "1 + 2 \\cdot 3", expectedOutput: Add(Int(1), Mul(Int(2), Int(3)))},
+		{name: "Implicit multiplication", input: "2x^2", expectedOutput: Mul(Int(2), Pow(x, Int(2)))},
+		{name: "Single digit exponent", input: "x^23", expectedOutput: Mul(Pow(x, Int(2)), Int(3))},
+		{name: "Exponent in braces", input: "2^{3^{4}}", expectedOutput: Pow(Int(2), Pow(Int(3), Int(4)))},
+		{name: "Unary minus binds weaker than powers", input: "-x^2", expectedOutput: Neg(Pow(x, Int(2)))},
+		{name: "Negative literal", input: "-3 + x", expectedOutput: Add(Int(-3), x)},
+		{name: "Division is left associative", input: "a/b/c", expectedOutput: Div(Div(a, b), c)},
+		{name: "Fraction", input: "\\frac{1}{x+1}", expectedOutput: Div(Int(1), Add(x, Int(1)))},
+		{name: "Square root", input: "\\sqrt{x}", expectedOutput: Sqrt(x)},
+		{name: "Cube root", input: "\\sqrt[3]{x}", expectedOutput: Pow(x, Div(Int(1), Int(3)))},
+		{name: "Function argument without parentheses", input: "\\sin 2x \\cos x", expectedOutput: Mul(Sin(Mul(Int(2), x)), Cos(x))},
+		{name: "Power of a function", input: "\\sin^2 x", expectedOutput: Pow(Sin(x), Int(2))},
+		{name: "Tangent", input: "\\tan(x)", expectedOutput: Div(Sin(x), Cos(x))},
+		{name: "Logarithm with a base", input: "\\log_2 8", expectedOutput: Div(Log(Int(8)), Log(Int(2)))},
+		{name: "Natural logarithm", input: "\\ln\\left(x\\right)", expectedOutput: Log(x)},
+		{name: "Powers of e", input: "e^{-x^2}", expectedOutput: Exp(Neg(Pow(x, Int(2))))},
+		{name: "Euler's number", input: "2e", expectedOutput: Mul(Int(2), E)},
+		{name: "\\left and \\right", input: "\\left(a+b\\right)^2", expectedOutput: Pow(Add(a, b), Int(2))},
+		{name: "Absolute value", input: "2|x - 1|", expectedOutput: Mul(Int(2), Abs(Sub(x, Int(1))))},
+		{name: "\\left| and \\right|", input: "\\left|x\\right|", expectedOutput: Abs(x)},
+		{
+			name:           "Subscripts",
+			input:          "x_1 + x_{12} + \\alpha_{i+1}",
+			expectedOutput: Add(Var("x_1"), Var("x_12"), Var("α_{i+1}")),
+		},
+		{name: "Pi and Greek letters", input: "2\\pi\\omega", expectedOutput: Mul(Int(2), PI, Var("ω"))},
+		{name: "Decimal numbers are exact", input: "0.25x", expectedOutput: Mul(Div(Int(1), Int(4)), x)},
+		{name: "Factorial", input: "n!", expectedOutput: Factorial(n)},
+		{name: "Operator name", input: "\\operatorname{f}(x, y)", expectedOutput: Function("f", x, y)},
+		{name: "Indefinite integral", input: "\\int x^2 \\, dx", expectedOutput: Function(integrateName, Pow(x, Int(2)), x)},
+		{name: "Definite integral", input: "\\int_0^1 x\\,\\mathrm{d}x", expectedOutput: Function(integrateName, x, x, Int(0), Int(1))},
+		{name: "Integral of a sum", input: "\\int (x + y) + 1 dy", expectedOutput: Function(integrateName, Add(x, y, Int(1)), y)},
+		{
+			name:           "Sum binds tighter than +",
+			input:          "\\sum_{k=1}^{n} k^2 + 1",
+			expectedOutput: Add(Function(sumName, Pow(k, Int(2)), k, Int(1), n), Int(1)),
+		},
+		{name: "Product", input: "\\prod_{k=1}^n k", expectedOutput: Function(productName, k, k, Int(1), n)},
+		{name: "Imaginary unit", input: "e^{i\\pi}", expectedOutput: Exp(Mul(I, PI))},
+		{name: "Upright imaginary unit", input: "3\\mathrm{i}", expectedOutput: Mul(Int(3), I)},
+		{name: "Index named i", input: "\\sum_{i=1}^n i", expectedOutput: Function(sumName, Var("i"), Var("i"), Int(1), n)},
+		{name: "Sum without bounds", input: "\\sum_k k", expectedOutput: Function(sumName, k, k)},
+		{name: "Product without bounds in braces", input: "\\prod_{k} 2k", expectedOutput: Function(productName, Mul(Int(2), k), k)},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := ParseLatex(test.input)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nInput: %v\nUnexpected error: %v", test.name, test.input, err)
+			}
+			if !Equal(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestParseLatexErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput SyntaxError
+	}{
+		{name: "Double superscript", input: "x^2^3", expectedOutput: SyntaxError{Line: 1, Column: 4}},
+		{name: "Unknown command", input: "1 + \\foo", expectedOutput: SyntaxError{Line: 1, Column: 5}},
+		{name: "Infinity", input: "\\int_0^\\infty e^{-x} dx", expectedOutput: SyntaxError{Line: 1, Column: 8}},
+		{name: "Unclosed parenthesis", input: "(x+1", expectedOutput: SyntaxError{Line: 1, Column: 5}},
+		{name: "Unclosed group", input: "\\frac{1}{", expectedOutput: SyntaxError{Line: 1, Column: 10}},
+		{name: "Error on a later line", input: "x +\n  )", expectedOutput: SyntaxError{Line: 2, Column: 3}},
+		{name: "Missing differential", input: "\\int x", expectedOutput: SyntaxError{Line: 1, Column: 7}},
+		{name: "Mismatched \\right", input: "\\left( x \\right]", expectedOutput: SyntaxError{Line: 1, Column: 17}},
+		{name: "Integer wrapping around int64", input: "25000000000000000000", expectedOutput: SyntaxError{Line: 1, Column: 1}},
+		{name: "Decimal too large", input: "2 + 9223372036854775808.5", expectedOutput: SyntaxError{Line: 1, Column: 5}},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			_, err := ParseLatex(test.input)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) || syntaxErr.Line != test.expectedOutput.Line || syntaxErr.Column != test.expectedOutput.Column {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: error at %d:%d\nGot: %v", test.name, test.input, test.expectedOutput.Line, test.expectedOutput.Column, err)
+			}
+		})
+	}
+}
+
+func TestParseLatexNumberTooLarge(t *testing.T) {
+	input := "1 + 25000000000000000000"
+	_, err := ParseLatex(input)
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || !strings.Contains(syntaxErr.Msg, "25000000000000000000") {
+		t.Errorf("Following test failed: number too large\nInput: %v\nExpected: an error naming the number\nGot: %v", input, err)
+	}
+}
@@ -0,0 +1,457 @@
+package gosymbol
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+The options of the LaTeX printer. The zero value of a field means
+its default, see DefaultLatexStyle.
+*/
+type LatexStyle struct {
+	// The multiplication sign written between factors that cannot be
+	// juxtaposed, as in 2 \cdot 3, e.g. \cdot or \times.
+	MulSymbol string
+	// Write MulSymbol between all factors instead of juxtaposing them.
+	ExplicitMul bool
+	// Write quotients as a / b instead of \frac{a}{b}.
+	InlineFractions bool
+	// Write exp(x) as \exp\left(x\right) instead of e^{x}.
+	ExpAsFunction bool
+	// The command of the natural logarithm, e.g. \ln or \log.
+	LogCommand string
+	// The symbol of the imaginary unit, e.g. i or \mathrm{i}.
+	ImaginaryUnit string
+}
+
+// Returns the style used by Latex.
+func DefaultLatexStyle() LatexStyle {
+	return LatexStyle{MulSymbol: `\cdot`, LogCommand: `\ln`, ImaginaryUnit: "i"}
+}
+
+/*
+Returns expr as a LaTeX math formula in the default style, e.g.
+
+	\frac{3 x^{2}}{2} - e^{-x} + \sqrt{y}
+
+See LatexWith.
+*/
+func Latex(expr Expr) string {
+	return LatexWith(expr, DefaultLatexStyle())
+}
+
+/*
+Returns expr as a LaTeX math formula in the given style. Parentheses
+are only written where the precedence of the operators requires them,
+negative terms are subtracted, factors with negative exponents are
+moved to the denominator of a \frac, and variables named after Greek
+letters, like α or alpha, are written as \alpha. Integrals, sums and
+products as returned by ParseLatex are written in their usual form,
+so that ParseLatex(Latex(expr)) is equal to expr for these too.
+*/
+func LatexWith(expr Expr, style LatexStyle) string {
+	defaults := DefaultLatexStyle()
+	if style.MulSymbol == "" {
+		style.MulSymbol = defaults.MulSymbol
+	}
+	if style.LogCommand == "" {
+		style.LogCommand = defaults.LogCommand
+	}
+	if style.ImaginaryUnit == "" {
+		style.ImaginaryUnit = defaults.ImaginaryUnit
+	}
+	str, _ := latexPrinter{style}.print(expr)
+	return str
+}
+
+// The precedence of the printed expressions, from the weakest binding.
+const (
+	latexSum = iota + 1
+	latexProduct
+	latexPower
+	latexAtom
+)
+
+// The LaTeX commands of the Greek letters, indexed by the letter.
+var greekCommands = func() map[string]string {
+	commands := map[string]string{string(PI.Name): `\pi`}
+	for command, letter := range greekLetters {
+		// Prefer \epsilon and \phi over their variants
+		if _, ok := commands[letter]; !ok || !strings.HasPrefix(command, "var") {
+			commands[letter] = `\` + command
+		}
+	}
+	return commands
+}()
+
+// The undefined functions written as a LaTeX command.
+var latexFunctionCommands = map[VarName]string{
+	diracDeltaName: `\delta`,
+	gammaName:      `\Gamma`,
+	reName:         `\operatorname{Re}`,
+	imName:         `\operatorname{Im}`,
+	argName:        `\arg`,
+}
+
+type latexPrinter struct {
+	style LatexStyle
+}
+
+// Returns expr as LaTeX together with its precedence.
+func (p latexPrinter) print(expr Expr) (string, int) {
+	switch e := expr.(type) {
+	case undefined:
+		return `\mathrm{Undefined}`, latexAtom
+	case integer:
+		if e.value < 0 {
+			return p.product([]Expr{e})
+		}
+		return e.String(), latexAtom
+	case fraction:
+		return p.product([]Expr{e})
+	case variable:
+		return latexName(e.Name), latexAtom
+	case constrainedVariable:
+		return latexName(e.Name), latexAtom
+	case add:
+		return p.sum(e.Operands), latexSum
+	case mul:
+		return p.product(e.Operands)
+	case pow:
+		return p.power(e)
+	case exp:
+		if Equal(e.Arg, Int(1)) {
+			return "e", latexAtom
+		}
+		if p.style.ExpAsFunction {
+			return `\exp` + p.arguments(e.Arg), latexAtom
+		}
+		return "e^{" + p.string(e.Arg) + "}", latexPower
+	case log:
+		return p.style.LogCommand + p.arguments(e.Arg), latexAtom
+	case sqrt:
+		return `\sqrt{` + p.string(e.Arg) + "}", latexAtom
+	case sin:
+		return `\sin` + p.arguments(e.Arg), latexAtom
+	case cos:
+		return `\cos` + p.arguments(e.Arg), latexAtom
+	case FunctionApplication:
+		return p.function(e)
+	case derivative:
+		operand := p.wrap(e.Arg, latexPower)
+		return `\frac{\mathrm{d}}{\mathrm{d}` + latexName(e.Var.Name) + "} " + operand, latexProduct
+	}
+	return expr.String(), latexAtom
+}
+
+func (p latexPrinter) string(expr Expr) string {
+	str, _ := p.print(expr)
+	return str
+}
+
+// Prints expr and parenthesizes it if it binds weaker than precedence.
+func (p latexPrinter) wrap(expr Expr, precedence int) string {
+	str, prec := p.print(expr)
+	if prec < precedence {
+		return `\left(` + str + `\right)`
+	}
+	return str
+}
+
+// Prints the parenthesized argument list of a function.
+func (p latexPrinter) arguments(args ...Expr) string {
+	strs := make([]string, len(args))
+	for ix, arg := range args {
+		strs[ix] = p.string(arg)
+	}
+	return `\left(` + strings.Join(strs, ", ") + `\right)`
+}
+
+// Prints the terms of a sum, subtracting the negative ones.
+func (p latexPrinter) sum(terms []Expr) string {
+	str := p.string(terms[0])
+	for _, term := range terms[1:] {
+		if negated, ok := negatedTerm(term); ok {
+			str += " - " + p.wrap(negated, latexProduct)
+		} else {
+			str += " + " + p.string(term)
+		}
+	}
+	return str
+}
+
+/*
+Returns -term and true if term is a negative number or a product with
+a negative coefficient, so that it can be subtracted.
+*/
+func negatedTerm(term Expr) (Expr, bool) {
+	switch e := term.(type) {
+	case integer, fraction:
+		r := e.(rational)
+		if r.approx() < 0 {
+			return ratMinus(r), true
+		}
+	case mul:
+		operands, negative := foldNegations(e.Operands)
+		if r, ok := operands[0].(rational); ok && r.approx() < 0 {
+			operands = append([]Expr{ratMinus(r)}, operands[1:]...)
+			negative = !negative
+		}
+		if !negative {
+			break
+		}
+		if len(operands) == 1 {
+			return operands[0], true
+		}
+		return mul{Operands: operands}, true
+	}
+	return nil, false
+}
+
+/*
+Removes the factors -1 from a product, e.g. of -(-x), and returns true
+if there was an odd number of them. A product of only -1 leaves 1.
+*/
+func foldNegations(factors []Expr) ([]Expr, bool) {
+	result := []Expr{}
+	negative := false
+	for _, factor := range factors {
+		if Equal(factor, Int(-1)) {
+			negative = !negative
+		} else {
+			result = append(result, factor)
+		}
+	}
+	if len(result) == 0 {
+		result = append(result, Int(1))
+	}
+	return result, negative
+}
+
+/*
+Prints a product, writing a leading negative coefficient as a minus
+sign and the factors with negative exponents as a denominator.
+*/
+func (p latexPrinter) product(factors []Expr) (string, int) {
+	factors, negative := foldNegations(factors)
+	numeratorCoeff, denominatorCoeff := "", ""
+	if r, ok := factors[0].(rational); ok && !Equal(r, Undefined()) {
+		factors = factors[1:]
+		num, den := r.numerator(), r.denominator()
+		negative = negative != (num.value < 0 != (den.value < 0))
+		num, den = intAbs(num), intAbs(den)
+		if num.value != 1 || len(factors) == 0 {
+			numeratorCoeff = num.String()
+		}
+		if den.value != 1 {
+			denominatorCoeff = den.String()
+		}
+	}
+	numerator, denominator := []Expr{}, []Expr{}
+	for _, factor := range factors {
+		if e, ok := factor.(pow); ok && !Equal(e, I) {
+			if r, ok := e.Exponent.(rational); ok && r.approx() < 0 {
+				if Equal(r, Int(-1)) {
+					denominator = append(denominator, e.Base)
+				} else {
+					denominator = append(denominator, pow{Base: e.Base, Exponent: ratMinus(r)})
+				}
+				continue
+			}
+		}
+		numerator = append(numerator, factor)
+	}
+
+	var str string
+	prec := latexProduct
+	switch {
+	case denominatorCoeff == "" && len(denominator) == 0:
+		if numeratorCoeff == "" && len(numerator) == 1 && !negative {
+			// A single factor keeps its own precedence, e.g. x^2
+			return p.print(numerator[0])
+		}
+		if len(numerator) == 0 {
+			prec = latexAtom
+		}
+		str = p.factors(numeratorCoeff, numerator, latexProduct)
+	case p.style.InlineFractions:
+		den := p.factors(denominatorCoeff, denominator, latexPower)
+		if denominatorCoeff != "" && len(denominator) > 0 || len(denominator) > 1 {
+			den = `\left(` + den + `\right)`
+		}
+		str = p.factors(numeratorCoeff, numerator, latexProduct) + " / " + den
+	default:
+		str = `\frac{` + p.factors(numeratorCoeff, numerator, latexSum) + "}{" + p.factors(denominatorCoeff, denominator, latexSum) + "}"
+	}
+	if negative {
+		return "-" + str, latexSum
+	}
+	return str, prec
+}
+
+/*
+Prints the factors of a numerator or denominator after the coefficient
+coeff, which may be empty. A lone factor is parenthesized if it binds
+weaker than precedence, several factors if they are sums.
+*/
+func (p latexPrinter) factors(coeff string, factors []Expr, precedence int) string {
+	if coeff == "" && len(factors) == 1 {
+		return p.wrap(factors[0], precedence)
+	}
+	strs := []string{}
+	if coeff != "" {
+		strs = append(strs, coeff)
+	}
+	for _, factor := range factors {
+		strs = append(strs, p.wrap(factor, latexProduct))
+	}
+	return p.juxtapose(strs)
+}
+
+/*
+Joins the factors of a product, juxtaposing them except before a
+number or a minus sign, where MulSymbol is written.
+*/
+func (p latexPrinter) juxtapose(factors []string) string {
+	if len(factors) == 0 {
+		return "1"
+	}
+	str := factors[0]
+	for ix := 1; ix < len(factors); ix++ {
+		next := factors[ix]
+		switch {
+		case p.style.ExplicitMul || isDigit(next[0]) || next[0] == '-':
+			str += " " + p.style.MulSymbol + " "
+		case !isAllDigits(factors[ix-1]):
+			str += " "
+		}
+		str += next
+	}
+	return str
+}
+
+func isAllDigits(s string) bool {
+	for ix := 0; ix < len(s); ix++ {
+		if !isDigit(s[ix]) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func (p latexPrinter) power(e pow) (string, int) {
+	if Equal(e, I) {
+		return p.style.ImaginaryUnit, latexAtom
+	}
+	r, isRational := e.Exponent.(rational)
+	if isRational && r.approx() < 0 {
+		return p.product([]Expr{e})
+	}
+	if isRational && r.numerator() == Int(1) && r.denominator().value > 1 {
+		if r.denominator() == Int(2) {
+			return `\sqrt{` + p.string(e.Base) + "}", latexAtom
+		}
+		return `\sqrt[` + r.denominator().String() + "]{" + p.string(e.Base) + "}", latexAtom
+	}
+
+	var exponent string
+	if _, ok := e.Exponent.(fraction); ok {
+		exponent = r.numerator().String() + "/" + r.denominator().String()
+	} else {
+		exponent = p.string(e.Exponent)
+	}
+	// Powers of functions are written \sin^{2}\left(x\right)
+	if n, ok := e.Exponent.(integer); ok && n.value > 0 {
+		switch base := e.Base.(type) {
+		case sin:
+			return `\sin^{` + exponent + "}" + p.arguments(base.Arg), latexAtom
+		case cos:
+			return `\cos^{` + exponent + "}" + p.arguments(base.Arg), latexAtom
+		case log:
+			return p.style.LogCommand + "^{" + exponent + "}" + p.arguments(base.Arg), latexAtom
+		}
+	}
+	return p.wrap(e.Base, latexAtom) + "^{" + exponent + "}", latexPower
+}
+
+func (p latexPrinter) function(f FunctionApplication) (string, int) {
+	args := f.Args
+	switch {
+	case f.Name == absName && len(args) == 1:
+		return `\left|` + p.string(args[0]) + `\right|`, latexAtom
+	case f.Name == conjugateName && len(args) == 1:
+		return `\overline{` + p.string(args[0]) + "}", latexAtom
+	case f.Name == factorialName && len(args) == 1:
+		return p.wrap(args[0], latexAtom) + "!", latexPower
+	case f.Name == binomialName && len(args) == 2:
+		return `\binom{` + p.string(args[0]) + "}{" + p.string(args[1]) + "}", latexAtom
+	case f.Name == integrateName && (len(args) == 2 || len(args) == 4):
+		str := `\int`
+		if len(args) == 4 {
+			str += "_{" + p.string(args[2]) + "}^{" + p.string(args[3]) + "}"
+		}
+		return str + " " + p.wrap(args[0], latexProduct) + ` \, \mathrm{d}` + p.string(args[1]), latexSum
+	case (f.Name == sumName || f.Name == productName) && (len(args) == 2 || len(args) == 4):
+		command := `\sum`
+		if f.Name == productName {
+			command = `\prod`
+		}
+		bounds := "_{" + p.string(args[1]) + "}"
+		if len(args) == 4 {
+			bounds = "_{" + p.string(args[1]) + " = " + p.string(args[2]) + "}^{" + p.string(args[3]) + "}"
+		}
+		return command + bounds + " " + p.wrap(args[0], latexProduct), latexSum
+	}
+
+	name, ok := latexFunctionCommands[f.Name]
+	if !ok {
+		name = latexSymbol(string(f.Name))
+		if !strings.HasPrefix(name, `\`) && utf8.RuneCountInString(string(f.Name)) > 1 {
+			name = `\operatorname{` + string(f.Name) + "}"
+		}
+	}
+	return name + p.arguments(args...), latexAtom
+}
+
+/*
+Returns the name of a variable in LaTeX. Greek letters, written as
+α or alpha, become commands, names of several letters are upright
+and the part after an underscore is a subscript, e.g. x_{12}.
+*/
+func latexName(name VarName) string {
+	base, subscript, hasSubscript := strings.Cut(string(name), "_")
+	str := latexSymbol(base)
+	if utf8.RuneCountInString(base) > 1 && !strings.HasPrefix(str, `\`) {
+		str = `\mathrm{` + str + "}"
+	}
+	if hasSubscript {
+		subscript = strings.TrimSuffix(strings.TrimPrefix(subscript, "{"), "}")
+		str += "_{" + latexSymbols(subscript) + "}"
+	}
+	return str
+}
+
+// Returns the command of a Greek letter or letter name, or s itself.
+func latexSymbol(s string) string {
+	if command, ok := greekCommands[s]; ok {
+		return command
+	}
+	if _, ok := greekLetters[s]; ok {
+		return `\` + s
+	}
+	return s
+}
+
+// Replaces the Greek letters in s by their commands.
+func latexSymbols(s string) string {
+	var str strings.Builder
+	for _, r := range s {
+		if command, ok := greekCommands[string(r)]; ok {
+			str.WriteString(command + " ")
+		} else {
+			str.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(str.String())
+}
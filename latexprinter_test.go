@@ -0,0 +1,140 @@
+package gosymbol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLatex(t *testing.T) {
+	x, y, k, n := Var("x"), Var("y"), Var("k"), Var("n")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput string
+	}{
+		{
+			name:           "Example from the documentation",
+			input:          Add(Mul(Div(Int(3), Int(2)), Pow(x, Int(2))), Neg(Exp(Neg(x))), Sqrt(y)),
+			expectedOutput: `\frac{3x^{2}}{2} - e^{-x} + \sqrt{y}`,
+		},
+		{name: "Difference", input: Sub(x, y), expectedOutput: `x - y`},
+		{name: "Difference with a coefficient", input: Sub(x, Mul(Int(2), y)), expectedOutput: `x - 2y`},
+		{name: "Subtracted sum", input: Sub(x, Add(y, Int(1))), expectedOutput: `x - \left(y + 1\right)`},
+		{name: "Negation", input: Neg(x), expectedOutput: `-x`},
+		{name: "Negated sum", input: Neg(Add(x, y)), expectedOutput: `-\left(x + y\right)`},
+		{name: "Leading negative number", input: Add(Int(-1), x), expectedOutput: `-1 + x`},
+		{name: "Quotient", input: Div(x, y), expectedOutput: `\frac{x}{y}`},
+		{name: "Quotient of sums", input: Div(Add(x, Int(1)), Sub(x, Int(1))), expectedOutput: `\frac{x + 1}{x - 1}`},
+		{name: "Negative power of a sum", input: Pow(Add(x, Int(1)), Int(-2)), expectedOutput: `\frac{1}{\left(x + 1\right)^{2}}`},
+		{name: "Fraction", input: Div(Int(-3), Int(4)), expectedOutput: `-\frac{3}{4}`},
+		{name: "Fractional coefficient", input: Mul(Div(Int(3), Int(2)), x), expectedOutput: `\frac{3x}{2}`},
+		{name: "Square root in a denominator", input: Mul(x, Pow(y, Div(Int(-1), Int(2)))), expectedOutput: `\frac{x}{\sqrt{y}}`},
+		{name: "Juxtaposition", input: Mul(Int(2), Pow(x, Int(2)), Add(x, y)), expectedOutput: `2x^{2} \left(x + y\right)`},
+		{name: "Product of numbers", input: Mul(Int(2), Int(3)), expectedOutput: `2 \cdot 3`},
+		{name: "Cube root", input: Pow(x, Div(Int(1), Int(3))), expectedOutput: `\sqrt[3]{x}`},
+		{name: "Rational exponent", input: Pow(x, Div(Int(3), Int(2))), expectedOutput: `x^{3/2}`},
+		{name: "Negative base", input: Pow(Int(-2), x), expectedOutput: `\left(-2\right)^{x}`},
+		{name: "Power of a power", input: Pow(Pow(x, y), Int(2)), expectedOutput: `\left(x^{y}\right)^{2}`},
+		{name: "Imaginary unit", input: Mul(Int(2), I, PI), expectedOutput: `2i \pi`},
+		{name: "Exponential", input: Exp(Div(x, Int(2))), expectedOutput: `e^{\frac{x}{2}}`},
+		{name: "Euler's number", input: E, expectedOutput: `e`},
+		{name: "Power of a function", input: Pow(Sin(x), Int(2)), expectedOutput: `\sin^{2}\left(x\right)`},
+		{name: "Logarithm", input: Log(Cos(x)), expectedOutput: `\ln\left(\cos\left(x\right)\right)`},
+		{name: "Greek letters", input: Mul(Var("alpha"), Var("θ_1")), expectedOutput: `\alpha \theta_{1}`},
+		{name: "Long variable name", input: Var("rate"), expectedOutput: `\mathrm{rate}`},
+		{name: "Undefined function", input: Function("f", x, y), expectedOutput: `f\left(x, y\right)`},
+		{name: "Long function name", input: Function("foo", x), expectedOutput: `\operatorname{foo}\left(x\right)`},
+		{name: "Gamma function", input: Gamma(x), expectedOutput: `\Gamma\left(x\right)`},
+		{name: "Absolute value", input: Abs(Sub(x, Int(1))), expectedOutput: `\left|x - 1\right|`},
+		{name: "Factorial", input: Factorial(Add(n, Int(1))), expectedOutput: `\left(n + 1\right)!`},
+		{name: "Binomial", input: Binomial(n, k), expectedOutput: `\binom{n}{k}`},
+		{name: "Derivative", input: Derivative(Function("f", x), x), expectedOutput: `\frac{\mathrm{d}}{\mathrm{d}x} f\left(x\right)`},
+		{name: "Definite integral", input: Function(integrateName, x, x, Int(0), Int(1)), expectedOutput: `\int_{0}^{1} x \, \mathrm{d}x`},
+		{
+			name:           "Sum",
+			input:          Add(Function(sumName, Pow(k, Int(2)), k, Int(1), n), Int(1)),
+			expectedOutput: `\sum_{k = 1}^{n} k^{2} + 1`,
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := Latex(test.input)
+			if result != test.expectedOutput {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestLatexWith(t *testing.T) {
+	x, y := Var("x"), Var("y")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		style          LatexStyle
+		expectedOutput string
+	}{
+		{
+			name:           "Inline fractions and \\times",
+			input:          Div(Mul(Int(2), x), Mul(y, Add(x, Int(1)))),
+			style:          LatexStyle{InlineFractions: true, ExplicitMul: true, MulSymbol: `\times`},
+			expectedOutput: `2 \times x / \left(y \times \left(x + 1\right)\right)`,
+		},
+		{
+			name:           "Inline fraction with a lone denominator",
+			input:          Div(x, Add(y, Int(1))),
+			style:          LatexStyle{InlineFractions: true},
+			expectedOutput: `x / \left(y + 1\right)`,
+		},
+		{
+			name:           "Exponential and logarithm as functions",
+			input:          Mul(Exp(x), Log(y)),
+			style:          LatexStyle{ExpAsFunction: true, LogCommand: `\log`},
+			expectedOutput: `\exp\left(x\right) \log\left(y\right)`,
+		},
+		{
+			name:           "Upright imaginary unit",
+			input:          Add(Int(1), I),
+			style:          LatexStyle{ImaginaryUnit: `\mathrm{i}`},
+			expectedOutput: `1 + \mathrm{i}`,
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := LatexWith(test.input, test.style)
+			if result != test.expectedOutput {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestLatexRoundTrip(t *testing.T) {
+	x, y, k, n := Var("x"), Var("y"), Var("k"), Var("n")
+
+	tests := []Expr{
+		Add(Mul(Int(3), Pow(x, Int(2))), Div(Sin(y), Int(2)), Neg(Exp(Neg(x)))),
+		Sub(Div(Add(x, Int(1)), Sub(x, Int(1))), Mul(Int(2), Pow(y, Int(-3)))).Simplify(),
+		Mul(Int(2), PI, Sqrt(Add(Pow(x, Int(2)), Int(1)))),
+		Pow(Cos(x), Int(2)),
+		Mul(Int(2), Abs(Sub(x, Int(1)))),
+		Function(integrateName, Pow(x, Int(2)), x, Int(0), Int(1)),
+		Add(Function(sumName, Pow(k, Int(2)), k, Int(1), n), Int(1)),
+		Function(sumName, Mul(I, k), k),
+		Function(productName, Mul(Int(2), k), k),
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			str := Latex(test)
+			result, err := ParseLatex(str)
+			if err != nil || !Equal(result.Simplify(), test.Simplify()) {
+				t.Errorf("Following test failed: round trip through ParseLatex\nInput: %v\nExpected: %v\nGot: %v (error %v)", str, test, result, err)
+			}
+		})
+	}
+}
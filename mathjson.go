@@ -0,0 +1,326 @@
+package gosymbol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// The largest integer that a JSON number keeps exactly in JavaScript.
+const maxSafeInteger = 1<<53 - 1
+
+// The MathJSON symbols of the constants.
+const (
+	mathJSONPi        = "Pi"
+	mathJSONE         = "ExponentialE"
+	mathJSONI         = "ImaginaryUnit"
+	mathJSONUndefined = "NaN"
+)
+
+// The MathJSON names of the undefined functions whose name differs.
+var mathJSONFunctionNames = map[VarName]string{
+	reName:  "Real",
+	imName:  "Imaginary",
+	argName: "Argument",
+}
+
+/*
+Returns expr in the MathJSON format of CortexJS, e.g.
+
+	["Add", ["Multiply", 3, ["Power", "x", 2]], ["Sin", "y"]]
+
+Numbers are JSON numbers, except integers beyond 2^53, which are
+written {"num": "..."} to keep their digits in JavaScript, and
+fractions, which are ["Rational", p, q]. The integrals, sums and
+products returned by ParseLatex use the Limits form of CortexJS.
+*/
+func MarshalMathJSON(expr Expr) ([]byte, error) {
+	return json.Marshal(toMathJSON(expr))
+}
+
+func toMathJSON(expr Expr) any {
+	switch e := expr.(type) {
+	case undefined:
+		return mathJSONUndefined
+	case integer:
+		if e.value > maxSafeInteger || e.value < -maxSafeInteger {
+			return map[string]string{"num": e.String()}
+		}
+		return e.value
+	case fraction:
+		return []any{"Rational", toMathJSON(e.numerator()), toMathJSON(e.denominator())}
+	case variable:
+		if e.Name == PI.Name {
+			return mathJSONPi
+		}
+		return string(e.Name)
+	case constrainedVariable:
+		return string(e.Name)
+	case add:
+		return mathJSONFunction("Add", e.Operands...)
+	case mul:
+		return mathJSONFunction("Multiply", e.Operands...)
+	case pow:
+		if Equal(e, I) {
+			return mathJSONI
+		}
+		return mathJSONFunction("Power", e.Base, e.Exponent)
+	case exp:
+		if Equal(e.Arg, Int(1)) {
+			return mathJSONE
+		}
+		return mathJSONFunction("Exp", e.Arg)
+	case log:
+		return mathJSONFunction("Ln", e.Arg)
+	case sqrt:
+		return mathJSONFunction("Sqrt", e.Arg)
+	case sin:
+		return mathJSONFunction("Sin", e.Arg)
+	case cos:
+		return mathJSONFunction("Cos", e.Arg)
+	case FunctionApplication:
+		switch {
+		case e.Name == integrateName && len(e.Args) == 4,
+			(e.Name == sumName || e.Name == productName) && len(e.Args) == 4:
+			return []any{string(e.Name), toMathJSON(e.Args[0]), mathJSONFunction("Limits", e.Args[1:]...)}
+		}
+		if name, ok := mathJSONFunctionNames[e.Name]; ok {
+			return mathJSONFunction(name, e.Args...)
+		}
+		return mathJSONFunction(string(e.Name), e.Args...)
+	case derivative:
+		return mathJSONFunction("D", e.Arg, e.Var)
+	}
+	return expr.String()
+}
+
+func mathJSONFunction(head string, args ...Expr) []any {
+	result := []any{head}
+	for _, arg := range args {
+		result = append(result, toMathJSON(arg))
+	}
+	return result
+}
+
+/*
+Parses an expression in the MathJSON format of CortexJS. Numbers may
+be JSON numbers, which are converted to exact rationals, or objects
+{"num": "..."}, symbols may be strings or {"sym": "..."}, and function
+applications arrays or {"fn": [...]}. Besides the heads written by
+MarshalMathJSON, Subtract, Negate, Divide, Square, Root, Log, Tan,
+Exp and Ln are understood, and the constants Pi, ExponentialE,
+ImaginaryUnit and NaN. Other heads become undefined functions. An
+InvalidNotationError is returned for malformed input.
+*/
+func ParseMathJSON(data []byte) (Expr, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, mathJSONError("%v", err)
+	}
+	if decoder.More() {
+		return nil, mathJSONError("more than one expression")
+	}
+	return fromMathJSON(value)
+}
+
+func mathJSONError(format string, args ...any) error {
+	return &InvalidNotationError{Format: "MathJSON", Msg: fmt.Sprintf(format, args...)}
+}
+
+func fromMathJSON(value any) (Expr, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return mathJSONNumber(v.String())
+	case string:
+		return mathJSONSymbol(v)
+	case []any:
+		return mathJSONApplication(v)
+	case map[string]any:
+		if num, ok := v["num"].(string); ok {
+			return mathJSONNumber(num)
+		}
+		if sym, ok := v["sym"].(string); ok {
+			return mathJSONSymbol(sym)
+		}
+		if fn, ok := v["fn"].([]any); ok {
+			return mathJSONApplication(fn)
+		}
+		return nil, mathJSONError("object without num, sym or fn")
+	}
+	return nil, mathJSONError("unexpected %v", value)
+}
+
+func mathJSONNumber(s string) (Expr, error) {
+	if s == mathJSONUndefined {
+		return Undefined(), nil
+	}
+	value, ok := parseNumberText(s)
+	if !ok {
+		return nil, mathJSONError("invalid number %q", s)
+	}
+	return value, nil
+}
+
+func mathJSONSymbol(s string) (Expr, error) {
+	switch s {
+	case mathJSONPi:
+		return PI, nil
+	case mathJSONE:
+		return E, nil
+	case mathJSONI:
+		return I, nil
+	case mathJSONUndefined:
+		return Undefined(), nil
+	case "":
+		return nil, mathJSONError("empty symbol")
+	}
+	if s[0] == '\'' {
+		return nil, mathJSONError("unexpected string %s", s)
+	}
+	return Var(VarName(s)), nil
+}
+
+func mathJSONApplication(fn []any) (Expr, error) {
+	if len(fn) == 0 {
+		return nil, mathJSONError("empty function application")
+	}
+	head, ok := fn[0].(string)
+	if sym, isObject := fn[0].(map[string]any); isObject {
+		head, ok = sym["sym"].(string)
+	}
+	if !ok {
+		return nil, mathJSONError("function head %v is not a symbol", fn[0])
+	}
+	if head == "Integrate" || head == "Sum" || head == "Product" {
+		return mathJSONBigOperator(head, fn[1:])
+	}
+	args := make([]Expr, len(fn)-1)
+	for ix, arg := range fn[1:] {
+		var err error
+		if args[ix], err = fromMathJSON(arg); err != nil {
+			return nil, err
+		}
+	}
+	arity := func(n int) error {
+		if len(args) != n {
+			return mathJSONError("%s takes %d arguments but got %d", head, n, len(args))
+		}
+		return nil
+	}
+	unary := map[string]func(Expr) Expr{
+		"Negate": func(x Expr) Expr { return Neg(x) },
+		"Exp":    func(x Expr) Expr { return Exp(x) },
+		"Ln":     func(x Expr) Expr { return Log(x) },
+		"Sqrt":   func(x Expr) Expr { return Sqrt(x) },
+		"Sin":    func(x Expr) Expr { return Sin(x) },
+		"Cos":    func(x Expr) Expr { return Cos(x) },
+		"Tan":    func(x Expr) Expr { return Div(Sin(x), Cos(x)) },
+		"Square": func(x Expr) Expr { return Pow(x, Int(2)) },
+	}
+
+	switch head {
+	case "Add", "Multiply":
+		if len(args) == 0 {
+			return nil, mathJSONError("%s without arguments", head)
+		}
+		if len(args) == 1 {
+			return args[0], nil
+		}
+		if head == "Add" {
+			return Add(args...), nil
+		}
+		return Mul(args...), nil
+	case "Subtract", "Divide", "Power", "Root":
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		switch head {
+		case "Subtract":
+			return Sub(args[0], args[1]), nil
+		case "Divide":
+			return Div(args[0], args[1]), nil
+		case "Power":
+			return Pow(args[0], args[1]), nil
+		}
+		return Pow(args[0], Div(Int(1), args[1])), nil
+	case "Rational":
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		p, pOk := args[0].(integer)
+		q, qOk := args[1].(integer)
+		if !pOk || !qOk || q.value == 0 {
+			return nil, mathJSONError("invalid rational %v/%v", args[0], args[1])
+		}
+		return ratDiv(p, q), nil
+	case "Log":
+		// The logarithm is decimal unless a base is given
+		if len(args) == 1 {
+			args = append(args, Int(10))
+		}
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		return Div(Log(args[0]), Log(args[1])), nil
+	case "D":
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		v, ok := args[1].(variable)
+		if !ok {
+			return nil, mathJSONError("D w.r.t. %v, which is not a symbol", args[1])
+		}
+		return Derivative(args[0], v), nil
+	}
+	if f, ok := unary[head]; ok {
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		return f(args[0]), nil
+	}
+	for name, jsonName := range mathJSONFunctionNames {
+		if jsonName == head {
+			return Function(name, args...), nil
+		}
+	}
+	return Function(VarName(head), args...), nil
+}
+
+/*
+Parses ["Integrate", f, x], ["Integrate", f, ["Limits", x, a, b]] and
+the same forms of Sum and Product, where Tuple and Triple may be used
+instead of Limits.
+*/
+func mathJSONBigOperator(head string, args []any) (Expr, error) {
+	if len(args) != 2 {
+		return nil, mathJSONError("%s takes 2 arguments but got %d", head, len(args))
+	}
+	body, err := fromMathJSON(args[0])
+	if err != nil {
+		return nil, err
+	}
+	limits := []any{args[1]}
+	if fn, ok := args[1].([]any); ok && len(fn) > 0 {
+		switch fn[0] {
+		case "Limits", "Tuple", "Triple":
+			limits = fn[1:]
+		}
+	}
+	result := []Expr{body}
+	for _, limit := range limits {
+		expr, err := fromMathJSON(limit)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, expr)
+	}
+	if _, ok := result[1].(variable); !ok {
+		return nil, mathJSONError("%s over %v, which is not a symbol", head, result[1])
+	}
+	if len(result) != 2 && len(result) != 4 {
+		return nil, mathJSONError("%s needs a variable and optionally lower and upper bounds", head)
+	}
+	return Function(VarName(head), result...), nil
+}
@@ -0,0 +1,151 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMarshalMathJSON(t *testing.T) {
+	x, y := Var("x"), Var("y")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput string
+	}{
+		{
+			name:           "Example from the documentation",
+			input:          Add(Mul(Int(3), Pow(x, Int(2))), Sin(y)),
+			expectedOutput: `["Add",["Multiply",3,["Power","x",2]],["Sin","y"]]`,
+		},
+		{name: "Fraction", input: Div(Int(-3), Int(4)), expectedOutput: `["Rational",-3,4]`},
+		{name: "Large integer", input: Int(1 << 60), expectedOutput: `{"num":"1152921504606846976"}`},
+		{name: "Constants", input: Mul(I, PI, E), expectedOutput: `["Multiply","ImaginaryUnit","Pi","ExponentialE"]`},
+		{name: "Undefined", input: Undefined(), expectedOutput: `"NaN"`},
+		{name: "Elementary functions", input: Log(Sqrt(Exp(x))), expectedOutput: `["Ln",["Sqrt",["Exp","x"]]]`},
+		{name: "Renamed function", input: Function(reName, x), expectedOutput: `["Real","x"]`},
+		{name: "Derivative", input: Derivative(Function("f", x), x), expectedOutput: `["D",["f","x"],"x"]`},
+		{
+			name:           "Definite integral",
+			input:          Function(integrateName, Cos(x), x, Int(0), PI),
+			expectedOutput: `["Integrate",["Cos","x"],["Limits","x",0,"Pi"]]`,
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := MarshalMathJSON(test.input)
+			if err != nil || string(result) != test.expectedOutput {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %s (error %v)", test.name, test.input, test.expectedOutput, result, err)
+			}
+		})
+	}
+}
+
+func TestParseMathJSON(t *testing.T) {
+	x, y, k, n := Var("x"), Var("y"), Var("k"), Var("n")
+
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput Expr
+	}{
+		{name: "Subtract", input: `["Subtract", "x", "y"]`, expectedOutput: Sub(x, y)},
+		{name: "Negate", input: `["Negate", "x"]`, expectedOutput: Neg(x)},
+		{name: "Divide", input: `["Divide", "x", 2]`, expectedOutput: Div(x, Int(2))},
+		{name: "Square", input: `["Square", "x"]`, expectedOutput: Pow(x, Int(2))},
+		{name: "Root", input: `["Root", "x", 3]`, expectedOutput: Pow(x, Div(Int(1), Int(3)))},
+		{name: "Decimal logarithm", input: `["Log", "x"]`, expectedOutput: Div(Log(x), Log(Int(10)))},
+		{name: "Logarithm with a base", input: `["Log", "x", 2]`, expectedOutput: Div(Log(x), Log(Int(2)))},
+		{name: "Tangent", input: `["Tan", "x"]`, expectedOutput: Div(Sin(x), Cos(x))},
+		{name: "Decimal number", input: `0.25`, expectedOutput: Div(Int(1), Int(4))},
+		{name: "Number with an exponent", input: `1.5e2`, expectedOutput: Int(150)},
+		{name: "Rational", input: `["Rational", 6, -4]`, expectedOutput: Div(Int(-3), Int(2))},
+		{
+			name:           "Object forms",
+			input:          `{"fn": [{"sym": "Add"}, {"num": "-12"}, {"sym": "x"}]}`,
+			expectedOutput: Add(Int(-12), x),
+		},
+		{name: "Undefined function", input: `["f", "x", "y"]`, expectedOutput: Function("f", x, y)},
+		{
+			name:           "Sum with a tuple",
+			input:          `["Sum", ["Power", "k", -1], ["Tuple", "k", 1, "n"]]`,
+			expectedOutput: Function(sumName, Pow(k, Int(-1)), k, Int(1), n),
+		},
+		{name: "Indefinite integral", input: `["Integrate", ["Sin", "x"], "x"]`, expectedOutput: Function(integrateName, Sin(x), x)},
+		{name: "Sum without bounds", input: `["Sum", "k", "k"]`, expectedOutput: Function(sumName, k, k)},
+		{name: "Product without bounds", input: `["Product", ["Multiply", 2, "k"], "k"]`, expectedOutput: Function(productName, Mul(Int(2), k), k)},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := ParseMathJSON([]byte(test.input))
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nInput: %v\nUnexpected error: %v", test.name, test.input, err)
+			}
+			if !Equal(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestParseMathJSONErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Malformed JSON", input: `["Add", "x"`},
+		{name: "Two expressions", input: `1 2`},
+		{name: "String literal", input: `"'hello'"`},
+		{name: "Empty application", input: `[]`},
+		{name: "Numeric head", input: `[1, 2]`},
+		{name: "Wrong number of arguments", input: `["Power", "x"]`},
+		{name: "Rational with a symbol", input: `["Rational", "x", 2]`},
+		{name: "Derivative w.r.t. a number", input: `["D", "x", 2]`},
+		{name: "Unknown object", input: `{"str": "x"}`},
+		{name: "Sum with a single bound", input: `["Sum", "k", ["Limits", "k", 1]]`},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			_, err := ParseMathJSON([]byte(test.input))
+			var notationErr *InvalidNotationError
+			if !errors.As(err, &notationErr) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: InvalidNotationError\nGot: %v", test.name, test.input, err)
+			}
+		})
+	}
+}
+
+func TestMathJSONRoundTrip(t *testing.T) {
+	x, y, k, n := Var("x"), Var("y"), Var("k"), Var("n")
+
+	tests := []Expr{
+		Add(Mul(Div(Int(3), Int(2)), Pow(x, Int(2))), Neg(Exp(Neg(x))), Sqrt(y)),
+		Mul(Div(Int(-3), Int(7)), Pow(x, Div(Int(-1), Int(2)))),
+		Log(Cos(Mul(Int(2), Sin(x)))),
+		Mul(I, PI, E),
+		Int(-1 << 62),
+		Function("f", x, Add(y, Int(1)), Function("g")),
+		Function(imName, Function(argName, x)),
+		Derivative(Function("f", x), x),
+		Function(productName, k, k, Int(1), n),
+		Function(sumName, Pow(k, Int(2)), k),
+		Undefined(),
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			data, err := MarshalMathJSON(test)
+			if err != nil {
+				t.Fatalf("Following test failed: round trip\nInput: %v\nUnexpected error: %v", test, err)
+			}
+			result, err := ParseMathJSON(data)
+			if err != nil || !Equal(result, test) {
+				t.Errorf("Following test failed: round trip\nInput: %s\nExpected: %v\nGot: %v (error %v)", data, test, result, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,618 @@
+package gosymbol
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const mathMLNamespace = "http://www.w3.org/1998/Math/MathML"
+
+/*
+Returns expr in Presentation MathML, i.e. laid out for display like
+Latex does, as a <math> element.
+*/
+func MathML(expr Expr) string {
+	return `<math xmlns="` + mathMLNamespace + `">` + presentationMathML(expr) + "</math>"
+}
+
+/*
+Returns expr in Content MathML, which describes its meaning rather
+than its layout, as a <math> element. It can be read back with
+ParseContentMathML.
+*/
+func ContentMathML(expr Expr) string {
+	return `<math xmlns="` + mathMLNamespace + `">` + contentMathML(expr) + "</math>"
+}
+
+/* Presentation MathML */
+
+func presentationMathML(expr Expr) string {
+	str, _ := mathMLPrinter{}.print(expr)
+	return str
+}
+
+func mathMLEscape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+func mrow(parts ...string) string {
+	return "<mrow>" + strings.Join(parts, "") + "</mrow>"
+}
+
+func mo(op string) string {
+	return "<mo>" + op + "</mo>"
+}
+
+// The operators between a function name and its arguments and between factors.
+const (
+	applyFunction  = "&#x2061;"
+	invisibleTimes = "&#x2062;"
+	middleDot      = "&#xB7;"
+)
+
+type mathMLPrinter struct{}
+
+// Returns expr as Presentation MathML together with its precedence,
+// which uses the levels of the LaTeX printer.
+func (p mathMLPrinter) print(expr Expr) (string, int) {
+	switch e := expr.(type) {
+	case undefined:
+		return "<mi>Undefined</mi>", latexAtom
+	case integer:
+		if e.value < 0 {
+			return p.product([]Expr{e})
+		}
+		return "<mn>" + e.String() + "</mn>", latexAtom
+	case fraction:
+		return p.product([]Expr{e})
+	case variable:
+		return mathMLName(e.Name), latexAtom
+	case constrainedVariable:
+		return mathMLName(e.Name), latexAtom
+	case add:
+		parts := []string{p.string(e.Operands[0])}
+		for _, term := range e.Operands[1:] {
+			if negated, ok := negatedTerm(term); ok {
+				parts = append(parts, mo("-"), p.wrap(negated, latexProduct))
+			} else {
+				parts = append(parts, mo("+"), p.string(term))
+			}
+		}
+		return mrow(parts...), latexSum
+	case mul:
+		return p.product(e.Operands)
+	case pow:
+		return p.power(e)
+	case exp:
+		if Equal(e.Arg, Int(1)) {
+			return "<mi>e</mi>", latexAtom
+		}
+		return "<msup><mi>e</mi>" + p.string(e.Arg) + "</msup>", latexPower
+	case log:
+		return p.call("ln", e.Arg), latexAtom
+	case sqrt:
+		return "<msqrt>" + p.string(e.Arg) + "</msqrt>", latexAtom
+	case sin:
+		return p.call("sin", e.Arg), latexAtom
+	case cos:
+		return p.call("cos", e.Arg), latexAtom
+	case FunctionApplication:
+		switch {
+		case e.Name == absName && len(e.Args) == 1:
+			return mrow(mo("|"), p.string(e.Args[0]), mo("|")), latexAtom
+		case e.Name == factorialName && len(e.Args) == 1:
+			return mrow(p.wrap(e.Args[0], latexAtom), mo("!")), latexPower
+		}
+		return p.call(string(e.Name), e.Args...), latexAtom
+	case derivative:
+		d := "<mfrac><mi>d</mi>" + mrow("<mi>d</mi>", mathMLName(e.Var.Name)) + "</mfrac>"
+		return mrow(d, p.wrap(e.Arg, latexPower)), latexProduct
+	}
+	return "<mi>" + mathMLEscape(expr.String()) + "</mi>", latexAtom
+}
+
+func (p mathMLPrinter) string(expr Expr) string {
+	str, _ := p.print(expr)
+	return str
+}
+
+// Prints expr and parenthesizes it if it binds weaker than precedence.
+func (p mathMLPrinter) wrap(expr Expr, precedence int) string {
+	str, prec := p.print(expr)
+	if prec < precedence {
+		return mrow(mo("("), str, mo(")"))
+	}
+	return str
+}
+
+func (p mathMLPrinter) call(name string, args ...Expr) string {
+	parts := []string{mo("(")}
+	for ix, arg := range args {
+		if ix > 0 {
+			parts = append(parts, mo(","))
+		}
+		parts = append(parts, p.string(arg))
+	}
+	parts = append(parts, mo(")"))
+	return mrow("<mi>"+mathMLEscape(name)+"</mi>", mo(applyFunction), mrow(parts...))
+}
+
+/*
+Prints a product as a fraction of the factors with positive and
+negative exponents, with a leading negative coefficient as a minus.
+*/
+func (p mathMLPrinter) product(factors []Expr) (string, int) {
+	negative := false
+	numerator, denominator := []string{}, []string{}
+	numeratorFactors, denominatorFactors := []Expr{}, []Expr{}
+	if r, ok := factors[0].(rational); ok && !Equal(r, Undefined()) {
+		factors = factors[1:]
+		num, den := r.numerator(), r.denominator()
+		negative = num.value < 0 != (den.value < 0)
+		num, den = intAbs(num), intAbs(den)
+		if num.value != 1 || len(factors) == 0 {
+			numerator = append(numerator, "<mn>"+num.String()+"</mn>")
+		}
+		if den.value != 1 {
+			denominator = append(denominator, "<mn>"+den.String()+"</mn>")
+		}
+	}
+	for _, factor := range factors {
+		if e, ok := factor.(pow); ok && !Equal(e, I) {
+			if r, ok := e.Exponent.(rational); ok && r.approx() < 0 {
+				if Equal(r, Int(-1)) {
+					denominatorFactors = append(denominatorFactors, e.Base)
+				} else {
+					denominatorFactors = append(denominatorFactors, pow{Base: e.Base, Exponent: ratMinus(r)})
+				}
+				continue
+			}
+		}
+		numeratorFactors = append(numeratorFactors, factor)
+	}
+
+	if !negative && len(numerator) == 0 && len(denominator) == 0 && len(numeratorFactors) == 1 && len(denominatorFactors) == 0 {
+		// A single factor keeps its own precedence, e.g. x^2
+		return p.print(numeratorFactors[0])
+	}
+	var str string
+	prec := latexProduct
+	if len(denominator) == 0 && len(denominatorFactors) == 0 {
+		str = p.factors(numerator, numeratorFactors)
+		if len(numeratorFactors) == 0 {
+			prec = latexAtom
+		}
+	} else {
+		str = "<mfrac>" + p.factors(numerator, numeratorFactors) + p.factors(denominator, denominatorFactors) + "</mfrac>"
+	}
+	if negative {
+		return mrow(mo("-"), str), latexSum
+	}
+	return str, prec
+}
+
+/*
+Joins a coefficient, if any, and factors with invisible times, or a
+middle dot before a number.
+*/
+func (p mathMLPrinter) factors(coefficient []string, factors []Expr) string {
+	if len(coefficient) == 0 && len(factors) == 1 {
+		return p.string(factors[0])
+	}
+	parts := append([]string{}, coefficient...)
+	for _, factor := range factors {
+		str := p.wrap(factor, latexProduct)
+		if len(parts) > 0 {
+			if strings.HasPrefix(str, "<mn>") {
+				parts = append(parts, mo(middleDot))
+			} else {
+				parts = append(parts, mo(invisibleTimes))
+			}
+		}
+		parts = append(parts, str)
+	}
+	switch len(parts) {
+	case 0:
+		return "<mn>1</mn>"
+	case 1:
+		return parts[0]
+	}
+	return mrow(parts...)
+}
+
+func (p mathMLPrinter) power(e pow) (string, int) {
+	if Equal(e, I) {
+		return "<mi>i</mi>", latexAtom
+	}
+	r, isRational := e.Exponent.(rational)
+	if isRational && r.approx() < 0 {
+		return p.product([]Expr{e})
+	}
+	if isRational && r.numerator() == Int(1) && r.denominator().value > 1 {
+		if r.denominator() == Int(2) {
+			return "<msqrt>" + p.string(e.Base) + "</msqrt>", latexAtom
+		}
+		return "<mroot>" + p.string(e.Base) + "<mn>" + r.denominator().String() + "</mn></mroot>", latexAtom
+	}
+	return "<msup>" + p.wrap(e.Base, latexAtom) + p.string(e.Exponent) + "</msup>", latexPower
+}
+
+// Returns a variable name as an identifier, with the part after an underscore as a subscript.
+func mathMLName(name VarName) string {
+	base, subscript, hasSubscript := strings.Cut(string(name), "_")
+	str := "<mi>" + mathMLEscape(base) + "</mi>"
+	if hasSubscript {
+		subscript = strings.TrimSuffix(strings.TrimPrefix(subscript, "{"), "}")
+		tag := "mi"
+		if isAllDigits(subscript) {
+			tag = "mn"
+		}
+		str = "<msub>" + str + "<" + tag + ">" + mathMLEscape(subscript) + "</" + tag + "></msub>"
+	}
+	return str
+}
+
+/* Content MathML */
+
+// The Content MathML elements of the undefined functions that have one.
+var contentMathMLFunctions = map[VarName]string{
+	absName:       "abs",
+	factorialName: "factorial",
+	reName:        "real",
+	imName:        "imaginary",
+	conjugateName: "conjugate",
+	argName:       "arg",
+}
+
+func contentMathML(expr Expr) string {
+	switch e := expr.(type) {
+	case undefined:
+		return "<notanumber/>"
+	case integer:
+		return `<cn type="integer">` + e.String() + "</cn>"
+	case fraction:
+		return `<cn type="rational">` + e.numerator().String() + "<sep/>" + e.denominator().String() + "</cn>"
+	case variable:
+		if e.Name == PI.Name {
+			return "<pi/>"
+		}
+		return "<ci>" + mathMLEscape(string(e.Name)) + "</ci>"
+	case constrainedVariable:
+		return "<ci>" + mathMLEscape(string(e.Name)) + "</ci>"
+	case add:
+		return contentApply("<plus/>", e.Operands...)
+	case mul:
+		return contentApply("<times/>", e.Operands...)
+	case pow:
+		if Equal(e, I) {
+			return "<imaginaryi/>"
+		}
+		return contentApply("<power/>", e.Base, e.Exponent)
+	case exp:
+		if Equal(e.Arg, Int(1)) {
+			return "<exponentiale/>"
+		}
+		return contentApply("<exp/>", e.Arg)
+	case log:
+		return contentApply("<ln/>", e.Arg)
+	case sqrt:
+		return contentApply("<root/>", e.Arg)
+	case sin:
+		return contentApply("<sin/>", e.Arg)
+	case cos:
+		return contentApply("<cos/>", e.Arg)
+	case FunctionApplication:
+		if element, ok := contentMathMLFunctions[e.Name]; ok && len(e.Args) == 1 {
+			return contentApply("<"+element+"/>", e.Args...)
+		}
+		return contentApply("<ci>"+mathMLEscape(string(e.Name))+"</ci>", e.Args...)
+	case derivative:
+		return "<apply><diff/><bvar>" + contentMathML(e.Var) + "</bvar>" + contentMathML(e.Arg) + "</apply>"
+	}
+	return "<ci>" + mathMLEscape(expr.String()) + "</ci>"
+}
+
+func contentApply(operator string, args ...Expr) string {
+	str := "<apply>" + operator
+	for _, arg := range args {
+		str += contentMathML(arg)
+	}
+	return str + "</apply>"
+}
+
+/*
+Parses Content MathML into an expression. Numbers (<cn> of type
+integer, real, rational and e-notation), identifiers, the constants
+<pi/>, <exponentiale/>, <imaginaryi/> and <notanumber/>, and the
+application of
+
+	plus, minus, times, divide, power, root, exp, ln, log, sin, cos,
+	tan, abs, factorial, real, imaginary, conjugate, arg and diff
+
+are understood, as well as <ci> and <csymbol> heads, which become
+undefined functions. The document may be a <math> element or the
+expression itself. An InvalidNotationError is returned for other
+elements.
+*/
+func ParseContentMathML(input string) (Expr, error) {
+	root, err := parseXMLTree(input)
+	if err != nil {
+		return nil, err
+	}
+	return fromContentMathML(root)
+}
+
+// An element of an XML document, with the text between its children.
+type xmlElement struct {
+	name     string
+	attrs    map[string]string
+	children []*xmlElement
+	texts    []string // texts[i] is the text before children[i]
+}
+
+func (e *xmlElement) text() string {
+	return strings.TrimSpace(strings.Join(e.texts, ""))
+}
+
+func parseXMLTree(input string) (*xmlElement, error) {
+	decoder := xml.NewDecoder(strings.NewReader(input))
+	var root *xmlElement
+	stack := []*xmlElement{}
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, &InvalidNotationError{Format: "Content MathML", Msg: err.Error()}
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			element := &xmlElement{name: t.Name.Local, attrs: map[string]string{}, texts: []string{""}}
+			for _, attr := range t.Attr {
+				element.attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, &InvalidNotationError{Format: "Content MathML", Msg: "more than one root element"}
+				}
+				root = element
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, element)
+				parent.texts = append(parent.texts, "")
+			}
+			stack = append(stack, element)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				current := stack[len(stack)-1]
+				current.texts[len(current.texts)-1] += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, &InvalidNotationError{Format: "Content MathML", Msg: "no element"}
+	}
+	return root, nil
+}
+
+func contentMathMLError(format string, args ...any) error {
+	return &InvalidNotationError{Format: "Content MathML", Msg: fmt.Sprintf(format, args...)}
+}
+
+func fromContentMathML(e *xmlElement) (Expr, error) {
+	switch e.name {
+	case "math", "semantics":
+		if len(e.children) == 0 {
+			return nil, contentMathMLError("empty <%s>", e.name)
+		}
+		return fromContentMathML(e.children[0])
+	case "cn":
+		return contentNumber(e)
+	case "ci":
+		if e.text() == "" {
+			return nil, contentMathMLError("empty <ci>")
+		}
+		return Var(VarName(e.text())), nil
+	case "pi":
+		return PI, nil
+	case "exponentiale":
+		return E, nil
+	case "imaginaryi":
+		return I, nil
+	case "notanumber":
+		return Undefined(), nil
+	case "apply":
+		return contentApplication(e)
+	}
+	return nil, contentMathMLError("unsupported element <%s>", e.name)
+}
+
+func contentNumber(e *xmlElement) (Expr, error) {
+	parts := make([]rational, len(e.texts))
+	for ix, text := range e.texts {
+		value, ok := parseNumberText(strings.TrimSpace(text))
+		if !ok {
+			return nil, contentMathMLError("invalid number %q", strings.TrimSpace(text))
+		}
+		parts[ix] = value
+	}
+	switch kind := e.attrs["type"]; {
+	case (kind == "" || kind == "integer" || kind == "real") && len(parts) == 1:
+		return parts[0], nil
+	case kind == "rational" && len(parts) == 2:
+		if Equal(parts[1], Int(0)) {
+			return nil, contentMathMLError("zero denominator")
+		}
+		return ratDiv(parts[0], parts[1]), nil
+	case kind == "e-notation" && len(parts) == 2:
+		exponent, ok := parts[1].(integer)
+		if !ok {
+			return nil, contentMathMLError("non-integer exponent %v", parts[1])
+		}
+		return Mul(parts[0], Pow(Int(10), exponent)).Simplify(), nil
+	}
+	return nil, contentMathMLError("unsupported number %q of type %q", e.text(), e.attrs["type"])
+}
+
+/*
+Returns the value of a decimal number such as -12, 2.5 or 1.5e-3, or
+false if s is not a number or it does not fit in an int64.
+*/
+func parseNumberText(s string) (rational, bool) {
+	negative := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+	if digits == "" || !(isDigit(digits[0]) || digits[0] == '.') {
+		return nil, false
+	}
+	tok, err := scanNumber(digits, 0)
+	if err != nil || tok.text != digits {
+		return nil, false
+	}
+	if negative {
+		return ratMinus(tok.value), true
+	}
+	return tok.value, true
+}
+
+func contentApplication(e *xmlElement) (Expr, error) {
+	if len(e.children) == 0 {
+		return nil, contentMathMLError("empty <apply>")
+	}
+	operator := e.children[0]
+	var bvar, degree, logbase *xmlElement
+	args := []Expr{}
+	for _, child := range e.children[1:] {
+		switch child.name {
+		case "bvar":
+			bvar = child
+		case "degree":
+			degree = child
+		case "logbase":
+			logbase = child
+		default:
+			arg, err := fromContentMathML(child)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+	}
+	// The value of a qualifier such as <degree><cn>3</cn></degree>
+	qualifier := func(q *xmlElement) (Expr, error) {
+		if len(q.children) != 1 {
+			return nil, contentMathMLError("<%s> must have one child", q.name)
+		}
+		return fromContentMathML(q.children[0])
+	}
+	arity := func(n int) error {
+		if len(args) != n {
+			return contentMathMLError("<%s/> takes %d arguments but got %d", operator.name, n, len(args))
+		}
+		return nil
+	}
+
+	switch operator.name {
+	case "plus", "times":
+		if len(args) == 0 {
+			return nil, contentMathMLError("<%s/> without arguments", operator.name)
+		}
+		if len(args) == 1 {
+			return args[0], nil
+		}
+		if operator.name == "plus" {
+			return Add(args...), nil
+		}
+		return Mul(args...), nil
+	case "minus":
+		switch len(args) {
+		case 1:
+			return Neg(args[0]), nil
+		case 2:
+			return Sub(args[0], args[1]), nil
+		}
+		return nil, contentMathMLError("<minus/> takes 1 or 2 arguments but got %d", len(args))
+	case "divide":
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		return Div(args[0], args[1]), nil
+	case "power":
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		return Pow(args[0], args[1]), nil
+	case "root":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		if degree == nil {
+			return Sqrt(args[0]), nil
+		}
+		n, err := qualifier(degree)
+		if err != nil {
+			return nil, err
+		}
+		return Pow(args[0], Div(Int(1), n)), nil
+	case "log":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		// The logarithm is decimal unless a base is given
+		base := Expr(Int(10))
+		if logbase != nil {
+			var err error
+			if base, err = qualifier(logbase); err != nil {
+				return nil, err
+			}
+		}
+		return Div(Log(args[0]), Log(base)), nil
+	case "exp", "ln", "sin", "cos", "tan":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		// The constructors are those of Parse, where log is the natural logarithm
+		name := operator.name
+		if name == "ln" {
+			name = "log"
+		}
+		return parserFunctions[name](args[0]), nil
+	case "diff":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		if bvar == nil {
+			return nil, contentMathMLError("<diff/> without <bvar>")
+		}
+		v, err := qualifier(bvar)
+		if err != nil {
+			return nil, err
+		}
+		x, ok := v.(variable)
+		if !ok {
+			return nil, contentMathMLError("<bvar> must be an identifier")
+		}
+		return Derivative(args[0], x), nil
+	case "ci", "csymbol":
+		if operator.text() == "" {
+			return nil, contentMathMLError("empty <%s>", operator.name)
+		}
+		return Function(VarName(operator.text()), args...), nil
+	}
+	for name, element := range contentMathMLFunctions {
+		if element == operator.name {
+			if err := arity(1); err != nil {
+				return nil, err
+			}
+			return Function(name, args...), nil
+		}
+	}
+	return nil, contentMathMLError("unsupported operator <%s/>", operator.name)
+}
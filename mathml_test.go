@@ -0,0 +1,180 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMathML(t *testing.T) {
+	x, y := Var("x"), Var("y")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput string
+	}{
+		{
+			name:           "Difference",
+			input:          Sub(x, Mul(Int(2), y)),
+			expectedOutput: "<mrow><mi>x</mi><mo>-</mo><mrow><mn>2</mn><mo>&#x2062;</mo><mi>y</mi></mrow></mrow>",
+		},
+		{
+			name:           "Quotient",
+			input:          Div(Add(x, Int(1)), y),
+			expectedOutput: "<mfrac><mrow><mi>x</mi><mo>+</mo><mn>1</mn></mrow><mi>y</mi></mfrac>",
+		},
+		{
+			name:           "Negative fraction",
+			input:          Div(Int(-3), Int(4)),
+			expectedOutput: "<mrow><mo>-</mo><mfrac><mn>3</mn><mn>4</mn></mfrac></mrow>",
+		},
+		{name: "Power", input: Pow(x, Int(2)), expectedOutput: "<msup><mi>x</mi><mn>2</mn></msup>"},
+		{name: "Square root", input: Sqrt(x), expectedOutput: "<msqrt><mi>x</mi></msqrt>"},
+		{name: "Cube root", input: Pow(x, Div(Int(1), Int(3))), expectedOutput: "<mroot><mi>x</mi><mn>3</mn></mroot>"},
+		{name: "Exponential", input: Exp(Neg(x)), expectedOutput: "<msup><mi>e</mi><mrow><mo>-</mo><mi>x</mi></mrow></msup>"},
+		{
+			name:           "Logarithm",
+			input:          Log(x),
+			expectedOutput: "<mrow><mi>ln</mi><mo>&#x2061;</mo><mrow><mo>(</mo><mi>x</mi><mo>)</mo></mrow></mrow>",
+		},
+		{name: "Imaginary unit", input: Mul(Int(2), I), expectedOutput: "<mrow><mn>2</mn><mo>&#x2062;</mo><mi>i</mi></mrow>"},
+		{name: "Subscript", input: Var("x_1"), expectedOutput: "<msub><mi>x</mi><mn>1</mn></msub>"},
+		{name: "Absolute value", input: Abs(x), expectedOutput: "<mrow><mo>|</mo><mi>x</mi><mo>|</mo></mrow>"},
+		{name: "Escaping", input: Var("a<b"), expectedOutput: "<mi>a&lt;b</mi>"},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := MathML(test.input)
+			if expected := `<math xmlns="http://www.w3.org/1998/Math/MathML">` + test.expectedOutput + "</math>"; result != expected {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, expected, result)
+			}
+		})
+	}
+}
+
+func TestContentMathML(t *testing.T) {
+	x, y := Var("x"), Var("y")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput string
+	}{
+		{
+			name:           "Polynomial",
+			input:          Add(Mul(Int(3), Pow(x, Int(2))), Int(1)),
+			expectedOutput: `<apply><plus/><apply><times/><cn type="integer">3</cn><apply><power/><ci>x</ci><cn type="integer">2</cn></apply></apply><cn type="integer">1</cn></apply>`,
+		},
+		{name: "Fraction", input: Div(Int(-3), Int(4)), expectedOutput: `<cn type="rational">-3<sep/>4</cn>`},
+		{name: "Constants", input: Mul(I, PI, E), expectedOutput: "<apply><times/><imaginaryi/><pi/><exponentiale/></apply>"},
+		{name: "Undefined function", input: Function("f", x, y), expectedOutput: "<apply><ci>f</ci><ci>x</ci><ci>y</ci></apply>"},
+		{name: "Derivative", input: Derivative(Sin(x), x), expectedOutput: "<apply><diff/><bvar><ci>x</ci></bvar><apply><sin/><ci>x</ci></apply></apply>"},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := ContentMathML(test.input)
+			if expected := `<math xmlns="http://www.w3.org/1998/Math/MathML">` + test.expectedOutput + "</math>"; result != expected {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, expected, result)
+			}
+		})
+	}
+}
+
+func TestParseContentMathML(t *testing.T) {
+	x, y := Var("x"), Var("y")
+
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput Expr
+	}{
+		{name: "Minus", input: "<apply><minus/><ci>x</ci><ci>y</ci></apply>", expectedOutput: Sub(x, y)},
+		{name: "Unary minus", input: "<apply><minus/><ci>x</ci></apply>", expectedOutput: Neg(x)},
+		{name: "Divide", input: "<apply><divide/><ci>x</ci><cn>2</cn></apply>", expectedOutput: Div(x, Int(2))},
+		{
+			name:           "Logarithm with a base",
+			input:          "<apply><log/><logbase><cn>2</cn></logbase><ci>x</ci></apply>",
+			expectedOutput: Div(Log(x), Log(Int(2))),
+		},
+		{name: "Decimal logarithm", input: "<apply><log/><ci>x</ci></apply>", expectedOutput: Div(Log(x), Log(Int(10)))},
+		{
+			name:           "Root with a degree",
+			input:          "<apply><root/><degree><cn>3</cn></degree><ci>x</ci></apply>",
+			expectedOutput: Pow(x, Div(Int(1), Int(3))),
+		},
+		{name: "Real number", input: "<cn>0.25</cn>", expectedOutput: Div(Int(1), Int(4))},
+		{name: "E-notation", input: `<cn type="e-notation">15<sep/>-1</cn>`, expectedOutput: Div(Int(3), Int(2))},
+		{name: "Rational", input: `<cn type="rational">6<sep/>-4</cn>`, expectedOutput: Div(Int(-3), Int(2))},
+		{name: "Tangent", input: "<apply><tan/><ci>x</ci></apply>", expectedOutput: Div(Sin(x), Cos(x))},
+		{name: "Csymbol head", input: "<apply><csymbol>f</csymbol><ci>x</ci></apply>", expectedOutput: Function("f", x)},
+		{
+			name:           "White space and semantics",
+			input:          "<math>\n  <semantics>\n    <apply> <abs/> <ci> x </ci> </apply>\n  </semantics>\n</math>",
+			expectedOutput: Abs(x),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := ParseContentMathML(test.input)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nInput: %v\nUnexpected error: %v", test.name, test.input, err)
+			}
+			if !Equal(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestParseContentMathMLErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Malformed XML", input: "<apply><plus/><ci>x</ci>"},
+		{name: "Presentation markup", input: "<mi>x</mi>"},
+		{name: "Invalid number", input: "<cn>1.2.3</cn>"},
+		{name: "Wrong number of arguments", input: "<apply><sin/><ci>x</ci><ci>y</ci></apply>"},
+		{name: "Empty application", input: "<apply/>"},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			_, err := ParseContentMathML(test.input)
+			var notationErr *InvalidNotationError
+			if !errors.As(err, &notationErr) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: InvalidNotationError\nGot: %v", test.name, test.input, err)
+			}
+		})
+	}
+}
+
+func TestContentMathMLRoundTrip(t *testing.T) {
+	x, y := Var("x"), Var("y")
+
+	tests := []Expr{
+		Add(Mul(Div(Int(3), Int(2)), Pow(x, Int(2))), Neg(Exp(Neg(x))), Sqrt(y)),
+		Mul(Div(Int(-3), Int(7)), Pow(x, Div(Int(-1), Int(2)))),
+		Log(Cos(Mul(Int(2), Sin(x)))),
+		Mul(I, PI, E),
+		Function("f", x, Add(y, Int(1)), Function("g")),
+		Factorial(Abs(x)),
+		Function(conjugateName, Function(argName, x)),
+		Derivative(Function("f", x), x),
+		Undefined(),
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			str := ContentMathML(test)
+			result, err := ParseContentMathML(str)
+			if err != nil || !Equal(result, test) {
+				t.Errorf("Following test failed: round trip\nInput: %v\nExpected: %v\nGot: %v (error %v)", str, test, result, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package gosymbol
+
+import (
+	"strings"
+)
+
+/*
+A Matrix is a dense matrix with expressions as entries. Matrices are
+immutable, i.e. every operation returns a new matrix. Entries are
+indexed from zero, just like the [][]Expr given to NewMatrix.
+
+The exact algorithms (Det, Inverse, RREF, Rank and NullSpace) compute
+with the entries as rational functions of their kernels, see Cancel,
+so zero pivots are always recognised as such and the resulting entries
+are returned in cancelled form.
+*/
+type Matrix struct {
+	rows    int
+	cols    int
+	entries []Expr // Row major
+}
+
+/* Factories */
+
+// Creates a matrix from a slice of rows. All rows must
+// be of the same length.
+func NewMatrix(rows [][]Expr) (Matrix, error) {
+	m := Matrix{rows: len(rows)}
+	if len(rows) > 0 {
+		m.cols = len(rows[0])
+	}
+	m.entries = make([]Expr, 0, m.rows*m.cols)
+	for _, row := range rows {
+		if len(row) != m.cols {
+			return Matrix{}, &DimensionMismatchError{Expected: m.cols, Got: len(row)}
+		}
+		m.entries = append(m.entries, row...)
+	}
+	return m, nil
+}
+
+// Creates a column vector, i.e. a len(entries) x 1 matrix.
+func ColumnVector(entries ...Expr) Matrix {
+	return Matrix{rows: len(entries), cols: 1, entries: append([]Expr{}, entries...)}
+}
+
+func Zeros(rows, cols int) Matrix {
+	m := Matrix{rows: rows, cols: cols, entries: make([]Expr, rows*cols)}
+	for ix := range m.entries {
+		m.entries[ix] = Int(0)
+	}
+	return m
+}
+
+func Identity(n int) Matrix {
+	m := Zeros(n, n)
+	for ix := 0; ix < n; ix++ {
+		m.entries[ix*n+ix] = Int(1)
+	}
+	return m
+}
+
+/* Accessors */
+
+func (m Matrix) Rows() int { return m.rows }
+
+func (m Matrix) Cols() int { return m.cols }
+
+// Returns the entry on row i and column j.
+func (m Matrix) At(i, j int) Expr {
+	return m.entries[i*m.cols+j]
+}
+
+// Returns a copy of m with the entry on row i and column j replaced by e.
+func (m Matrix) With(i, j int, e Expr) Matrix {
+	result := m.mapEntries(func(e Expr) Expr { return e })
+	result.entries[i*m.cols+j] = e
+	return result
+}
+
+func (m Matrix) String() string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := 0; i < m.rows; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("[")
+		for j := 0; j < m.cols; j++ {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(m.At(i, j).String())
+		}
+		sb.WriteString("]")
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+/* Arithmetic */
+
+func (m Matrix) Add(n Matrix) (Matrix, error) {
+	if m.rows != n.rows || m.cols != n.cols {
+		return Matrix{}, &DimensionMismatchError{Expected: m.rows * m.cols, Got: n.rows * n.cols}
+	}
+	result := Matrix{rows: m.rows, cols: m.cols, entries: make([]Expr, len(m.entries))}
+	for ix := range m.entries {
+		result.entries[ix] = Add(m.entries[ix], n.entries[ix])
+	}
+	return result, nil
+}
+
+func (m Matrix) Sub(n Matrix) (Matrix, error) {
+	return m.Add(n.Scale(Int(-1)))
+}
+
+// Returns the matrix product m*n.
+func (m Matrix) Mul(n Matrix) (Matrix, error) {
+	if m.cols != n.rows {
+		return Matrix{}, &DimensionMismatchError{Expected: m.cols, Got: n.rows}
+	}
+	result := Matrix{rows: m.rows, cols: n.cols, entries: make([]Expr, m.rows*n.cols)}
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < n.cols; j++ {
+			terms := make([]Expr, m.cols)
+			for k := 0; k < m.cols; k++ {
+				terms[k] = Mul(m.At(i, k), n.At(k, j))
+			}
+			result.entries[i*n.cols+j] = Add(terms...)
+		}
+	}
+	return result, nil
+}
+
+// Multiplies every entry of m with c.
+func (m Matrix) Scale(c Expr) Matrix {
+	return m.mapEntries(func(e Expr) Expr { return Mul(c, e) })
+}
+
+func (m Matrix) Transpose() Matrix {
+	result := Matrix{rows: m.cols, cols: m.rows, entries: make([]Expr, len(m.entries))}
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			result.entries[j*m.rows+i] = m.At(i, j)
+		}
+	}
+	return result
+}
+
+func (m Matrix) Trace() (Expr, error) {
+	if m.rows != m.cols {
+		return nil, &NonSquareMatrixError{Rows: m.rows, Cols: m.cols}
+	}
+	diagonal := make([]Expr, m.rows)
+	for ix := range diagonal {
+		diagonal[ix] = m.At(ix, ix)
+	}
+	return Add(diagonal...).Simplify(), nil
+}
+
+/* Element-wise operations */
+
+func (m Matrix) Simplify() Matrix {
+	return m.mapEntries(func(e Expr) Expr { return e.Simplify() })
+}
+
+// Substitutes u for t in every entry of m.
+func (m Matrix) Substitute(u, t Expr) Matrix {
+	return m.mapEntries(func(e Expr) Expr { return Substitute(e, u, t) })
+}
+
+// Differentiates every entry of m w.r.t. v.
+func (m Matrix) D(v variable) Matrix {
+	return m.mapEntries(func(e Expr) Expr { return e.D(v) })
+}
+
+func (m Matrix) mapEntries(f func(Expr) Expr) Matrix {
+	result := Matrix{rows: m.rows, cols: m.cols, entries: make([]Expr, len(m.entries))}
+	for ix, e := range m.entries {
+		result.entries[ix] = f(e)
+	}
+	return result
+}
+
+/* Exact algorithms */
+
+/*
+Computes the determinant of m with the fraction free Bareiss algorithm [1].
+All divisions in the algorithm are exact, so for matrices with polynomial
+entries every intermediate result is a polynomial as well.
+
+[1] BAREISS, Erwin H. Sylvester's identity and multistep integer-preserving Gaussian elimination. Mathematics of computation, 1968.
+*/
+func (m Matrix) Det() (Expr, error) {
+	if m.rows != m.cols {
+		return nil, &NonSquareMatrixError{Rows: m.rows, Cols: m.cols}
+	}
+	kt := &kernelTable{}
+	a, ok := m.toRatFuncs(kt)
+	if !ok {
+		return Undefined(), nil
+	}
+
+	n := m.rows
+	if n == 0 {
+		return Int(1), nil
+	}
+	sign := constRatFunc(Int(1))
+	prev := constRatFunc(Int(1))
+	for k := 0; k < n-1; k++ {
+		if a[k][k].isZero() {
+			// Swaps in a row with non-zero pivot, if there
+			// is no such row the determinant is zero
+			pivot := k + 1
+			for pivot < n && a[pivot][k].isZero() {
+				pivot++
+			}
+			if pivot == n {
+				return Int(0), nil
+			}
+			a[k], a[pivot] = a[pivot], a[k]
+			sign = sign.neg()
+		}
+
+		for i := k + 1; i < n; i++ {
+			for j := k + 1; j < n; j++ {
+				numerator := a[k][k].mul(a[i][j]).sub(a[i][k].mul(a[k][j]))
+				a[i][j], _ = numerator.div(prev)
+			}
+		}
+		prev = a[k][k]
+	}
+
+	return sign.mul(a[n-1][n-1]).toExpr(kt), nil
+}
+
+// Computes the inverse of m by Gauss-Jordan elimination
+// of the augmented matrix [m | I].
+func (m Matrix) Inverse() (Matrix, error) {
+	if m.rows != m.cols {
+		return Matrix{}, &NonSquareMatrixError{Rows: m.rows, Cols: m.cols}
+	}
+	kt := &kernelTable{}
+	a, ok := m.toRatFuncs(kt)
+	if !ok {
+		return Zeros(m.rows, m.cols).mapEntries(func(Expr) Expr { return Undefined() }), nil
+	}
+
+	n := m.rows
+	for i := range a {
+		identityRow := make([]ratFunc, n)
+		for j := range identityRow {
+			identityRow[j] = constRatFunc(Int(0))
+		}
+		identityRow[i] = constRatFunc(Int(1))
+		a[i] = append(a[i], identityRow...)
+	}
+
+	pivots := rrefRatFuncs(a, n)
+	if len(pivots) < n {
+		return Matrix{}, &SingularMatrixError{}
+	}
+
+	inverse := make([][]ratFunc, n)
+	for i := range a {
+		inverse[i] = a[i][n:]
+	}
+	return matrixFromRatFuncs(inverse, n, kt), nil
+}
+
+// Returns the reduced row echelon form of m together
+// with the indexes of the pivot columns.
+func (m Matrix) RREF() (Matrix, []int) {
+	kt := &kernelTable{}
+	a, ok := m.toRatFuncs(kt)
+	if !ok {
+		return m.mapEntries(func(Expr) Expr { return Undefined() }), []int{}
+	}
+	pivots := rrefRatFuncs(a, m.cols)
+	return matrixFromRatFuncs(a, m.cols, kt), pivots
+}
+
+func (m Matrix) Rank() int {
+	_, pivots := m.RREF()
+	return len(pivots)
+}
+
+/*
+Returns a basis of the null space of m, i.e. of the solutions to m*x = 0,
+as a slice of column vectors. There is one basis vector per non-pivot
+column of the reduced row echelon form of m, with a one in the position
+of that column.
+*/
+func (m Matrix) NullSpace() []Matrix {
+	kt := &kernelTable{}
+	a, ok := m.toRatFuncs(kt)
+	if !ok {
+		return nil
+	}
+	pivots := rrefRatFuncs(a, m.cols)
+
+	isPivot := make([]bool, m.cols)
+	for _, p := range pivots {
+		isPivot[p] = true
+	}
+
+	basis := []Matrix{}
+	for free := 0; free < m.cols; free++ {
+		if isPivot[free] {
+			continue
+		}
+		v := make([]Expr, m.cols)
+		for ix := range v {
+			v[ix] = Int(0)
+		}
+		v[free] = Int(1)
+		for row, p := range pivots {
+			v[p] = a[row][free].neg().toExpr(kt)
+		}
+		basis = append(basis, ColumnVector(v...))
+	}
+	return basis
+}
+
+/*
+Reduces a to reduced row echelon form in place using Gauss-Jordan
+elimination, only choosing pivots among the first cols columns.
+Returns the indexes of the pivot columns.
+*/
+func rrefRatFuncs(a [][]ratFunc, cols int) []int {
+	pivots := []int{}
+	row := 0
+	for col := 0; col < cols && row < len(a); col++ {
+		pivot := row
+		for pivot < len(a) && a[pivot][col].isZero() {
+			pivot++
+		}
+		if pivot == len(a) {
+			continue
+		}
+		a[row], a[pivot] = a[pivot], a[row]
+
+		// Normalises the pivot row
+		inv, _ := a[row][col].inv()
+		for j := range a[row] {
+			a[row][j] = a[row][j].mul(inv)
+		}
+
+		// Eliminates the pivot column from all other rows
+		for i := range a {
+			if i == row || a[i][col].isZero() {
+				continue
+			}
+			factor := a[i][col]
+			for j := range a[i] {
+				a[i][j] = a[i][j].sub(factor.mul(a[row][j]))
+			}
+		}
+
+		pivots = append(pivots, col)
+		row++
+	}
+	return pivots
+}
+
+// Converts the entries of m into rational functions over kt. False
+// is returned if any entry is undefined or contains a division by zero.
+func (m Matrix) toRatFuncs(kt *kernelTable) ([][]ratFunc, bool) {
+	a := make([][]ratFunc, m.rows)
+	for i := range a {
+		a[i] = make([]ratFunc, m.cols)
+		for j := range a[i] {
+			r, ok := toRatFunc(m.At(i, j), kt)
+			if !ok {
+				return nil, false
+			}
+			a[i][j] = r
+		}
+	}
+	return a, true
+}
+
+// Converts the first cols columns of a into a Matrix.
+func matrixFromRatFuncs(a [][]ratFunc, cols int, kt *kernelTable) Matrix {
+	m := Matrix{rows: len(a), cols: cols, entries: make([]Expr, 0, len(a)*cols)}
+	for _, row := range a {
+		for _, r := range row[:cols] {
+			m.entries = append(m.entries, r.toExpr(kt))
+		}
+	}
+	return m
+}
@@ -0,0 +1,209 @@
+package gosymbol
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMatrixDet(t *testing.T) {
+	a, b, c, d := Var("a"), Var("b"), Var("c"), Var("d")
+	x := Var("x")
+
+	tests := []struct {
+		name           string
+		input          [][]Expr
+		expectedOutput Expr
+	}{
+		{
+			name:           "Symbolic 2x2",
+			input:          [][]Expr{{a, b}, {c, d}},
+			expectedOutput: Sub(Mul(a, d), Mul(b, c)),
+		},
+		{
+			name:           "Integer 3x3 requiring a row swap",
+			input:          [][]Expr{{Int(0), Int(2), Int(1)}, {Int(1), Int(3), Int(2)}, {Int(4), Int(1), Int(1)}},
+			expectedOutput: Int(3),
+		},
+		{
+			name:           "Singular matrix",
+			input:          [][]Expr{{Int(2), Int(0), Int(1)}, {Int(1), Int(3), Int(2)}, {Int(1), Int(1), Int(1)}},
+			expectedOutput: Int(0),
+		},
+		{
+			name:           "Tridiagonal with polynomial entries",
+			input:          [][]Expr{{x, Int(1), Int(0)}, {Int(1), x, Int(1)}, {Int(0), Int(1), x}},
+			expectedOutput: Sub(Pow(x, Int(3)), Mul(Int(2), x)),
+		},
+		{
+			name:           "Empty matrix",
+			input:          [][]Expr{},
+			expectedOutput: Int(1),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, err := NewMatrix(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := m.Det()
+			if err != nil || !isZero(Sub(result, test.expectedOutput)) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v, error: %v", test.name, m, test.expectedOutput, result, err)
+			}
+		})
+	}
+}
+
+func TestMatrixInverse(t *testing.T) {
+	a, b, c, d := Var("a"), Var("b"), Var("c"), Var("d")
+
+	tests := []struct {
+		name  string
+		input [][]Expr
+	}{
+		{
+			name:  "Symbolic 2x2",
+			input: [][]Expr{{a, b}, {c, d}},
+		},
+		{
+			name:  "Rational 3x3",
+			input: [][]Expr{{Int(0), Int(2), Int(1)}, {Int(1), Div(Int(1), Int(2)), Int(2)}, {Int(4), Int(1), Int(1)}},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			inv, err := m.Inverse()
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+
+			prod, _ := m.Mul(inv)
+			if !matrixIsZero(t, prod, Identity(m.Rows())) {
+				t.Errorf("Following test failed: %s\nInput: %v\nInverse: %v\nProduct: %v", test.name, m, inv, prod)
+			}
+		})
+	}
+
+	singular, _ := NewMatrix([][]Expr{{a, b}, {Mul(Int(2), a), Mul(Int(2), b)}})
+	if _, err := singular.Inverse(); !reflect.DeepEqual(err, &SingularMatrixError{}) {
+		t.Errorf("Expected SingularMatrixError but got: %v", err)
+	}
+}
+
+func TestMatrixRankAndNullSpace(t *testing.T) {
+	a := Var("a")
+
+	tests := []struct {
+		name          string
+		input         [][]Expr
+		expectedRank  int
+		expectedNulls int
+	}{
+		{
+			name:          "Full rank",
+			input:         [][]Expr{{Int(1), Int(2)}, {Int(3), Int(4)}},
+			expectedRank:  2,
+			expectedNulls: 0,
+		},
+		{
+			name:          "Dependent rows with symbolic entry",
+			input:         [][]Expr{{Int(1), Int(2), Int(3)}, {Int(2), Int(4), Int(6)}, {a, Int(0), Int(1)}},
+			expectedRank:  2,
+			expectedNulls: 1,
+		},
+		{
+			name:          "Symbolically dependent rows",
+			input:         [][]Expr{{a, Pow(a, Int(2))}, {Int(1), a}},
+			expectedRank:  1,
+			expectedNulls: 1,
+		},
+		{
+			name:          "Zero matrix",
+			input:         [][]Expr{{Int(0), Int(0)}, {Int(0), Int(0)}},
+			expectedRank:  0,
+			expectedNulls: 2,
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			m, _ := NewMatrix(test.input)
+			if rank := m.Rank(); rank != test.expectedRank {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected rank: %v\nGot: %v", test.name, m, test.expectedRank, rank)
+			}
+
+			nullSpace := m.NullSpace()
+			if len(nullSpace) != test.expectedNulls {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected null space dimension: %v\nGot: %v", test.name, m, test.expectedNulls, len(nullSpace))
+			}
+			for _, v := range nullSpace {
+				prod, _ := m.Mul(v)
+				if !matrixIsZero(t, prod, Zeros(m.Rows(), 1)) {
+					t.Errorf("Following test failed: %s\nInput: %v\nNull space vector %v is mapped to %v", test.name, m, v, prod)
+				}
+			}
+		})
+	}
+}
+
+func TestMatrixRREF(t *testing.T) {
+	m, _ := NewMatrix([][]Expr{{Int(0), Int(2), Int(4)}, {Int(1), Int(1), Int(1)}})
+	expected, _ := NewMatrix([][]Expr{{Int(1), Int(0), Int(-1)}, {Int(0), Int(1), Int(2)}})
+
+	result, pivots := m.RREF()
+	if !reflect.DeepEqual(result, expected) || !reflect.DeepEqual(pivots, []int{0, 1}) {
+		t.Errorf("Expected: %v with pivots [0 1]\nGot: %v with pivots %v", expected, result, pivots)
+	}
+}
+
+func TestMatrixElementWise(t *testing.T) {
+	x := Var("x")
+	y := Var("y")
+	m, _ := NewMatrix([][]Expr{{Mul(x, y), Pow(x, Int(2))}, {Int(1), y}})
+
+	transposed, _ := NewMatrix([][]Expr{{Mul(x, y), Int(1)}, {Pow(x, Int(2)), y}})
+	if result := m.Transpose(); !reflect.DeepEqual(result, transposed) {
+		t.Errorf("Transpose failed\nExpected: %v\nGot: %v", transposed, result)
+	}
+
+	derivative, _ := NewMatrix([][]Expr{{y, Mul(Int(2), x)}, {Int(0), Int(0)}})
+	if result := m.D(x); !reflect.DeepEqual(result, derivative) {
+		t.Errorf("D failed\nExpected: %v\nGot: %v", derivative, result)
+	}
+
+	substituted, _ := NewMatrix([][]Expr{{Int(2), Int(4)}, {Int(1), Int(1)}})
+	if result := m.Substitute(x, Int(2)).Substitute(y, Int(1)).Simplify(); !reflect.DeepEqual(result, substituted) {
+		t.Errorf("Substitute failed\nExpected: %v\nGot: %v", substituted, result)
+	}
+
+	if trace, _ := m.Trace(); !isZero(Sub(trace, Add(Mul(x, y), y))) {
+		t.Errorf("Trace failed\nExpected: %v\nGot: %v", Add(Mul(x, y), y), trace)
+	}
+
+	if _, err := NewMatrix([][]Expr{{x, y}, {x}}); err == nil {
+		t.Errorf("Expected error for ragged rows")
+	}
+}
+
+/* HELPER FUNCTIONS */
+
+// Returns true if m - n is zero as a matrix of rational functions.
+func matrixIsZero(t *testing.T, m, n Matrix) bool {
+	t.Helper()
+	diff, err := m.Sub(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < diff.Rows(); i++ {
+		for j := 0; j < diff.Cols(); j++ {
+			if !isZero(diff.At(i, j)) {
+				return false
+			}
+		}
+	}
+	return true
+}
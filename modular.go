@@ -0,0 +1,269 @@
+package gosymbol
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+/*
+A ModInt is an integer modulo a positive modulus, i.e. an element
+of Z/mZ. The value is always kept in the range [0, m). Arithmetic
+between ModInts with different moduli is an error.
+
+All operations work on int64 without overflow, so any modulus up
+to 2^63 - 1 can be used.
+*/
+type ModInt struct {
+	value, modulus int64
+}
+
+// Returns value mod modulus, which must be positive.
+func NewModInt(value, modulus int64) (ModInt, error) {
+	if modulus < 1 {
+		return ModInt{}, &InvalidModulusError{Modulus: modulus}
+	}
+	return ModInt{value: reduceMod(value, modulus), modulus: modulus}, nil
+}
+
+func (a ModInt) Value() int64 { return a.value }
+
+func (a ModInt) Modulus() int64 { return a.modulus }
+
+func (a ModInt) String() string {
+	return fmt.Sprintf("%d (mod %d)", a.value, a.modulus)
+}
+
+// Returns the representative of a in [0, m) as an integer expression.
+func (a ModInt) ToExpr() Expr {
+	return Int(a.value)
+}
+
+func (a ModInt) Add(b ModInt) (ModInt, error) {
+	if a.modulus != b.modulus {
+		return ModInt{}, &ModulusMismatchError{A: a.modulus, B: b.modulus}
+	}
+	return ModInt{value: addMod(a.value, b.value, a.modulus), modulus: a.modulus}, nil
+}
+
+func (a ModInt) Sub(b ModInt) (ModInt, error) {
+	return a.Add(b.Neg())
+}
+
+func (a ModInt) Mul(b ModInt) (ModInt, error) {
+	if a.modulus != b.modulus {
+		return ModInt{}, &ModulusMismatchError{A: a.modulus, B: b.modulus}
+	}
+	return ModInt{value: mulMod(a.value, b.value, a.modulus), modulus: a.modulus}, nil
+}
+
+func (a ModInt) Neg() ModInt {
+	if a.value == 0 {
+		return a
+	}
+	return ModInt{value: a.modulus - a.value, modulus: a.modulus}
+}
+
+// Returns the multiplicative inverse of a, which exists iff gcd(a, m) = 1.
+func (a ModInt) Inv() (ModInt, error) {
+	inv, ok := invMod(a.value, a.modulus)
+	if !ok {
+		return ModInt{}, &NotInvertibleError{Value: a.value, Modulus: a.modulus}
+	}
+	return ModInt{value: inv, modulus: a.modulus}, nil
+}
+
+/*
+Returns a^n by square-and-multiply. Negative exponents are powers
+of the inverse, so they require a to be invertible.
+*/
+func (a ModInt) Pow(n int64) (ModInt, error) {
+	if n < 0 {
+		inv, err := a.Inv()
+		if err != nil {
+			return ModInt{}, err
+		}
+		// -n overflows for the smallest int64, so one factor is split off
+		return ModInt{value: mulMod(powMod(inv.value, -(n+1), a.modulus), inv.value, a.modulus), modulus: a.modulus}, nil
+	}
+	return ModInt{value: powMod(a.value, n, a.modulus), modulus: a.modulus}, nil
+}
+
+/*
+Returns a square root r of a modulo a prime p, computed by the
+Tonelli-Shanks algorithm. Of the two roots r and p - r the smaller
+one is returned. NoSquareRootError is returned if a is a quadratic
+non-residue and NotPrimeError if the modulus is not prime.
+
+See [1] algorithm 2.3.8.
+
+[1] CRANDALL, Richard; POMERANCE, Carl. Prime numbers: A computational perspective. Springer, 2005.
+*/
+func (a ModInt) Sqrt() (ModInt, error) {
+	p := a.modulus
+	if !IsPrime(p) {
+		return ModInt{}, &NotPrimeError{N: p}
+	}
+	if p == 2 || a.value == 0 {
+		return a, nil
+	}
+	if powMod(a.value, (p-1)/2, p) != 1 {
+		return ModInt{}, &NoSquareRootError{Value: a.value, Modulus: p}
+	}
+
+	var r int64
+	if p%4 == 3 {
+		r = powMod(a.value, (p+1)/4, p)
+	} else {
+		r = tonelliShanks(a.value, p)
+	}
+	if r > p-r {
+		r = p - r
+	}
+	return ModInt{value: r, modulus: p}, nil
+}
+
+// Tonelli-Shanks for a quadratic residue a modulo an odd prime p.
+func tonelliShanks(a, p int64) int64 {
+	// p - 1 = q * 2^s with q odd
+	q, s := p-1, 0
+	for q%2 == 0 {
+		q /= 2
+		s++
+	}
+
+	// Any quadratic non-residue z works, and half of all residues are
+	z := int64(2)
+	for powMod(z, (p-1)/2, p) != p-1 {
+		z++
+	}
+
+	m, c := s, powMod(z, q, p)
+	t, r := powMod(a, q, p), powMod(a, (q+1)/2, p)
+	for t != 1 {
+		// The least i with t^(2^i) = 1
+		i, t2 := 0, t
+		for t2 != 1 {
+			t2 = mulMod(t2, t2, p)
+			i++
+		}
+		b := c
+		for j := 0; j < m-i-1; j++ {
+			b = mulMod(b, b, p)
+		}
+		m = i
+		c = mulMod(b, b, p)
+		t = mulMod(t, c, p)
+		r = mulMod(r, b, p)
+	}
+	return r
+}
+
+/*
+Reconstructs x from the congruences x = a_i (mod m_i) by the Chinese
+remainder theorem. The moduli need not be pairwise coprime, and the
+result is modulo the least common multiple of them. If the
+congruences contradict each other InconsistentCongruencesError is
+returned, e.g. for x = 1 (mod 4) and x = 2 (mod 6).
+*/
+func CRT(residues ...ModInt) (ModInt, error) {
+	x, m := big.NewInt(0), big.NewInt(1)
+	g, u, diff := new(big.Int), new(big.Int), new(big.Int)
+	for _, r := range residues {
+		a, n := big.NewInt(r.value), big.NewInt(r.modulus)
+
+		// x + m*k = a (mod n) is solvable iff gcd(m, n) divides a - x
+		g.GCD(u, nil, m, n)
+		diff.Sub(a, x)
+		if new(big.Int).Mod(diff, g).Sign() != 0 {
+			return ModInt{}, &InconsistentCongruencesError{}
+		}
+		nReduced := new(big.Int).Quo(n, g)
+		k := new(big.Int).Quo(diff, g)
+		k.Mul(k, u).Mod(k, nReduced)
+
+		x.Add(x, k.Mul(k, m))
+		m.Mul(m, nReduced)
+		x.Mod(x, m)
+	}
+	if !m.IsInt64() {
+		return ModInt{}, &ModulusOverflowError{}
+	}
+	return ModInt{value: x.Int64(), modulus: m.Int64()}, nil
+}
+
+/*
+A FiniteField is the prime field GF(p) = Z/pZ. It constructs the
+ModInts and polynomials of the field, which lets the modulus be
+given once:
+
+	F, _ := GF(7)
+	a := F.Elem(3)
+	f := F.Poly(1, 0, 1) // x^2 + 1
+*/
+type FiniteField struct {
+	p int64
+}
+
+// Returns the finite field with p elements, where p must be prime.
+func GF(p int64) (FiniteField, error) {
+	if !IsPrime(p) {
+		return FiniteField{}, &NotPrimeError{N: p}
+	}
+	return FiniteField{p: p}, nil
+}
+
+func (f FiniteField) Characteristic() int64 { return f.p }
+
+func (f FiniteField) String() string {
+	return fmt.Sprintf("GF(%d)", f.p)
+}
+
+// Returns a as an element of the field.
+func (f FiniteField) Elem(a int64) ModInt {
+	return ModInt{value: reduceMod(a, f.p), modulus: f.p}
+}
+
+func reduceMod(a, m int64) int64 {
+	a %= m
+	if a < 0 {
+		a += m
+	}
+	return a
+}
+
+// Returns a + b mod m for a, b in [0, m).
+func addMod(a, b, m int64) int64 {
+	s := uint64(a) + uint64(b)
+	if s >= uint64(m) {
+		s -= uint64(m)
+	}
+	return int64(s)
+}
+
+// Returns a * b mod m for a, b in [0, m) using a 128 bit product.
+func mulMod(a, b, m int64) int64 {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	return int64(bits.Rem64(hi, lo, uint64(m)))
+}
+
+// Returns a^n mod m for n >= 0 by square-and-multiply.
+func powMod(a, n, m int64) int64 {
+	result := reduceMod(1, m)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result = mulMod(result, a, m)
+		}
+		a = mulMod(a, a, m)
+	}
+	return result
+}
+
+// Returns the inverse of a mod m by the extended Euclidean algorithm.
+func invMod(a, m int64) (int64, bool) {
+	g, x, _ := ExtendedGCD(reduceMod(a, m), m)
+	if g != 1 {
+		return 0, false
+	}
+	return reduceMod(x, m), true
+}
@@ -0,0 +1,193 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func mustModInt(t *testing.T, value, modulus int64) ModInt {
+	t.Helper()
+	a, err := NewModInt(value, modulus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestModIntArithmetic(t *testing.T) {
+	a, b := mustModInt(t, 5, 7), mustModInt(t, -4, 7)
+	sum, _ := a.Add(b)
+	difference, _ := a.Sub(b)
+	product, _ := a.Mul(b)
+	inverse, _ := a.Inv()
+	power, _ := a.Pow(6)
+	negativePower, _ := a.Pow(-2)
+	large := mustModInt(t, 1<<62, 1<<62+1)
+	largeProduct, _ := large.Mul(large)
+
+	tests := []struct {
+		name           string
+		input          ModInt
+		expectedOutput int64
+	}{
+		{name: "Values are reduced", input: b, expectedOutput: 3},
+		{name: "Sum", input: sum, expectedOutput: 1},
+		{name: "Difference", input: difference, expectedOutput: 2},
+		{name: "Product", input: product, expectedOutput: 1},
+		{name: "Inverse", input: inverse, expectedOutput: 3},
+		{name: "Fermat's little theorem", input: power, expectedOutput: 1},
+		{name: "Negative exponent", input: negativePower, expectedOutput: 2},
+		{name: "Product does not overflow", input: largeProduct, expectedOutput: 1},
+		{name: "Negation", input: a.Neg(), expectedOutput: 2},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			if test.input.Value() != test.expectedOutput {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, test.input.Value())
+			}
+		})
+	}
+}
+
+func TestModIntSqrt(t *testing.T) {
+	// Primes with p = 3 (mod 4) and with different powers of two in p - 1
+	primes := []int64{2, 3, 7, 13, 17, 41, 97, 257, 7681, 1000000007}
+	for _, p := range primes {
+		t.Run(fmt.Sprint(p), func(t *testing.T) {
+			F, err := GF(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, value := range []int64{0, 1, 2, 3, 5, 10, 12345, p - 1} {
+				a := F.Elem(value)
+				r, err := a.Sqrt()
+				isSquare := a.Value() == 0 || p == 2 || powMod(a.Value(), (p-1)/2, p) == 1
+				if !isSquare {
+					var noRoot *NoSquareRootError
+					if !errors.As(err, &noRoot) {
+						t.Errorf("Following test failed: non-residue\nInput: %v\nExpected: NoSquareRootError\nGot: %v", a, err)
+					}
+					continue
+				}
+				square, _ := r.Mul(r)
+				if err != nil || square != a || r.Value() > p-r.Value() {
+					t.Errorf("Following test failed: square root\nInput: %v\nExpected: smallest r with r^2 = %v\nGot: %v (error %v)", a, a, r, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCRT(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          []ModInt
+		expectedOutput ModInt
+	}{
+		{name: "No congruences", input: nil, expectedOutput: mustModInt(t, 0, 1)},
+		{name: "Sun Tzu", input: []ModInt{mustModInt(t, 2, 3), mustModInt(t, 3, 5), mustModInt(t, 2, 7)}, expectedOutput: mustModInt(t, 23, 105)},
+		{name: "Non-coprime moduli", input: []ModInt{mustModInt(t, 3, 4), mustModInt(t, 1, 6)}, expectedOutput: mustModInt(t, 7, 12)},
+		{
+			name:           "Large moduli",
+			input:          []ModInt{mustModInt(t, 1, 1000000007), mustModInt(t, 2, 998244353)},
+			expectedOutput: mustModInt(t, 993328913953302350, 998244359987710471),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := CRT(test.input...)
+			if err != nil || result != test.expectedOutput {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v (error %v)", test.name, test.input, test.expectedOutput, result, err)
+			}
+		})
+	}
+}
+
+func TestModularErrors(t *testing.T) {
+	var modulusErr *InvalidModulusError
+	if _, err := NewModInt(1, 0); !errors.As(err, &modulusErr) {
+		t.Errorf("Following test failed: zero modulus\nExpected: InvalidModulusError\nGot: %v", err)
+	}
+	var mismatchErr *ModulusMismatchError
+	if _, err := mustModInt(t, 1, 3).Add(mustModInt(t, 1, 5)); !errors.As(err, &mismatchErr) {
+		t.Errorf("Following test failed: different moduli\nExpected: ModulusMismatchError\nGot: %v", err)
+	}
+	var invErr *NotInvertibleError
+	if _, err := mustModInt(t, 4, 6).Inv(); !errors.As(err, &invErr) {
+		t.Errorf("Following test failed: zero divisor\nExpected: NotInvertibleError\nGot: %v", err)
+	}
+	var primeErr *NotPrimeError
+	if _, err := GF(91); !errors.As(err, &primeErr) {
+		t.Errorf("Following test failed: composite field order\nExpected: NotPrimeError\nGot: %v", err)
+	}
+	if _, err := mustModInt(t, 4, 15).Sqrt(); !errors.As(err, &primeErr) {
+		t.Errorf("Following test failed: square root modulo a composite\nExpected: NotPrimeError\nGot: %v", err)
+	}
+	var inconsistentErr *InconsistentCongruencesError
+	if _, err := CRT(mustModInt(t, 1, 4), mustModInt(t, 2, 6)); !errors.As(err, &inconsistentErr) {
+		t.Errorf("Following test failed: inconsistent congruences\nExpected: InconsistentCongruencesError\nGot: %v", err)
+	}
+	var overflowErr *ModulusOverflowError
+	if _, err := CRT(mustModInt(t, 1, 1<<40), mustModInt(t, 1, 1<<40-1)); !errors.As(err, &overflowErr) {
+		t.Errorf("Following test failed: overflowing modulus\nExpected: ModulusOverflowError\nGot: %v", err)
+	}
+}
+
+func TestGFPoly(t *testing.T) {
+	F, _ := GF(7)
+	x := Var("x")
+	f := F.Poly(6, 0, 1)    // x^2 - 1
+	g := F.Poly(1, 2, 1)    // (x + 1)^2
+	h := F.Poly(3, 0, 0, 1) // x^3 + 3
+
+	sum, _ := f.Add(g)
+	product, _ := f.Mul(g)
+	quo, rem, _ := h.QuoRem(f)
+	gcd, _ := f.GCD(g)
+	frobenius, _ := F.Poly(0, 1).PowMod(7, h)
+	fromExpr, _ := F.PolyFromExpr(Add(Mul(Div(Int(1), Int(2)), Pow(x, Int(2))), Int(-1)), x)
+
+	tests := []struct {
+		name           string
+		input          GFPoly
+		expectedOutput GFPoly
+	}{
+		{name: "Coefficients are reduced", input: F.Poly(-1, 7, 8, 0), expectedOutput: F.Poly(6, 0, 1)},
+		{name: "Sum", input: sum, expectedOutput: F.Poly(0, 2, 2)},
+		{name: "Product", input: product, expectedOutput: F.Poly(6, 5, 0, 2, 1)},
+		{name: "Quotient", input: quo, expectedOutput: F.Poly(0, 1)},
+		{name: "Remainder", input: rem, expectedOutput: F.Poly(3, 1)},
+		{name: "GCD", input: gcd, expectedOutput: F.Poly(1, 1)},
+		{name: "Monic", input: F.Poly(2, 4, 3).Monic(), expectedOutput: F.Poly(3, 6, 1)},
+		{name: "x^7 mod x^3 + 3", input: frobenius, expectedOutput: F.Poly(0, 2)},
+		{name: "Derivative", input: h.Derivative(), expectedOutput: F.Poly(0, 0, 3)},
+		{name: "Derivative vanishes at multiples of p", input: F.Poly(1, 0, 0, 0, 0, 0, 0, 1).Derivative(), expectedOutput: F.Poly()},
+		{name: "From expression", input: fromExpr, expectedOutput: F.Poly(6, 0, 4)},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			if !test.input.Equal(test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, test.input)
+			}
+		})
+	}
+
+	if value, _ := h.Eval(F.Elem(2)); value != F.Elem(4) {
+		t.Errorf("Following test failed: evaluation\nInput: %v\nExpected: %v\nGot: %v", h, F.Elem(4), value)
+	}
+	if expr := g.ToExpr(x); !Equal(expr, Add(Pow(x, Int(2)), Mul(Int(2), x), Int(1))) {
+		t.Errorf("Following test failed: conversion to expression\nInput: %v\nExpected: %v\nGot: %v", g, "x^2 + 2*x + 1", expr)
+	}
+	var zeroErr *DivisionByZeroError
+	if _, _, err := f.QuoRem(F.Poly()); !errors.As(err, &zeroErr) {
+		t.Errorf("Following test failed: division by zero\nExpected: DivisionByZeroError\nGot: %v", err)
+	}
+	var invErr *NotInvertibleError
+	if _, err := F.PolyFromExpr(Div(x, Int(7)), x); !errors.As(err, &invErr) {
+		t.Errorf("Following test failed: denominator divisible by p\nExpected: NotInvertibleError\nGot: %v", err)
+	}
+}
@@ -0,0 +1,371 @@
+package gosymbol
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	odeRelTolerance = 1e-8
+	odeAbsTolerance = 1e-10
+	maxODESteps     = 100000
+	rk4Steps        = 1000
+)
+
+// A method for numerically integrating ordinary differential equations.
+type ODEMethod int
+
+const (
+	// The classical fourth order Runge-Kutta method
+	// with rk4Steps steps of equal length.
+	RK4 ODEMethod = iota
+	// The adaptive Dormand-Prince method of order 5(4).
+	RK45
+	// The adaptive linearly implicit Rosenbrock method ROS2
+	// of order 2, which is suitable for stiff systems.
+	Rosenbrock
+)
+
+func (m ODEMethod) String() string {
+	switch m {
+	case RK4:
+		return "rk4"
+	case RK45:
+		return "rk45"
+	case Rosenbrock:
+		return "rosenbrock"
+	default:
+		return fmt.Sprintf("ODEMethod(%d)", int(m))
+	}
+}
+
+/*
+A numerical solution of an initial value problem. T holds the times of
+the steps taken, in the direction of integration, and Y the state at
+each of these times.
+*/
+type Trajectory struct {
+	T      []float64
+	Y      [][]float64
+	Method ODEMethod
+	// The derivative of the state at each time,
+	// used for the interpolation
+	dy [][]float64
+}
+
+/*
+Returns the state at time t, interpolated between the steps with the
+cubic Hermite polynomial matching the state and its derivative at both
+ends. An OutOfRangeError is returned if t is outside of the integrated
+interval.
+*/
+func (tr *Trajectory) At(t float64) ([]float64, error) {
+	n := len(tr.T)
+	start, end := tr.T[0], tr.T[n-1]
+	if !(t >= math.Min(start, end) && t <= math.Max(start, end)) {
+		return nil, &OutOfRangeError{T: t, Start: start, End: end}
+	}
+
+	// Index of the first step at or past t
+	dir := 1.0
+	if end < start {
+		dir = -1
+	}
+	ix := sort.Search(n, func(i int) bool { return dir*tr.T[i] >= dir*t })
+	if ix == 0 || tr.T[ix] == t {
+		return append([]float64{}, tr.Y[ix]...), nil
+	}
+
+	t0, t1 := tr.T[ix-1], tr.T[ix]
+	h := t1 - t0
+	s := (t - t0) / h
+	h00 := (1 + 2*s) * (1 - s) * (1 - s)
+	h10 := s * (1 - s) * (1 - s)
+	h01 := s * s * (3 - 2*s)
+	h11 := s * s * (s - 1)
+	y := make([]float64, len(tr.Y[ix]))
+	for k := range y {
+		y[k] = h00*tr.Y[ix-1][k] + h10*h*tr.dy[ix-1][k] + h01*tr.Y[ix][k] + h11*h*tr.dy[ix][k]
+	}
+	return y, nil
+}
+
+func (tr *Trajectory) push(t float64, y, dy []float64) {
+	tr.T = append(tr.T, t)
+	tr.Y = append(tr.Y, y)
+	tr.dy = append(tr.dy, dy)
+}
+
+// The right hand side of y' = f(t, y).
+type odeFunc func(t float64, y []float64) []float64
+
+/*
+Numerically solves the initial value problem state' = system with
+state = y0 at time tspan[0], from tspan[0] to tspan[1] which may be
+less than tspan[0]. The k:th expression in system is the derivative of
+the k:th variable in state and may depend on the state variables and t.
+
+The right hand side is compiled once with CompileNumeric. The Rosenbrock
+method additionally derives the Jacobian of the system symbolically.
+The adaptive methods keep the local error estimate of each step below
+odeRelTolerance relative to the state plus odeAbsTolerance.
+
+If the integration fails, e.g. because the solution blows up, the
+trajectory up to that point is returned together with the error.
+*/
+func NDSolve(system []Expr, state []variable, t variable, y0 []float64, tspan [2]float64, method ODEMethod) (*Trajectory, error) {
+	n := len(state)
+	if len(system) != n {
+		return nil, &DimensionMismatchError{Expected: n, Got: len(system)}
+	} else if len(y0) != n {
+		return nil, &DimensionMismatchError{Expected: n, Got: len(y0)}
+	}
+
+	vars := append(append([]variable{}, state...), t)
+	rhs, err := compileSystem(system, vars)
+	if err != nil {
+		return nil, err
+	}
+	f := func(t float64, y []float64) []float64 {
+		x := append(append(make([]float64, 0, n+1), y...), t)
+		dy := make([]float64, n)
+		for ix := range rhs {
+			dy[ix] = rhs[ix](x)
+		}
+		return dy
+	}
+
+	tr := &Trajectory{Method: method}
+	start := append([]float64{}, y0...)
+	tr.push(tspan[0], start, f(tspan[0], start))
+
+	switch method {
+	case RK4:
+		err = rk4(f, tr, tspan[1])
+	case RK45:
+		err = adaptiveSolve(dormandPrinceStep(f), 4, tr, tspan[1])
+	case Rosenbrock:
+		// The Jacobian w.r.t. both the state and t
+		jacobian := make([][]NumericFunc, n)
+		for ix, e := range system {
+			derivatives := make([]Expr, n+1)
+			for jx, v := range vars {
+				derivatives[jx] = differentiate(e, v)
+			}
+			if jacobian[ix], err = compileSystem(derivatives, vars); err != nil {
+				return nil, err
+			}
+		}
+		err = adaptiveSolve(rosenbrockStep(f, jacobian), 1, tr, tspan[1])
+	default:
+		return nil, &UnknownMethodError{Method: method}
+	}
+	return tr, err
+}
+
+func compileSystem(system []Expr, vars []variable) ([]NumericFunc, error) {
+	compiled := make([]NumericFunc, len(system))
+	for ix, e := range system {
+		f, err := CompileNumeric(e, vars)
+		if err != nil {
+			return nil, err
+		}
+		compiled[ix] = f
+	}
+	return compiled, nil
+}
+
+// Integrates to end with rk4Steps steps of the classical Runge-Kutta method.
+func rk4(f odeFunc, tr *Trajectory, end float64) error {
+	t0, y := tr.T[0], tr.Y[0]
+	h := (end - t0) / rk4Steps
+	if h == 0 {
+		return nil
+	}
+	for step := 1; step <= rk4Steps; step++ {
+		t := tr.T[len(tr.T)-1]
+		k1 := tr.dy[len(tr.dy)-1]
+		k2 := f(t+h/2, axpy(h/2, k1, y))
+		k3 := f(t+h/2, axpy(h/2, k2, y))
+		k4 := f(t+h, axpy(h, k3, y))
+		y = axpy(h/6, k4, axpy(h/3, k3, axpy(h/3, k2, axpy(h/6, k1, y))))
+
+		// Computing t from the step count avoids
+		// accumulating rounding errors
+		t = t0 + float64(step)*h
+		if step == rk4Steps {
+			t = end
+		}
+		if !allFinite(y) {
+			return &NonFiniteStateError{T: t}
+		}
+		tr.push(t, y, f(t, y))
+	}
+	return nil
+}
+
+/*
+A step of an embedded method from time t with step length h, where dy is
+the derivative at (t, y). It returns the new state, the derivative at the
+new state and an estimate of the local error.
+*/
+type embeddedStep func(t, h float64, y, dy []float64) ([]float64, []float64, []float64)
+
+/*
+Integrates to end with the embedded method step, whose error estimate is
+of the given order. Steps with a too large error are rejected, and the
+step length is adjusted after each step based on the error estimate.
+*/
+func adaptiveSolve(step embeddedStep, order int, tr *Trajectory, end float64) error {
+	last := len(tr.T) - 1
+	t, y, dy := tr.T[last], tr.Y[last], tr.dy[last]
+	span := end - t
+	if span == 0 {
+		return nil
+	}
+	minStep := 16 * machineEpsilon * math.Max(math.Abs(t), math.Abs(end))
+	h := span / 100
+
+	for steps := 0; steps < maxODESteps; steps++ {
+		final := (t+h-end)*span >= 0
+		if final {
+			h = end - t
+		}
+		yNew, dyNew, estimate := step(t, h, y, dy)
+
+		// Root mean square of the error relative to the tolerance
+		errNorm := 0.0
+		for ix, e := range estimate {
+			tolerance := odeAbsTolerance + odeRelTolerance*math.Max(math.Abs(y[ix]), math.Abs(yNew[ix]))
+			errNorm += (e / tolerance) * (e / tolerance)
+		}
+		errNorm = math.Sqrt(errNorm / float64(max(len(estimate), 1)))
+
+		if errNorm <= 1 && allFinite(yNew) && allFinite(dyNew) {
+			if final {
+				t = end
+			} else {
+				t += h
+			}
+			y, dy = yNew, dyNew
+			tr.push(t, y, dy)
+			if final {
+				return nil
+			}
+		}
+
+		factor := 0.2
+		if errNorm == 0 {
+			factor = 5
+		} else if !math.IsNaN(errNorm) {
+			factor = math.Max(0.2, math.Min(5, 0.9*math.Pow(errNorm, -1/float64(order+1))))
+		}
+		h *= factor
+		if math.Abs(h) < minStep {
+			return &StepSizeError{T: t}
+		}
+	}
+	return &ConvergenceError{Method: tr.Method.String(), Iterations: maxODESteps}
+}
+
+// The Butcher tableau of the Dormand-Prince method, where the last row
+// of dpA is also the weights of the fifth order solution, and dpE the
+// difference between the weights of the fifth and fourth order solutions.
+var (
+	dpC = []float64{0, 1.0 / 5, 3.0 / 10, 4.0 / 5, 8.0 / 9, 1, 1}
+	dpA = [][]float64{
+		{},
+		{1.0 / 5},
+		{3.0 / 40, 9.0 / 40},
+		{44.0 / 45, -56.0 / 15, 32.0 / 9},
+		{19372.0 / 6561, -25360.0 / 2187, 64448.0 / 6561, -212.0 / 729},
+		{9017.0 / 3168, -355.0 / 33, 46732.0 / 5247, 49.0 / 176, -5103.0 / 18656},
+		{35.0 / 384, 0, 500.0 / 1113, 125.0 / 192, -2187.0 / 6784, 11.0 / 84},
+	}
+	dpE = []float64{71.0 / 57600, 0, -71.0 / 16695, 71.0 / 1920, -17253.0 / 339200, 22.0 / 525, -1.0 / 40}
+)
+
+/*
+Returns a step of the Dormand-Prince method. The derivative at the new
+state is the last stage, so each step takes six new evaluations of f.
+*/
+func dormandPrinceStep(f odeFunc) embeddedStep {
+	return func(t, h float64, y, dy []float64) ([]float64, []float64, []float64) {
+		k := make([][]float64, len(dpC))
+		k[0] = dy
+		var yNew []float64
+		for stage := 1; stage < len(dpC); stage++ {
+			yStage := y
+			for jx, a := range dpA[stage] {
+				yStage = axpy(h*a, k[jx], yStage)
+			}
+			k[stage] = f(t+dpC[stage]*h, yStage)
+			yNew = yStage
+		}
+		estimate := make([]float64, len(y))
+		for jx, e := range dpE {
+			estimate = axpy(h*e, k[jx], estimate)
+		}
+		return yNew, k[len(k)-1], estimate
+	}
+}
+
+/*
+Returns a step of the ROS2 method for the system extended with t as an
+additional state with derivative one, so that the method also applies to
+non-autonomous systems. With J the Jacobian of the extended system F at
+z = (y, t) and gamma = 1 + 1/sqrt(2), a step is
+
+	(I - gamma*h*J) k1 = F(z)
+	(I - gamma*h*J) k2 = F(z + h*k1) - 2*k1
+	z' = z + 3/2*h*k1 + 1/2*h*k2
+
+where the linearly implicit Euler step z + h*k1 is used to estimate the
+error.
+*/
+func rosenbrockStep(f odeFunc, jacobian [][]NumericFunc) embeddedStep {
+	gamma := 1 + 1/math.Sqrt2
+	return func(t, h float64, y, dy []float64) ([]float64, []float64, []float64) {
+		n := len(y)
+		x := append(append(make([]float64, 0, n+1), y...), t)
+		w := identityMatrix(n + 1)
+		for ix := 0; ix < n; ix++ {
+			for jx := 0; jx <= n; jx++ {
+				w[ix][jx] -= gamma * h * jacobian[ix][jx](x)
+			}
+		}
+
+		k1, ok := gaussSolve(w, append(append([]float64{}, dy...), 1))
+		if !ok {
+			return y, dy, nanVector(n)
+		}
+		z := axpy(h, k1, x)
+		rhs := append(f(z[n], z[:n]), 1)
+		k2, ok := gaussSolve(w, axpy(-2, k1, rhs))
+		if !ok {
+			return y, dy, nanVector(n)
+		}
+
+		yNew := axpy(0.5*h, k2[:n], axpy(1.5*h, k1[:n], y))
+		estimate := scale(axpy(1, k1[:n], k2[:n]), 0.5*h)
+		return yNew, f(t+h, yNew), estimate
+	}
+}
+
+func allFinite(v []float64) bool {
+	for _, x := range v {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func nanVector(n int) []float64 {
+	v := make([]float64, n)
+	for ix := range v {
+		v[ix] = math.NaN()
+	}
+	return v
+}
@@ -0,0 +1,130 @@
+package gosymbol
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestNDSolve(t *testing.T) {
+	x, y, s := Var("x"), Var("y"), Var("t")
+
+	tests := []struct {
+		name      string
+		system    []Expr
+		state     []variable
+		y0        []float64
+		tspan     [2]float64
+		exact     func(t float64) []float64
+		tolerance float64
+	}{
+		{
+			name:   "Exponential decay",
+			system: []Expr{Neg(x)},
+			state:  []variable{x},
+			y0:     []float64{1},
+			tspan:  [2]float64{0, 2},
+			exact:  func(t float64) []float64 { return []float64{math.Exp(-t)} },
+		},
+		{
+			name:   "Harmonic oscillator",
+			system: []Expr{y, Neg(x)},
+			state:  []variable{x, y},
+			y0:     []float64{1, 0},
+			tspan:  [2]float64{0, 2 * math.Pi},
+			exact:  func(t float64) []float64 { return []float64{math.Cos(t), -math.Sin(t)} },
+		},
+		{
+			name:   "Non-autonomous",
+			system: []Expr{Mul(Int(2), s, x)},
+			state:  []variable{x},
+			y0:     []float64{1},
+			tspan:  [2]float64{0, 1},
+			exact:  func(t float64) []float64 { return []float64{math.Exp(t * t)} },
+		},
+		{
+			name:   "Backwards in time",
+			system: []Expr{x},
+			state:  []variable{x},
+			y0:     []float64{1},
+			tspan:  [2]float64{0, -1},
+			exact:  func(t float64) []float64 { return []float64{math.Exp(t)} },
+		},
+		{
+			name:   "Stiff",
+			system: []Expr{Mul(Int(-1000), Sub(x, Cos(s)))},
+			state:  []variable{x},
+			y0:     []float64{1},
+			tspan:  [2]float64{0, 1},
+			exact: func(t float64) []float64 {
+				c := 1000.0 / (1000*1000 + 1)
+				return []float64{1000*c*math.Cos(t) + c*math.Sin(t) + (1-1000*c)*math.Exp(-1000*t)}
+			},
+		},
+	}
+
+	for _, method := range []ODEMethod{RK4, RK45, Rosenbrock} {
+		for ix, test := range tests {
+			// The stiff problem would need a very small step with RK4
+			if method == RK4 && test.name == "Stiff" {
+				continue
+			}
+			t.Run(fmt.Sprintf("%v/%d", method, ix+1), func(t *testing.T) {
+				result, err := NDSolve(test.system, test.state, s, test.y0, test.tspan, method)
+				if err != nil {
+					t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+				}
+				if result.T[len(result.T)-1] != test.tspan[1] {
+					t.Errorf("Following test failed: %s\nIntegration ended at %v", test.name, result.T[len(result.T)-1])
+				}
+
+				// Checks the interpolation between the steps
+				for k := 0; k <= 10; k++ {
+					tk := test.tspan[0] + float64(k)/10*(test.tspan[1]-test.tspan[0])
+					got, err := result.At(tk)
+					if err != nil {
+						t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+					}
+					for jx, expected := range test.exact(tk) {
+						if math.Abs(got[jx]-expected) > 1e-4 {
+							t.Errorf("Following test failed: %s\nAt t = %v\nExpected: %v\nGot: %v", test.name, tk, expected, got[jx])
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestNDSolveErrors(t *testing.T) {
+	x, s := Var("x"), Var("t")
+
+	_, err := NDSolve([]Expr{x}, []variable{x}, s, []float64{1, 2}, [2]float64{0, 1}, RK45)
+	if _, ok := err.(*DimensionMismatchError); !ok {
+		t.Errorf("Expected DimensionMismatchError but got: %v", err)
+	}
+
+	_, err = NDSolve([]Expr{Var("y")}, []variable{x}, s, []float64{1}, [2]float64{0, 1}, RK45)
+	if _, ok := err.(*UnboundVariableError); !ok {
+		t.Errorf("Expected UnboundVariableError but got: %v", err)
+	}
+
+	result, err := NDSolve([]Expr{x}, []variable{x}, s, []float64{1}, [2]float64{0, 1}, ODEMethod(99))
+	if methodErr, ok := err.(*UnknownMethodError); !ok || methodErr.Method != ODEMethod(99) || result != nil {
+		t.Errorf("Expected UnknownMethodError but got: %v", err)
+	}
+
+	// x' = x^2 with x(0) = 1 blows up at t = 1
+	result, err = NDSolve([]Expr{Pow(x, Int(2))}, []variable{x}, s, []float64{1}, [2]float64{0, 2}, RK45)
+	if _, ok := err.(*StepSizeError); !ok {
+		t.Errorf("Expected StepSizeError but got: %v", err)
+	} else if last := result.T[len(result.T)-1]; math.Abs(last-1) > 1e-3 {
+		t.Errorf("Expected the integration to stop close to t = 1 but it stopped at %v", last)
+	}
+
+	result, _ = NDSolve([]Expr{x}, []variable{x}, s, []float64{1}, [2]float64{0, 1}, RK4)
+	_, err = result.At(1.5)
+	if _, ok := err.(*OutOfRangeError); !ok {
+		t.Errorf("Expected OutOfRangeError but got: %v", err)
+	}
+}
@@ -0,0 +1,636 @@
+package gosymbol
+
+import (
+	"math/big"
+	"sort"
+)
+
+// The names of the number theoretic functions, which are evaluated
+// by the simplifier when their arguments are integers.
+const (
+	nextPrimeName      VarName = "NextPrime"
+	eulerPhiName       VarName = "EulerPhi"
+	moebiusMuName      VarName = "MoebiusMu"
+	lcmName            VarName = "LCM"
+	jacobiSymbolName   VarName = "JacobiSymbol"
+	modularInverseName VarName = "ModularInverse"
+)
+
+// Bounds on the work done by FactorInteger and ContinuedFraction.
+const (
+	maxTrialDivisor      = 1000
+	maxPollardRhoSteps   = 1 << 20
+	maxEllipticCurves    = 64
+	ellipticCurveBound   = 2000
+	maxContinuedFraction = 1 << 16
+)
+
+// The smallest prime larger than n.
+func NextPrime(n Expr) FunctionApplication {
+	return Function(nextPrimeName, n)
+}
+
+// Euler's totient function, i.e. the number of integers in [1, n] coprime to n.
+func EulerPhi(n Expr) FunctionApplication {
+	return Function(eulerPhiName, n)
+}
+
+/*
+The Möbius function, which is zero if n has a square factor and
+otherwise (-1)^k for n with k prime factors.
+*/
+func MoebiusMu(n Expr) FunctionApplication {
+	return Function(moebiusMuName, n)
+}
+
+// The least common multiple of the integers a and b.
+func LCM(a, b Expr) FunctionApplication {
+	return Function(lcmName, a, b)
+}
+
+// The Jacobi symbol (a/n) for odd positive n, which is the Legendre symbol when n is prime.
+func JacobiSymbol(a, n Expr) FunctionApplication {
+	return Function(jacobiSymbolName, a, n)
+}
+
+// The inverse of a modulo m, i.e. the b in [0, m) with a*b = 1 (mod m).
+func ModularInverse(a, m Expr) FunctionApplication {
+	return Function(modularInverseName, a, m)
+}
+
+/*
+Returns the value of a number theoretic function at integer arguments
+where it is defined, as long as the value fits in an int64.
+*/
+func numberTheoreticFunction(f FunctionApplication) (Expr, bool) {
+	args := make([]int64, len(f.Args))
+	for ix, arg := range f.Args {
+		n, ok := arg.(integer)
+		if !ok {
+			return nil, false
+		}
+		args[ix] = n.value
+	}
+
+	var value int64
+	ok := false
+	switch {
+	case f.Name == nextPrimeName && len(args) == 1:
+		value, ok = nextPrime(args[0])
+	case f.Name == eulerPhiName && len(args) == 1:
+		value, ok = eulerPhi(args[0])
+	case f.Name == moebiusMuName && len(args) == 1:
+		value, ok = moebiusMu(args[0])
+	case f.Name == lcmName && len(args) == 2:
+		value, ok = lcm(args[0], args[1])
+	case f.Name == jacobiSymbolName && len(args) == 2:
+		value, ok = jacobiSymbol(args[0], args[1])
+	case f.Name == modularInverseName && len(args) == 2:
+		value, ok = modularInverse(args[0], args[1])
+	}
+	if !ok {
+		return nil, false
+	}
+	return Int(value), true
+}
+
+// Witnesses making Miller-Rabin deterministic for all n < 2^64.
+var millerRabinBases = []int64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37}
+
+/*
+Returns true if n is prime. The Miller-Rabin test is run with the
+first twelve primes as bases, which is known to give no false
+positives below 2^64, so the answer is always correct.
+*/
+func IsPrime(n int64) bool {
+	if n < 2 {
+		return false
+	}
+	for _, p := range millerRabinBases {
+		if n%p == 0 {
+			return n == p
+		}
+	}
+
+	// n - 1 = d * 2^s with d odd
+	d, s := n-1, 0
+	for d%2 == 0 {
+		d /= 2
+		s++
+	}
+	for _, a := range millerRabinBases {
+		x := powMod(a, d, n)
+		if x == 1 || x == n-1 {
+			continue
+		}
+		composite := true
+		for i := 1; i < s && composite; i++ {
+			x = mulMod(x, x, n)
+			composite = x != n-1
+		}
+		if composite {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Returns g = gcd(a, b) >= 0 together with x and y such that
+a*x + b*y = g.
+
+The exception is gcd(a, b) = 2^63, which int64 cannot hold, i.e. when
+a and b are math.MinInt64 or zero and not both zero. Then g is
+math.MinInt64, and a*x + b*y = g holds in wrapped int64 arithmetic.
+*/
+func ExtendedGCD(a, b int64) (int64, int64, int64) {
+	oldR, r := a, b
+	oldX, x := int64(1), int64(0)
+	oldY, y := int64(0), int64(1)
+	for r != 0 {
+		q := oldR / r
+		oldR, r = r, oldR-q*r
+		oldX, x = x, oldX-q*x
+		oldY, y = y, oldY-q*y
+	}
+	if oldR < 0 {
+		return -oldR, -oldX, -oldY
+	}
+	return oldR, oldX, oldY
+}
+
+// A prime power Prime^Exponent in the factorization of an integer.
+type PrimePower struct {
+	Prime, Exponent int64
+}
+
+/*
+Returns the prime factorization of n in increasing order of the
+primes. For negative n the first factor is -1, e.g. -12 = -1 * 2^2 * 3.
+
+Small factors are found by trial division, and the remaining
+cofactor is split by Pollard's rho method with Brent's cycle
+detection. Should that fail, Lenstra's elliptic curve method is
+tried with a fixed set of curves and a small smoothness bound,
+which is enough for the semiprimes int64 can hold.
+*/
+func FactorInteger(n int64) ([]PrimePower, error) {
+	if n == 0 {
+		return nil, &InvalidArgumentError{Function: "FactorInteger", Arg: Int(0)}
+	}
+	factors := []PrimePower{}
+	if n < 0 {
+		factors = append(factors, PrimePower{Prime: -1, Exponent: 1})
+	}
+
+	// The absolute value of the smallest int64 does not fit in
+	// an int64, so one factor 2 is removed first
+	m := n
+	if n%2 == 0 {
+		m /= 2
+	}
+	if m < 0 {
+		m = -m
+	}
+	primes := map[int64]int64{}
+	if n%2 == 0 {
+		primes[2]++
+	}
+
+	for p := int64(2); p < maxTrialDivisor && p*p <= m; p++ {
+		for m%p == 0 {
+			primes[p]++
+			m /= p
+		}
+	}
+	if err := splitFactor(m, primes); err != nil {
+		return nil, err
+	}
+
+	sorted := make([]int64, 0, len(primes))
+	for p := range primes {
+		sorted = append(sorted, p)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, p := range sorted {
+		factors = append(factors, PrimePower{Prime: p, Exponent: primes[p]})
+	}
+	return factors, nil
+}
+
+// Adds the prime factors of m to primes by recursively splitting it.
+func splitFactor(m int64, primes map[int64]int64) error {
+	switch {
+	case m == 1:
+		return nil
+	case IsPrime(m):
+		primes[m]++
+		return nil
+	}
+	d, ok := pollardRho(m)
+	if !ok {
+		d, ok = ellipticCurveFactor(m)
+	}
+	if !ok {
+		return &ConvergenceError{Method: "FactorInteger", Iterations: maxPollardRhoSteps}
+	}
+	if err := splitFactor(d, primes); err != nil {
+		return err
+	}
+	return splitFactor(m/d, primes)
+}
+
+/*
+Returns a non-trivial factor of the odd composite m by Pollard's rho
+method with Brent's cycle detection, trying the polynomials x^2 + c
+for a few c. The differences are multiplied together in batches so
+that only one gcd is computed per batch.
+
+See [1] algorithm 5.2.1.
+
+[1] CRANDALL, Richard; POMERANCE, Carl. Prime numbers: A computational perspective. Springer, 2005.
+*/
+func pollardRho(m int64) (int64, bool) {
+	const batch = 128
+	for c := int64(1); c < 16; c++ {
+		f := func(x int64) int64 { return addMod(mulMod(x, x, m), c, m) }
+		y, g, r := int64(2), int64(1), 1
+		var x, ys int64
+		for steps := 0; g == 1 && steps < maxPollardRhoSteps; r *= 2 {
+			x = y
+			for i := 0; i < r; i++ {
+				y = f(y)
+			}
+			for k := 0; k < r && g == 1; k += batch {
+				ys = y
+				q := int64(1)
+				for i := 0; i < min(batch, r-k); i++ {
+					y = f(y)
+					q = mulMod(q, absDiff(x, y), m)
+				}
+				g, _, _ = ExtendedGCD(q, m)
+				steps += batch
+			}
+		}
+		// The batch overshot, so the last one is redone step by step
+		if g == m {
+			g = 1
+			for g == 1 {
+				ys = f(ys)
+				g, _, _ = ExtendedGCD(absDiff(x, ys), m)
+			}
+		}
+		if g != 1 && g != m {
+			return g, true
+		}
+	}
+	return 0, false
+}
+
+func absDiff(a, b int64) int64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// A point on an elliptic curve modulo m, where inf marks the point at infinity.
+type curvePoint struct {
+	x, y int64
+	inf  bool
+}
+
+/*
+Returns a non-trivial factor of m by Lenstra's elliptic curve
+method. On the curves y^2 = x^3 + a*x + b through (1, 1) the point is
+multiplied by every prime power below ellipticCurveBound. A factor
+is found when a slope has a denominator sharing a factor with m,
+which happens when the order of the curve modulo a prime factor of
+m is smooth.
+*/
+func ellipticCurveFactor(m int64) (int64, bool) {
+	for a := int64(1); a <= maxEllipticCurves; a++ {
+		point := curvePoint{x: 1, y: 1}
+		for q := int64(2); q < ellipticCurveBound; q++ {
+			if !IsPrime(q) {
+				continue
+			}
+			k := q
+			for k*q < ellipticCurveBound {
+				k *= q
+			}
+			var d int64
+			point, d = curveMul(point, k, a, m)
+			if d != 1 && d != m {
+				return d, true
+			} else if d == m || point.inf {
+				break
+			}
+		}
+	}
+	return 0, false
+}
+
+/*
+Returns k*p on the curve with coefficient a modulo m by double-and-add.
+If a slope can not be computed the gcd of its denominator and m is
+returned, and otherwise 1.
+*/
+func curveMul(p curvePoint, k, a, m int64) (curvePoint, int64) {
+	result := curvePoint{inf: true}
+	for ; k > 0; k >>= 1 {
+		var d int64
+		if k&1 == 1 {
+			if result, d = curveAdd(result, p, a, m); d != 1 {
+				return result, d
+			}
+		}
+		if p, d = curveAdd(p, p, a, m); d != 1 {
+			return p, d
+		}
+	}
+	return result, 1
+}
+
+func curveAdd(p, q curvePoint, a, m int64) (curvePoint, int64) {
+	switch {
+	case p.inf:
+		return q, 1
+	case q.inf:
+		return p, 1
+	case p.x == q.x && addMod(p.y, q.y, m) == 0:
+		return curvePoint{inf: true}, 1
+	}
+
+	var num, den int64
+	if p.x == q.x {
+		num = addMod(mulMod(3, mulMod(p.x, p.x, m), m), reduceMod(a, m), m)
+		den = addMod(p.y, p.y, m)
+	} else {
+		num = addMod(q.y, m-p.y, m)
+		den = addMod(q.x, m-p.x, m)
+	}
+	g, inv, _ := ExtendedGCD(den, m)
+	if g != 1 {
+		return curvePoint{}, g
+	}
+	slope := mulMod(num, reduceMod(inv, m), m)
+	x := addMod(mulMod(slope, slope, m), m-addMod(p.x, q.x, m), m)
+	y := addMod(mulMod(slope, addMod(p.x, m-x, m), m), m-p.y, m)
+	return curvePoint{x: x, y: y}, 1
+}
+
+/*
+Returns the positive divisors of n in increasing order, which are
+generated from the prime factorization of n.
+*/
+func Divisors(n int64) ([]int64, error) {
+	factors, err := FactorInteger(n)
+	if err != nil {
+		return nil, err
+	}
+	divisors := []int64{1}
+	for _, f := range factors {
+		if f.Prime < 0 {
+			continue
+		}
+		count := len(divisors)
+		power := int64(1)
+		for e := int64(1); e <= f.Exponent; e++ {
+			power *= f.Prime
+			for _, d := range divisors[:count] {
+				divisors = append(divisors, d*power)
+			}
+		}
+	}
+	sort.Slice(divisors, func(i, j int) bool { return divisors[i] < divisors[j] })
+	return divisors, nil
+}
+
+func nextPrime(n int64) (int64, bool) {
+	if n < 2 {
+		return 2, true
+	}
+	for p := n + 1; p > n; p++ {
+		if IsPrime(p) {
+			return p, true
+		}
+	}
+	// The addition overflowed
+	return 0, false
+}
+
+func modularInverse(a, m int64) (int64, bool) {
+	if m < 1 {
+		return 0, false
+	}
+	return invMod(a, m)
+}
+
+func eulerPhi(n int64) (int64, bool) {
+	if n < 1 {
+		return 0, false
+	}
+	factors, err := FactorInteger(n)
+	if err != nil {
+		return 0, false
+	}
+	phi := n
+	for _, f := range factors {
+		phi = phi / f.Prime * (f.Prime - 1)
+	}
+	return phi, true
+}
+
+func moebiusMu(n int64) (int64, bool) {
+	if n < 1 {
+		return 0, false
+	}
+	factors, err := FactorInteger(n)
+	if err != nil {
+		return 0, false
+	}
+	mu := int64(1)
+	for _, f := range factors {
+		if f.Exponent > 1 {
+			return 0, true
+		}
+		mu = -mu
+	}
+	return mu, true
+}
+
+func lcm(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	x, y := new(big.Int).Abs(big.NewInt(a)), new(big.Int).Abs(big.NewInt(b))
+	result := new(big.Int).Mul(x, y)
+	result.Quo(result, new(big.Int).GCD(nil, nil, x, y))
+	if !result.IsInt64() {
+		return 0, false
+	}
+	return result.Int64(), true
+}
+
+/*
+Computes the Jacobi symbol (a/n) by quadratic reciprocity, which
+needs no factorization of n. Only odd positive n are allowed.
+*/
+func jacobiSymbol(a, n int64) (int64, bool) {
+	if n <= 0 || n%2 == 0 {
+		return 0, false
+	}
+	a = reduceMod(a, n)
+	result := int64(1)
+	for a != 0 {
+		for a%2 == 0 {
+			a /= 2
+			if r := n % 8; r == 3 || r == 5 {
+				result = -result
+			}
+		}
+		a, n = n, a
+		if a%4 == 3 && n%4 == 3 {
+			result = -result
+		}
+		a %= n
+	}
+	if n != 1 {
+		return 0, true
+	}
+	return result, true
+}
+
+/*
+Returns the first terms of the simple continued fraction expansion
+[a_0; a_1, a_2, ...] of x. The expansion is exact for rationals,
+where it is finite, and for quadratic irrationals a + b*c^(1/2),
+where it is eventually periodic, e.g. 2^(1/2) = [1; 2, 2, 2, ...].
+*/
+func ContinuedFraction(x Expr, terms int) ([]int64, error) {
+	x = Expand(x)
+	if r, ok := x.(rational); ok {
+		return rationalContinuedFraction(r, terms), nil
+	}
+	a, b, c, ok := surdParts(x)
+	if !ok {
+		return nil, &NoClosedFormError{Operation: "continued fraction", Expr: x}
+	}
+	return surdContinuedFraction(a, b, c, terms)
+}
+
+func rationalContinuedFraction(r rational, terms int) []int64 {
+	num, den := r.numerator().value, r.denominator().value
+	result := []int64{}
+	for len(result) < terms && den != 0 {
+		q := num / den
+		if num%den != 0 && num < 0 {
+			q--
+		}
+		result = append(result, q)
+		num, den = den, num-q*den
+	}
+	return result
+}
+
+// Returns a, b and c if x = a + b*c^(1/2), where c^(1/2) is irrational.
+func surdParts(x Expr) (rational, rational, rational, bool) {
+	if a, b, c, ok := quadraticSurd(x); ok {
+		return a, b, c, true
+	}
+	a, b, c, ok := quadraticSurd(Add(Int(0), x))
+	if !ok || !Equal(a, Int(0)) {
+		return nil, nil, nil, false
+	}
+	return a, b, c, true
+}
+
+/*
+Expands the quadratic irrational x = a + b*c^(1/2). With x written
+as (P + D^(1/2))/Q, where Q divides D - P^2, the terms and the next
+complete quotients are given by
+
+	a_k = floor((P_k + D^(1/2))/Q_k)
+	P_{k+1} = a_k*Q_k - P_k
+	Q_{k+1} = (D - P_{k+1}^2)/Q_k
+
+See [1] chapter 7.7.
+
+[1] NIVEN, Ivan; ZUCKERMAN, Herbert S.; MONTGOMERY, Hugh L. An introduction to the theory of numbers. John Wiley & Sons, 1991.
+*/
+func surdContinuedFraction(a, b, c rational, terms int) ([]int64, error) {
+	ratOf := func(r rational) *big.Rat {
+		return new(big.Rat).SetFrac64(r.numerator().value, r.denominator().value)
+	}
+	ra, rb, rc := ratOf(a), ratOf(b), ratOf(c)
+
+	// x = (u + v*n^(1/2))/w with integers, where c = n/d and
+	// c^(1/2) = (n*d)^(1/2)/d
+	d := rc.Denom()
+	w := new(big.Int).Mul(ra.Denom(), rb.Denom())
+	w.Mul(w, d)
+	u := new(big.Int).Mul(ra.Num(), new(big.Int).Quo(w, ra.Denom()))
+	v := new(big.Int).Mul(rb.Num(), new(big.Int).Quo(w, new(big.Int).Mul(rb.Denom(), d)))
+	n := new(big.Int).Mul(rc.Num(), d)
+
+	// (P + D^(1/2))/Q with D = v^2*n and the sign of v moved to Q,
+	// after which both are scaled by |Q| so that Q divides D - P^2
+	P, Q := new(big.Int).Set(u), new(big.Int).Set(w)
+	if v.Sign() < 0 {
+		P.Neg(P)
+		Q.Neg(Q)
+	}
+	D := new(big.Int).Mul(v, v)
+	D.Mul(D, n)
+	absQ := new(big.Int).Abs(Q)
+	P.Mul(P, absQ)
+	D.Mul(D, absQ).Mul(D, absQ)
+	Q.Mul(Q, absQ)
+
+	sqrtD := new(big.Int).Sqrt(D)
+	result := []int64{}
+	for len(result) < min(terms, maxContinuedFraction) {
+		// As D is not a square, floor((P + D^(1/2))/Q) is floor((P + s)/Q)
+		// for Q > 0 and floor((-P - s - 1)/(-Q)) for Q < 0, where s is the
+		// integer square root of D
+		num := new(big.Int).Add(P, sqrtD)
+		den := new(big.Int).Set(Q)
+		if Q.Sign() < 0 {
+			num.Neg(num).Sub(num, big.NewInt(1))
+			den.Neg(den)
+		}
+		term := new(big.Int).Div(num, den)
+		if !term.IsInt64() {
+			return nil, &ModulusOverflowError{}
+		}
+		result = append(result, term.Int64())
+
+		P.Sub(new(big.Int).Mul(term, Q), P)
+		next := new(big.Int).Mul(P, P)
+		Q.Quo(next.Sub(D, next), Q)
+	}
+	return result, nil
+}
+
+/*
+Returns the value [a_0; a_1, ..., a_n] of a finite continued fraction,
+or undefined if it does not fit in an int64 fraction.
+*/
+func FromContinuedFraction(terms []int64) Expr {
+	if len(terms) == 0 {
+		return Undefined()
+	}
+	value := new(big.Rat).SetInt64(terms[len(terms)-1])
+	for ix := len(terms) - 2; ix >= 0; ix-- {
+		if value.Sign() == 0 {
+			return Undefined()
+		}
+		value.Inv(value).Add(value, new(big.Rat).SetInt64(terms[ix]))
+	}
+	r, ok := bigRatToRational(value)
+	if !ok {
+		return Undefined()
+	}
+	return r
+}
@@ -0,0 +1,179 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestIsPrime(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          int64
+		expectedOutput bool
+	}{
+		{name: "One", input: 1, expectedOutput: false},
+		{name: "Two", input: 2, expectedOutput: true},
+		{name: "Negative", input: -7, expectedOutput: false},
+		{name: "Carmichael number", input: 561, expectedOutput: false},
+		{name: "Small prime", input: 7919, expectedOutput: true},
+		{name: "Strong pseudoprime to the bases 2, 3, 5 and 7", input: 3215031751, expectedOutput: false},
+		{name: "Square of a large prime", input: 3037000493 * 3037000493, expectedOutput: false},
+		{name: "Largest int64 prime", input: 9223372036854775783, expectedOutput: true},
+		{name: "Largest int64", input: math.MaxInt64, expectedOutput: false},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			if result := IsPrime(test.input); result != test.expectedOutput {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestFactorInteger(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          int64
+		expectedOutput []PrimePower
+	}{
+		{name: "One", input: 1, expectedOutput: []PrimePower{}},
+		{name: "Small factors", input: 360, expectedOutput: []PrimePower{{2, 3}, {3, 2}, {5, 1}}},
+		{name: "Negative", input: -12, expectedOutput: []PrimePower{{-1, 1}, {2, 2}, {3, 1}}},
+		{name: "Prime", input: 1000000007, expectedOutput: []PrimePower{{1000000007, 1}}},
+		{name: "Semiprime", input: 1000000007 * 998244353, expectedOutput: []PrimePower{{998244353, 1}, {1000000007, 1}}},
+		{name: "Square of a large prime", input: 3037000493 * 3037000493, expectedOutput: []PrimePower{{3037000493, 2}}},
+		{name: "Smallest int64", input: math.MinInt64, expectedOutput: []PrimePower{{-1, 1}, {2, 63}}},
+		{name: "Mixed", input: 2 * 2 * 1009 * 1000003 * 1000033, expectedOutput: []PrimePower{{2, 2}, {1009, 1}, {1000003, 1}, {1000033, 1}}},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := FactorInteger(test.input)
+			if err != nil || !reflect.DeepEqual(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v (error %v)", test.name, test.input, test.expectedOutput, result, err)
+			}
+		})
+	}
+
+	var argErr *InvalidArgumentError
+	if _, err := FactorInteger(0); !errors.As(err, &argErr) {
+		t.Errorf("Following test failed: zero\nExpected: InvalidArgumentError\nGot: %v", err)
+	}
+}
+
+func TestEllipticCurveFactor(t *testing.T) {
+	n := int64(1000003 * 1000033)
+	d, ok := ellipticCurveFactor(n)
+	if !ok || (d != 1000003 && d != 1000033) {
+		t.Errorf("Following test failed: elliptic curve method\nInput: %v\nExpected: 1000003 or 1000033\nGot: %v", n, d)
+	}
+}
+
+func TestDivisorsAndExtendedGCD(t *testing.T) {
+	if result, _ := Divisors(-12); !reflect.DeepEqual(result, []int64{1, 2, 3, 4, 6, 12}) {
+		t.Errorf("Following test failed: divisors\nInput: %v\nExpected: %v\nGot: %v", -12, []int64{1, 2, 3, 4, 6, 12}, result)
+	}
+	if g, x, y := ExtendedGCD(240, -46); g != 2 || 240*x-46*y != g {
+		t.Errorf("Following test failed: extended GCD\nInput: %v\nExpected: %v\nGot: %v (x = %v, y = %v)", "240, -46", 2, g, x, y)
+	}
+
+	// The gcd is not negative unless it is 2^63
+	for _, input := range [][2]int64{{math.MinInt64, 3}, {6, math.MinInt64}, {math.MinInt64, -2}, {math.MinInt64, math.MaxInt64}} {
+		if g, x, y := ExtendedGCD(input[0], input[1]); g <= 0 || input[0]*x+input[1]*y != g {
+			t.Errorf("Following test failed: extended GCD\nInput: %v\nExpected: a positive gcd\nGot: %v (x = %v, y = %v)", input, g, x, y)
+		}
+	}
+	for _, input := range [][2]int64{{math.MinInt64, 0}, {0, math.MinInt64}, {math.MinInt64, math.MinInt64}} {
+		if g, x, y := ExtendedGCD(input[0], input[1]); g != math.MinInt64 || input[0]*x+input[1]*y != g {
+			t.Errorf("Following test failed: extended GCD\nInput: %v\nExpected: %v\nGot: %v (x = %v, y = %v)", input, int64(math.MinInt64), g, x, y)
+		}
+	}
+}
+
+func TestNumberTheoreticFunctions(t *testing.T) {
+	x := Var("x")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput Expr
+	}{
+		{name: "NextPrime", input: NextPrime(Int(13)), expectedOutput: Int(17)},
+		{name: "NextPrime of a negative number", input: NextPrime(Int(-5)), expectedOutput: Int(2)},
+		{name: "EulerPhi", input: EulerPhi(Int(36)), expectedOutput: Int(12)},
+		{name: "EulerPhi of a prime", input: EulerPhi(Int(1000000007)), expectedOutput: Int(1000000006)},
+		{name: "Square free MoebiusMu", input: MoebiusMu(Int(30)), expectedOutput: Int(-1)},
+		{name: "MoebiusMu with a square factor", input: MoebiusMu(Int(12)), expectedOutput: Int(0)},
+		{name: "LCM", input: LCM(Int(4), Int(-6)), expectedOutput: Int(12)},
+		{name: "JacobiSymbol", input: JacobiSymbol(Int(1001), Int(9907)), expectedOutput: Int(-1)},
+		{name: "JacobiSymbol with a common factor", input: JacobiSymbol(Int(3), Int(21)), expectedOutput: Int(0)},
+		{name: "JacobiSymbol of an even modulus", input: JacobiSymbol(Int(2), Int(4)), expectedOutput: JacobiSymbol(Int(2), Int(4))},
+		{name: "ModularInverse", input: ModularInverse(Int(3), Int(7)), expectedOutput: Int(5)},
+		{name: "ModularInverse of a zero divisor", input: ModularInverse(Int(2), Int(4)), expectedOutput: ModularInverse(Int(2), Int(4))},
+		{name: "Symbolic argument", input: EulerPhi(x), expectedOutput: EulerPhi(x)},
+		{name: "Arguments are simplified first", input: EulerPhi(Add(Int(5), Int(5))), expectedOutput: Int(4)},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := test.input.Simplify()
+			if !Equal(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+
+	if !Ask(EulerPhi(x), IsPositive|IsInteger) {
+		t.Errorf("Following test failed: EulerPhi is a positive integer\nInput: %v\nExpected: %v\nGot: %v", EulerPhi(x), true, false)
+	}
+	f, err := CompileNumeric(LCM(x, Int(6)), []variable{x})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := f([]float64{4}); value != 12 {
+		t.Errorf("Following test failed: numeric LCM\nInput: %v\nExpected: %v\nGot: %v", LCM(x, Int(6)), 12, value)
+	}
+	if value := f([]float64{4.5}); !math.IsNaN(value) {
+		t.Errorf("Following test failed: numeric LCM of a non-integer\nInput: %v\nExpected: %v\nGot: %v", LCM(x, Int(6)), math.NaN(), value)
+	}
+}
+
+func TestContinuedFraction(t *testing.T) {
+	half := Div(Int(1), Int(2))
+
+	tests := []struct {
+		name           string
+		input          Expr
+		terms          int
+		expectedOutput []int64
+	}{
+		{name: "Rational", input: Div(Int(415), Int(93)), terms: 10, expectedOutput: []int64{4, 2, 6, 7}},
+		{name: "Negative rational", input: Div(Int(-7), Int(3)), terms: 10, expectedOutput: []int64{-3, 1, 2}},
+		{name: "Truncated rational", input: Div(Int(415), Int(93)), terms: 2, expectedOutput: []int64{4, 2}},
+		{name: "Square root of two", input: Sqrt(Int(2)), terms: 5, expectedOutput: []int64{1, 2, 2, 2, 2}},
+		{name: "Golden ratio", input: Div(Add(Int(1), Sqrt(Int(5))), Int(2)), terms: 5, expectedOutput: []int64{1, 1, 1, 1, 1}},
+		{name: "Negative surd", input: Sub(Int(3), Mul(Div(Int(2), Int(3)), Pow(Int(7), half))), terms: 8, expectedOutput: []int64{1, 4, 4, 3, 1, 2, 1, 3}},
+		{name: "Negative square root of a fraction", input: Neg(Pow(Div(Int(3), Int(2)), half)), terms: 8, expectedOutput: []int64{-2, 1, 3, 2, 4, 2, 4, 2}},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := ContinuedFraction(test.input, test.terms)
+			if err != nil || !reflect.DeepEqual(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v (error %v)", test.name, test.input, test.expectedOutput, result, err)
+			}
+		})
+	}
+
+	if result := FromContinuedFraction([]int64{4, 2, 6, 7}); !Equal(result, Div(Int(415), Int(93)).Simplify()) {
+		t.Errorf("Following test failed: convergent\nInput: %v\nExpected: %v\nGot: %v", []int64{4, 2, 6, 7}, "415/93", result)
+	}
+	var closedFormErr *NoClosedFormError
+	if _, err := ContinuedFraction(PI, 5); !errors.As(err, &closedFormErr) {
+		t.Errorf("Following test failed: transcendental number\nExpected: NoClosedFormError\nGot: %v", err)
+	}
+}
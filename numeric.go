@@ -0,0 +1,492 @@
+package gosymbol
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"reflect"
+)
+
+// A NumericFunc is an expression compiled into a plain
+// float64 function. The values in x are bound to the
+// variables in the order they were given to CompileNumeric.
+type NumericFunc func(x []float64) float64
+
+/*
+Compiles expr into a NumericFunc of the variables in vars. The
+expression tree is only traversed once, so the returned function
+is cheap to call repeatedly, e.g. inside an iterative solver.
+
+PI is replaced by math.Pi unless it is explicitly listed in vars.
+An UnboundVariableError is returned if expr contains any other
+variable not present in vars.
+*/
+func CompileNumeric(expr Expr, vars []variable) (NumericFunc, error) {
+	index := make(map[VarName]int, len(vars))
+	for ix, v := range vars {
+		if _, ok := index[v.Name]; ok {
+			return nil, &DuplicateArgumentError{}
+		}
+		index[v.Name] = ix
+	}
+	return compileNumeric(expr, index)
+}
+
+/*
+Numerically evaluates expr with the values in args. It is
+a convenience wrapper around CompileNumeric for one-off
+evaluations.
+*/
+func EvalFloat(expr Expr, args map[VarName]float64) (float64, error) {
+	vars := make([]variable, 0, len(args))
+	values := make([]float64, 0, len(args))
+	for name, value := range args {
+		vars = append(vars, Var(name))
+		values = append(values, value)
+	}
+	f, err := CompileNumeric(expr, vars)
+	if err != nil {
+		return math.NaN(), err
+	}
+	return f(values), nil
+}
+
+func compileNumeric(expr Expr, index map[VarName]int) (NumericFunc, error) {
+	switch e := expr.(type) {
+	case undefined:
+		return func(x []float64) float64 { return math.NaN() }, nil
+	case rational:
+		value := e.approx()
+		return func(x []float64) float64 { return value }, nil
+	case variable:
+		if ix, ok := index[e.Name]; ok {
+			return func(x []float64) float64 { return x[ix] }, nil
+		} else if e.Name == PI.Name {
+			return func(x []float64) float64 { return math.Pi }, nil
+		}
+		return nil, &UnboundVariableError{Name: e.Name}
+	case constrainedVariable:
+		if ix, ok := index[e.Name]; ok {
+			return func(x []float64) float64 { return x[ix] }, nil
+		}
+		return nil, &UnboundVariableError{Name: e.Name}
+	case add:
+		ops, err := compileNumericOperands(e, index)
+		if err != nil {
+			return nil, err
+		}
+		return func(x []float64) float64 {
+			sum := 0.0
+			for _, op := range ops {
+				sum += op(x)
+			}
+			return sum
+		}, nil
+	case mul:
+		ops, err := compileNumericOperands(e, index)
+		if err != nil {
+			return nil, err
+		}
+		return func(x []float64) float64 {
+			prod := 1.0
+			for _, op := range ops {
+				prod *= op(x)
+			}
+			return prod
+		}, nil
+	case pow:
+		base, err := compileNumeric(e.Base, index)
+		if err != nil {
+			return nil, err
+		}
+		// Small integer exponents are by far the most common
+		// case, and repeated multiplication is both faster and
+		// more accurate than math.Pow for those.
+		if n, ok := e.Exponent.(integer); ok && n.value >= -4 && n.value <= 4 {
+			k := n.value
+			return func(x []float64) float64 {
+				b := base(x)
+				result := 1.0
+				for ix := int64(0); ix < k || ix < -k; ix++ {
+					result *= b
+				}
+				if k < 0 {
+					return 1 / result
+				}
+				return result
+			}, nil
+		}
+		exponent, err := compileNumeric(e.Exponent, index)
+		if err != nil {
+			return nil, err
+		}
+		return func(x []float64) float64 { return math.Pow(base(x), exponent(x)) }, nil
+	case exp:
+		arg, err := compileNumeric(e.Arg, index)
+		if err != nil {
+			return nil, err
+		}
+		return func(x []float64) float64 { return math.Exp(arg(x)) }, nil
+	case log:
+		arg, err := compileNumeric(e.Arg, index)
+		if err != nil {
+			return nil, err
+		}
+		return func(x []float64) float64 { return math.Log(arg(x)) }, nil
+	case sqrt:
+		arg, err := compileNumeric(e.Arg, index)
+		if err != nil {
+			return nil, err
+		}
+		return func(x []float64) float64 { return math.Sqrt(arg(x)) }, nil
+	case sin:
+		arg, err := compileNumeric(e.Arg, index)
+		if err != nil {
+			return nil, err
+		}
+		return func(x []float64) float64 { return math.Sin(arg(x)) }, nil
+	case cos:
+		arg, err := compileNumeric(e.Arg, index)
+		if err != nil {
+			return nil, err
+		}
+		return func(x []float64) float64 { return math.Cos(arg(x)) }, nil
+	case FunctionApplication:
+		if value, ok := rootOfValue(e); ok {
+			if imag(value) != 0 {
+				return nil, &NotRealRootError{Expr: e}
+			}
+			return func(x []float64) float64 { return real(value) }, nil
+		}
+		if f, ok := specialBinaryFunctions[e.Name]; ok && len(e.Args) == 2 {
+			args, err := compileNumericOperands(e, index)
+			if err != nil {
+				return nil, err
+			}
+			return func(x []float64) float64 { return f(args[0](x), args[1](x)) }, nil
+		}
+		f, ok := specialFunctions[e.Name]
+		if !ok || len(e.Args) != 1 {
+			return nil, &UnboundVariableError{Name: e.Name}
+		}
+		arg, err := compileNumeric(e.Args[0], index)
+		if err != nil {
+			return nil, err
+		}
+		return func(x []float64) float64 { return f(arg(x)) }, nil
+	case derivative:
+		return nil, &NotNumericError{Expr: e}
+	default:
+		errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e))
+		panic(errMsg)
+	}
+}
+
+// The numeric values of the special undefined functions.
+var specialFunctions = map[VarName]func(float64) float64{
+	diracDeltaName: func(x float64) float64 {
+		if x == 0 {
+			return math.Inf(1)
+		}
+		return 0
+	},
+	heavisideName: func(x float64) float64 {
+		switch {
+		case x > 0:
+			return 1
+		case x < 0:
+			return 0
+		}
+		return 0.5
+	},
+	rectName: func(x float64) float64 {
+		switch {
+		case math.Abs(x) < 0.5:
+			return 1
+		case math.Abs(x) > 0.5:
+			return 0
+		}
+		return 0.5
+	},
+	sincName: func(x float64) float64 {
+		if x == 0 {
+			return 1
+		}
+		return math.Sin(x) / x
+	},
+	factorialName: func(x float64) float64 {
+		return math.Gamma(x + 1)
+	},
+	gammaName: math.Gamma,
+	reName: func(x float64) float64 {
+		return x
+	},
+	imName: func(x float64) float64 {
+		return 0
+	},
+	conjugateName: func(x float64) float64 {
+		return x
+	},
+	argName: func(x float64) float64 {
+		if x < 0 {
+			return math.Pi
+		}
+		return 0
+	},
+	absName:       math.Abs,
+	nextPrimeName: integerFunction(nextPrime),
+	eulerPhiName:  integerFunction(eulerPhi),
+	moebiusMuName: integerFunction(moebiusMu),
+}
+
+// The numeric values of the special undefined functions of two arguments.
+var specialBinaryFunctions = map[VarName]func(float64, float64) float64{
+	binomialName: func(n, k float64) float64 {
+		if n == math.Trunc(n) && k == math.Trunc(k) && n >= 0 && (k < 0 || k > n) {
+			return 0
+		}
+		return math.Gamma(n+1) / (math.Gamma(k+1) * math.Gamma(n-k+1))
+	},
+	lcmName:            integerBinaryFunction(lcm),
+	jacobiSymbolName:   integerBinaryFunction(jacobiSymbol),
+	modularInverseName: integerBinaryFunction(modularInverse),
+}
+
+// Evaluates the integer function f at integer x, and is NaN elsewhere.
+func integerFunction(f func(int64) (int64, bool)) func(float64) float64 {
+	return func(x float64) float64 {
+		n, ok := floatToInt64(x)
+		if !ok {
+			return math.NaN()
+		}
+		value, ok := f(n)
+		if !ok {
+			return math.NaN()
+		}
+		return float64(value)
+	}
+}
+
+func integerBinaryFunction(f func(int64, int64) (int64, bool)) func(float64, float64) float64 {
+	return func(x, y float64) float64 {
+		a, okA := floatToInt64(x)
+		b, okB := floatToInt64(y)
+		if !okA || !okB {
+			return math.NaN()
+		}
+		value, ok := f(a, b)
+		if !ok {
+			return math.NaN()
+		}
+		return float64(value)
+	}
+}
+
+func floatToInt64(x float64) (int64, bool) {
+	if x != math.Trunc(x) || math.Abs(x) >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(x), true
+}
+
+func compileNumericOperands(expr Expr, index map[VarName]int) ([]NumericFunc, error) {
+	ops := make([]NumericFunc, NumberOfOperands(expr))
+	for ix := range ops {
+		op, err := compileNumeric(Operand(expr, ix+1), index)
+		if err != nil {
+			return nil, err
+		}
+		ops[ix] = op
+	}
+	return ops, nil
+}
+
+// A ComplexFunc is an expression compiled into a complex128
+// function, see CompileComplex.
+type ComplexFunc func(z []complex128) complex128
+
+/*
+Compiles expr into a ComplexFunc of the variables in vars, just like
+CompileNumeric but with complex arithmetic. Logarithms, square roots
+and powers use the principal branch, so e.g. I evaluates to 1i.
+
+The special functions of a real argument, e.g. Heaviside, evaluate
+to NaN unless their arguments are real.
+*/
+func CompileComplex(expr Expr, vars []variable) (ComplexFunc, error) {
+	index := make(map[VarName]int, len(vars))
+	for ix, v := range vars {
+		if _, ok := index[v.Name]; ok {
+			return nil, &DuplicateArgumentError{}
+		}
+		index[v.Name] = ix
+	}
+	return compileComplex(expr, index)
+}
+
+/*
+Numerically evaluates expr with the complex values in args. It is
+a convenience wrapper around CompileComplex for one-off evaluations.
+*/
+func EvalComplex(expr Expr, args map[VarName]complex128) (complex128, error) {
+	vars := make([]variable, 0, len(args))
+	values := make([]complex128, 0, len(args))
+	for name, value := range args {
+		vars = append(vars, Var(name))
+		values = append(values, value)
+	}
+	f, err := CompileComplex(expr, vars)
+	if err != nil {
+		return cmplx.NaN(), err
+	}
+	return f(values), nil
+}
+
+func compileComplex(expr Expr, index map[VarName]int) (ComplexFunc, error) {
+	switch e := expr.(type) {
+	case undefined:
+		return func(z []complex128) complex128 { return cmplx.NaN() }, nil
+	case rational:
+		value := complex(e.approx(), 0)
+		return func(z []complex128) complex128 { return value }, nil
+	case variable:
+		if ix, ok := index[e.Name]; ok {
+			return func(z []complex128) complex128 { return z[ix] }, nil
+		} else if e.Name == PI.Name {
+			return func(z []complex128) complex128 { return math.Pi }, nil
+		}
+		return nil, &UnboundVariableError{Name: e.Name}
+	case constrainedVariable:
+		if ix, ok := index[e.Name]; ok {
+			return func(z []complex128) complex128 { return z[ix] }, nil
+		}
+		return nil, &UnboundVariableError{Name: e.Name}
+	case add:
+		ops, err := compileComplexOperands(e, index)
+		if err != nil {
+			return nil, err
+		}
+		return func(z []complex128) complex128 {
+			var sum complex128
+			for _, op := range ops {
+				sum += op(z)
+			}
+			return sum
+		}, nil
+	case mul:
+		ops, err := compileComplexOperands(e, index)
+		if err != nil {
+			return nil, err
+		}
+		return func(z []complex128) complex128 {
+			prod := complex(1, 0)
+			for _, op := range ops {
+				prod *= op(z)
+			}
+			return prod
+		}, nil
+	case pow:
+		// The imaginary unit is exact rather than cmplx.Pow(-1, 0.5)
+		if Equal(e, I) {
+			return func(z []complex128) complex128 { return 1i }, nil
+		}
+		base, err := compileComplex(e.Base, index)
+		if err != nil {
+			return nil, err
+		}
+		if n, ok := e.Exponent.(integer); ok && n.value >= -4 && n.value <= 4 {
+			k := n.value
+			return func(z []complex128) complex128 {
+				b := base(z)
+				result := complex(1, 0)
+				for ix := int64(0); ix < k || ix < -k; ix++ {
+					result *= b
+				}
+				if k < 0 {
+					return 1 / result
+				}
+				return result
+			}, nil
+		}
+		exponent, err := compileComplex(e.Exponent, index)
+		if err != nil {
+			return nil, err
+		}
+		return func(z []complex128) complex128 { return cmplx.Pow(base(z), exponent(z)) }, nil
+	case exp:
+		return compileComplexUnary(e.Arg, index, cmplx.Exp)
+	case log:
+		return compileComplexUnary(e.Arg, index, cmplx.Log)
+	case sqrt:
+		return compileComplexUnary(e.Arg, index, cmplx.Sqrt)
+	case sin:
+		return compileComplexUnary(e.Arg, index, cmplx.Sin)
+	case cos:
+		return compileComplexUnary(e.Arg, index, cmplx.Cos)
+	case FunctionApplication:
+		if value, ok := rootOfValue(e); ok {
+			return func(z []complex128) complex128 { return value }, nil
+		}
+		if f, ok := complexSpecialFunctions[e.Name]; ok && len(e.Args) == 1 {
+			return compileComplexUnary(e.Args[0], index, f)
+		}
+		if f, ok := specialBinaryFunctions[e.Name]; ok && len(e.Args) == 2 {
+			args, err := compileComplexOperands(e, index)
+			if err != nil {
+				return nil, err
+			}
+			return func(z []complex128) complex128 {
+				n, k := args[0](z), args[1](z)
+				if imag(n) != 0 || imag(k) != 0 {
+					return cmplx.NaN()
+				}
+				return complex(f(real(n), real(k)), 0)
+			}, nil
+		}
+		f, ok := specialFunctions[e.Name]
+		if !ok || len(e.Args) != 1 {
+			return nil, &UnboundVariableError{Name: e.Name}
+		}
+		return compileComplexUnary(e.Args[0], index, func(w complex128) complex128 {
+			if imag(w) != 0 {
+				return cmplx.NaN()
+			}
+			return complex(f(real(w)), 0)
+		})
+	case derivative:
+		return nil, &NotNumericError{Expr: e}
+	default:
+		errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e))
+		panic(errMsg)
+	}
+}
+
+// The complex values of the special undefined functions that
+// are defined off the real line.
+var complexSpecialFunctions = map[VarName]func(complex128) complex128{
+	reName:        func(w complex128) complex128 { return complex(real(w), 0) },
+	imName:        func(w complex128) complex128 { return complex(imag(w), 0) },
+	conjugateName: cmplx.Conj,
+	argName:       func(w complex128) complex128 { return complex(cmplx.Phase(w), 0) },
+	absName:       func(w complex128) complex128 { return complex(cmplx.Abs(w), 0) },
+}
+
+func compileComplexUnary(arg Expr, index map[VarName]int, f func(complex128) complex128) (ComplexFunc, error) {
+	compiled, err := compileComplex(arg, index)
+	if err != nil {
+		return nil, err
+	}
+	return func(z []complex128) complex128 { return f(compiled(z)) }, nil
+}
+
+func compileComplexOperands(expr Expr, index map[VarName]int) ([]ComplexFunc, error) {
+	ops := make([]ComplexFunc, NumberOfOperands(expr))
+	for ix := range ops {
+		op, err := compileComplex(Operand(expr, ix+1), index)
+		if err != nil {
+			return nil, err
+		}
+		ops[ix] = op
+	}
+	return ops, nil
+}
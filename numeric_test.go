@@ -0,0 +1,89 @@
+package gosymbol
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCompileNumeric(t *testing.T) {
+	x := Var("x")
+	y := Var("y")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		args           []float64
+		expectedOutput float64
+	}{
+		{
+			name:           "Constant",
+			input:          Div(Int(3), Int(4)),
+			args:           []float64{0, 0},
+			expectedOutput: 0.75,
+		},
+		{
+			name:           "Sum and product of variables",
+			input:          Add(Mul(Int(2), x), y),
+			args:           []float64{3, 4},
+			expectedOutput: 10,
+		},
+		{
+			name:           "Negative integer power",
+			input:          Pow(x, Int(-2)),
+			args:           []float64{2, 0},
+			expectedOutput: 0.25,
+		},
+		{
+			name:           "Non-integer power",
+			input:          Pow(x, Div(Int(1), Int(2))),
+			args:           []float64{9, 0},
+			expectedOutput: 3,
+		},
+		{
+			name:           "Exp and log",
+			input:          Mul(Exp(x), Log(y)),
+			args:           []float64{0, math.E},
+			expectedOutput: 1,
+		},
+		{
+			name:           "PI is replaced by its value",
+			input:          Mul(PI, x),
+			args:           []float64{2, 0},
+			expectedOutput: 2 * math.Pi,
+		},
+		{
+			name:           "Special functions",
+			input:          Add(Heaviside(x), Rect(y), Sinc(x)),
+			args:           []float64{0, 0.5},
+			expectedOutput: 0.5 + 0.5 + 1,
+		},
+		{
+			name:           "Factorial and binomial coefficients",
+			input:          Add(Factorial(x), Binomial(x, y), Binomial(y, x)),
+			args:           []float64{4, 2},
+			expectedOutput: 24 + 6 + 0,
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			f, err := CompileNumeric(test.input, []variable{x, y})
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+
+			result := f(test.args)
+			if math.Abs(result-test.expectedOutput) > 1e-12 {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestCompileNumericUnboundVariable(t *testing.T) {
+	_, err := CompileNumeric(Add(Var("x"), Var("y")), []variable{Var("x")})
+	if _, ok := err.(*UnboundVariableError); !ok {
+		t.Errorf("Expected UnboundVariableError but got: %v", err)
+	}
+}
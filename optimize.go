@@ -0,0 +1,318 @@
+package gosymbol
+
+import (
+	"math"
+)
+
+const (
+	minimizeTolerance     = 1e-10
+	maxMinimizeIterations = 200
+)
+
+// Convergence diagnostics of a numerical minimisation.
+type MinimizeResult struct {
+	Point        []float64
+	Value        float64
+	GradientNorm float64
+	Iterations   int
+	Converged    bool
+	// Either "newton", "bfgs" or "newton+bfgs" depending
+	// on which kind of steps were taken.
+	Method string
+}
+
+/*
+Numerically finds a local minimum of expr w.r.t. vars starting at start.
+
+The gradient and Hessian are derived symbolically with D and compiled
+once. Each iteration takes a Newton step whenever the Hessian is positive
+definite at the current point, otherwise a quasi-Newton step from a BFGS
+approximation of the inverse Hessian. The step lengths are chosen with a
+backtracking line search, so every iteration decreases the value of expr.
+
+A ConvergenceError is returned together with the last iterate if the
+gradient did not vanish within the iteration limit.
+*/
+func Minimize(expr Expr, vars []variable, start []float64) (MinimizeResult, error) {
+	n := len(vars)
+	if len(start) != n {
+		return MinimizeResult{}, &DimensionMismatchError{Expected: n, Got: len(start)}
+	}
+
+	f, err := CompileNumeric(expr, vars)
+	if err != nil {
+		return MinimizeResult{}, err
+	}
+
+	gradient := make([]Expr, n)
+	grad := make([]NumericFunc, n)
+	for ix, v := range vars {
+		gradient[ix] = expr.D(v)
+		grad[ix], err = CompileNumeric(gradient[ix], vars)
+		if err != nil {
+			return MinimizeResult{}, err
+		}
+	}
+
+	// The Hessian is symmetric so only the upper
+	// triangle is derived and compiled
+	hess := make([][]NumericFunc, n)
+	for ix := range hess {
+		hess[ix] = make([]NumericFunc, n)
+		for jx := ix; jx < n; jx++ {
+			hess[ix][jx], err = CompileNumeric(gradient[ix].D(vars[jx]), vars)
+			if err != nil {
+				return MinimizeResult{}, err
+			}
+		}
+	}
+
+	evalGrad := func(x []float64) []float64 {
+		g := make([]float64, n)
+		for ix := range g {
+			g[ix] = grad[ix](x)
+		}
+		return g
+	}
+	evalHess := func(x []float64) [][]float64 {
+		h := make([][]float64, n)
+		for ix := range h {
+			h[ix] = make([]float64, n)
+		}
+		for ix := range h {
+			for jx := ix; jx < n; jx++ {
+				h[ix][jx] = hess[ix][jx](x)
+				h[jx][ix] = h[ix][jx]
+			}
+		}
+		return h
+	}
+
+	x := append([]float64{}, start...)
+	fx := f(x)
+	g := evalGrad(x)
+	invHess := identityMatrix(n)
+	usedNewton, usedBFGS := false, false
+
+	result := func(it int, converged bool) MinimizeResult {
+		method := "newton"
+		if usedBFGS && usedNewton {
+			method = "newton+bfgs"
+		} else if usedBFGS {
+			method = "bfgs"
+		}
+		return MinimizeResult{Point: x, Value: fx, GradientNorm: norm(g), Iterations: it, Converged: converged, Method: method}
+	}
+
+	for it := 1; it <= maxMinimizeIterations; it++ {
+		if norm(g) <= minimizeTolerance*(1+math.Abs(fx)) {
+			return result(it-1, true), nil
+		}
+
+		// Newton direction if the Hessian is positive definite,
+		// otherwise the BFGS quasi-Newton direction
+		var direction []float64
+		if l, ok := cholesky(evalHess(x)); ok {
+			direction = choleskySolve(l, scale(g, -1))
+			usedNewton = true
+		} else {
+			direction = scale(matVec(invHess, g), -1)
+			usedBFGS = true
+		}
+		if dot(direction, g) >= 0 {
+			// Not a descent direction, so we fall back on steepest
+			// descent and restart the inverse Hessian approximation
+			direction = scale(g, -1)
+			invHess = identityMatrix(n)
+		}
+
+		// Backtracking line search with the Armijo condition
+		t := 1.0
+		slope := dot(direction, g)
+		var xNew []float64
+		var fNew float64
+		for ls := 0; ls < 60; ls++ {
+			xNew = axpy(t, direction, x)
+			fNew = f(xNew)
+			if fNew <= fx+1e-4*t*slope {
+				break
+			}
+			t /= 2
+		}
+		if !(fNew <= fx) {
+			// No decrease possible along the direction, we
+			// are as close to the minimum as we can get
+			return result(it, norm(g) <= math.Sqrt(minimizeTolerance)), nil
+		}
+
+		gNew := evalGrad(xNew)
+		s := axpy(-1, x, xNew)
+		y := axpy(-1, g, gNew)
+		bfgsUpdate(invHess, s, y)
+
+		x, fx, g = xNew, fNew, gNew
+		if norm(s) <= machineEpsilon*(1+norm(x)) {
+			return result(it, norm(g) <= math.Sqrt(minimizeTolerance)), nil
+		}
+	}
+
+	if norm(g) <= minimizeTolerance*(1+math.Abs(fx)) {
+		return result(maxMinimizeIterations, true), nil
+	}
+	return result(maxMinimizeIterations, false), &ConvergenceError{Method: "minimize", Iterations: maxMinimizeIterations}
+}
+
+/*
+Updates the inverse Hessian approximation h in place using
+the BFGS formula with step s and gradient change y. The update
+is skipped if the curvature condition y·s > 0 is violated, since
+h would no longer be positive definite.
+*/
+func bfgsUpdate(h [][]float64, s, y []float64) {
+	ys := dot(y, s)
+	if ys <= 0 {
+		return
+	}
+	rho := 1 / ys
+	hy := matVec(h, y)
+	yhy := dot(y, hy)
+	for ix := range h {
+		for jx := range h[ix] {
+			h[ix][jx] += (1+rho*yhy)*rho*s[ix]*s[jx] - rho*(hy[ix]*s[jx]+s[ix]*hy[jx])
+		}
+	}
+}
+
+/* Dense float linear algebra helpers */
+
+func identityMatrix(n int) [][]float64 {
+	m := make([][]float64, n)
+	for ix := range m {
+		m[ix] = make([]float64, n)
+		m[ix][ix] = 1
+	}
+	return m
+}
+
+// Returns the lower triangular L with a = L*L^T,
+// and false if a is not positive definite.
+func cholesky(a [][]float64) ([][]float64, bool) {
+	n := len(a)
+	l := make([][]float64, n)
+	for ix := range l {
+		l[ix] = make([]float64, n)
+	}
+	for ix := 0; ix < n; ix++ {
+		for jx := 0; jx <= ix; jx++ {
+			sum := a[ix][jx]
+			for kx := 0; kx < jx; kx++ {
+				sum -= l[ix][kx] * l[jx][kx]
+			}
+			if ix == jx {
+				if !(sum > 0) {
+					return nil, false
+				}
+				l[ix][ix] = math.Sqrt(sum)
+			} else {
+				l[ix][jx] = sum / l[jx][jx]
+			}
+		}
+	}
+	return l, true
+}
+
+// Solves L*L^T x = b by forward and backward substitution.
+func choleskySolve(l [][]float64, b []float64) []float64 {
+	n := len(b)
+	y := make([]float64, n)
+	for ix := 0; ix < n; ix++ {
+		sum := b[ix]
+		for kx := 0; kx < ix; kx++ {
+			sum -= l[ix][kx] * y[kx]
+		}
+		y[ix] = sum / l[ix][ix]
+	}
+	x := make([]float64, n)
+	for ix := n - 1; ix >= 0; ix-- {
+		sum := y[ix]
+		for kx := ix + 1; kx < n; kx++ {
+			sum -= l[kx][ix] * x[kx]
+		}
+		x[ix] = sum / l[ix][ix]
+	}
+	return x
+}
+
+func matVec(m [][]float64, v []float64) []float64 {
+	result := make([]float64, len(m))
+	for ix := range m {
+		result[ix] = dot(m[ix], v)
+	}
+	return result
+}
+
+func dot(u, v []float64) float64 {
+	sum := 0.0
+	for ix := range u {
+		sum += u[ix] * v[ix]
+	}
+	return sum
+}
+
+func norm(v []float64) float64 {
+	return math.Sqrt(dot(v, v))
+}
+
+func scale(v []float64, a float64) []float64 {
+	result := make([]float64, len(v))
+	for ix := range v {
+		result[ix] = a * v[ix]
+	}
+	return result
+}
+
+// Returns a*x + y.
+func axpy(a float64, x, y []float64) []float64 {
+	result := make([]float64, len(x))
+	for ix := range x {
+		result[ix] = a*x[ix] + y[ix]
+	}
+	return result
+}
+
+// Solves a*x = b by Gaussian elimination with partial
+// pivoting, and returns false if a is singular.
+func gaussSolve(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	m := make([][]float64, n)
+	for ix := range m {
+		m[ix] = append(append(make([]float64, 0, n+1), a[ix]...), b[ix])
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for ix := col + 1; ix < n; ix++ {
+			if math.Abs(m[ix][col]) > math.Abs(m[pivot][col]) {
+				pivot = ix
+			}
+		}
+		if m[pivot][col] == 0 {
+			return nil, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for ix := col + 1; ix < n; ix++ {
+			factor := m[ix][col] / m[col][col]
+			for jx := col; jx <= n; jx++ {
+				m[ix][jx] -= factor * m[col][jx]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for ix := n - 1; ix >= 0; ix-- {
+		sum := m[ix][n]
+		for kx := ix + 1; kx < n; kx++ {
+			sum -= m[ix][kx] * x[kx]
+		}
+		x[ix] = sum / m[ix][ix]
+	}
+	return x, true
+}
@@ -0,0 +1,61 @@
+package gosymbol
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestMinimize(t *testing.T) {
+	x := Var("x")
+	y := Var("y")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		start          []float64
+		expectedOutput []float64
+	}{
+		{
+			name:           "Convex quadratic",
+			input:          Add(Pow(Sub(x, Int(1)), Int(2)), Mul(Int(3), Pow(Add(y, Int(2)), Int(2)))),
+			start:          []float64{0, 0},
+			expectedOutput: []float64{1, -2},
+		},
+		{
+			name: "Rosenbrock function",
+			input: Add(
+				Pow(Sub(Int(1), x), Int(2)),
+				Mul(Int(100), Pow(Sub(y, Pow(x, Int(2))), Int(2))),
+			),
+			start:          []float64{-1.2, 1},
+			expectedOutput: []float64{1, 1},
+		},
+		{
+			name:           "Indefinite Hessian at the start point",
+			input:          Add(Pow(x, Int(4)), Neg(Pow(x, Int(2))), Pow(y, Int(2))),
+			start:          []float64{0.1, 1},
+			expectedOutput: []float64{1 / math.Sqrt2, 0},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := Minimize(test.input, []variable{x, y}, test.start)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+
+			if !result.Converged || math.Abs(result.Point[0]-test.expectedOutput[0]) > 1e-6 || math.Abs(result.Point[1]-test.expectedOutput[1]) > 1e-6 {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %+v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestMinimizeDimensionMismatch(t *testing.T) {
+	_, err := Minimize(Var("x"), []variable{Var("x")}, []float64{1, 2})
+	if _, ok := err.(*DimensionMismatchError); !ok {
+		t.Errorf("Expected DimensionMismatchError but got: %v", err)
+	}
+}
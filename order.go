@@ -110,6 +110,31 @@ func orderRule4(e1, e2 pow) bool {
 		return compare(e1Exponent, e2Exponent)
 	}
 }
+
+// Functions are ordered by their first arguments, and by their
+// names if the arguments are equal, e.g. cos(x) < sin(x). Undefined
+// functions with different names are ordered by name, and any
+// remaining operands are compared last.
+func orderRuleFunction(e1, e2 Expr) bool {
+	f1, ok1 := e1.(FunctionApplication)
+	f2, ok2 := e2.(FunctionApplication)
+	if ok1 && ok2 && f1.Name != f2.Name {
+		return f1.Name < f2.Name
+	}
+
+	n1, n2 := NumberOfOperands(e1), NumberOfOperands(e2)
+	if n1 > 0 && n2 > 0 && !Equal(Operand(e1, 1), Operand(e2, 1)) {
+		return compare(Operand(e1, 1), Operand(e2, 1))
+	} else if name1, name2 := reflect.TypeOf(e1).Name(), reflect.TypeOf(e2).Name(); name1 != name2 {
+		return name1 < name2
+	}
+	for ix := 2; ix <= n1 && ix <= n2; ix++ {
+		if !Equal(Operand(e1, ix), Operand(e2, ix)) {
+			return compare(Operand(e1, ix), Operand(e2, ix))
+		}
+	}
+	return n1 < n2
+}
 func orderRule5(e1, e2 Expr) bool {
 	panic("rule dedicated to factorial which is not implemented")
 }
@@ -167,6 +192,17 @@ func compare(e1, e2 Expr) bool {
 			return compare(Log(e1), e2)
 		case sqrt:
 			return compare(Sqrt(e1), e2)
+		case sin:
+			return compare(Sin(e1), e2)
+		case cos:
+			return compare(Cos(e1), e2)
+		case FunctionApplication:
+			if e1Typed.Name != e2Typed.Name {
+				return e1Typed.Name < e2Typed.Name
+			}
+			return true
+		case derivative:
+			return compare(e1, e2Typed.Arg)
 		default:
 			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
 			panic(errMsg)
@@ -191,6 +227,17 @@ func compare(e1, e2 Expr) bool {
 			return compare(Log(e1), e2)
 		case sqrt:
 			return compare(Sqrt(e1), e2)
+		case sin:
+			return compare(Sin(e1), e2)
+		case cos:
+			return compare(Cos(e1), e2)
+		case FunctionApplication:
+			if e1Typed.Name != e2Typed.Name {
+				return e1Typed.Name < e2Typed.Name
+			}
+			return true
+		case derivative:
+			return compare(e1, e2Typed.Arg)
 		default:
 			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
 			panic(errMsg)
@@ -215,6 +262,14 @@ func compare(e1, e2 Expr) bool {
 			return compare(e1, Add(e2))
 		case sqrt:
 			return compare(e1, Add(e2))
+		case sin:
+			return compare(e1, Add(e2))
+		case cos:
+			return compare(e1, Add(e2))
+		case FunctionApplication:
+			return compare(e1, Add(e2))
+		case derivative:
+			return compare(e1, Add(e2))
 		default:
 			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
 			panic(errMsg)
@@ -239,6 +294,14 @@ func compare(e1, e2 Expr) bool {
 			return compare(e1, Mul(e2))
 		case sqrt:
 			return compare(e1, Mul(e2))
+		case sin:
+			return compare(e1, Mul(e2))
+		case cos:
+			return compare(e1, Mul(e2))
+		case FunctionApplication:
+			return compare(e1, Mul(e2))
+		case derivative:
+			return compare(e1, Mul(e2))
 		default:
 			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
 			panic(errMsg)
@@ -263,6 +326,14 @@ func compare(e1, e2 Expr) bool {
 			return compare(e1, Pow(e2, (Int(1))))
 		case sqrt:
 			return compare(e1, Pow(e2, (Int(1))))
+		case sin:
+			return compare(e1, Pow(e2, (Int(1))))
+		case cos:
+			return compare(e1, Pow(e2, (Int(1))))
+		case FunctionApplication:
+			return compare(e1, Pow(e2, (Int(1))))
+		case derivative:
+			return compare(e1, Pow(e2, (Int(1))))
 		default:
 			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
 			panic(errMsg)
@@ -293,6 +364,14 @@ func compare(e1, e2 Expr) bool {
 			e1Arg := Operand(e1, 1)
 			e2Arg := Operand(e2, 1)
 			return compare(e1Arg, e2Arg)
+		case sin:
+			return orderRuleFunction(e1, e2)
+		case cos:
+			return orderRuleFunction(e1, e2)
+		case FunctionApplication:
+			return orderRuleFunction(e1, e2)
+		case derivative:
+			return orderRuleFunction(e1, e2)
 		default:
 			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
 			panic(errMsg)
@@ -323,6 +402,14 @@ func compare(e1, e2 Expr) bool {
 			e1Arg := Operand(e1, 1)
 			e2Arg := Operand(e2, 1)
 			return compare(e1Arg, e2Arg)
+		case sin:
+			return orderRuleFunction(e1, e2)
+		case cos:
+			return orderRuleFunction(e1, e2)
+		case FunctionApplication:
+			return orderRuleFunction(e1, e2)
+		case derivative:
+			return orderRuleFunction(e1, e2)
 		default:
 			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
 			panic(errMsg)
@@ -353,6 +440,118 @@ func compare(e1, e2 Expr) bool {
 			e1Arg := Operand(e1, 1)
 			e2Arg := Operand(e2, 1)
 			return compare(e1Arg, e2Arg)
+		case sin:
+			return orderRuleFunction(e1, e2)
+		case cos:
+			return orderRuleFunction(e1, e2)
+		case FunctionApplication:
+			return orderRuleFunction(e1, e2)
+		case derivative:
+			return orderRuleFunction(e1, e2)
+		default:
+			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
+			panic(errMsg)
+		}
+	case sin:
+		switch e2.(type) {
+		case rational:
+			return false
+		case variable:
+			return compare(e1, Sin(e2))
+		case constrainedVariable:
+			return compare(e1, Sin(e2))
+		case add:
+			return compare(Add(e1), e2)
+		case mul:
+			return compare(Mul(e1), e2)
+		case pow:
+			return compare(Pow(e1, Int(1)), e2)
+		case exp:
+			return orderRuleFunction(e1, e2)
+		case log:
+			return orderRuleFunction(e1, e2)
+		case sqrt:
+			return orderRuleFunction(e1, e2)
+		case sin:
+			return orderRuleFunction(e1, e2)
+		case cos:
+			return orderRuleFunction(e1, e2)
+		case FunctionApplication:
+			return orderRuleFunction(e1, e2)
+		case derivative:
+			return orderRuleFunction(e1, e2)
+		default:
+			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
+			panic(errMsg)
+		}
+	case cos:
+		switch e2.(type) {
+		case rational:
+			return false
+		case variable:
+			return compare(e1, Cos(e2))
+		case constrainedVariable:
+			return compare(e1, Cos(e2))
+		case add:
+			return compare(Add(e1), e2)
+		case mul:
+			return compare(Mul(e1), e2)
+		case pow:
+			return compare(Pow(e1, Int(1)), e2)
+		case exp:
+			return orderRuleFunction(e1, e2)
+		case log:
+			return orderRuleFunction(e1, e2)
+		case sqrt:
+			return orderRuleFunction(e1, e2)
+		case sin:
+			return orderRuleFunction(e1, e2)
+		case cos:
+			return orderRuleFunction(e1, e2)
+		case FunctionApplication:
+			return orderRuleFunction(e1, e2)
+		case derivative:
+			return orderRuleFunction(e1, e2)
+		default:
+			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
+			panic(errMsg)
+		}
+	case FunctionApplication:
+		switch e2.(type) {
+		case rational:
+			return false
+		case variable:
+			return !compare(e2, e1)
+		case constrainedVariable:
+			return !compare(e2, e1)
+		case add:
+			return compare(Add(e1), e2)
+		case mul:
+			return compare(Mul(e1), e2)
+		case pow:
+			return compare(Pow(e1, Int(1)), e2)
+		case exp, log, sqrt, sin, cos, FunctionApplication, derivative:
+			return orderRuleFunction(e1, e2)
+		default:
+			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
+			panic(errMsg)
+		}
+	case derivative:
+		switch e2.(type) {
+		case rational:
+			return false
+		case variable:
+			return !compare(e2, e1)
+		case constrainedVariable:
+			return !compare(e2, e1)
+		case add:
+			return compare(Add(e1), e2)
+		case mul:
+			return compare(Mul(e1), e2)
+		case pow:
+			return compare(Pow(e1, Int(1)), e2)
+		case exp, log, sqrt, sin, cos, FunctionApplication, derivative:
+			return orderRuleFunction(e1, e2)
 		default:
 			errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(e1Typed))
 			panic(errMsg)
@@ -0,0 +1,387 @@
+package gosymbol
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+A SymbolTable maps identifiers to the expressions Parse replaces
+them with. Identifiers missing from the table become variables.
+*/
+type SymbolTable map[string]Expr
+
+// Returns the symbols known to Parse: pi and π for PI, e for E and I for the imaginary unit.
+func DefaultSymbols() SymbolTable {
+	return SymbolTable{"pi": PI, string(PI.Name): PI, "e": E, "I": I}
+}
+
+/*
+Parses a plain text infix expression such as
+
+	3*x^2 + sin(y)/2 - exp(-x)
+
+with the default symbols. See ParseWith.
+*/
+func Parse(input string) (Expr, error) {
+	return ParseWith(input, DefaultSymbols())
+}
+
+/*
+Parses a plain text infix expression, replacing the identifiers
+in symbols. The grammar is the usual one:
+
+  - + and - bind weakest, then * and /, then unary minus, then the
+    right associative ^ and last the postfix factorial !, so that
+    -x^2 is -(x^2) and 2^3^2 is 2^9,
+  - f(a, b, ...) calls exp, log, sqrt, sin, cos and tan, D(f, x) is
+    the derivative of f w.r.t. x, and any other name becomes an
+    undefined function such as Gamma(x),
+  - integers and decimals like 1.5 and 2.5e-3, which become exact
+    rationals. Two integers separated by / without white space form
+    a single rational literal, so that x^1/2 is x^(1/2) like the
+    debug format prints it.
+
+Both formats of String() can be parsed back, see Format.
+On failure a SyntaxError with the line and column of the offending
+token is returned.
+*/
+func ParseWith(input string, symbols SymbolTable) (Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &infixParser{src: input, tokens: tokens, symbols: symbols}
+	expr, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorAt(tok, "unexpected %s", tok)
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int
+	value rational // The value of number tokens
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// Splits input into numbers, identifiers and the operators + - * / ^ ! ( ) and comma.
+func tokenize(input string) ([]token, error) {
+	tokens := []token{}
+	pos := 0
+	for pos < len(input) {
+		r, size := utf8.DecodeRuneInString(input[pos:])
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+		case isDigit(input[pos]) || (r == '.' && pos+1 < len(input) && isDigit(input[pos+1])):
+			tok, err := scanNumber(input, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			pos += len(tok.text)
+		case unicode.IsLetter(r) || r == '_':
+			end := pos + size
+			for end < len(input) {
+				r, size := utf8.DecodeRuneInString(input[end:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: input[pos:end], pos: pos})
+			pos = end
+		case strings.ContainsRune("+-*/^!(),", r):
+			tokens = append(tokens, token{kind: tokenOperator, text: string(r), pos: pos})
+			pos += size
+		default:
+			return nil, newSyntaxError(input, pos, fmt.Sprintf("unexpected character %q", r))
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+func absInt64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+/*
+Scans the number starting at pos, which is an integer, a decimal
+with an optional exponent or a rational literal like 3/4.
+*/
+func scanNumber(input string, pos int) (token, error) {
+	digitsEnd := func(ix int) int {
+		for ix < len(input) && isDigit(input[ix]) {
+			ix++
+		}
+		return ix
+	}
+
+	end := digitsEnd(pos)
+	digits, decimals := input[pos:end], 0
+	isInteger := true
+	if end < len(input) && input[end] == '.' {
+		fracEnd := digitsEnd(end + 1)
+		digits += input[end+1 : fracEnd]
+		decimals = fracEnd - end - 1
+		end, isInteger = fracEnd, false
+	}
+	var exponent int64
+	if end < len(input) && (input[end] == 'e' || input[end] == 'E') {
+		ix := end + 1
+		sign := int64(1)
+		if ix < len(input) && (input[ix] == '+' || input[ix] == '-') {
+			if input[ix] == '-' {
+				sign = -1
+			}
+			ix++
+		}
+		// Only an exponent if digits follow, so that 2e is 2 followed by e
+		if expEnd := digitsEnd(ix); expEnd > ix {
+			for _, d := range input[ix:expEnd] {
+				exponent = 10*exponent + int64(d-'0')
+				if exponent > 18 {
+					return token{}, newSyntaxError(input, pos, "exponent of number is too large")
+				}
+			}
+			exponent *= sign
+			end, isInteger = expEnd, false
+		}
+	}
+
+	// digits*10^(exponent - decimals) as an exact rational
+	num, _ := new(big.Int).SetString(digits, 10)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(absInt64(exponent-int64(decimals))), nil)
+	exact := new(big.Rat).SetInt(num)
+	if exponent-int64(decimals) >= 0 {
+		exact.Mul(exact, new(big.Rat).SetInt(scale))
+	} else {
+		exact.Quo(exact, new(big.Rat).SetInt(scale))
+	}
+	if !exact.Num().IsInt64() {
+		return token{}, newSyntaxError(input, pos, fmt.Sprintf("number %s does not fit in an int64", input[pos:end]))
+	}
+
+	// Rational literal n/d
+	if isInteger && end+1 < len(input) && input[end] == '/' && isDigit(input[end+1]) {
+		denEnd := digitsEnd(end + 1)
+		den, _ := new(big.Int).SetString(input[end+1:denEnd], 10)
+		if !den.IsInt64() {
+			return token{}, newSyntaxError(input, pos, fmt.Sprintf("number %s does not fit in an int64", input[pos:denEnd]))
+		}
+		if den.Sign() == 0 {
+			return token{}, newSyntaxError(input, pos, "division by zero in rational literal")
+		}
+		exact.Quo(exact, new(big.Rat).SetInt(den))
+		end = denEnd
+	}
+	value, ok := bigRatToRational(exact)
+	if !ok {
+		return token{}, newSyntaxError(input, pos, fmt.Sprintf("number %s does not fit in an int64", input[pos:end]))
+	}
+	return token{kind: tokenNumber, text: input[pos:end], pos: pos, value: value}, nil
+}
+
+type infixParser struct {
+	src     string
+	tokens  []token
+	next    int
+	symbols SymbolTable
+}
+
+func (p *infixParser) peek() token { return p.tokens[p.next] }
+
+func (p *infixParser) advance() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEOF {
+		p.next++
+	}
+	return tok
+}
+
+func (p *infixParser) errorAt(tok token, format string, args ...any) error {
+	return newSyntaxError(p.src, tok.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *infixParser) expect(op string) error {
+	if tok := p.peek(); tok.kind != tokenOperator || tok.text != op {
+		return p.errorAt(tok, "expected %q but found %s", op, tok)
+	}
+	p.advance()
+	return nil
+}
+
+// The binding powers of the infix and postfix operators.
+var infixBindingPower = map[string]int{
+	"+": 10, "-": 10,
+	"*": 20, "/": 20,
+	"^": 40,
+	"!": 50,
+}
+
+// Unary minus binds tighter than * but weaker than ^.
+const prefixBindingPower = 30
+
+/*
+Parses an expression whose operators bind tighter than minPower by
+Pratt's top down operator precedence method.
+*/
+func (p *infixParser) parseExpr(minPower int) (Expr, error) {
+	lhs, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		power, ok := infixBindingPower[tok.text]
+		if tok.kind != tokenOperator || !ok || power <= minPower {
+			return lhs, nil
+		}
+		p.advance()
+		if tok.text == "!" {
+			lhs = Factorial(lhs)
+			continue
+		}
+
+		// ^ is right associative, so its right operand may contain another ^
+		rhsPower := power
+		if tok.text == "^" {
+			rhsPower--
+		}
+		rhs, err := p.parseExpr(rhsPower)
+		if err != nil {
+			return nil, err
+		}
+		switch tok.text {
+		case "+":
+			lhs = Add(lhs, rhs)
+		case "-":
+			lhs = Sub(lhs, rhs)
+		case "*":
+			lhs = Mul(lhs, rhs)
+		case "/":
+			lhs = Div(lhs, rhs)
+		case "^":
+			lhs = Pow(lhs, rhs)
+		}
+	}
+}
+
+func (p *infixParser) parsePrefix() (Expr, error) {
+	tok := p.advance()
+	switch {
+	case tok.kind == tokenNumber:
+		return tok.value, nil
+	case tok.kind == tokenIdent:
+		if next := p.peek(); next.kind == tokenOperator && next.text == "(" {
+			return p.parseCall(tok)
+		}
+		if expr, ok := p.symbols[tok.text]; ok {
+			return expr, nil
+		}
+		if tok.text == "Undefined" {
+			return Undefined(), nil
+		}
+		return Var(VarName(tok.text)), nil
+	case tok.kind == tokenOperator && (tok.text == "-" || tok.text == "+"):
+		operand, err := p.parseExpr(prefixBindingPower)
+		if err != nil {
+			return nil, err
+		}
+		if tok.text == "+" {
+			return operand, nil
+		}
+		if r, ok := operand.(rational); ok {
+			return ratMinus(r), nil
+		}
+		return Neg(operand), nil
+	case tok.kind == tokenOperator && tok.text == "(":
+		expr, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+	return nil, p.errorAt(tok, "unexpected %s", tok)
+}
+
+// The functions of one argument that Parse maps to their constructors.
+var parserFunctions = map[string]func(Expr) Expr{
+	"exp":  func(x Expr) Expr { return Exp(x) },
+	"log":  func(x Expr) Expr { return Log(x) },
+	"ln":   func(x Expr) Expr { return Log(x) },
+	"sqrt": func(x Expr) Expr { return Sqrt(x) },
+	"sin":  func(x Expr) Expr { return Sin(x) },
+	"cos":  func(x Expr) Expr { return Cos(x) },
+	"tan":  func(x Expr) Expr { return Div(Sin(x), Cos(x)) },
+}
+
+// Parses the call name(args...) and maps it to the constructor of name.
+func (p *infixParser) parseCall(name token) (Expr, error) {
+	p.advance()
+	args := []Expr{}
+	if next := p.peek(); next.kind != tokenOperator || next.text != ")" {
+		for {
+			arg, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if next := p.peek(); next.kind != tokenOperator || next.text != "," {
+				break
+			}
+			p.advance()
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if f, ok := parserFunctions[name.text]; ok {
+		if len(args) != 1 {
+			return nil, p.errorAt(name, "%s takes 1 argument but got %d", name.text, len(args))
+		}
+		return f(args[0]), nil
+	}
+	if name.text == "D" {
+		if len(args) != 2 {
+			return nil, p.errorAt(name, "D takes 2 arguments but got %d", len(args))
+		}
+		v, ok := args[1].(variable)
+		if !ok {
+			return nil, p.errorAt(name, "D can only differentiate w.r.t. a variable, not %v", args[1])
+		}
+		return Derivative(args[0], v), nil
+	}
+	return Function(VarName(name.text), args...), nil
+}
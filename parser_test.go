@@ -0,0 +1,149 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	x, y, z, n := Var("x"), Var("y"), Var("z"), Var("n")
+
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput Expr
+	}{
+		{
+			name:           "Example from the documentation",
+			input:          "3*x^2 + sin(y)/2 - exp(-x)",
+			expectedOutput: Sub(Add(Mul(Int(3), Pow(x, Int(2))), Div(Sin(y), Int(2))), Exp(Neg(x))),
+		},
+		{name: "Precedence of * over +", input: "1 + 2*3", expectedOutput: Add(Int(1), Mul(Int(2), Int(3)))},
+		{name: "Parentheses", input: "(1 + 2)*3", expectedOutput: Mul(Add(Int(1), Int(2)), Int(3))},
+		{name: "Subtraction is left associative", input: "x - y - z", expectedOutput: Sub(Sub(x, y), z)},
+		{name: "Division is left associative", input: "x/y/z", expectedOutput: Div(Div(x, y), z)},
+		{name: "Powers are right associative", input: "x^y^z", expectedOutput: Pow(x, Pow(y, z))},
+		{name: "Unary minus binds weaker than powers", input: "-x^2", expectedOutput: Neg(Pow(x, Int(2)))},
+		{name: "Unary minus binds tighter than products", input: "-x*y", expectedOutput: Mul(Neg(x), y)},
+		{name: "Negative exponent", input: "x^-2", expectedOutput: Pow(x, Int(-2))},
+		{name: "Repeated signs", input: "x - -y", expectedOutput: Sub(x, Neg(y))},
+		{name: "Negative literal", input: "-3 + x", expectedOutput: Add(Int(-3), x)},
+		{name: "Rational literal", input: "3/4*x", expectedOutput: Mul(Div(Int(3), Int(4)), x)},
+		{name: "Rational exponent", input: "x^1/2", expectedOutput: Pow(x, Div(Int(1), Int(2)))},
+		{name: "Division with white space", input: "x^1 / 2", expectedOutput: Div(Pow(x, Int(1)), Int(2))},
+		{name: "Decimal literal", input: "0.25*x", expectedOutput: Mul(Div(Int(1), Int(4)), x)},
+		{name: "Decimal literal with an exponent", input: "2.5e-3", expectedOutput: Div(Int(1), Int(400))},
+		{name: "Decimal literal with a positive exponent", input: "1.5E2", expectedOutput: Int(150)},
+		{name: "Factorial", input: "n!^2", expectedOutput: Pow(Factorial(n), Int(2))},
+		{name: "Functions", input: "log(sqrt(x)) + cos(x)", expectedOutput: Add(Log(Sqrt(x)), Cos(x))},
+		{name: "Natural logarithm", input: "ln(x)", expectedOutput: Log(x)},
+		{name: "Tangent", input: "tan(x)", expectedOutput: Div(Sin(x), Cos(x))},
+		{name: "Derivative", input: "D(x^2, x)", expectedOutput: Derivative(Pow(x, Int(2)), x)},
+		{name: "Undefined function", input: "f(x, y)", expectedOutput: Function("f", x, y)},
+		{name: "Function without arguments", input: "f()", expectedOutput: Function("f")},
+		{name: "Default symbols", input: "2*pi + e^I", expectedOutput: Add(Mul(Int(2), PI), Pow(E, I))},
+		{name: "Unicode identifiers", input: "π*θ_1", expectedOutput: Mul(PI, Var("θ_1"))},
+		{name: "Undefined", input: "Undefined", expectedOutput: Undefined()},
+		{name: "White space", input: "\tx\n+\ty ", expectedOutput: Add(x, y)},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := Parse(test.input)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nInput: %v\nUnexpected error: %v", test.name, test.input, err)
+			}
+			if !Equal(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestParseWith(t *testing.T) {
+	x := Var("x")
+	symbols := SymbolTable{"c": Int(299792458), "k": Var("k", Positive)}
+
+	result, err := ParseWith("c*x + k + e", symbols)
+	expected := Add(Mul(Int(299792458), x), Var("k", Positive), Var("e"))
+	if err != nil || !Equal(result, expected) {
+		t.Errorf("Following test failed: custom symbol table\nInput: %v\nExpected: %v\nGot: %v (error %v)", "c*x + k + e", expected, result, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput SyntaxError
+	}{
+		{name: "Empty input", input: "", expectedOutput: SyntaxError{Line: 1, Column: 1}},
+		{name: "Missing operand", input: "x + ", expectedOutput: SyntaxError{Line: 1, Column: 5}},
+		{name: "Unclosed parenthesis", input: "(x + 1", expectedOutput: SyntaxError{Line: 1, Column: 7}},
+		{name: "Unexpected closing parenthesis", input: "x + 1)", expectedOutput: SyntaxError{Line: 1, Column: 6}},
+		{name: "Unknown character", input: "x # y", expectedOutput: SyntaxError{Line: 1, Column: 3}},
+		{name: "Juxtaposition", input: "2 x", expectedOutput: SyntaxError{Line: 1, Column: 3}},
+		{name: "Wrong number of arguments", input: "sin(x, y)", expectedOutput: SyntaxError{Line: 1, Column: 1}},
+		{name: "Derivative w.r.t. an expression", input: "D(x, 2*y)", expectedOutput: SyntaxError{Line: 1, Column: 1}},
+		{name: "Rational literal with zero denominator", input: "x + 1/0", expectedOutput: SyntaxError{Line: 1, Column: 5}},
+		{name: "Too large integer", input: "99999999999999999999", expectedOutput: SyntaxError{Line: 1, Column: 1}},
+		{name: "Integer wrapping around int64", input: "25000000000000000000", expectedOutput: SyntaxError{Line: 1, Column: 1}},
+		{name: "Too large denominator", input: "x + 1/25000000000000000000", expectedOutput: SyntaxError{Line: 1, Column: 5}},
+		{name: "Error on a later line", input: "x +\n  * y", expectedOutput: SyntaxError{Line: 2, Column: 3}},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			_, err := Parse(test.input)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) || syntaxErr.Line != test.expectedOutput.Line || syntaxErr.Column != test.expectedOutput.Column {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: error at %d:%d\nGot: %v", test.name, test.input, test.expectedOutput.Line, test.expectedOutput.Column, err)
+			}
+		})
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	x, y, z := Var("x"), Var("y"), Var("z")
+
+	tests := []Expr{
+		Add(Mul(Int(3), Pow(x, Int(2))), Div(Sin(y), Int(2)), Neg(Exp(Neg(x)))),
+		Sub(x, Sub(y, z)),
+		Div(Int(-3), Int(7)),
+		Mul(Div(Int(-3), Int(7)), Pow(x, Div(Int(-1), Int(2)))),
+		Pow(Div(Int(-2), Int(3)), x),
+		Pow(Int(-8), Div(Int(1), Int(3))),
+		Pow(Pow(x, y), z),
+		Pow(x, Pow(y, z)),
+		Mul(I, PI, E),
+		Sqrt(Add(Pow(x, Int(2)), Int(1))),
+		Log(Cos(Mul(Int(2), x))),
+		Function("f", x, Add(y, Int(1)), Function("g")),
+		Factorial(Add(x, Int(1))),
+		Derivative(Function("f", x), x),
+		Undefined(),
+		Sub(Div(Add(x, Int(1)), Sub(x, Int(1))), Mul(Int(2), Pow(y, Int(-3)))).Simplify(),
+		Pow(Add(x, y), Int(3)).Simplify(),
+		Expand(Pow(Add(x, Sqrt(Int(2))), Int(3))),
+		Mul(Pow(Int(12), Div(Int(1), Int(2))), x).Simplify(),
+		Mul(Int(3), Pow(Int(2), Int(-1))),
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			str := Format(test, DebugFormat)
+			result, err := Parse(str)
+			if err != nil || !Equal(result, test) {
+				t.Errorf("Following test failed: round trip through the debug format\nInput: %v\nExpected: %v\nGot: %v (error %v)", str, test, result, err)
+			}
+
+			// The compact format only gives back an expression with the same simplification
+			str = test.String()
+			result, err = Parse(str)
+			if err != nil || !Equal(result.Simplify(), test.Simplify()) {
+				t.Errorf("Following test failed: round trip through String()\nInput: %v\nExpected: %v\nGot: %v (error %v)", str, test.Simplify(), result.Simplify(), err)
+			}
+		})
+	}
+}
@@ -137,6 +137,42 @@ func patternMatch(expr, pattern Expr, bindings Binding) bool {
 		}
 		return false
 
+	case sqrt:
+		if s, ok := expr.(sqrt); ok {
+			return patternMatch(s.Arg, p.Arg, bindings)
+		}
+		return false
+
+	case sin:
+		if s, ok := expr.(sin); ok {
+			return patternMatch(s.Arg, p.Arg, bindings)
+		}
+		return false
+
+	case cos:
+		if c, ok := expr.(cos); ok {
+			return patternMatch(c.Arg, p.Arg, bindings)
+		}
+		return false
+
+	case FunctionApplication:
+		f, ok := expr.(FunctionApplication)
+		if !ok || f.Name != p.Name || len(f.Args) != len(p.Args) {
+			return false
+		}
+		for ix := range p.Args {
+			if !patternMatch(f.Args[ix], p.Args[ix], bindings) {
+				return false
+			}
+		}
+		return true
+
+	case derivative:
+		if d, ok := expr.(derivative); ok {
+			return patternMatch(d.Arg, p.Arg, bindings) && patternMatch(d.Var, p.Var, bindings)
+		}
+		return false
+
 	default:
 		errMsg := fmt.Errorf("ERROR: expression %#v have no match pattern case implemented", p)
 		panic(errMsg)
@@ -0,0 +1,919 @@
+package gosymbol
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Returns the coefficients of expr seen as a polynomial in v, i.e.
+the slice [c_0, c_1, ..., c_n] such that expr = c_0 + c_1*v + ... + c_n*v^n.
+The coefficients are free of v but may contain other variables.
+
+The expression does not need to be expanded beforehand, products
+and non-negative integer powers of sums are multiplied out while
+extracting the coefficients. If expr is not a polynomial in v,
+e.g. if v appears inside an exp or with a negative exponent, a
+NotPolynomialError is returned.
+
+The returned slice always has at least one element and the
+leading coefficient is non-zero unless expr itself is zero.
+
+See [1] chapter 6.2 for the general polynomial expression (GPE) view
+of expressions this builds upon.
+
+[1] COHEN, Joel S. Computer algebra and symbolic computation: Mathematical methods. AK Peters/CRC Press, 2003.
+*/
+func polynomialCoefficients(expr Expr, v variable) ([]Expr, error) {
+	coeffs, err := coefficientList(expr, v)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Expr, len(coeffs))
+	for ix, c := range coeffs {
+		if c == nil {
+			result[ix] = Int(0)
+		} else {
+			result[ix] = c.Simplify()
+		}
+	}
+
+	// Trims trailing zero coefficients so that
+	// the last element is the leading coefficient
+	n := len(result)
+	for n > 1 && Equal(result[n-1], Int(0)) {
+		n--
+	}
+	return result[:n], nil
+}
+
+/*
+Recursively computes the (unsimplified) coefficient list of expr
+w.r.t. v. A nil element represents a zero coefficient, which lets
+us avoid building sums cluttered with zeros.
+*/
+func coefficientList(expr Expr, v variable) ([]Expr, error) {
+	if !RecContains(expr, v) {
+		return []Expr{expr}, nil
+	}
+
+	switch e := expr.(type) {
+	case variable:
+		// Since expr contains v it must be v
+		return []Expr{nil, Int(1)}, nil
+	case add:
+		var result []Expr
+		for _, op := range e.Operands {
+			opCoeffs, err := coefficientList(op, v)
+			if err != nil {
+				return nil, err
+			}
+			result = addCoefficientLists(result, opCoeffs)
+		}
+		return result, nil
+	case mul:
+		result := []Expr{Int(1)}
+		for _, op := range e.Operands {
+			opCoeffs, err := coefficientList(op, v)
+			if err != nil {
+				return nil, err
+			}
+			result = mulCoefficientLists(result, opCoeffs)
+		}
+		return result, nil
+	case pow:
+		n, ok := e.Exponent.(integer)
+		if !ok || n.value < 0 || RecContains(e.Exponent, v) {
+			return nil, &NotPolynomialError{Expr: expr, Var: v}
+		}
+		baseCoeffs, err := coefficientList(e.Base, v)
+		if err != nil {
+			return nil, err
+		}
+		result := []Expr{Int(1)}
+		for ix := int64(0); ix < n.value; ix++ {
+			result = mulCoefficientLists(result, baseCoeffs)
+		}
+		return result, nil
+	default:
+		return nil, &NotPolynomialError{Expr: expr, Var: v}
+	}
+}
+
+func addCoefficientLists(a, b []Expr) []Expr {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	result := make([]Expr, len(a))
+	copy(result, a)
+	for ix, c := range b {
+		result[ix] = addCoefficient(result[ix], c)
+	}
+	return result
+}
+
+func mulCoefficientLists(a, b []Expr) []Expr {
+	result := make([]Expr, len(a)+len(b)-1)
+	for ix, ca := range a {
+		if ca == nil {
+			continue
+		}
+		for jx, cb := range b {
+			if cb == nil {
+				continue
+			}
+			result[ix+jx] = addCoefficient(result[ix+jx], Mul(ca, cb))
+		}
+	}
+	return result
+}
+
+func addCoefficient(a, b Expr) Expr {
+	if a == nil {
+		return b
+	} else if b == nil {
+		return a
+	}
+	return Add(a, b)
+}
+
+/* Multivariate polynomials */
+
+/*
+A kernelTable keeps track of the kernels, i.e. the non-polynomial building
+blocks such as variables or exp(x), which multivariate polynomials are
+expressed in. Two polynomials can only be combined if they were built
+using the same kernelTable.
+*/
+type kernelTable struct {
+	kernels []Expr
+	// Radical kernels k = b^(1/n), where b is a polynomial in the other
+	// kernels, satisfy k^n = b. This relation is used by reduce to keep
+	// the exponents of k below n, so that e.g. sqrt(2)^2 - 2 is zero.
+	// Algebraic numbers RootOf(q, j) are stored the same way, with b
+	// the lower order terms of the monic q in k itself.
+	radicals map[int]radical
+	// Maps the index of a kernel sin(u) to the index of cos(u) when both
+	// are present, so that reduce can use sin(u)^2 = 1 - cos(u)^2.
+	pythagorean map[int]int
+}
+
+type radical struct {
+	degree int
+	base   polynomial
+}
+
+// Returns the index of kernel in the table, adding it if needed.
+func (kt *kernelTable) index(kernel Expr) int {
+	for ix, k := range kt.kernels {
+		if Equal(k, kernel) {
+			return ix
+		}
+	}
+	kt.kernels = append(kt.kernels, kernel)
+	ix := len(kt.kernels) - 1
+
+	var partner Expr
+	switch k := kernel.(type) {
+	case sin:
+		partner = Cos(k.Arg)
+	case cos:
+		partner = Sin(k.Arg)
+	case FunctionApplication:
+		if q, _, ok := rootOfParts(k); ok {
+			// k^n = -(q_0 + q_1*k + ... + q_(n-1)*k^(n-1))
+			base := polynomial{}
+			for jx, c := range q[:q.degree()] {
+				coeff, _ := bigRatToRational(new(big.Rat).Neg(c))
+				base = base.add(kernelPoly(ix, jx).scale(coeff))
+			}
+			if kt.radicals == nil {
+				kt.radicals = map[int]radical{}
+			}
+			kt.radicals[ix] = radical{degree: q.degree(), base: base}
+		}
+	}
+	for jx, k := range kt.kernels[:ix] {
+		if partner != nil && Equal(k, partner) {
+			if kt.pythagorean == nil {
+				kt.pythagorean = map[int]int{}
+			}
+			if _, ok := kernel.(sin); ok {
+				kt.pythagorean[ix] = jx
+			} else {
+				kt.pythagorean[jx] = ix
+			}
+		}
+	}
+	return ix
+}
+
+// Returns the index of the kernel base^(1/n), adding it and its
+// defining relation if needed. The base must be a polynomial over kt.
+func (kt *kernelTable) radical(base polynomial, n int) int {
+	ix := kt.index(Pow(base.toExpr(kt), Div(Int(1), Int(int64(n)))))
+	if kt.radicals == nil {
+		kt.radicals = map[int]radical{}
+	}
+	kt.radicals[ix] = radical{degree: n, base: base}
+	return ix
+}
+
+// Rewrites every power k^e of a radical kernel k = b^(1/n) with e >= n
+// as k^(e mod n) * b^(e div n), and every power sin(u)^e with e >= 2 as
+// sin(u)^(e-2) * (1 - cos(u)^2), until no such powers remain.
+func (kt *kernelTable) reduce(p polynomial) polynomial {
+	if len(kt.radicals) == 0 && len(kt.pythagorean) == 0 {
+		return p
+	}
+	for {
+		changed := false
+		result := polynomial{}
+		for _, t := range p {
+			reduced := false
+			for ix, r := range kt.radicals {
+				e := t.mono.exponent(ix)
+				if e < r.degree {
+					continue
+				}
+				mono := make(monomial, len(t.mono))
+				copy(mono, t.mono)
+				mono[ix] = e % r.degree
+				term := polynomial{}
+				term.addTerm(polyTerm{coeff: t.coeff, mono: mono.trim()})
+				result = result.add(term.mul(r.base.pow(e / r.degree)))
+				reduced = true
+				break
+			}
+			for ix, jx := range kt.pythagorean {
+				e := t.mono.exponent(ix)
+				if reduced || e < 2 {
+					continue
+				}
+				mono := make(monomial, len(t.mono))
+				copy(mono, t.mono)
+				mono[ix] = e - 2
+				term := polynomial{}
+				term.addTerm(polyTerm{coeff: t.coeff, mono: mono.trim()})
+				result = result.add(term.mul(constPoly(Int(1)).sub(kernelPoly(jx, 2))))
+				reduced = true
+			}
+			if !reduced {
+				result.addTerm(t)
+			}
+			changed = changed || reduced
+		}
+		if !changed {
+			return result
+		}
+		p = result
+	}
+}
+
+// The exponents of the kernels in a term. Element ix is the exponent of
+// kernel ix and trailing zeros are always trimmed, so that every monomial
+// has a unique representation. Negative exponents are allowed, but then
+// the polynomial arithmetic is Laurent polynomial arithmetic.
+type monomial []int
+
+func (m monomial) key() string {
+	var sb strings.Builder
+	for _, e := range m {
+		sb.WriteString(strconv.Itoa(e))
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
+
+func (m monomial) exponent(ix int) int {
+	if ix < len(m) {
+		return m[ix]
+	}
+	return 0
+}
+
+func (m monomial) trim() monomial {
+	n := len(m)
+	for n > 0 && m[n-1] == 0 {
+		n--
+	}
+	return m[:n]
+}
+
+func monomialMul(a, b monomial) monomial {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	result := make(monomial, len(a))
+	copy(result, a)
+	for ix, e := range b {
+		result[ix] += e
+	}
+	return result.trim()
+}
+
+// Returns a/b and whether b divides a, i.e.
+// whether the quotient has no negative exponents.
+func monomialDiv(a, b monomial) (monomial, bool) {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	result := make(monomial, n)
+	for ix := range result {
+		result[ix] = a.exponent(ix) - b.exponent(ix)
+		if result[ix] < 0 {
+			return nil, false
+		}
+	}
+	return result.trim(), true
+}
+
+// Lexicographic order of monomials, returning -1, 0 or 1.
+func monomialCompare(a, b monomial) int {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	for ix := 0; ix < n; ix++ {
+		ea, eb := a.exponent(ix), b.exponent(ix)
+		if ea < eb {
+			return -1
+		} else if ea > eb {
+			return 1
+		}
+	}
+	return 0
+}
+
+type polyTerm struct {
+	coeff rational
+	mono  monomial
+}
+
+/*
+A sparse multivariate polynomial with rational coefficients in the
+kernels of some kernelTable. The terms are keyed by monomial.key()
+and terms with zero coefficient are never stored, so the zero
+polynomial is the empty map.
+*/
+type polynomial map[string]polyTerm
+
+func constPoly(c rational) polynomial {
+	p := polynomial{}
+	p.addTerm(polyTerm{coeff: c, mono: monomial{}})
+	return p
+}
+
+// The polynomial kernel^n, where kernel is given by its index.
+func kernelPoly(kernel, n int) polynomial {
+	mono := make(monomial, kernel+1)
+	mono[kernel] = n
+	return polynomial{mono.trim().key(): polyTerm{coeff: Int(1), mono: mono.trim()}}
+}
+
+// Adds t to p in place.
+func (p polynomial) addTerm(t polyTerm) {
+	key := t.mono.key()
+	if old, ok := p[key]; ok {
+		t.coeff = ratAdd(old.coeff, t.coeff)
+	}
+	if isZeroRational(t.coeff) {
+		delete(p, key)
+	} else {
+		p[key] = t
+	}
+}
+
+func isZeroRational(c rational) bool {
+	return c.numerator() == Int(0) && c.denominator() != Int(0)
+}
+
+func (p polynomial) copy() polynomial {
+	result := make(polynomial, len(p))
+	for key, t := range p {
+		result[key] = t
+	}
+	return result
+}
+
+func (p polynomial) add(q polynomial) polynomial {
+	result := p.copy()
+	for _, t := range q {
+		result.addTerm(t)
+	}
+	return result
+}
+
+func (p polynomial) scale(c rational) polynomial {
+	result := polynomial{}
+	for _, t := range p {
+		result.addTerm(polyTerm{coeff: ratMul(t.coeff, c), mono: t.mono})
+	}
+	return result
+}
+
+func (p polynomial) neg() polynomial {
+	return p.scale(Int(-1))
+}
+
+func (p polynomial) sub(q polynomial) polynomial {
+	return p.add(q.neg())
+}
+
+func (p polynomial) mulTerm(t polyTerm) polynomial {
+	result := polynomial{}
+	for _, s := range p {
+		result.addTerm(polyTerm{coeff: ratMul(s.coeff, t.coeff), mono: monomialMul(s.mono, t.mono)})
+	}
+	return result
+}
+
+func (p polynomial) mul(q polynomial) polynomial {
+	result := polynomial{}
+	for _, s := range p {
+		for _, t := range q {
+			result.addTerm(polyTerm{coeff: ratMul(s.coeff, t.coeff), mono: monomialMul(s.mono, t.mono)})
+		}
+	}
+	return result
+}
+
+func (p polynomial) pow(n int) polynomial {
+	result := constPoly(Int(1))
+	for ix := 0; ix < n; ix++ {
+		result = result.mul(p)
+	}
+	return result
+}
+
+func (p polynomial) isZero() bool {
+	return len(p) == 0
+}
+
+// Returns the value of p and true if p is constant.
+func (p polynomial) constant() (rational, bool) {
+	if p.isZero() {
+		return Int(0), true
+	} else if t, ok := p[monomial{}.key()]; ok && len(p) == 1 {
+		return t.coeff, true
+	}
+	return nil, false
+}
+
+func (p polynomial) equal(q polynomial) bool {
+	return p.sub(q).isZero()
+}
+
+// Returns the terms of p in decreasing lexicographic order.
+func (p polynomial) sortedTerms() []polyTerm {
+	terms := make([]polyTerm, 0, len(p))
+	for _, t := range p {
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		return monomialCompare(terms[i].mono, terms[j].mono) > 0
+	})
+	return terms
+}
+
+func (p polynomial) leadingTerm() polyTerm {
+	var lt polyTerm
+	first := true
+	for _, t := range p {
+		if first || monomialCompare(t.mono, lt.mono) > 0 {
+			lt = t
+			first = false
+		}
+	}
+	return lt
+}
+
+/*
+Divides p by d using the multivariate division algorithm with respect
+to the lexicographic order, see e.g. [1] chapter 2.3. Since there is
+only one divisor, the remainder is zero if and only if d divides p.
+
+[1] COX, David, LITTLE, John and O'SHEA, Donal. Ideals, varieties, and algorithms. Springer, 2015.
+*/
+func (p polynomial) quoRem(d polynomial) (polynomial, polynomial) {
+	quo, rem := polynomial{}, polynomial{}
+	ltd := d.leadingTerm()
+	p = p.copy()
+	for !p.isZero() {
+		lt := p.leadingTerm()
+		if mono, ok := monomialDiv(lt.mono, ltd.mono); ok {
+			t := polyTerm{coeff: ratDiv(lt.coeff, ltd.coeff), mono: mono}
+			quo.addTerm(t)
+			p = p.sub(d.mulTerm(t))
+		} else {
+			rem.addTerm(lt)
+			delete(p, lt.mono.key())
+		}
+	}
+	return quo, rem
+}
+
+// Returns p/d and true if d divides p, otherwise false.
+func (p polynomial) exactQuo(d polynomial) (polynomial, bool) {
+	quo, rem := p.quoRem(d)
+	return quo, rem.isZero()
+}
+
+/*
+Returns the square root of p and true if p is the square of a polynomial.
+The root is computed term by term like in long division: once the root r
+is known down to some term, the leading term of p - r^2 divided by twice
+the leading term of r gives the next term.
+*/
+func (p polynomial) sqrt() (polynomial, bool) {
+	if p.isZero() {
+		return p, true
+	}
+	lt := p.leadingTerm()
+	c, ok := rationalRoot(lt.coeff, 2)
+	if !ok {
+		return nil, false
+	}
+	mono := make(monomial, len(lt.mono))
+	for ix, e := range lt.mono {
+		if e%2 != 0 {
+			return nil, false
+		}
+		mono[ix] = e / 2
+	}
+	first := polyTerm{coeff: c, mono: mono}
+	root := polynomial{}
+	root.addTerm(first)
+
+	twice := ratMul(Int(2), c)
+	for ix := 0; ix <= 2*len(p); ix++ {
+		rem := p.sub(root.mul(root))
+		if rem.isZero() {
+			return root, true
+		}
+		lt := rem.leadingTerm()
+		mono, ok := monomialDiv(lt.mono, first.mono)
+		if !ok || monomialCompare(mono, first.mono) >= 0 {
+			return nil, false
+		}
+		root.addTerm(polyTerm{coeff: ratDiv(lt.coeff, twice), mono: mono})
+	}
+	return nil, false
+}
+
+// The degree of p in the kernel with index ix.
+// The zero polynomial has degree -1.
+func (p polynomial) degreeIn(ix int) int {
+	degree := -1
+	for _, t := range p {
+		if e := t.mono.exponent(ix); e > degree {
+			degree = e
+		}
+	}
+	return degree
+}
+
+// The partial derivative of p w.r.t. the kernel with index ix.
+func (p polynomial) derivativeIn(ix int) polynomial {
+	result := polynomial{}
+	for _, t := range p {
+		e := t.mono.exponent(ix)
+		if e == 0 {
+			continue
+		}
+		mono := make(monomial, len(t.mono))
+		copy(mono, t.mono)
+		mono[ix] = e - 1
+		result.addTerm(polyTerm{coeff: ratMul(t.coeff, Int(int64(e))), mono: mono.trim()})
+	}
+	return result
+}
+
+// The coefficient of kernel ix to the power n in p, i.e. a polynomial
+// in the remaining kernels.
+func (p polynomial) coefficientIn(ix, n int) polynomial {
+	result := polynomial{}
+	for _, t := range p {
+		if t.mono.exponent(ix) == n {
+			mono := make(monomial, len(t.mono))
+			copy(mono, t.mono)
+			if ix < len(mono) {
+				mono[ix] = 0
+			}
+			result.addTerm(polyTerm{coeff: t.coeff, mono: mono.trim()})
+		}
+	}
+	return result
+}
+
+// Returns the sorted indexes of the kernels occurring in the polynomials.
+func kernelsIn(ps ...polynomial) []int {
+	seen := map[int]bool{}
+	for _, p := range ps {
+		for _, t := range p {
+			for ix, e := range t.mono {
+				if e != 0 {
+					seen[ix] = true
+				}
+			}
+		}
+	}
+	result := make([]int, 0, len(seen))
+	for ix := range seen {
+		result = append(result, ix)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// Scales p so that its leading coefficient is one.
+func (p polynomial) monic() polynomial {
+	if p.isZero() {
+		return p
+	}
+	return p.scale(ratInv(p.leadingTerm().coeff))
+}
+
+/*
+Greatest common divisor of u and v, normalised to be monic. The
+algorithm is the recursive primitive polynomial remainder sequence
+algorithm from [1] chapter 4.3, where the polynomials are seen as
+polynomials in the first kernel with coefficients that are
+polynomials in the remaining kernels.
+
+[1] COHEN, Joel S. Computer algebra and symbolic computation: Mathematical methods. AK Peters/CRC Press, 2003.
+*/
+func polyGCD(u, v polynomial) polynomial {
+	return mvPolyGCD(u, v, kernelsIn(u, v))
+}
+
+func mvPolyGCD(u, v polynomial, kernels []int) polynomial {
+	if u.isZero() {
+		return v.monic()
+	} else if v.isZero() {
+		return u.monic()
+	} else if len(kernels) == 0 {
+		// Non-zero constants are units
+		return constPoly(Int(1))
+	}
+
+	x, rest := kernels[0], kernels[1:]
+	contU := polyContent(u, x, rest)
+	contV := polyContent(v, x, rest)
+	d := mvPolyGCD(contU, contV, rest)
+
+	ppU, _ := u.exactQuo(contU)
+	ppV, _ := v.exactQuo(contV)
+	if ppU.degreeIn(x) < ppV.degreeIn(x) {
+		ppU, ppV = ppV, ppU
+	}
+	for !ppV.isZero() {
+		r := pseudoRem(ppU, ppV, x)
+		ppU = ppV
+		if r.isZero() {
+			break
+		}
+		ppV, _ = r.exactQuo(polyContent(r, x, rest))
+	}
+
+	// A primitive polynomial of degree zero is a unit
+	if ppU.degreeIn(x) == 0 {
+		return d.monic()
+	}
+	return d.mul(ppU).monic()
+}
+
+// The content of u seen as a polynomial in kernel x, i.e. the gcd of
+// its coefficients, which are polynomials in the rest kernels.
+func polyContent(u polynomial, x int, rest []int) polynomial {
+	g := polynomial{}
+	for n := u.degreeIn(x); n >= 0; n-- {
+		c := u.coefficientIn(x, n)
+		if !c.isZero() {
+			g = mvPolyGCD(g, c, rest)
+		}
+	}
+	return g
+}
+
+// The pseudo-remainder of u divided by v seen as polynomials in kernel x.
+func pseudoRem(u, v polynomial, x int) polynomial {
+	n := v.degreeIn(x)
+	lcv := v.coefficientIn(x, n)
+	delta := u.degreeIn(x) - n + 1
+	r := u
+	for !r.isZero() && r.degreeIn(x) >= n {
+		m := r.degreeIn(x)
+		lcr := r.coefficientIn(x, m)
+		r = lcv.mul(r).sub(lcr.mul(kernelPoly(x, m-n)).mul(v))
+		delta--
+	}
+	return lcv.pow(delta).mul(r)
+}
+
+// Converts p back into an expression using the kernels in kt.
+func (p polynomial) toExpr(kt *kernelTable) Expr {
+	terms := p.sortedTerms()
+	if len(terms) == 0 {
+		return Int(0)
+	}
+
+	ops := make([]Expr, len(terms))
+	for ix, t := range terms {
+		factors := []Expr{t.coeff}
+		for kx, e := range t.mono {
+			if e != 0 {
+				factors = append(factors, Pow(kt.kernels[kx], Int(int64(e))))
+			}
+		}
+		ops[ix] = Mul(factors...)
+	}
+	return Add(ops...).Simplify()
+}
+
+/*
+Converts expr into a (Laurent) polynomial in the kernels of kt. Sums,
+products and integer powers are multiplied out, while every other
+expression becomes a kernel with expanded operands. Negative powers of
+sums become kernels of the form (sum)^-1. False is returned if expr
+contains undefined or a division by zero.
+*/
+func toPolynomial(expr Expr, kt *kernelTable) (polynomial, bool) {
+	switch e := expr.(type) {
+	case undefined:
+		return nil, false
+	case rational:
+		return constPoly(e), true
+	case add:
+		result := polynomial{}
+		for _, op := range e.Operands {
+			p, ok := toPolynomial(op, kt)
+			if !ok {
+				return nil, false
+			}
+			result = result.add(p)
+		}
+		return result, true
+	case mul:
+		result := constPoly(Int(1))
+		for _, op := range e.Operands {
+			p, ok := toPolynomial(op, kt)
+			if !ok {
+				return nil, false
+			}
+			result = kt.reduce(result.mul(p))
+		}
+		return result, true
+	case sqrt:
+		return radicalPolynomial(e.Arg, 1, 2, kt)
+	case pow:
+		if exponent, ok := e.Exponent.(fraction); ok {
+			if r, ok := exponent.simplifyRational().(fraction); ok && r.den.value > 0 {
+				return radicalPolynomial(e.Base, int(r.num.value), int(r.den.value), kt)
+			}
+			break
+		}
+		n, ok := e.Exponent.(integer)
+		if !ok {
+			break
+		}
+		base, ok := toPolynomial(e.Base, kt)
+		if !ok {
+			return nil, false
+		} else if n.value >= 0 {
+			if base.isZero() && n.value == 0 {
+				return nil, false
+			}
+			return kt.reduce(base.pow(int(n.value))), true
+		} else if base.isZero() {
+			return nil, false
+		} else if len(base) == 1 {
+			// Inverting a single term is just inverting
+			// the coefficient and negating the exponents
+			t := base.leadingTerm()
+			mono := make(monomial, len(t.mono))
+			for ix, exponent := range t.mono {
+				mono[ix] = -exponent
+			}
+			inv := polynomial{}
+			inv.addTerm(polyTerm{coeff: ratInv(t.coeff), mono: mono})
+			return inv.pow(int(-n.value)), true
+		}
+		kernel := Pow(base.toExpr(kt), Int(-1))
+		return kernelPoly(kt.index(kernel), int(-n.value)), true
+	}
+
+	kernel, ok := canonicalKernel(expr, Expand)
+	if !ok {
+		return nil, false
+	} else if c, ok := kernel.Simplify().(rational); ok {
+		// Kernels like exp(0) that simplify into numbers
+		return toPolynomial(c, kt)
+	}
+	return kernelPoly(kt.index(kernel), 1), true
+}
+
+/*
+Converts base^(p/q) into a polynomial over kt, where q > 1. Rational
+bases with an exact q:th root are evaluated, e.g. 4^(3/2) = 8, and
+polynomial bases give the radical kernel base^(1/q) raised to p.
+*/
+func radicalPolynomial(baseExpr Expr, p, q int, kt *kernelTable) (polynomial, bool) {
+	base, ok := toPolynomial(baseExpr, kt)
+	if !ok {
+		return nil, false
+	}
+	return kt.radicalPower(base, p, q)
+}
+
+func (kt *kernelTable) radicalPower(base polynomial, p, q int) (polynomial, bool) {
+	if base.isZero() {
+		if p <= 0 {
+			return nil, false
+		}
+		return polynomial{}, true
+	}
+	if c, ok := base.constant(); ok {
+		if root, ok := rationalRoot(c, q); ok {
+			if p < 0 {
+				return constPoly(ratPow(ratInv(root), Int(int64(-p)))), true
+			}
+			return constPoly(ratPow(root, Int(int64(p)))), true
+		}
+		// The denominator d of c = n/d is moved out of the radical as
+		// c^(p/q) = (n*d^(q-1))^(p/q) / d^p, so that e.g. sqrt(1/2) and
+		// sqrt(2) are expressed through the same kernel
+		if d := c.denominator().value; d != 1 && math.Pow(float64(d), float64(q)) < 1e15 {
+			numerator := ratMul(c.numerator(), ratPow(Int(d), Int(int64(q-1))))
+			root, ok := kt.radicalPower(constPoly(numerator), p, q)
+			if !ok {
+				return nil, false
+			}
+			return root.scale(ratPow(Int(d), Int(int64(-p)))), true
+		}
+	}
+	for _, t := range base {
+		for _, e := range t.mono {
+			if e < 0 {
+				// Radicals of Laurent polynomials have no relation
+				// we can use, so they are kept as opaque kernels
+				kernel := Pow(base.toExpr(kt), Div(Int(int64(p)), Int(int64(q))))
+				return kernelPoly(kt.index(kernel), 1), true
+			}
+		}
+	}
+	return kt.reduce(kernelPoly(kt.radical(base, q), p)), true
+}
+
+// Returns the exact q:th root of the non-negative rational c, and
+// false if c is negative or not the q:th power of a rational.
+func rationalRoot(c rational, q int) (rational, bool) {
+	num, ok := intRoot(c.numerator().value, q)
+	if !ok {
+		return nil, false
+	}
+	den, ok := intRoot(c.denominator().value, q)
+	if !ok {
+		return nil, false
+	}
+	return Div(Int(num), Int(den)).(rational).simplifyRational(), true
+}
+
+// Returns the exact q:th root of the non-negative a
+// and false if a is negative or not a perfect power.
+func intRoot(a int64, q int) (int64, bool) {
+	if a < 0 {
+		return 0, false
+	}
+	guess := int64(math.Round(math.Pow(float64(a), 1/float64(q))))
+	target := big.NewInt(a)
+	for r := guess - 1; r <= guess+1; r++ {
+		if r < 0 {
+			continue
+		}
+		power := new(big.Int).Exp(big.NewInt(r), big.NewInt(int64(q)), nil)
+		if power.Cmp(target) == 0 {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// Applies normalize to the operands of expr so that equal kernels are
+// recognised as such. False is returned if any operand is undefined.
+// Kernels that simplify into numbers are handled by the callers.
+func canonicalKernel(expr Expr, normalize func(Expr) Expr) (Expr, bool) {
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		op := normalize(Operand(expr, ix))
+		if _, ok := op.(undefined); ok {
+			return nil, false
+		}
+		expr = replaceOperand(expr, ix, op)
+	}
+	return expr, true
+}
@@ -0,0 +1,75 @@
+package gosymbol
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPolynomialCoefficients(t *testing.T) {
+	x := Var("x")
+	a := Var("a")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput []Expr
+	}{
+		{
+			name:           "Constant",
+			input:          Int(3),
+			expectedOutput: []Expr{Int(3)},
+		},
+		{
+			name:           "Polynomial in expanded form",
+			input:          Add(Int(1), Mul(Int(2), x), Mul(Int(3), Pow(x, Int(2)))),
+			expectedOutput: []Expr{Int(1), Int(2), Int(3)},
+		},
+		{
+			name:           "Power of a sum is multiplied out",
+			input:          Pow(Add(x, Int(1)), Int(2)),
+			expectedOutput: []Expr{Int(1), Int(2), Int(1)},
+		},
+		{
+			name:           "Symbolic coefficients",
+			input:          Mul(a, Add(x, Int(-1))),
+			expectedOutput: []Expr{Mul(Int(-1), a), a},
+		},
+		{
+			name:           "Cancelling leading terms are trimmed",
+			input:          Add(Pow(x, Int(2)), x, Neg(Pow(x, Int(2)))),
+			expectedOutput: []Expr{Int(0), Int(1)},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := polynomialCoefficients(test.input, x)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+
+			if !reflect.DeepEqual(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestPolynomialCoefficientsNotPolynomial(t *testing.T) {
+	x := Var("x")
+	tests := []Expr{
+		Exp(x),
+		Pow(x, Int(-1)),
+		Pow(Int(2), x),
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			_, err := polynomialCoefficients(test, x)
+			if _, ok := err.(*NotPolynomialError); !ok {
+				t.Errorf("Expected NotPolynomialError for %v but got: %v", test, err)
+			}
+		})
+	}
+}
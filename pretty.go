@@ -0,0 +1,530 @@
+package gosymbol
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// The options of the two dimensional printer.
+type PrettyStyle struct {
+	// Draw with ASCII characters only, for terminals without Unicode.
+	ASCII bool
+}
+
+/*
+Returns expr laid out in two dimensions with Unicode characters, e.g.
+
+	   2
+	3⋅x    -x   ___
+	──── - e   + ╲╱ y
+	 2
+
+Quotients are written as vertical fractions, exponents are raised,
+roots get a radical sign and the integrals, sums and products returned
+by ParseLatex get their usual signs. See PrettyWith for ASCII output.
+*/
+func Pretty(expr Expr) string {
+	return PrettyWith(expr, PrettyStyle{})
+}
+
+// Returns expr laid out in two dimensions in the given style, see Pretty.
+func PrettyWith(expr Expr, style PrettyStyle) string {
+	box, _ := prettyPrinter{style}.print(expr)
+	return box.String()
+}
+
+// Returns m laid out in two dimensions, with the entries as in Pretty.
+func PrettyMatrix(m Matrix) string {
+	return PrettyMatrixWith(m, PrettyStyle{})
+}
+
+// Returns m laid out in two dimensions in the given style, see Pretty.
+func PrettyMatrixWith(m Matrix, style PrettyStyle) string {
+	return prettyPrinter{style}.matrix(m).String()
+}
+
+/*
+A block of text in which all lines have the same width. The baseline
+is the line that is aligned with the neighbouring blocks, e.g. the
+fraction bar of a quotient.
+*/
+type prettyBox struct {
+	lines    []string
+	baseline int
+}
+
+func prettyText(s string) prettyBox {
+	return prettyBox{lines: []string{s}}
+}
+
+func (b prettyBox) width() int {
+	return utf8.RuneCountInString(b.lines[0])
+}
+
+func (b prettyBox) height() int {
+	return len(b.lines)
+}
+
+func (b prettyBox) String() string {
+	lines := make([]string, len(b.lines))
+	for ix, line := range b.lines {
+		lines[ix] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Pads s with spaces to the width w, centering it.
+func centered(s string, w int) string {
+	pad := w - utf8.RuneCountInString(s)
+	return strings.Repeat(" ", pad/2) + s + strings.Repeat(" ", pad-pad/2)
+}
+
+// Places boxes side by side, aligning their baselines.
+func prettyRow(boxes ...prettyBox) prettyBox {
+	above, below := 0, 0
+	for _, box := range boxes {
+		above = max(above, box.baseline)
+		below = max(below, box.height()-box.baseline-1)
+	}
+	lines := make([]string, above+below+1)
+	for _, box := range boxes {
+		offset := above - box.baseline
+		blank := strings.Repeat(" ", box.width())
+		for ix := range lines {
+			if ix >= offset && ix-offset < box.height() {
+				lines[ix] += box.lines[ix-offset]
+			} else {
+				lines[ix] += blank
+			}
+		}
+	}
+	return prettyBox{lines: lines, baseline: above}
+}
+
+// Stacks boxes on top of each other, centering them horizontally.
+func prettyColumn(baseline int, boxes ...prettyBox) prettyBox {
+	w := 0
+	for _, box := range boxes {
+		w = max(w, box.width())
+	}
+	lines := []string{}
+	for _, box := range boxes {
+		for _, line := range box.lines {
+			lines = append(lines, centered(line, w))
+		}
+	}
+	return prettyBox{lines: lines, baseline: baseline}
+}
+
+// A delimiter of one line, or the top, middle and bottom of a taller one.
+type prettyDelimiter struct {
+	single, top, middle, bottom string
+}
+
+// Places the delimiters left and right of box, stretched to its height.
+func (b prettyBox) delimited(left, right prettyDelimiter) prettyBox {
+	lines := make([]string, b.height())
+	for ix, line := range b.lines {
+		switch {
+		case b.height() == 1:
+			lines[ix] = left.single + line + right.single
+		case ix == 0:
+			lines[ix] = left.top + line + right.top
+		case ix == b.height()-1:
+			lines[ix] = left.bottom + line + right.bottom
+		default:
+			lines[ix] = left.middle + line + right.middle
+		}
+	}
+	return prettyBox{lines: lines, baseline: b.baseline}
+}
+
+// The characters a style draws with.
+type prettySymbols struct {
+	times, bar, overline                      string
+	leftParen, rightParen                     prettyDelimiter
+	leftBracket, rightBracket, vertical       prettyDelimiter
+	rootRise, rootFall                        string
+	integralTop, integralStem, integralBottom string
+	sumFall, sumRise, productTop, productLegs string
+	imaginaryUnit                             string
+}
+
+var unicodeSymbols = prettySymbols{
+	times: "⋅", bar: "─", overline: "‾",
+	leftParen:      prettyDelimiter{"(", "⎛", "⎜", "⎝"},
+	rightParen:     prettyDelimiter{")", "⎞", "⎟", "⎠"},
+	leftBracket:    prettyDelimiter{"[", "⎡", "⎢", "⎣"},
+	rightBracket:   prettyDelimiter{"]", "⎤", "⎥", "⎦"},
+	vertical:       prettyDelimiter{"│", "│", "│", "│"},
+	rootRise:       "╱",
+	rootFall:       "╲",
+	integralTop:    "⌠",
+	integralStem:   "⎮",
+	integralBottom: "⌡",
+	sumFall:        "╲",
+	sumRise:        "╱",
+	productTop:     "─┬──┬─",
+	productLegs:    " │  │ ",
+	imaginaryUnit:  "ⅈ",
+}
+
+var asciiSymbols = prettySymbols{
+	times: "*", bar: "-", overline: "-",
+	leftParen:      prettyDelimiter{"(", "/", "|", "\\"},
+	rightParen:     prettyDelimiter{")", "\\", "|", "/"},
+	leftBracket:    prettyDelimiter{"[", "[", "[", "["},
+	rightBracket:   prettyDelimiter{"]", "]", "]", "]"},
+	vertical:       prettyDelimiter{"|", "|", "|", "|"},
+	rootRise:       "/",
+	rootFall:       "\\",
+	integralTop:    " /",
+	integralStem:   " |",
+	integralBottom: "/ ",
+	sumFall:        "\\",
+	sumRise:        "/",
+	productTop:     "______",
+	productLegs:    " |  | ",
+	imaginaryUnit:  "I",
+}
+
+// The precedence of the printed expressions, from the weakest binding.
+const (
+	prettySum = iota + 1
+	prettyProduct
+	prettyUnary
+	prettyPower
+	prettyAtom
+)
+
+type prettyPrinter struct {
+	style PrettyStyle
+}
+
+func (p prettyPrinter) symbols() prettySymbols {
+	if p.style.ASCII {
+		return asciiSymbols
+	}
+	return unicodeSymbols
+}
+
+// Returns the layout of expr together with its precedence.
+func (p prettyPrinter) print(expr Expr) (prettyBox, int) {
+	switch e := expr.(type) {
+	case integer:
+		if e.value < 0 {
+			return prettyText(e.String()), prettyUnary
+		}
+		return prettyText(e.String()), prettyAtom
+	case fraction:
+		return p.product([]Expr{e})
+	case add:
+		boxes := []prettyBox{p.box(e.Operands[0])}
+		for _, term := range e.Operands[1:] {
+			if negated, ok := negatedTerm(term); ok {
+				boxes = append(boxes, prettyText(" - "), p.wrap(negated, prettyProduct))
+			} else {
+				boxes = append(boxes, prettyText(" + "), p.box(term))
+			}
+		}
+		return prettyRow(boxes...), prettySum
+	case mul:
+		return p.product(e.Operands)
+	case pow:
+		return p.power(e)
+	case exp:
+		if Equal(e.Arg, Int(1)) {
+			return prettyText("e"), prettyAtom
+		}
+		return p.superscript(prettyText("e"), p.box(e.Arg)), prettyPower
+	case log:
+		return p.call("log", e.Arg), prettyAtom
+	case sqrt:
+		return p.radical(p.box(e.Arg), ""), prettyAtom
+	case sin:
+		return p.call("sin", e.Arg), prettyAtom
+	case cos:
+		return p.call("cos", e.Arg), prettyAtom
+	case FunctionApplication:
+		return p.function(e)
+	case derivative:
+		d := p.fraction(prettyText("d"), prettyText("d"+e.Var.String()))
+		return prettyRow(d, p.wrap(e.Arg, prettyPower)), prettyProduct
+	}
+	return prettyText(Format(expr, CompactFormat)), prettyAtom
+}
+
+func (p prettyPrinter) box(expr Expr) prettyBox {
+	box, _ := p.print(expr)
+	return box
+}
+
+// Lays out expr and parenthesizes it if it binds weaker than precedence.
+func (p prettyPrinter) wrap(expr Expr, precedence int) prettyBox {
+	box, prec := p.print(expr)
+	if prec < precedence {
+		return box.delimited(p.symbols().leftParen, p.symbols().rightParen)
+	}
+	return box
+}
+
+func (p prettyPrinter) call(name string, args ...Expr) prettyBox {
+	boxes := []prettyBox{}
+	for ix, arg := range args {
+		if ix > 0 {
+			boxes = append(boxes, prettyText(", "))
+		}
+		boxes = append(boxes, p.box(arg))
+	}
+	inner := prettyRow(boxes...)
+	if len(args) == 0 {
+		inner = prettyText("")
+	}
+	return prettyRow(prettyText(name), inner.delimited(p.symbols().leftParen, p.symbols().rightParen))
+}
+
+// Raises exponent to the upper right of base.
+func (p prettyPrinter) superscript(base, exponent prettyBox) prettyBox {
+	lines := []string{}
+	for _, line := range exponent.lines {
+		lines = append(lines, strings.Repeat(" ", base.width())+line)
+	}
+	for _, line := range base.lines {
+		lines = append(lines, line+strings.Repeat(" ", exponent.width()))
+	}
+	return prettyBox{lines: lines, baseline: exponent.height() + base.baseline}
+}
+
+func (p prettyPrinter) fraction(numerator, denominator prettyBox) prettyBox {
+	w := max(numerator.width(), denominator.width())
+	bar := prettyText(strings.Repeat(p.symbols().bar, w))
+	return prettyColumn(numerator.height(), numerator, bar, denominator)
+}
+
+/*
+Draws a radical sign of the height of radicand, with the index of the
+root, if any, in its upper left corner.
+*/
+func (p prettyPrinter) radical(radicand prettyBox, index string) prettyBox {
+	radicand = prettyRow(prettyText(" "), radicand)
+	h := radicand.height()
+	extra := max(0, utf8.RuneCountInString(index)-h)
+	prefixWidth := extra + h + 1
+
+	lines := []string{padRight(index, prefixWidth) + strings.Repeat("_", radicand.width())}
+	for r, line := range radicand.lines {
+		prefix := make([]string, prefixWidth)
+		for ix := range prefix {
+			prefix[ix] = " "
+		}
+		prefix[extra+h-r] = p.symbols().rootRise
+		if r == h-1 {
+			prefix[extra] = p.symbols().rootFall
+		}
+		lines = append(lines, strings.Join(prefix, "")+line)
+	}
+	return prettyBox{lines: lines, baseline: 1 + radicand.baseline}
+}
+
+// Pads s with spaces on the right to the width w.
+func padRight(s string, w int) string {
+	return s + strings.Repeat(" ", w-utf8.RuneCountInString(s))
+}
+
+/*
+Lays out a product as a fraction of the factors with positive and
+negative exponents. A negative coefficient becomes a leading minus.
+*/
+func (p prettyPrinter) product(factors []Expr) (prettyBox, int) {
+	negative := false
+	numerator, denominator := []prettyBox{}, []prettyBox{}
+	numeratorFactors, denominatorFactors := []Expr{}, []Expr{}
+	if r, ok := factors[0].(rational); ok && !Equal(r, Undefined()) {
+		factors = factors[1:]
+		num, den := r.numerator(), r.denominator()
+		negative = num.value < 0 != (den.value < 0)
+		num, den = intAbs(num), intAbs(den)
+		if num.value != 1 || len(factors) == 0 {
+			numerator = append(numerator, prettyText(num.String()))
+		}
+		if den.value != 1 {
+			denominator = append(denominator, prettyText(den.String()))
+		}
+	}
+	for _, factor := range factors {
+		if e, ok := factor.(pow); ok && !Equal(e, I) {
+			if r, ok := e.Exponent.(rational); ok && r.approx() < 0 {
+				if Equal(r, Int(-1)) {
+					denominatorFactors = append(denominatorFactors, e.Base)
+				} else {
+					denominatorFactors = append(denominatorFactors, pow{Base: e.Base, Exponent: ratMinus(r)})
+				}
+				continue
+			}
+		}
+		numeratorFactors = append(numeratorFactors, factor)
+	}
+
+	if !negative && len(numerator) == 0 && len(denominator) == 0 && len(numeratorFactors) == 1 && len(denominatorFactors) == 0 {
+		// A single factor keeps its own precedence, e.g. x^2
+		return p.print(numeratorFactors[0])
+	}
+	var box prettyBox
+	prec := prettyProduct
+	if len(denominator) == 0 && len(denominatorFactors) == 0 {
+		box = p.factors(numerator, numeratorFactors)
+		if len(numeratorFactors) == 0 {
+			prec = prettyAtom
+		}
+	} else {
+		box = p.fraction(p.factors(numerator, numeratorFactors), p.factors(denominator, denominatorFactors))
+	}
+	if negative {
+		return prettyRow(prettyText("-"), box), prettyUnary
+	}
+	return box, prec
+}
+
+/*
+Joins the coefficient, if any, and the factors of a numerator or
+denominator with the multiplication sign. A lone factor is not
+parenthesized, since the fraction bar groups it.
+*/
+func (p prettyPrinter) factors(coefficient []prettyBox, factors []Expr) prettyBox {
+	if len(coefficient) == 0 && len(factors) == 1 {
+		return p.box(factors[0])
+	}
+	boxes := append([]prettyBox{}, coefficient...)
+	for _, factor := range factors {
+		boxes = append(boxes, p.wrap(factor, prettyPower))
+	}
+	if len(boxes) == 0 {
+		return prettyText("1")
+	}
+	row := []prettyBox{boxes[0]}
+	for _, box := range boxes[1:] {
+		row = append(row, prettyText(p.symbols().times), box)
+	}
+	return prettyRow(row...)
+}
+
+func (p prettyPrinter) power(e pow) (prettyBox, int) {
+	if Equal(e, I) {
+		return prettyText(p.symbols().imaginaryUnit), prettyAtom
+	}
+	r, isRational := e.Exponent.(rational)
+	if isRational && r.approx() < 0 {
+		return p.product([]Expr{e})
+	}
+	if isRational && r.numerator() == Int(1) && r.denominator().value > 1 {
+		index := ""
+		if r.denominator() != Int(2) {
+			index = r.denominator().String()
+		}
+		return p.radical(p.box(e.Base), index), prettyAtom
+	}
+	exponent := p.box(e.Exponent)
+	if _, ok := e.Exponent.(fraction); ok {
+		exponent = prettyText(r.String())
+	}
+	return p.superscript(p.wrap(e.Base, prettyAtom), exponent), prettyPower
+}
+
+func (p prettyPrinter) function(f FunctionApplication) (prettyBox, int) {
+	args := f.Args
+	switch {
+	case f.Name == absName && len(args) == 1:
+		return p.box(args[0]).delimited(p.symbols().vertical, p.symbols().vertical), prettyAtom
+	case f.Name == factorialName && len(args) == 1:
+		return prettyRow(p.wrap(args[0], prettyAtom), prettyText("!")), prettyAtom
+	case f.Name == integrateName && (len(args) == 2 || len(args) == 4):
+		body := prettyRow(p.wrap(args[0], prettyProduct), prettyText(" d"), p.box(args[1]))
+		sign := []prettyBox{prettyText(p.symbols().integralTop)}
+		for ix := 0; ix < body.height(); ix++ {
+			sign = append(sign, prettyText(p.symbols().integralStem))
+		}
+		sign = append(sign, prettyText(p.symbols().integralBottom))
+		bounds := []prettyBox{}
+		if len(args) == 4 {
+			bounds = append(bounds, p.box(args[2]), p.box(args[3]))
+		}
+		return p.bigOperator(sign, 1+body.baseline, body, bounds...), prettySum
+	case f.Name == sumName && len(args) == 4:
+		body := p.wrap(args[0], prettyProduct)
+		n := max(1, (body.height()+1)/2)
+		sign := []prettyBox{prettyText(strings.Repeat("_", n+1))}
+		for ix := 0; ix < n; ix++ {
+			sign = append(sign, prettyText(strings.Repeat(" ", ix)+p.symbols().sumFall+strings.Repeat(" ", n-ix)))
+		}
+		for ix := n - 1; ix >= 0; ix-- {
+			sign = append(sign, prettyText(strings.Repeat(" ", ix)+p.symbols().sumRise+strings.Repeat(" ", n-ix)))
+		}
+		sign = append(sign, prettyText(strings.Repeat(p.symbols().overline, n+1)))
+		baseline := 1 + (2*n-body.height())/2 + body.baseline
+		return p.bigOperator(sign, baseline, body, p.lowerBound(args[1], args[2]), p.box(args[3])), prettySum
+	case f.Name == productName && len(args) == 4:
+		body := p.wrap(args[0], prettyProduct)
+		sign := []prettyBox{prettyText(p.symbols().productTop)}
+		for ix := 0; ix < body.height(); ix++ {
+			sign = append(sign, prettyText(p.symbols().productLegs))
+		}
+		return p.bigOperator(sign, 1+body.baseline, body, p.lowerBound(args[1], args[2]), p.box(args[3])), prettySum
+	}
+	return p.call(string(f.Name), args...), prettyAtom
+}
+
+// The lower bound k = a of a sum or product.
+func (p prettyPrinter) lowerBound(k, a Expr) prettyBox {
+	return prettyRow(p.box(k), prettyText(" = "), p.box(a))
+}
+
+/*
+Places the lines of an integral, sum or product sign left of body,
+with the lower and upper bound, if given, below and above the sign.
+The row baseline of the sign is aligned with the baseline of body.
+*/
+func (p prettyPrinter) bigOperator(sign []prettyBox, baseline int, body prettyBox, bounds ...prettyBox) prettyBox {
+	column := sign
+	if len(bounds) == 2 {
+		lower, upper := bounds[0], bounds[1]
+		column = append(append([]prettyBox{upper}, sign...), lower)
+		baseline += upper.height()
+	}
+	return prettyRow(prettyColumn(baseline, column...), prettyText(" "), body)
+}
+
+func (p prettyPrinter) matrix(m Matrix) prettyBox {
+	if m.rows == 0 || m.cols == 0 {
+		return prettyText("[]")
+	}
+	cells := make([]prettyBox, len(m.entries))
+	widths := make([]int, m.cols)
+	for ix, entry := range m.entries {
+		cells[ix] = p.box(entry)
+		widths[ix%m.cols] = max(widths[ix%m.cols], cells[ix].width())
+	}
+	rows := []prettyBox{}
+	for i := 0; i < m.rows; i++ {
+		if i > 0 {
+			// A blank line between the rows
+			rows = append(rows, prettyText(""))
+		}
+		row := []prettyBox{}
+		for j := 0; j < m.cols; j++ {
+			if j > 0 {
+				row = append(row, prettyText("  "))
+			}
+			cell := cells[i*m.cols+j]
+			lines := make([]string, cell.height())
+			for ix, line := range cell.lines {
+				lines[ix] = centered(line, widths[j])
+			}
+			row = append(row, prettyBox{lines: lines, baseline: cell.baseline})
+		}
+		rows = append(rows, prettyRow(row...))
+	}
+	grid := prettyColumn(0, rows...)
+	// Rows are as wide as each other, so centering does not move them
+	grid.baseline = grid.height() / 2
+	return grid.delimited(p.symbols().leftBracket, p.symbols().rightBracket)
+}
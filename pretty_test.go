@@ -0,0 +1,161 @@
+package gosymbol
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPretty(t *testing.T) {
+	x, y, k, n := Var("x"), Var("y"), Var("k"), Var("n")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput []string
+	}{
+		{
+			name:  "Example from the documentation",
+			input: Add(Mul(Div(Int(3), Int(2)), Pow(x, Int(2))), Neg(Exp(Neg(x))), Sqrt(y)),
+			expectedOutput: []string{
+				"   2",
+				"3⋅x     -x     __",
+				"──── - e   + ╲╱ y",
+				" 2",
+			},
+		},
+		{name: "Difference", input: Sub(x, Mul(Int(2), y)), expectedOutput: []string{"x - 2⋅y"}},
+		{
+			name:           "Quotient of sums",
+			input:          Div(Add(x, Int(1)), Sub(x, Int(1))),
+			expectedOutput: []string{"x + 1", "─────", "x - 1"},
+		},
+		{
+			name:           "Negative quotient",
+			input:          Neg(Div(x, y)),
+			expectedOutput: []string{" x", "-─", " y"},
+		},
+		{
+			name:           "Power of a quotient",
+			input:          Pow(Div(x, y), Int(2)),
+			expectedOutput: []string{"   2", "⎛x⎞", "⎜─⎟", "⎝y⎠"},
+		},
+		{name: "Nested exponents", input: Pow(Int(2), Pow(x, Int(2))), expectedOutput: []string{"  2", " x", "2"}},
+		{name: "Rational exponent", input: Pow(x, Div(Int(3), Int(2))), expectedOutput: []string{" 3/2", "x"}},
+		{
+			name:           "Cube root",
+			input:          Pow(Add(x, Int(1)), Div(Int(1), Int(3))),
+			expectedOutput: []string{"3 ______", "╲╱ x + 1"},
+		},
+		{
+			name:  "Tall radical",
+			input: Sqrt(Div(x, Add(y, Int(1)))),
+			expectedOutput: []string{
+				"    ______",
+				"   ╱   x",
+				"  ╱  ─────",
+				"╲╱   y + 1",
+			},
+		},
+		{name: "Imaginary unit", input: Mul(Int(2), I), expectedOutput: []string{"2⋅ⅈ"}},
+		{name: "Absolute value", input: Abs(Div(x, y)), expectedOutput: []string{"│x│", "│─│", "│y│"}},
+		{name: "Factorial", input: Factorial(Add(n, Int(1))), expectedOutput: []string{"(n + 1)!"}},
+		{name: "Function", input: Function("f", x, Pow(y, Int(2))), expectedOutput: []string{" ⎛    2⎞", "f⎝x, y ⎠"}},
+		{name: "Derivative", input: Derivative(Function("f", x), x), expectedOutput: []string{"d", "──f(x)", "dx"}},
+		{
+			name:  "Sum",
+			input: Function(sumName, Div(Int(1), Pow(k, Int(2))), k, Int(1), n),
+			expectedOutput: []string{
+				"  n",
+				" ___",
+				" ╲    1",
+				"  ╲   ──",
+				"  ╱    2",
+				" ╱    k",
+				" ‾‾‾",
+				"k = 1",
+			},
+		},
+		{
+			name:           "Product",
+			input:          Function(productName, k, k, Int(1), n),
+			expectedOutput: []string{"  n", "─┬──┬─", " │  │  k", "k = 1"},
+		},
+		{
+			name:           "Definite integral",
+			input:          Function(integrateName, Pow(x, Int(2)), x, Int(0), Int(1)),
+			expectedOutput: []string{"1", "⌠", "⎮  2", "⎮ x  dx", "⌡", "0"},
+		},
+		{
+			name:           "Indefinite integral",
+			input:          Function(integrateName, Sin(x), x),
+			expectedOutput: []string{"⌠", "⎮ sin(x) dx", "⌡"},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := Pretty(test.input)
+			if expected := strings.Join(test.expectedOutput, "\n"); result != expected {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected:\n%v\nGot:\n%v", test.name, test.input, expected, result)
+			}
+		})
+	}
+}
+
+func TestPrettyASCII(t *testing.T) {
+	x, y := Var("x"), Var("y")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput []string
+	}{
+		{
+			name:           "Fraction and radical",
+			input:          Add(Div(Mul(Int(3), x), Int(2)), Sqrt(y)),
+			expectedOutput: []string{"3*x     __", "--- + \\/ y", " 2"},
+		},
+		{
+			name:           "Parentheses",
+			input:          Pow(Div(x, y), Int(2)),
+			expectedOutput: []string{"   2", "/x\\", "|-|", "\\y/"},
+		},
+		{
+			name:           "Integral",
+			input:          Function(integrateName, Sin(x), x),
+			expectedOutput: []string{" /", " | sin(x) dx", "/"},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := PrettyWith(test.input, PrettyStyle{ASCII: true})
+			if expected := strings.Join(test.expectedOutput, "\n"); result != expected {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected:\n%v\nGot:\n%v", test.name, test.input, expected, result)
+			}
+		})
+	}
+}
+
+func TestPrettyMatrix(t *testing.T) {
+	x, y := Var("x"), Var("y")
+	m, _ := NewMatrix([][]Expr{{Int(1), Div(x, Int(2))}, {Pow(y, Int(2)), Int(-10)}})
+
+	expected := strings.Join([]string{
+		"⎡     x ⎤",
+		"⎢1    ─ ⎥",
+		"⎢     2 ⎥",
+		"⎢       ⎥",
+		"⎢ 2     ⎥",
+		"⎣y   -10⎦",
+	}, "\n")
+	if result := PrettyMatrix(m); result != expected {
+		t.Errorf("Following test failed: matrix\nInput: %v\nExpected:\n%v\nGot:\n%v", m, expected, result)
+	}
+
+	row, _ := NewMatrix([][]Expr{{Int(1), x}})
+	if result := PrettyMatrixWith(row, PrettyStyle{ASCII: true}); result != "[1  x]" {
+		t.Errorf("Following test failed: row vector\nInput: %v\nExpected:\n%v\nGot:\n%v", row, "[1  x]", result)
+	}
+}
@@ -0,0 +1,239 @@
+package gosymbol
+
+import (
+	"math/big"
+)
+
+// Perfect powers are only searched for among the numbers below
+// this bound when normalizing radicals of integers beyond int64.
+const maxRadicalTrialDivisor = 1 << 16
+
+// The n:th root of arg as the power arg^(1/n).
+func Root(arg Expr, n int64) pow {
+	return Pow(arg, Div(Int(1), Int(n)))
+}
+
+/*
+Returns the normal form of the radical c^(p/q) for a rational c > 0,
+which is r * b^(s/q) with r rational, 0 < s < q and b an integer with
+no q:th power factors, e.g. 8^(1/2) = 2*2^(1/2), 4^(3/2) = 8 and
+(1/2)^(1/2) = 1/2*2^(1/2). Denominators are moved out of the radical,
+which rationalizes them. False is returned if c^(p/q) is already in
+normal form, or if the normal form does not fit in int64.
+*/
+func radicalNormalForm(c rational, exponent fraction) (Expr, bool) {
+	p, q := exponent.numerator().value, exponent.denominator().value
+	if q <= 1 {
+		return nil, false
+	}
+	k := p / q
+	if p%q < 0 {
+		k--
+	}
+	s := p - k*q
+
+	// c^(s/q) = (n*d^(q-1))^(s/q) / d^s
+	n, d := big.NewInt(c.numerator().value), big.NewInt(c.denominator().value)
+	radicand := new(big.Int).Mul(n, new(big.Int).Exp(d, big.NewInt(q-1), nil))
+	outside, inside := extractPowers(radicand, q)
+	if k == 0 && d.IsInt64() && d.Int64() == 1 && outside.Cmp(big.NewInt(1)) == 0 {
+		return nil, false
+	}
+
+	coeff := new(big.Rat).SetFrac(new(big.Int).Exp(outside, big.NewInt(s), nil), new(big.Int).Exp(d, big.NewInt(s), nil))
+	if k >= 0 {
+		num := new(big.Int).Exp(n, big.NewInt(k), nil)
+		den := new(big.Int).Exp(d, big.NewInt(k), nil)
+		coeff.Mul(coeff, new(big.Rat).SetFrac(num, den))
+	} else {
+		num := new(big.Int).Exp(d, big.NewInt(-k), nil)
+		den := new(big.Int).Exp(n, big.NewInt(-k), nil)
+		coeff.Mul(coeff, new(big.Rat).SetFrac(num, den))
+	}
+	r, ok := bigRatToRational(coeff)
+	if !ok || !inside.IsInt64() {
+		return nil, false
+	} else if inside.Cmp(big.NewInt(1)) == 0 {
+		return r, true
+	}
+	return Mul(r, Pow(Int(inside.Int64()), Div(Int(s), Int(q)))), true
+}
+
+/*
+Writes the positive integer m as a^q * b, where b has no q:th power
+factors, and returns a and b. The powers are read off the prime
+factorization of m when m fits in an int64. Larger m are only
+searched for factors below maxRadicalTrialDivisor, and for square
+roots a remaining cofactor that is a perfect square is also moved
+out.
+*/
+func extractPowers(m *big.Int, q int64) (*big.Int, *big.Int) {
+	if m.IsInt64() {
+		if factors, err := FactorInteger(m.Int64()); err == nil {
+			outside, inside := big.NewInt(1), big.NewInt(1)
+			for _, f := range factors {
+				p := big.NewInt(f.Prime)
+				outside.Mul(outside, new(big.Int).Exp(p, big.NewInt(f.Exponent/q), nil))
+				inside.Mul(inside, new(big.Int).Exp(p, big.NewInt(f.Exponent%q), nil))
+			}
+			return outside, inside
+		}
+	}
+
+	outside, inside := big.NewInt(1), new(big.Int).Set(m)
+	rem := new(big.Int)
+	for f := int64(2); f < maxRadicalTrialDivisor; f++ {
+		factor := big.NewInt(f)
+		power := new(big.Int).Exp(factor, big.NewInt(q), nil)
+		if power.Cmp(inside) > 0 {
+			break
+		}
+		for {
+			quo, r := new(big.Int).QuoRem(inside, power, rem)
+			if r.Sign() != 0 {
+				break
+			}
+			inside = quo
+			outside.Mul(outside, factor)
+		}
+	}
+	if root := new(big.Int).Sqrt(inside); q == 2 && new(big.Int).Mul(root, root).Cmp(inside) == 0 {
+		return outside.Mul(outside, root), big.NewInt(1)
+	}
+	return outside, inside
+}
+
+func bigRatToRational(r *big.Rat) (rational, bool) {
+	if !r.Num().IsInt64() || !r.Denom().IsInt64() {
+		return nil, false
+	}
+	if r.IsInt() {
+		return Int(r.Num().Int64()), true
+	}
+	return Div(Int(r.Num().Int64()), Int(r.Denom().Int64())).(rational), true
+}
+
+func numericRadicalNormalForm(expr Expr) (Expr, bool) {
+	if !isNumericRadical(expr) {
+		return nil, false
+	}
+	power := expr.(pow)
+	return radicalNormalForm(power.Base.(rational), power.Exponent.(fraction))
+}
+
+// Returns true if expr is r^e for a rational r > 0 and a non-integer e.
+func isNumericRadical(expr Expr) bool {
+	power, ok := expr.(pow)
+	if !ok {
+		return false
+	}
+	base, ok1 := power.Base.(rational)
+	_, ok2 := power.Exponent.(fraction)
+	return ok1 && ok2 && base.approx() > 0
+}
+
+/*
+Returns the product of the numeric radicals a and b if they have the
+same base or the same exponent, e.g. 2^(1/2)*2^(1/3) = 2^(5/6) and
+2^(1/2)*3^(1/2) = 6^(1/2).
+*/
+func mergeRadicals(a, b Expr) (Expr, bool) {
+	if !isNumericRadical(a) || !isNumericRadical(b) {
+		return nil, false
+	}
+	pa, pb := a.(pow), b.(pow)
+	if Equal(pa.Base, pb.Base) {
+		return Pow(pa.Base, ratAdd(pa.Exponent.(rational), pb.Exponent.(rational))), true
+	} else if Equal(pa.Exponent, pb.Exponent) {
+		return Pow(ratMul(pa.Base.(rational), pb.Base.(rational)), pa.Exponent), true
+	}
+	return nil, false
+}
+
+// Merges the first two factors of product that mergeRadicals can merge.
+func mergeRadicalFactors(product mul) (Expr, bool) {
+	for ix, a := range product.Operands {
+		for jx := ix + 1; jx < len(product.Operands); jx++ {
+			merged, ok := mergeRadicals(a, product.Operands[jx])
+			if !ok {
+				continue
+			}
+			factors := append([]Expr{}, product.Operands[:ix]...)
+			factors = append(factors, merged)
+			factors = append(factors, product.Operands[ix+1:jx]...)
+			factors = append(factors, product.Operands[jx+1:]...)
+			return Mul(factors...), true
+		}
+	}
+	return nil, false
+}
+
+/*
+Returns a, b and c if expr is the quadratic surd a + b*c^(1/2) with
+rationals a, b and c, where c > 0 is not a square.
+*/
+func quadraticSurd(expr Expr) (rational, rational, rational, bool) {
+	sum, ok := expr.(add)
+	if !ok || len(sum.Operands) != 2 {
+		return nil, nil, nil, false
+	}
+	a, ok := sum.Operands[0].(rational)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	var b rational = Int(1)
+	radical := sum.Operands[1]
+	if product, ok := radical.(mul); ok && len(product.Operands) == 2 {
+		if b, ok = product.Operands[0].(rational); !ok {
+			return nil, nil, nil, false
+		}
+		radical = product.Operands[1]
+	}
+	power, ok := radical.(pow)
+	if !ok || !isNumericRadical(power) || !Equal(power.Exponent, Div(Int(1), Int(2))) {
+		return nil, nil, nil, false
+	}
+	c := power.Base.(rational)
+	if _, ok := rationalRoot(c, 2); ok {
+		return nil, nil, nil, false
+	}
+	return a, b, c, true
+}
+
+/*
+Denests sqrt(a + b*sqrt(c)) into sqrt((a + d)/2) ± sqrt((a - d)/2),
+where d = sqrt(a^2 - b^2*c) must be rational and the sign is that of
+b, e.g. sqrt(3 + 2*sqrt(2)) = 1 + sqrt(2).
+*/
+func denestSquareRoot(radicand Expr) (Expr, bool) {
+	a, b, c, ok := quadraticSurd(radicand)
+	if !ok || a.approx() <= 0 {
+		return nil, false
+	}
+	disc := ratSubtract(ratMul(a, a), ratMul(ratMul(b, b), c))
+	d, ok := rationalRoot(disc, 2)
+	if !ok {
+		return nil, false
+	}
+	half := Div(Int(1), Int(2))
+	first := Pow(ratMul(ratAdd(a, d), half.(rational)), half)
+	second := Pow(ratMul(ratSubtract(a, d), half.(rational)), half)
+	if b.approx() < 0 {
+		return Sub(first, second), true
+	}
+	return Add(first, second), true
+}
+
+// Rationalizes 1/(a + b*sqrt(c)) = (a - b*sqrt(c))/(a^2 - b^2*c).
+func rationalizeSurd(denominator Expr) (Expr, bool) {
+	a, b, c, ok := quadraticSurd(denominator)
+	if !ok {
+		return nil, false
+	}
+	norm := ratSubtract(ratMul(a, a), ratMul(ratMul(b, b), c))
+	if norm.approx() == 0 {
+		return nil, false
+	}
+	radical := Pow(c, Div(Int(1), Int(2)))
+	return Add(ratDiv(a, norm), Mul(ratDiv(ratMinus(b), norm), radical)), true
+}
@@ -0,0 +1,85 @@
+package gosymbol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRadicalSimplify(t *testing.T) {
+	half := Div(Int(1), Int(2))
+	x := Var("x")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput Expr
+	}{
+		{name: "sqrt(x) = x^(1/2)", input: Sqrt(x), expectedOutput: Pow(x, half)},
+		{name: "Root(x, 3) = x^(1/3)", input: Root(x, 3), expectedOutput: Pow(x, Div(Int(1), Int(3)))},
+		{name: "sqrt(8) = 2*sqrt(2)", input: Sqrt(Int(8)), expectedOutput: Mul(Int(2), Pow(Int(2), half))},
+		{name: "sqrt(16) = 4", input: Sqrt(Int(16)), expectedOutput: Int(4)},
+		{name: "16^(1/3) = 2*2^(1/3)", input: Root(Int(16), 3), expectedOutput: Mul(Int(2), Pow(Int(2), Div(Int(1), Int(3))))},
+		{name: "4^(3/2) = 8", input: Pow(Int(4), Div(Int(3), Int(2))), expectedOutput: Int(8)},
+		{name: "sqrt(2)^3 = 2*sqrt(2)", input: Pow(Sqrt(Int(2)), Int(3)), expectedOutput: Mul(Int(2), Pow(Int(2), half))},
+		{name: "sqrt(1/2) = sqrt(2)/2", input: Sqrt(Div(Int(1), Int(2))), expectedOutput: Mul(half, Pow(Int(2), half))},
+		{name: "2^(-1/2) = sqrt(2)/2", input: Pow(Int(2), Div(Int(-1), Int(2))), expectedOutput: Mul(half, Pow(Int(2), half))},
+		{name: "8^(2/3) = 4", input: Pow(Int(8), Div(Int(2), Int(3))), expectedOutput: Int(4)},
+		{name: "12^(1/2) = 2*3^(1/2)", input: Pow(Int(12), half), expectedOutput: Mul(Int(2), Pow(Int(3), half))},
+		{name: "(4/9)^(1/2) = 2/3", input: Pow(Div(Int(4), Int(9)), half), expectedOutput: Div(Int(2), Int(3))},
+		{name: "27^(-2/3) = 1/9", input: Pow(Int(27), Div(Int(-2), Int(3))), expectedOutput: Div(Int(1), Int(9))},
+		{name: "Large square factors are extracted", input: Sqrt(Int(2 * 1000003 * 1000003)), expectedOutput: Mul(Int(1000003), Pow(Int(2), half))},
+		{name: "Overflowing powers are not evaluated", input: Pow(Int(3), Int(50)), expectedOutput: Pow(Int(3), Int(50))},
+		{name: "sqrt(2)*sqrt(2) = 2", input: Mul(Sqrt(Int(2)), Sqrt(Int(2))), expectedOutput: Int(2)},
+		{name: "sqrt(2)*sqrt(3) = sqrt(6)", input: Mul(Sqrt(Int(2)), Sqrt(Int(3))), expectedOutput: Pow(Int(6), half)},
+		{name: "3*sqrt(2)*sqrt(6) = 6*sqrt(3)", input: Mul(Int(3), Sqrt(Int(2)), Sqrt(Int(6))), expectedOutput: Mul(Int(6), Pow(Int(3), half))},
+		{name: "sqrt(2)*2^(1/3) = 2^(5/6)", input: Mul(Sqrt(Int(2)), Root(Int(2), 3)), expectedOutput: Pow(Int(2), Div(Int(5), Int(6)))},
+		{name: "sqrt(sqrt(16)) = 2", input: Sqrt(Sqrt(Int(16))), expectedOutput: Int(2)},
+		{name: "sqrt(-8) = 2*sqrt(2)*I", input: Sqrt(Int(-8)), expectedOutput: Mul(Int(2), I, Pow(Int(2), half))},
+		{
+			name:           "sqrt(3 + 2*sqrt(2)) = 1 + sqrt(2)",
+			input:          Sqrt(Add(Int(3), Mul(Int(2), Sqrt(Int(2))))),
+			expectedOutput: Add(Int(1), Pow(Int(2), half)),
+		},
+		{
+			name:           "sqrt(5 - 2*sqrt(6)) = sqrt(3) - sqrt(2)",
+			input:          Sqrt(Sub(Int(5), Mul(Int(2), Sqrt(Int(6))))),
+			expectedOutput: Add(Mul(Int(-1), Pow(Int(2), half)), Pow(Int(3), half)),
+		},
+		{
+			name:           "sqrt(2 + sqrt(2)) can not be denested",
+			input:          Sqrt(Add(Int(2), Sqrt(Int(2)))),
+			expectedOutput: Pow(Add(Int(2), Pow(Int(2), half)), half),
+		},
+		{
+			name:           "1/(1 + sqrt(2)) = sqrt(2) - 1",
+			input:          Div(Int(1), Add(Int(1), Sqrt(Int(2)))),
+			expectedOutput: Add(Int(-1), Pow(Int(2), half)),
+		},
+		{
+			name:           "1/(3 - 2*sqrt(2)) = 3 + 2*sqrt(2)",
+			input:          Div(Int(1), Sub(Int(3), Mul(Int(2), Sqrt(Int(2))))),
+			expectedOutput: Add(Int(3), Mul(Int(2), Pow(Int(2), half))),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := test.input.Simplify()
+			if !Equal(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestSqrtStringAndEval(t *testing.T) {
+	x := Var("x")
+	if result := Sqrt(x).String(); result != "sqrt(x)" {
+		t.Errorf("Following test failed: String of sqrt\nExpected: %v\nGot: %v", "sqrt(x)", result)
+	}
+	result := Sqrt(x).Eval()(Arguments{x: Int(18)})
+	expected := Mul(Int(3), Pow(Int(2), Div(Int(1), Int(2))))
+	if !Equal(result, expected) {
+		t.Errorf("Following test failed: Eval of sqrt\nInput: %v\nExpected: %v\nGot: %v", Sqrt(x), expected, result)
+	}
+}
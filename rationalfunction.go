@@ -0,0 +1,308 @@
+package gosymbol
+
+/*
+A ratFunc is a quotient of two multivariate polynomials in the kernels of
+some kernelTable. It is kept in canonical form, i.e. num and den have no
+common factor and den is monic, which means that two ratFuncs represent
+the same rational function exactly when their numerators and denominators
+are equal. In particular a ratFunc is zero if and only if num is zero.
+
+Powers of radical kernels are reduced using the relations in kt, and
+square roots are removed from the denominator by multiplying with the
+conjugate, so the canonical form is kept for algebraic kernels as well.
+*/
+type ratFunc struct {
+	num polynomial
+	den polynomial
+	kt  *kernelTable // Nil for constants, which need no reduction
+}
+
+func constRatFunc(c rational) ratFunc {
+	return ratFunc{num: constPoly(c), den: constPoly(Int(1))}
+}
+
+// Returns num/den in canonical form. The denominator must not be zero.
+func newRatFunc(num, den polynomial, kt *kernelTable) ratFunc {
+	if kt != nil {
+		num, den = kt.reduce(num), kt.reduce(den)
+		num, den = kt.rationalize(num, den)
+	}
+	if num.isZero() {
+		return ratFunc{num: polynomial{}, den: constPoly(Int(1)), kt: kt}
+	}
+	if _, ok := den.constant(); !ok {
+		g := polyGCD(num, den)
+		num, _ = num.exactQuo(g)
+		den, _ = den.exactQuo(g)
+	}
+	lc := ratInv(den.leadingTerm().coeff)
+	return ratFunc{num: num.scale(lc), den: den.scale(lc), kt: kt}
+}
+
+/*
+Removes square root kernels from the denominator of num/den by repeatedly
+multiplying with the conjugate, i.e. the denominator p + q*sqrt(b) becomes
+p^2 - q^2*b. The kernels are handled in decreasing order since the base of
+a square root only contains kernels that were added before it.
+*/
+func (kt *kernelTable) rationalize(num, den polynomial) (polynomial, polynomial) {
+	for ix := len(kt.kernels) - 1; ix >= 0; ix-- {
+		if r, ok := kt.radicals[ix]; !ok || r.degree != 2 || den.degreeIn(ix) < 1 {
+			continue
+		}
+		conjugate := polynomial{}
+		for _, t := range den {
+			if t.mono.exponent(ix)%2 == 1 {
+				t.coeff = ratMinus(t.coeff)
+			}
+			conjugate.addTerm(t)
+		}
+		num = kt.reduce(num.mul(conjugate))
+		den = kt.reduce(den.mul(conjugate))
+	}
+	return num, den
+}
+
+// The kernel table shared by a and b, if any.
+func (a ratFunc) table(b ratFunc) *kernelTable {
+	if a.kt != nil {
+		return a.kt
+	}
+	return b.kt
+}
+
+func (a ratFunc) add(b ratFunc) ratFunc {
+	if a.den.equal(b.den) {
+		return newRatFunc(a.num.add(b.num), a.den, a.table(b))
+	}
+	return newRatFunc(a.num.mul(b.den).add(b.num.mul(a.den)), a.den.mul(b.den), a.table(b))
+}
+
+func (a ratFunc) neg() ratFunc {
+	return ratFunc{num: a.num.neg(), den: a.den, kt: a.kt}
+}
+
+func (a ratFunc) sub(b ratFunc) ratFunc {
+	return a.add(b.neg())
+}
+
+func (a ratFunc) mul(b ratFunc) ratFunc {
+	return newRatFunc(a.num.mul(b.num), a.den.mul(b.den), a.table(b))
+}
+
+// Returns 1/a and false if a is zero.
+func (a ratFunc) inv() (ratFunc, bool) {
+	if a.isZero() {
+		return ratFunc{}, false
+	}
+	return newRatFunc(a.den, a.num, a.kt), true
+}
+
+// Returns a/b and false if b is zero.
+func (a ratFunc) div(b ratFunc) (ratFunc, bool) {
+	bInv, ok := b.inv()
+	if !ok {
+		return ratFunc{}, false
+	}
+	return a.mul(bInv), true
+}
+
+func (a ratFunc) pow(n int) ratFunc {
+	if n < 0 {
+		// Callers make sure a is not zero
+		a, _ = a.inv()
+		n = -n
+	}
+	return newRatFunc(a.num.pow(n), a.den.pow(n), a.kt)
+}
+
+func (a ratFunc) isZero() bool {
+	return a.num.isZero()
+}
+
+func (a ratFunc) equal(b ratFunc) bool {
+	return a.num.equal(b.num) && a.den.equal(b.den)
+}
+
+func (a ratFunc) toExpr(kt *kernelTable) Expr {
+	num := a.num.toExpr(kt)
+	if _, ok := a.den.constant(); ok {
+		return num
+	}
+
+	// A monomial denominator is written as negative
+	// powers of the kernels instead of as a power of
+	// a product, e.g. x/y instead of x*(y)^-1
+	if len(a.den) == 1 {
+		factors := []Expr{num}
+		for kx, e := range a.den.leadingTerm().mono {
+			if e != 0 {
+				factors = append(factors, Pow(kt.kernels[kx], Int(int64(-e))))
+			}
+		}
+		return Mul(factors...).Simplify()
+	}
+	return Mul(num, Pow(a.den.toExpr(kt), Int(-1))).Simplify()
+}
+
+/*
+Converts expr into a rational function in the kernels of kt. Sums,
+products and integer powers are combined over a common denominator,
+while every other expression becomes a kernel with canonical operands.
+False is returned if expr contains undefined or a division by zero.
+*/
+func toRatFunc(expr Expr, kt *kernelTable) (ratFunc, bool) {
+	switch e := expr.(type) {
+	case undefined:
+		return ratFunc{}, false
+	case rational:
+		if e.denominator() == Int(0) {
+			return ratFunc{}, false
+		}
+		return constRatFunc(e), true
+	case add:
+		result := constRatFunc(Int(0))
+		for _, op := range e.Operands {
+			r, ok := toRatFunc(op, kt)
+			if !ok {
+				return ratFunc{}, false
+			}
+			result = result.add(r)
+		}
+		return result, true
+	case mul:
+		result := constRatFunc(Int(1))
+		for _, op := range e.Operands {
+			r, ok := toRatFunc(op, kt)
+			if !ok {
+				return ratFunc{}, false
+			}
+			result = result.mul(r)
+		}
+		return result, true
+	case sqrt:
+		return radicalRatFunc(e.Arg, 1, 2, kt)
+	case pow:
+		if exponent, ok := e.Exponent.(fraction); ok {
+			if r, ok := exponent.simplifyRational().(fraction); ok && r.den.value > 0 {
+				return radicalRatFunc(e.Base, int(r.num.value), int(r.den.value), kt)
+			}
+			break
+		}
+		n, ok := e.Exponent.(integer)
+		if !ok {
+			break
+		}
+		base, ok := toRatFunc(e.Base, kt)
+		if !ok || (base.isZero() && n.value <= 0) {
+			return ratFunc{}, false
+		}
+		return base.pow(int(n.value)), true
+	}
+
+	kernel, ok := canonicalKernel(expr, Cancel)
+	if !ok {
+		return ratFunc{}, false
+	} else if c, ok := kernel.Simplify().(rational); ok {
+		return toRatFunc(c, kt)
+	}
+	return newRatFunc(kernelPoly(kt.index(kernel), 1), constPoly(Int(1)), kt), true
+}
+
+// Converts base^(p/q) into a rational function over kt, where q > 1,
+// in the same way as radicalPolynomial.
+func radicalRatFunc(baseExpr Expr, p, q int, kt *kernelTable) (ratFunc, bool) {
+	base, ok := toRatFunc(baseExpr, kt)
+	if !ok || (base.isZero() && p <= 0) {
+		return ratFunc{}, false
+	} else if base.isZero() {
+		return base, true
+	}
+	if _, ok := base.den.constant(); !ok {
+		// Only radicals of polynomials have a relation we can use
+		kernel := Pow(base.toExpr(kt), Div(Int(int64(p)), Int(int64(q))))
+		return newRatFunc(kernelPoly(kt.index(kernel), 1), constPoly(Int(1)), kt), true
+	}
+	n := p
+	if n < 0 {
+		n = -n
+	}
+	num, ok := kt.radicalPower(base.num, n, q)
+	if !ok {
+		return ratFunc{}, false
+	}
+	r := newRatFunc(num, constPoly(Int(1)), kt)
+	if p < 0 {
+		r, _ = r.inv()
+	}
+	return r, true
+}
+
+/*
+Returns expr written as p/q, where p and q are expanded polynomials without
+common factors. The polynomials are in the kernels of expr, i.e. its
+variables and any non-polynomial subexpressions like exp(x), whose operands
+are canonicalised in the same way.
+
+Note that two expressions representing the same rational function are always
+cancelled into the same expression, so Cancel(u - w) is zero exactly when u
+and w are equal as rational functions of their kernels.
+*/
+func Cancel(expr Expr) Expr {
+	kt := &kernelTable{}
+	r, ok := toRatFunc(expr, kt)
+	if !ok {
+		return Undefined()
+	}
+	return r.toExpr(kt)
+}
+
+/*
+Expands products and non-negative integer powers of sums in expr and
+collects like terms. Negative powers of sums are kept as they are, but
+with their bases expanded, e.g. (x+1)^2/(x+1) expands into x^2/(x+1) +
+2x/(x+1) + 1/(x+1). Use Cancel to also combine the terms into a single
+fraction.
+*/
+func Expand(expr Expr) Expr {
+	kt := &kernelTable{}
+	p, ok := toPolynomial(expr, kt)
+	if !ok {
+		return Undefined()
+	}
+	// Relations between kernels found after a power
+	// was reduced still need to be applied to it
+	return kt.reduce(p).toExpr(kt)
+}
+
+/*
+Returns the terms of the expansion of expr. Unlike the operands of
+Expand(expr) these are never recombined by the simplification of the
+sum, e.g. x*f(a) + x*f(b) is not collected into x*(f(a) + f(b)).
+False is returned if expr contains undefined or a division by zero.
+*/
+func expandedTerms(expr Expr) ([]Expr, bool) {
+	kt := &kernelTable{}
+	p, ok := toPolynomial(expr, kt)
+	if !ok {
+		return nil, false
+	}
+	p = kt.reduce(p)
+	if p.isZero() {
+		return []Expr{Int(0)}, true
+	}
+	terms := make([]Expr, 0, len(p))
+	for _, t := range p.sortedTerms() {
+		single := polynomial{}
+		single.addTerm(t)
+		terms = append(terms, single.toExpr(kt))
+	}
+	return terms, true
+}
+
+// Returns true if expr is identically zero as a rational
+// function of its kernels.
+func isZero(expr Expr) bool {
+	r, ok := toRatFunc(expr, &kernelTable{})
+	return ok && r.isZero()
+}
@@ -0,0 +1,162 @@
+package gosymbol
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	x := Var("x")
+	y := Var("y")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput Expr
+	}{
+		{
+			name:           "Product of sums",
+			input:          Mul(Add(x, Int(1)), Sub(x, Int(1))),
+			expectedOutput: Add(Int(-1), Pow(x, Int(2))),
+		},
+		{
+			name:           "Binomial cube",
+			input:          Pow(Add(x, y), Int(3)),
+			expectedOutput: Add(Pow(x, Int(3)), Mul(Int(3), Pow(x, Int(2)), y), Mul(Int(3), x, Pow(y, Int(2))), Pow(y, Int(3))),
+		},
+		{
+			name:           "Like terms are collected",
+			input:          Add(Mul(Int(2), x, y), Mul(y, x), Neg(Mul(Int(3), x, y))),
+			expectedOutput: Int(0),
+		},
+		{
+			name:           "Operands of kernels are expanded",
+			input:          Exp(Mul(x, Add(x, Int(1)))),
+			expectedOutput: Exp(Add(x, Pow(x, Int(2)))),
+		},
+		{
+			name:           "Undefined",
+			input:          Add(x, Undefined()),
+			expectedOutput: Undefined(),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := Expand(test.input)
+
+			if !reflect.DeepEqual(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	x := Var("x")
+	y := Var("y")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput Expr
+	}{
+		{
+			name:           "Univariate common factor",
+			input:          Div(Sub(Pow(x, Int(2)), Int(1)), Add(x, Int(1))),
+			expectedOutput: Add(Int(-1), x),
+		},
+		{
+			name:           "Multivariate common factor",
+			input:          Div(Sub(Pow(x, Int(2)), Pow(y, Int(2))), Sub(Mul(Int(3), x), Mul(Int(3), y))),
+			expectedOutput: Add(Mul(Div(Int(1), Int(3)), x), Mul(Div(Int(1), Int(3)), y)),
+		},
+		{
+			name:           "Sum of fractions",
+			input:          Add(Div(Int(1), x), Div(Int(1), y)),
+			expectedOutput: Mul(Pow(x, Int(-1)), Pow(y, Int(-1)), Add(x, y)),
+		},
+		{
+			name:           "Equal kernels cancel",
+			input:          Sub(Exp(Add(x, x)), Exp(Mul(Int(2), x))),
+			expectedOutput: Int(0),
+		},
+		{
+			name:           "Division by zero",
+			input:          Div(x, Sub(y, y)),
+			expectedOutput: Undefined(),
+		},
+		{
+			name:           "Powers of radicals are reduced",
+			input:          Sub(Pow(Sqrt(x), Int(2)), x),
+			expectedOutput: Int(0),
+		},
+		{
+			name:           "Square roots are removed from the denominator",
+			input:          Div(Int(1), Add(Int(1), Pow(Int(2), Div(Int(1), Int(2))))),
+			expectedOutput: Add(Int(-1), Pow(Int(2), Div(Int(1), Int(2)))),
+		},
+		{
+			name:           "Perfect powers are evaluated",
+			input:          Pow(Int(4), Div(Int(3), Int(2))),
+			expectedOutput: Int(8),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := Cancel(test.input)
+
+			if !reflect.DeepEqual(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestPolyGCD(t *testing.T) {
+	x := Var("x")
+	y := Var("y")
+
+	tests := []struct {
+		name           string
+		input1         Expr
+		input2         Expr
+		expectedOutput Expr
+	}{
+		{
+			name:           "Coprime polynomials",
+			input1:         Add(x, Int(1)),
+			input2:         Add(x, Int(2)),
+			expectedOutput: Int(1),
+		},
+		{
+			name:           "Univariate",
+			input1:         Mul(Pow(Add(x, Int(1)), Int(2)), Sub(x, Int(3))),
+			input2:         Mul(Int(4), Add(x, Int(1)), Add(x, Int(5))),
+			expectedOutput: Add(Int(1), x),
+		},
+		{
+			name:           "Multivariate",
+			input1:         Mul(Add(x, y), Sub(x, y), Add(x, Int(2))),
+			input2:         Mul(Int(2), Add(x, y), Pow(Add(x, Int(2)), Int(2)), y),
+			expectedOutput: Add(Pow(x, Int(2)), Mul(Int(2), x), Mul(x, y), Mul(Int(2), y)),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			kt := &kernelTable{}
+			kt.index(x)
+			u, _ := toPolynomial(test.input1, kt)
+			v, _ := toPolynomial(test.input2, kt)
+			expected, _ := toPolynomial(test.expectedOutput, kt)
+			result := polyGCD(u, v)
+
+			if !result.equal(expected) {
+				t.Errorf("Following test failed: %s\nInput: %v, %v\nExpected: %v\nGot: %v", test.name, test.input1, test.input2, test.expectedOutput, result.toExpr(kt))
+			}
+		})
+	}
+}
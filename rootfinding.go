@@ -0,0 +1,469 @@
+package gosymbol
+
+import (
+	"math"
+	"math/big"
+	"sort"
+)
+
+const (
+	rootTolerance     = 1e-12
+	maxRootIterations = 100
+	machineEpsilon    = 2.220446049250313e-16
+)
+
+// Convergence diagnostics of a numerical root search.
+type RootResult struct {
+	Root       float64
+	Residual   float64 // |f(Root)|
+	Iterations int
+	Converged  bool
+	Method     string
+}
+
+/*
+Numerically finds a root of expr w.r.t. v using Newton's method
+starting from guess. The derivative is computed symbolically with D.
+Whenever the derivative vanishes (or is not finite) at an iterate the
+iterate is nudged slightly before the search continues. Use
+FindRootSecant or FindRootBrent if expr is not differentiable.
+
+A ConvergenceError is returned together with the last iterate if
+no root was found within the iteration limit.
+*/
+func FindRoot(expr Expr, v variable, guess float64) (RootResult, error) {
+	f, err := CompileNumeric(expr, []variable{v})
+	if err != nil {
+		return RootResult{}, err
+	}
+	df, err := CompileNumeric(expr.D(v), []variable{v})
+	if err != nil {
+		return RootResult{}, err
+	}
+	return newtonRoot(univariate(f), univariate(df), guess)
+}
+
+/*
+Numerically finds a root of expr w.r.t. v using the secant method
+with the two starting points x0 and x1.
+*/
+func FindRootSecant(expr Expr, v variable, x0, x1 float64) (RootResult, error) {
+	f, err := CompileNumeric(expr, []variable{v})
+	if err != nil {
+		return RootResult{}, err
+	}
+	return secantRoot(univariate(f), x0, x1)
+}
+
+/*
+Numerically finds a root of expr w.r.t. v in the interval [a, b]
+using Brent's method. The expression must change sign on the
+interval, otherwise a BracketError is returned. Brent's method
+is guaranteed to converge for continuous functions.
+*/
+func FindRootBrent(expr Expr, v variable, a, b float64) (RootResult, error) {
+	f, err := CompileNumeric(expr, []variable{v})
+	if err != nil {
+		return RootResult{}, err
+	}
+	return brentRoot(univariate(f), a, b)
+}
+
+/*
+Returns all real roots, in increasing order and without multiplicity,
+of expr seen as a polynomial in v. The coefficients may be any
+expressions that evaluates to numbers.
+
+The roots are isolated exactly using a Sturm sequence of the square
+free part of the polynomial and are then refined by bisection, so no
+root is missed or duplicated regardless of multiplicities or how
+close the roots are. Non-zero constant polynomials yield no roots,
+while the zero polynomial, which every number solves, gives an
+InvalidArgumentError.
+*/
+func NSolve(expr Expr, v variable) ([]float64, error) {
+	coeffs, err := polynomialCoefficients(expr, v)
+	if err != nil {
+		return nil, err
+	}
+
+	p := make(bigPoly, len(coeffs))
+	for ix, c := range coeffs {
+		p[ix], err = toBigRat(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p = p.trim()
+	if len(p) == 0 {
+		return nil, &InvalidArgumentError{Function: "NSolve", Arg: expr}
+	}
+	if p.degree() < 1 {
+		return []float64{}, nil
+	}
+
+	squareFree := p.quo(bigPolyGCD(p, p.derivative()))
+	sturm := sturmSequence(squareFree)
+
+	// Cauchy's bound: every root satisfies |x| < 1 + max|a_i/a_n|
+	bound := new(big.Rat)
+	lc := squareFree[squareFree.degree()]
+	for _, c := range squareFree[:squareFree.degree()] {
+		ratio := new(big.Rat).Quo(c, lc)
+		ratio.Abs(ratio)
+		if ratio.Cmp(bound) > 0 {
+			bound = ratio
+		}
+	}
+	bound.Add(bound, big.NewRat(1, 1))
+
+	var roots []float64
+	isolateRoots(sturm, new(big.Rat).Neg(bound), bound, &roots)
+	sort.Float64s(roots)
+	return roots, nil
+}
+
+func univariate(f NumericFunc) func(float64) float64 {
+	return func(x float64) float64 { return f([]float64{x}) }
+}
+
+func newtonRoot(f, df func(float64) float64, x float64) (RootResult, error) {
+	for it := 1; it <= maxRootIterations; it++ {
+		fx := f(x)
+		if fx == 0 {
+			return RootResult{Root: x, Iterations: it, Converged: true, Method: "newton"}, nil
+		}
+
+		d := df(x)
+		if math.IsNaN(fx) || math.IsNaN(x) {
+			break
+		} else if d == 0 || math.IsNaN(d) || math.IsInf(d, 0) {
+			// Newton is stuck at a stationary point (or a
+			// singularity), so we nudge the iterate and retry
+			x += math.Max(math.Abs(x), 1) * 1e-3
+			continue
+		}
+
+		step := fx / d
+		x -= step
+		if math.Abs(step) <= rootTolerance*(1+math.Abs(x)) {
+			return RootResult{Root: x, Residual: math.Abs(f(x)), Iterations: it, Converged: true, Method: "newton"}, nil
+		}
+	}
+	result := RootResult{Root: x, Residual: math.Abs(f(x)), Iterations: maxRootIterations, Method: "newton"}
+	return result, &ConvergenceError{Method: "newton", Iterations: maxRootIterations}
+}
+
+func secantRoot(f func(float64) float64, x0, x1 float64) (RootResult, error) {
+	f0, f1 := f(x0), f(x1)
+	for it := 1; it <= maxRootIterations; it++ {
+		if f1 == 0 {
+			return RootResult{Root: x1, Iterations: it, Converged: true, Method: "secant"}, nil
+		}
+		if f1 == f0 {
+			break
+		}
+
+		x2 := x1 - f1*(x1-x0)/(f1-f0)
+		x0, f0 = x1, f1
+		x1, f1 = x2, f(x2)
+		// Requiring a non-increasing residual avoids stopping
+		// on tiny steps taken from wildly inaccurate secants
+		if math.Abs(x1-x0) <= rootTolerance*(1+math.Abs(x1)) && math.Abs(f1) <= math.Abs(f0) {
+			return RootResult{Root: x1, Residual: math.Abs(f1), Iterations: it, Converged: true, Method: "secant"}, nil
+		}
+	}
+	result := RootResult{Root: x1, Residual: math.Abs(f1), Iterations: maxRootIterations, Method: "secant"}
+	return result, &ConvergenceError{Method: "secant", Iterations: maxRootIterations}
+}
+
+/*
+Brent's method as described in [1]: inverse quadratic interpolation
+and secant steps are taken when they are safe, otherwise bisection.
+
+[1] BRENT, Richard P. Algorithms for minimization without derivatives. Prentice-Hall, 1973. Chapter 4.
+*/
+func brentRoot(f func(float64) float64, a, b float64) (RootResult, error) {
+	fa, fb := f(a), f(b)
+	if fa == 0 {
+		return RootResult{Root: a, Converged: true, Method: "brent"}, nil
+	} else if fb == 0 {
+		return RootResult{Root: b, Converged: true, Method: "brent"}, nil
+	} else if (fa > 0) == (fb > 0) {
+		return RootResult{}, &BracketError{A: a, B: b}
+	}
+
+	c, fc := a, fa
+	d := b - a
+	e := d
+	for it := 1; it <= maxRootIterations; it++ {
+		if (fb > 0) == (fc > 0) {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+
+		tol := 2*machineEpsilon*math.Abs(b) + 0.5*rootTolerance
+		m := 0.5 * (c - b)
+		if math.Abs(m) <= tol || fb == 0 {
+			return RootResult{Root: b, Residual: math.Abs(fb), Iterations: it, Converged: true, Method: "brent"}, nil
+		}
+
+		if math.Abs(e) >= tol && math.Abs(fa) > math.Abs(fb) {
+			// Attempts interpolation
+			var p, q float64
+			s := fb / fa
+			if a == c {
+				// Secant step
+				p = 2 * m * s
+				q = 1 - s
+			} else {
+				// Inverse quadratic interpolation
+				qa := fa / fc
+				r := fb / fc
+				p = s * (2*m*qa*(qa-r) - (b-a)*(r-1))
+				q = (qa - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			} else {
+				p = -p
+			}
+			if 2*p < math.Min(3*m*q-math.Abs(tol*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d = m
+				e = d
+			}
+		} else {
+			d = m
+			e = d
+		}
+
+		a, fa = b, fb
+		if math.Abs(d) > tol {
+			b += d
+		} else if m > 0 {
+			b += tol
+		} else {
+			b -= tol
+		}
+		fb = f(b)
+	}
+	result := RootResult{Root: b, Residual: math.Abs(fb), Iterations: maxRootIterations, Method: "brent"}
+	return result, &ConvergenceError{Method: "brent", Iterations: maxRootIterations}
+}
+
+/* Exact real root isolation */
+
+// A univariate polynomial with arbitrary precision rational
+// coefficients. Element ix is the coefficient of x^ix.
+type bigPoly []*big.Rat
+
+// Converts a constant expression to a big.Rat. Rationals are
+// converted exactly, anything else is evaluated numerically.
+func toBigRat(expr Expr) (*big.Rat, error) {
+	if r, ok := expr.(rational); ok {
+		if _, isUndefined := r.(undefined); !isUndefined {
+			return big.NewRat(r.numerator().value, r.denominator().value), nil
+		}
+	}
+	value, err := EvalFloat(expr, nil)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, &NotNumericError{Expr: expr}
+	}
+	return new(big.Rat).SetFloat64(value), nil
+}
+
+func (p bigPoly) trim() bigPoly {
+	n := len(p)
+	for n > 0 && p[n-1].Sign() == 0 {
+		n--
+	}
+	return p[:n]
+}
+
+// Degree of p, where the zero polynomial has degree -1.
+func (p bigPoly) degree() int {
+	return len(p.trim()) - 1
+}
+
+func (p bigPoly) derivative() bigPoly {
+	if len(p) <= 1 {
+		return bigPoly{}
+	}
+	result := make(bigPoly, len(p)-1)
+	for ix := 1; ix < len(p); ix++ {
+		result[ix-1] = new(big.Rat).Mul(p[ix], big.NewRat(int64(ix), 1))
+	}
+	return result.trim()
+}
+
+func (p bigPoly) eval(x *big.Rat) *big.Rat {
+	result := new(big.Rat)
+	for ix := len(p) - 1; ix >= 0; ix-- {
+		result.Mul(result, x)
+		result.Add(result, p[ix])
+	}
+	return result
+}
+
+// Polynomial long division of p by q, returning quotient and remainder.
+func (p bigPoly) quoRem(q bigPoly) (bigPoly, bigPoly) {
+	q = q.trim()
+	rem := make(bigPoly, len(p))
+	for ix, c := range p {
+		rem[ix] = new(big.Rat).Set(c)
+	}
+	rem = rem.trim()
+	if len(rem) < len(q) {
+		return bigPoly{}, rem
+	}
+
+	quo := make(bigPoly, len(rem)-len(q)+1)
+	for ix := range quo {
+		quo[ix] = new(big.Rat)
+	}
+	lc := q[len(q)-1]
+	for len(rem) >= len(q) {
+		shift := len(rem) - len(q)
+		factor := new(big.Rat).Quo(rem[len(rem)-1], lc)
+		quo[shift] = factor
+		for ix, c := range q {
+			term := new(big.Rat).Mul(factor, c)
+			rem[ix+shift].Sub(rem[ix+shift], term)
+		}
+		// The leading coefficient is exactly cancelled
+		rem = rem[:len(rem)-1].trim()
+	}
+	return quo, rem
+}
+
+func (p bigPoly) quo(q bigPoly) bigPoly {
+	quo, _ := p.quoRem(q)
+	return quo
+}
+
+func (p bigPoly) rem(q bigPoly) bigPoly {
+	_, rem := p.quoRem(q)
+	return rem
+}
+
+func (p bigPoly) neg() bigPoly {
+	result := make(bigPoly, len(p))
+	for ix, c := range p {
+		result[ix] = new(big.Rat).Neg(c)
+	}
+	return result
+}
+
+// Greatest common divisor of p and q computed with the Euclidean algorithm.
+func bigPolyGCD(p, q bigPoly) bigPoly {
+	p, q = p.trim(), q.trim()
+	for len(q) > 0 {
+		p, q = q, p.rem(q)
+	}
+	return p
+}
+
+func sturmSequence(p bigPoly) []bigPoly {
+	seq := []bigPoly{p, p.derivative()}
+	for {
+		next := seq[len(seq)-2].rem(seq[len(seq)-1]).neg()
+		if len(next) == 0 {
+			return seq
+		}
+		seq = append(seq, next)
+	}
+}
+
+// Number of sign changes in the Sturm sequence evaluated at x.
+func sturmSignChanges(seq []bigPoly, x *big.Rat) int {
+	changes := 0
+	lastSign := 0
+	for _, p := range seq {
+		sign := p.eval(x).Sign()
+		if sign == 0 {
+			continue
+		}
+		if lastSign != 0 && sign != lastSign {
+			changes++
+		}
+		lastSign = sign
+	}
+	return changes
+}
+
+/*
+Appends the roots in the interval (a, b] to roots. By Sturm's theorem
+the number of distinct roots in (a, b] is V(a) - V(b), where V(x) is
+the number of sign changes in the Sturm sequence at x. We bisect until
+every interval holds at most one root, which is then refined further.
+*/
+func isolateRoots(seq []bigPoly, a, b *big.Rat, roots *[]float64) {
+	count := sturmSignChanges(seq, a) - sturmSignChanges(seq, b)
+	switch {
+	case count == 0:
+		return
+	case count == 1:
+		*roots = append(*roots, refineRoot(seq, a, b))
+		return
+	}
+
+	mid := new(big.Rat).Add(a, b)
+	mid.Quo(mid, big.NewRat(2, 1))
+	isolateRoots(seq, a, mid, roots)
+	isolateRoots(seq, mid, b, roots)
+}
+
+// Bisects the isolating interval (a, b] until it is
+// within floating point precision of the single root.
+func refineRoot(seq []bigPoly, a, b *big.Rat) float64 {
+	p := seq[0]
+	if p.eval(b).Sign() == 0 {
+		f, _ := b.Float64()
+		return f
+	}
+
+	two := big.NewRat(2, 1)
+	tol := new(big.Rat).SetFloat64(1e-17)
+	for it := 0; it < 4*maxRootIterations; it++ {
+		mid := new(big.Rat).Add(a, b)
+		mid.Quo(mid, two)
+		if p.eval(mid).Sign() == 0 {
+			f, _ := mid.Float64()
+			return f
+		}
+
+		if sturmSignChanges(seq, a)-sturmSignChanges(seq, mid) == 1 {
+			b = mid
+		} else {
+			a = mid
+		}
+
+		// Stops when the interval is small relative to
+		// the magnitude of the root, which we only know
+		// once the interval excludes zero.
+		if a.Sign() == b.Sign() {
+			width := new(big.Rat).Sub(b, a)
+			scale := new(big.Rat).Abs(a)
+			if absB := new(big.Rat).Abs(b); absB.Cmp(scale) < 0 {
+				scale = absB
+			}
+			if width.Cmp(new(big.Rat).Mul(scale, tol)) <= 0 {
+				break
+			}
+		}
+	}
+	mid := new(big.Rat).Add(a, b)
+	f, _ := mid.Quo(mid, two).Float64()
+	return f
+}
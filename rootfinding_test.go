@@ -0,0 +1,137 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestFindRoot(t *testing.T) {
+	x := Var("x")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		guess          float64
+		expectedOutput float64
+	}{
+		{
+			name:           "Square root of two",
+			input:          Sub(Pow(x, Int(2)), Int(2)),
+			guess:          1,
+			expectedOutput: math.Sqrt2,
+		},
+		{
+			name:           "Transcendental equation exp(x) = 2",
+			input:          Sub(Exp(x), Int(2)),
+			guess:          0,
+			expectedOutput: math.Ln2,
+		},
+		{
+			name:           "Vanishing derivative at the guess",
+			input:          Sub(Pow(x, Int(3)), Int(8)),
+			guess:          0,
+			expectedOutput: 2,
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := FindRoot(test.input, x, test.guess)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+
+			if !result.Converged || math.Abs(result.Root-test.expectedOutput) > 1e-9 {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %+v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestFindRootSecant(t *testing.T) {
+	x := Var("x")
+	result, err := FindRootSecant(Sub(Log(x), Int(1)), x, 2, 3)
+	if err != nil || math.Abs(result.Root-math.E) > 1e-9 {
+		t.Errorf("Expected root %v but got: %+v, error: %v", math.E, result, err)
+	}
+}
+
+func TestFindRootBrent(t *testing.T) {
+	x := Var("x")
+
+	result, err := FindRootBrent(Sub(Mul(x, Exp(x)), Int(1)), x, 0, 1)
+	if err != nil || math.Abs(result.Root-0.5671432904097838) > 1e-9 {
+		t.Errorf("Expected omega constant but got: %+v, error: %v", result, err)
+	}
+
+	_, err = FindRootBrent(Add(Pow(x, Int(2)), Int(1)), x, -1, 1)
+	if _, ok := err.(*BracketError); !ok {
+		t.Errorf("Expected BracketError but got: %v", err)
+	}
+}
+
+func TestNSolve(t *testing.T) {
+	x := Var("x")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput []float64
+	}{
+		{
+			name:           "Quadratic with two roots",
+			input:          Sub(Pow(x, Int(2)), Int(2)),
+			expectedOutput: []float64{-math.Sqrt2, math.Sqrt2},
+		},
+		{
+			name:           "Quadratic without real roots",
+			input:          Add(Pow(x, Int(2)), Int(1)),
+			expectedOutput: []float64{},
+		},
+		{
+			name:           "Repeated roots are only returned once",
+			input:          Mul(Pow(Sub(x, Int(1)), Int(3)), Add(x, Int(2))),
+			expectedOutput: []float64{-2, 1},
+		},
+		{
+			name:           "Root at zero and rational coefficients",
+			input:          Mul(x, Sub(x, Div(Int(1), Int(3))), Sub(x, Int(5))),
+			expectedOutput: []float64{0, 1.0 / 3, 5},
+		},
+		{
+			name:           "Closely spaced roots",
+			input:          Mul(Sub(x, Int(1)), Sub(x, Add(Int(1), Div(Int(1), Int(1000000))))),
+			expectedOutput: []float64{1, 1.000001},
+		},
+		{
+			name:           "Constant polynomial",
+			input:          Int(4),
+			expectedOutput: []float64{},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := NSolve(test.input, x)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+
+			ok := len(result) == len(test.expectedOutput)
+			for jx := 0; ok && jx < len(result); jx++ {
+				ok = math.Abs(result[jx]-test.expectedOutput[jx]) <= 1e-12*(1+math.Abs(test.expectedOutput[jx]))
+			}
+			if !ok {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+
+	// Every number solves the zero polynomial, which has no finite list of roots
+	var argErr *InvalidArgumentError
+	if _, err := NSolve(Sub(x, x), x); !errors.As(err, &argErr) {
+		t.Errorf("Following test failed: zero polynomial\nInput: %v\nExpected: InvalidArgumentError\nGot: %v", Sub(x, x), err)
+	}
+}
@@ -0,0 +1,491 @@
+package gosymbol
+
+import (
+	"math"
+	"math/big"
+	"math/cmplx"
+	"sort"
+)
+
+// The variable standing for the root in the polynomial of a RootOf.
+var rootOfSlot = Var("#")
+
+// The number of Durand-Kerner iterations used to locate the non-real roots.
+const maxDurandKernerIterations = 500
+
+/*
+Returns the k:th root of the polynomial p in x, counting from one,
+where the distinct roots are ordered with the real roots first in
+increasing order, followed by the non-real roots ordered by real
+and then imaginary part. The coefficients must be rational numbers.
+
+If the roots can be written with radicals, see polynomialRoots, the
+root itself is returned. Otherwise the result is the algebraic number
+RootOf(q, k), where q is the monic square free part of p in the slot
+variable #. Such numbers are kernels satisfying q = 0 in Simplify,
+Expand and Cancel, so e.g. RootOf(#^3 - # - 1, 1)^3 simplifies to
+RootOf(...) + 1. They are evaluated by CompileComplex, and by
+CompileNumeric if they are real, while CompileNumeric returns a
+NotRealRootError for the non-real ones.
+*/
+func RootOf(p Expr, x variable, k int) (Expr, error) {
+	q, err := rootOfPolynomial(p, x)
+	if err != nil {
+		return nil, err
+	}
+	n := q.degree()
+	if n < 1 || k < 1 || k > n {
+		return nil, &RootIndexError{Expr: p, Index: k, Count: max(n, 0)}
+	}
+
+	slotPoly := q.toExpr(rootOfSlot)
+	if roots, err := polynomialRoots(slotPoly, rootOfSlot, nil); err == nil && len(roots) == n {
+		values := make([]Expr, n)
+		numeric := make([]complex128, n)
+		for ix, r := range roots {
+			value, err := EvalComplex(r.value, nil)
+			if err != nil {
+				break
+			}
+			values[ix], numeric[ix] = r.value, value
+		}
+		if values[n-1] != nil {
+			sort.Sort(rootsByComplexValue{roots: values, values: numeric})
+			return values[k-1].Simplify(), nil
+		}
+	}
+	return Function(rootOfName, slotPoly, Int(int64(k))), nil
+}
+
+/*
+Returns rational lo and hi with lo < root <= hi and hi - lo <= tolerance
+for a real RootOf. The interval is found exactly with Sturm sequences.
+*/
+func IsolateRoot(root Expr, tolerance float64) (Expr, Expr, error) {
+	q, k, ok := rootOfParts(root)
+	if !ok {
+		return nil, nil, &NotRealRootError{Expr: root}
+	}
+	intervals := q.isolateRealRoots()
+	if k > len(intervals) {
+		return nil, nil, &NotRealRootError{Expr: root}
+	}
+	lo, hi := q.refineRoot(intervals[k-1], big.NewRat(1, 1).SetFloat64(tolerance))
+	loR, ok1 := bigRatToRational(lo)
+	hiR, ok2 := bigRatToRational(hi)
+	if !ok1 || !ok2 {
+		return nil, nil, &NotRealRootError{Expr: root}
+	}
+	return loR, hiR, nil
+}
+
+// Returns the square free part of p in x, which must have rational coefficients.
+func rootOfPolynomial(p Expr, x variable) (exactPoly, error) {
+	coeffs, err := polynomialCoefficients(p, x)
+	if err != nil {
+		return nil, err
+	}
+	poly := make(exactPoly, len(coeffs))
+	for ix, c := range coeffs {
+		r, ok := c.(rational)
+		if !ok {
+			return nil, &NotPolynomialError{Expr: p, Var: x}
+		}
+		poly[ix] = big.NewRat(r.numerator().value, r.denominator().value)
+	}
+	poly = poly.trim()
+	if poly.degree() < 1 {
+		return poly, nil
+	}
+	g := poly.gcd(poly.derivative())
+	sqfree, _ := poly.quoRem(g)
+	return sqfree.monic(), nil
+}
+
+// Returns the polynomial and index of a RootOf application.
+func rootOfParts(expr Expr) (exactPoly, int, bool) {
+	f, ok := expr.(FunctionApplication)
+	if !ok || f.Name != rootOfName || len(f.Args) != 2 {
+		return nil, 0, false
+	}
+	k, ok := f.Args[1].(integer)
+	if !ok {
+		return nil, 0, false
+	}
+	q, err := rootOfPolynomial(f.Args[0], rootOfSlot)
+	if err != nil || k.value < 1 || int(k.value) > q.degree() {
+		return nil, 0, false
+	}
+	return q, int(k.value), true
+}
+
+// Returns the numeric value of a RootOf application.
+func rootOfValue(expr Expr) (complex128, bool) {
+	q, k, ok := rootOfParts(expr)
+	if !ok {
+		return cmplx.NaN(), false
+	}
+	return q.numericRoots()[k-1], true
+}
+
+/*
+Returns root^n for a RootOf(q, k) and an integer n at least the degree
+of q, reduced by q(root) = 0 to a polynomial in root of lower degree.
+The result is not simplified, since this is used by the simplifier.
+*/
+func reduceRootOfPower(root Expr, n integer) (Expr, bool) {
+	q, _, ok := rootOfParts(root)
+	if !ok || n.value < int64(q.degree()) {
+		return nil, false
+	}
+
+	// root^n mod q by repeated squaring
+	result, base := exactPoly{big.NewRat(1, 1)}, exactPoly{new(big.Rat), big.NewRat(1, 1)}
+	for k := n.value; k > 0; k /= 2 {
+		if k%2 == 1 {
+			_, result = result.mul(base).quoRem(q)
+		}
+		if k > 1 {
+			_, base = base.mul(base).quoRem(q)
+		}
+	}
+
+	terms := []Expr{}
+	for ix, c := range result {
+		if c.Sign() == 0 {
+			continue
+		}
+		coeff, ok := bigRatToRational(c)
+		if !ok {
+			return nil, false
+		}
+		terms = append(terms, Mul(coeff, Pow(root, Int(int64(ix)))))
+	}
+	return Add(terms...), true
+}
+
+/*
+Returns the properties of a RootOf, which is real if it is one of the
+real roots, with the sign given by the number of negative real roots.
+*/
+func rootOfProperties(expr Expr) Property {
+	q, k, ok := rootOfParts(expr)
+	if !ok {
+		return 0
+	}
+	bound := q.rootBound()
+	seq := q.sturmSequence()
+	realRoots := signChanges(seq, new(big.Rat).Neg(bound)) - signChanges(seq, bound)
+	if k > realRoots {
+		return 0
+	}
+	zero := new(big.Rat)
+	if q.eval(zero).Sign() == 0 {
+		return IsReal
+	}
+	negative := signChanges(seq, new(big.Rat).Neg(bound)) - signChanges(seq, zero)
+	if k <= negative {
+		return IsNegative.closure()
+	}
+	return IsPositive.closure()
+}
+
+// Orders roots with the real ones first as in RootOf.
+type rootsByComplexValue struct {
+	roots  []Expr
+	values []complex128
+}
+
+func (r rootsByComplexValue) Len() int { return len(r.roots) }
+func (r rootsByComplexValue) Less(i, j int) bool {
+	return complexRootLess(r.values[i], r.values[j])
+}
+func (r rootsByComplexValue) Swap(i, j int) {
+	r.roots[i], r.roots[j] = r.roots[j], r.roots[i]
+	r.values[i], r.values[j] = r.values[j], r.values[i]
+}
+
+func complexRootLess(a, b complex128) bool {
+	const tol = 1e-12
+	aReal, bReal := math.Abs(imag(a)) < tol, math.Abs(imag(b)) < tol
+	switch {
+	case aReal != bReal:
+		return aReal
+	case math.Abs(real(a)-real(b)) >= tol:
+		return real(a) < real(b)
+	}
+	return imag(a) < imag(b)
+}
+
+/*
+A univariate polynomial with exact rational coefficients, where element
+ix is the coefficient of x^ix. It is used for the root isolation of
+RootOf, which needs exact signs at rational points.
+*/
+type exactPoly []*big.Rat
+
+func (p exactPoly) trim() exactPoly {
+	n := len(p)
+	for n > 0 && p[n-1].Sign() == 0 {
+		n--
+	}
+	return p[:n]
+}
+
+// The degree of p, where the zero polynomial has degree -1.
+func (p exactPoly) degree() int {
+	return len(p.trim()) - 1
+}
+
+func (p exactPoly) eval(x *big.Rat) *big.Rat {
+	result := new(big.Rat)
+	for ix := len(p) - 1; ix >= 0; ix-- {
+		result.Mul(result, x)
+		result.Add(result, p[ix])
+	}
+	return result
+}
+
+func (p exactPoly) derivative() exactPoly {
+	if len(p) < 2 {
+		return exactPoly{}
+	}
+	result := make(exactPoly, len(p)-1)
+	for ix := range result {
+		result[ix] = new(big.Rat).Mul(p[ix+1], big.NewRat(int64(ix+1), 1))
+	}
+	return result.trim()
+}
+
+func (p exactPoly) mul(q exactPoly) exactPoly {
+	if len(p) == 0 || len(q) == 0 {
+		return exactPoly{}
+	}
+	result := make(exactPoly, len(p)+len(q)-1)
+	for ix := range result {
+		result[ix] = new(big.Rat)
+	}
+	for ix, a := range p {
+		for jx, b := range q {
+			result[ix+jx].Add(result[ix+jx], new(big.Rat).Mul(a, b))
+		}
+	}
+	return result.trim()
+}
+
+func (p exactPoly) monic() exactPoly {
+	p = p.trim()
+	if len(p) == 0 {
+		return p
+	}
+	lc := p[len(p)-1]
+	result := make(exactPoly, len(p))
+	for ix, c := range p {
+		result[ix] = new(big.Rat).Quo(c, lc)
+	}
+	return result
+}
+
+// Polynomial long division, d must not be zero.
+func (p exactPoly) quoRem(d exactPoly) (exactPoly, exactPoly) {
+	d = d.trim()
+	rem := make(exactPoly, len(p.trim()))
+	for ix := range rem {
+		rem[ix] = new(big.Rat).Set(p[ix])
+	}
+	if len(rem) < len(d) {
+		return exactPoly{}, rem
+	}
+	quo := make(exactPoly, len(rem)-len(d)+1)
+	lc := d[len(d)-1]
+	for ix := len(quo) - 1; ix >= 0; ix-- {
+		c := new(big.Rat).Quo(rem[ix+len(d)-1], lc)
+		quo[ix] = c
+		for jx, dc := range d {
+			rem[ix+jx].Sub(rem[ix+jx], new(big.Rat).Mul(c, dc))
+		}
+	}
+	return quo.trim(), rem.trim()
+}
+
+func (p exactPoly) gcd(q exactPoly) exactPoly {
+	a, b := p.trim(), q.trim()
+	for len(b) > 0 {
+		_, r := a.quoRem(b)
+		a, b = b, r
+	}
+	return a.monic()
+}
+
+func (p exactPoly) toExpr(x variable) Expr {
+	terms := []Expr{}
+	for ix, c := range p.trim() {
+		if c.Sign() == 0 {
+			continue
+		}
+		coeff, _ := bigRatToRational(c)
+		terms = append(terms, Mul(coeff, Pow(x, Int(int64(ix)))))
+	}
+	return Add(terms...).Simplify()
+}
+
+// Returns B such that all roots of p lie in (-B, B), by Cauchy's bound.
+func (p exactPoly) rootBound() *big.Rat {
+	p = p.trim()
+	lc := p[len(p)-1]
+	bound := new(big.Rat)
+	for _, c := range p[:len(p)-1] {
+		ratio := new(big.Rat).Abs(new(big.Rat).Quo(c, lc))
+		if ratio.Cmp(bound) > 0 {
+			bound = ratio
+		}
+	}
+	return bound.Add(bound, big.NewRat(1, 1))
+}
+
+// The Sturm sequence p, p', -rem(p, p'), ... of a square free p.
+func (p exactPoly) sturmSequence() []exactPoly {
+	seq := []exactPoly{p.trim(), p.derivative()}
+	for {
+		_, r := seq[len(seq)-2].quoRem(seq[len(seq)-1])
+		if len(r) == 0 {
+			return seq
+		}
+		for ix := range r {
+			r[ix].Neg(r[ix])
+		}
+		seq = append(seq, r)
+	}
+}
+
+// The number of sign changes of the Sturm sequence at x, ignoring zeros.
+func signChanges(seq []exactPoly, x *big.Rat) int {
+	changes, last := 0, 0
+	for _, p := range seq {
+		sign := p.eval(x).Sign()
+		if sign == 0 {
+			continue
+		}
+		if last != 0 && sign != last {
+			changes++
+		}
+		last = sign
+	}
+	return changes
+}
+
+/*
+Returns disjoint intervals (lo, hi] in increasing order containing exactly
+one real root of the square free p each. No endpoint is a root of p.
+*/
+func (p exactPoly) isolateRealRoots() [][2]*big.Rat {
+	seq := p.sturmSequence()
+	bound := p.rootBound()
+	result := [][2]*big.Rat{}
+	var isolate func(lo, hi *big.Rat)
+	isolate = func(lo, hi *big.Rat) {
+		count := signChanges(seq, lo) - signChanges(seq, hi)
+		if count == 0 {
+			return
+		} else if count == 1 {
+			result = append(result, [2]*big.Rat{lo, hi})
+			return
+		}
+		mid := p.splitPoint(lo, hi)
+		isolate(lo, mid)
+		isolate(mid, hi)
+	}
+	isolate(new(big.Rat).Neg(bound), bound)
+	return result
+}
+
+// Returns a point of (lo, hi) close to the midpoint which is not a root of p.
+func (p exactPoly) splitPoint(lo, hi *big.Rat) *big.Rat {
+	width := new(big.Rat).Sub(hi, lo)
+	mid := new(big.Rat).Add(lo, new(big.Rat).Mul(width, big.NewRat(1, 2)))
+	for step := int64(7); p.eval(mid).Sign() == 0; step *= 2 {
+		mid.Add(mid, new(big.Rat).Quo(width, big.NewRat(step, 1)))
+	}
+	return mid
+}
+
+// Bisects the isolating interval of a root until it is at most tolerance wide.
+func (p exactPoly) refineRoot(interval [2]*big.Rat, tolerance *big.Rat) (*big.Rat, *big.Rat) {
+	lo, hi := new(big.Rat).Set(interval[0]), new(big.Rat).Set(interval[1])
+	loSign := p.eval(lo).Sign()
+	for ix := 0; ix < 200 && new(big.Rat).Sub(hi, lo).Cmp(tolerance) > 0; ix++ {
+		mid := p.splitPoint(lo, hi)
+		if p.eval(mid).Sign() == loSign {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo, hi
+}
+
+/*
+Returns all roots of the square free p in the order of RootOf. The real
+roots are found by bisecting their isolating intervals and the non-real
+ones with the Durand-Kerner method.
+*/
+func (p exactPoly) numericRoots() []complex128 {
+	n := p.degree()
+	result := make([]complex128, 0, n)
+	for _, interval := range p.isolateRealRoots() {
+		lo, hi := p.refineRoot(interval, big.NewRat(1, 1<<60))
+		l, _ := lo.Float64()
+		h, _ := hi.Float64()
+		result = append(result, complex((l+h)/2, 0))
+	}
+	if len(result) == n {
+		return result
+	}
+
+	// The non-real roots are those furthest from the real line
+	approx := p.durandKerner()
+	sort.Slice(approx, func(i, j int) bool { return math.Abs(imag(approx[i])) > math.Abs(imag(approx[j])) })
+	nonReal := approx[:n-len(result)]
+	sort.Slice(nonReal, func(i, j int) bool { return complexRootLess(nonReal[i], nonReal[j]) })
+	return append(result, nonReal...)
+}
+
+func (p exactPoly) durandKerner() []complex128 {
+	monic := p.monic()
+	n := monic.degree()
+	coeffs := make([]complex128, n+1)
+	for ix, c := range monic {
+		f, _ := c.Float64()
+		coeffs[ix] = complex(f, 0)
+	}
+	eval := func(z complex128) complex128 {
+		result := complex(0, 0)
+		for ix := n; ix >= 0; ix-- {
+			result = result*z + coeffs[ix]
+		}
+		return result
+	}
+
+	roots := make([]complex128, n)
+	for ix := range roots {
+		roots[ix] = cmplx.Pow(0.4+0.9i, complex(float64(ix), 0))
+	}
+	for it := 0; it < maxDurandKernerIterations; it++ {
+		delta := 0.0
+		for ix, z := range roots {
+			den := complex(1, 0)
+			for jx, w := range roots {
+				if jx != ix {
+					den *= z - w
+				}
+			}
+			step := eval(z) / den
+			roots[ix] = z - step
+			delta = math.Max(delta, cmplx.Abs(step))
+		}
+		if delta < 1e-15 {
+			break
+		}
+	}
+	return roots
+}
@@ -0,0 +1,154 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestRootOf(t *testing.T) {
+	x := Var("x")
+	half := Div(Int(1), Int(2))
+
+	tests := []struct {
+		name           string
+		poly           Expr
+		k              int
+		expectedOutput Expr
+	}{
+		{name: "Rational root", poly: Sub(Mul(Int(2), x), Int(3)), k: 1, expectedOutput: Div(Int(3), Int(2))},
+		{name: "Smallest root of x^2 - 2", poly: Sub(Pow(x, Int(2)), Int(2)), k: 1, expectedOutput: Mul(Int(-1), Pow(Int(2), half))},
+		{name: "Largest root of x^2 - 2", poly: Sub(Pow(x, Int(2)), Int(2)), k: 2, expectedOutput: Pow(Int(2), half)},
+		{name: "Repeated roots are counted once", poly: Pow(Sub(x, Int(1)), Int(3)), k: 1, expectedOutput: Int(1)},
+		{name: "Real roots come first", poly: Mul(Add(Pow(x, Int(2)), Int(1)), Sub(x, Int(5))), k: 1, expectedOutput: Int(5)},
+		{name: "Non-real roots by imaginary part", poly: Mul(Add(Pow(x, Int(2)), Int(1)), Sub(x, Int(5))), k: 2, expectedOutput: Neg(I)},
+		{
+			name:           "Irreducible cubic",
+			poly:           Sub(Sub(Pow(x, Int(3)), x), Int(1)),
+			k:              1,
+			expectedOutput: Function(rootOfName, Add(Int(-1), Neg(rootOfSlot), Pow(rootOfSlot, Int(3))), Int(1)),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := RootOf(test.poly, x, test.k)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nInput: %v\nUnexpected error: %v", test.name, test.poly, err)
+			}
+			if !Equal(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.poly, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestRootOfArithmetic(t *testing.T) {
+	x := Var("x")
+	a, err := RootOf(Sub(Sub(Pow(x, Int(3)), x), Int(1)), x, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a^3 = a + 1 and a^5 = a^2 + a + 1
+	tests := []struct {
+		name     string
+		input    Expr
+		expected Expr
+	}{
+		{name: "a^3", input: Pow(a, Int(3)), expected: Add(Int(1), a)},
+		{name: "a^5", input: Pow(a, Int(5)), expected: Add(Int(1), a, Pow(a, Int(2)))},
+		{name: "a^3 - a - 1", input: Sub(Sub(Pow(a, Int(3)), a), Int(1)), expected: Int(0)},
+	}
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := Expand(test.input)
+			if !isZero(Expand(Sub(result, test.expected))) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expected, result)
+			}
+		})
+	}
+
+	// Simplify reduces the powers as well
+	simplifyTests := []struct {
+		name     string
+		input    Expr
+		expected Expr
+	}{
+		{name: "a^3", input: Pow(a, Int(3)), expected: Add(Int(1), a)},
+		{name: "a^5", input: Pow(a, Int(5)), expected: Add(Int(1), a, Pow(a, Int(2)))},
+		{name: "a^2 is kept", input: Pow(a, Int(2)), expected: Pow(a, Int(2))},
+	}
+	for ix, test := range simplifyTests {
+		t.Run(fmt.Sprint("Simplify ", ix+1), func(t *testing.T) {
+			result := test.input.Simplify()
+			if !Equal(result, test.expected.Simplify()) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expected, result)
+			}
+		})
+	}
+
+	if !Ask(a, IsPositive) {
+		t.Errorf("Following test failed: the real root of x^3 - x - 1 is positive\nInput: %v", a)
+	}
+	b, _ := RootOf(Sub(Sub(Pow(x, Int(3)), x), Int(1)), x, 2)
+	if Ask(b, IsReal) {
+		t.Errorf("Following test failed: the second root of x^3 - x - 1 is not real\nInput: %v", b)
+	}
+}
+
+func TestRootOfNumeric(t *testing.T) {
+	x := Var("x")
+	p := Sub(Sub(Pow(x, Int(5)), x), Int(1))
+	for k := 1; k <= 5; k++ {
+		root, err := RootOf(p, x, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := EvalComplex(root, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		residual := cmplx.Pow(value, 5) - value - 1
+		if cmplx.Abs(residual) > 1e-12 {
+			t.Errorf("Following test failed: root %d of %v\nGot: %v with residual %v", k, p, value, residual)
+		}
+	}
+
+	realRoot, _ := RootOf(p, x, 1)
+	value, err := EvalFloat(realRoot, nil)
+	if err != nil || math.Abs(math.Pow(value, 5)-value-1) > 1e-12 {
+		t.Errorf("Following test failed: EvalFloat of %v\nGot: %v (%v)", realRoot, value, err)
+	}
+
+	lo, hi, err := IsolateRoot(realRoot, 1e-9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, h := lo.(rational).approx(), hi.(rational).approx()
+	if !(l < value && value <= h) || h-l > 1e-9 {
+		t.Errorf("Following test failed: isolating interval of %v\nExpected: an interval of width 1e-9 around %v\nGot: (%v, %v]", realRoot, value, lo, hi)
+	}
+}
+
+func TestRootOfErrors(t *testing.T) {
+	x := Var("x")
+	var indexErr *RootIndexError
+	if _, err := RootOf(Sub(Pow(x, Int(2)), Int(2)), x, 3); !errors.As(err, &indexErr) {
+		t.Errorf("Following test failed: index out of range\nExpected: RootIndexError\nGot: %v", err)
+	}
+	var polyErr *NotPolynomialError
+	if _, err := RootOf(Sub(Pow(x, Int(2)), Var("y")), x, 1); !errors.As(err, &polyErr) {
+		t.Errorf("Following test failed: symbolic coefficient\nExpected: NotPolynomialError\nGot: %v", err)
+	}
+	complexRoot, _ := RootOf(Sub(Sub(Pow(x, Int(3)), x), Int(1)), x, 2)
+	var realErr *NotRealRootError
+	if _, _, err := IsolateRoot(complexRoot, 1e-6); !errors.As(err, &realErr) {
+		t.Errorf("Following test failed: isolating a non-real root\nExpected: NotRealRootError\nGot: %v", err)
+	}
+	if _, err := EvalFloat(complexRoot, nil); !errors.As(err, &realErr) {
+		t.Errorf("Following test failed: EvalFloat of a non-real root\nExpected: NotRealRootError\nGot: %v", err)
+	}
+}
@@ -0,0 +1,342 @@
+package gosymbol
+
+import (
+	"math"
+	"sort"
+)
+
+// An exact root of a polynomial together with its multiplicity.
+type polynomialRoot struct {
+	value        Expr
+	multiplicity int
+}
+
+/*
+Returns the exact roots of expr seen as a polynomial in v, with
+multiplicities. The coefficients may contain other variables, in
+which case the roots are expressions in those.
+
+The polynomial is first split into square free factors with Yun's
+algorithm [1]. Linear factors x - c of each factor are then split off
+by testing the candidate roots, which are the given candidates together
+with all rational roots allowed by the rational root theorem when the
+coefficients are numbers. What remains is solved with the quadratic
+formula if it is of degree two, or of degree two in v^2. Any other
+remaining factor results in an UnsolvablePolynomialError.
+
+[1] YUN, David YY. On square-free decomposition algorithms. Proceedings of the third ACM symposium on Symbolic and algebraic computation, 1976.
+*/
+func polynomialRoots(expr Expr, v variable, candidates []Expr) ([]polynomialRoot, error) {
+	if _, err := polynomialCoefficients(expr, v); err != nil {
+		return nil, err
+	}
+	kt := &kernelTable{}
+	x := kt.index(v)
+	r, ok := toRatFunc(expr, kt)
+	if !ok {
+		return nil, &NotPolynomialError{Expr: expr, Var: v}
+	} else if r.num.degreeIn(x) <= 0 {
+		return []polynomialRoot{}, nil
+	}
+
+	candidateRatFuncs := []ratFunc{}
+	for _, c := range candidates {
+		if RecContains(c, v) {
+			continue
+		}
+		if r, ok := toRatFunc(c, kt); ok {
+			candidateRatFuncs = append(candidateRatFuncs, r)
+		}
+	}
+
+	result := []polynomialRoot{}
+	for multiplicity, f := range squareFreeFactors(r.num, x) {
+		factor := make(ratFuncPoly, f.degreeIn(x)+1)
+		for ix := range factor {
+			factor[ix] = newRatFunc(f.coefficientIn(x, ix), constPoly(Int(1)), kt)
+		}
+		roots, ok := factor.solve(append(candidateRatFuncs, factor.rationalRootCandidates()...), kt)
+		if !ok {
+			return nil, &UnsolvablePolynomialError{Expr: expr, Var: v}
+		}
+		for _, r := range roots {
+			result = append(result, polynomialRoot{value: r, multiplicity: multiplicity + 1})
+		}
+	}
+
+	// Numeric roots are sorted in increasing order
+	// while symbolic ones are kept as they are found
+	values := make([]float64, len(result))
+	for ix, r := range result {
+		value, err := EvalFloat(r.value, nil)
+		if err != nil || math.IsNaN(value) {
+			return result, nil
+		}
+		values[ix] = value
+	}
+	sort.Sort(rootsByValue{roots: result, values: values})
+	return result, nil
+}
+
+type rootsByValue struct {
+	roots  []polynomialRoot
+	values []float64
+}
+
+func (r rootsByValue) Len() int           { return len(r.roots) }
+func (r rootsByValue) Less(i, j int) bool { return r.values[i] < r.values[j] }
+func (r rootsByValue) Swap(i, j int) {
+	r.roots[i], r.roots[j] = r.roots[j], r.roots[i]
+	r.values[i], r.values[j] = r.values[j], r.values[i]
+}
+
+/*
+A univariate polynomial with rational functions as coefficients, where
+element ix is the coefficient of x^ix.
+*/
+type ratFuncPoly []ratFunc
+
+func (p ratFuncPoly) trim() ratFuncPoly {
+	n := len(p)
+	for n > 0 && p[n-1].isZero() {
+		n--
+	}
+	return p[:n]
+}
+
+// The degree of p, where the zero polynomial has degree -1.
+func (p ratFuncPoly) degree() int {
+	return len(p.trim()) - 1
+}
+
+func (p ratFuncPoly) eval(x ratFunc) ratFunc {
+	result := constRatFunc(Int(0))
+	for ix := len(p) - 1; ix >= 0; ix-- {
+		result = result.mul(x).add(p[ix])
+	}
+	return result
+}
+
+// Polynomial long division, d must not be zero.
+func (p ratFuncPoly) quoRem(d ratFuncPoly) (ratFuncPoly, ratFuncPoly) {
+	d = d.trim()
+	rem := append(ratFuncPoly{}, p.trim()...)
+	if len(rem) < len(d) {
+		return ratFuncPoly{}, rem
+	}
+	quo := make(ratFuncPoly, len(rem)-len(d)+1)
+	lcInv, _ := d[len(d)-1].inv()
+	for ix := len(quo) - 1; ix >= 0; ix-- {
+		c := rem[ix+len(d)-1].mul(lcInv)
+		quo[ix] = c
+		for jx, dc := range d {
+			rem[ix+jx] = rem[ix+jx].sub(c.mul(dc))
+		}
+	}
+	return quo.trim(), rem.trim()
+}
+
+/*
+Returns the square free factors of p seen as a polynomial in kernel x
+with Yun's algorithm, where element ix is the product of all irreducible
+factors of multiplicity ix + 1. Factors free of x are left out, and the
+greatest common divisors are computed with polyGCD so that coefficient
+growth is kept in check also when there are other kernels.
+*/
+func squareFreeFactors(p polynomial, x int) []polynomial {
+	dp := p.derivativeIn(x)
+	c := polyGCD(p, dp)
+	w, _ := p.exactQuo(c)
+	y, _ := dp.exactQuo(c)
+	z := y.sub(w.derivativeIn(x))
+
+	factors := []polynomial{}
+	for w.degreeIn(x) > 0 {
+		g := polyGCD(w, z)
+		factors = append(factors, g)
+		w, _ = w.exactQuo(g)
+		y, _ = z.exactQuo(g)
+		z = y.sub(w.derivativeIn(x))
+	}
+	return factors
+}
+
+/*
+Returns the candidates p/q for rational roots of p, where p divides the
+constant coefficient and q divides the leading coefficient once the
+coefficients are scaled to integers. No candidates are returned if the
+coefficients are not numbers or too large to factor by trial division.
+*/
+func (p ratFuncPoly) rationalRootCandidates() []ratFunc {
+	coeffs := make([]rational, len(p))
+	lcm := int64(1)
+	for ix, c := range p {
+		r, ok := c.num.constant()
+		if !ok {
+			return nil
+		} else if _, ok := c.den.constant(); !ok {
+			return nil
+		}
+		coeffs[ix] = r
+		den := r.denominator().value
+		g, _ := gcd(Int(lcm), Int(den))
+		lcm = lcm / g.value * den
+	}
+
+	// Roots that are zero are handled separately since every
+	// integer divides a vanishing constant coefficient
+	low := 0
+	for low < len(coeffs) && coeffs[low].numerator().value == 0 {
+		low++
+	}
+	candidates := []ratFunc{}
+	if low > 0 {
+		candidates = append(candidates, constRatFunc(Int(0)))
+	}
+	if low >= len(coeffs)-1 {
+		return candidates
+	}
+
+	scaled := func(c rational) int64 {
+		return ratMul(c, Int(lcm)).numerator().value
+	}
+	const maxTrialDivision = int64(1) << 40
+	a0, an := intAbs(Int(scaled(coeffs[low]))).value, intAbs(Int(scaled(coeffs[len(coeffs)-1]))).value
+	if a0 > maxTrialDivision || an > maxTrialDivision {
+		return candidates
+	}
+	for _, num := range divisors(a0) {
+		for _, den := range divisors(an) {
+			c := Div(Int(num), Int(den)).(rational).simplifyRational()
+			candidates = append(candidates, constRatFunc(c), constRatFunc(ratMinus(c)))
+		}
+	}
+	return candidates
+}
+
+// The positive divisors of n > 0 in increasing order.
+func divisors(n int64) []int64 {
+	small, large := []int64{}, []int64{}
+	for d := int64(1); d*d <= n; d++ {
+		if n%d == 0 {
+			small = append(small, d)
+			if d*d != n {
+				large = append(large, n/d)
+			}
+		}
+	}
+	for ix := len(large) - 1; ix >= 0; ix-- {
+		small = append(small, large[ix])
+	}
+	return small
+}
+
+/*
+Returns the roots of the square free polynomial p, and false if some
+factor of p could not be solved. Linear factors x - c for the candidates
+c are split off first, and the remaining factor is solved directly.
+*/
+func (p ratFuncPoly) solve(candidates []ratFunc, kt *kernelTable) ([]Expr, bool) {
+	roots := []Expr{}
+	for _, c := range candidates {
+		if p.degree() <= 2 {
+			break
+		}
+		if p.eval(c).isZero() {
+			roots = append(roots, c.toExpr(kt))
+			p, _ = p.quoRem(ratFuncPoly{c.neg(), constRatFunc(Int(1))})
+		}
+	}
+
+	switch {
+	case p.degree() < 1:
+		return roots, true
+	case p.degree() == 1:
+		root, _ := p[0].neg().div(p[1])
+		return append(roots, root.toExpr(kt)), true
+	case p.degree() == 2:
+		return append(roots, solveQuadratic(p[2], p[1], p[0], kt)...), true
+	case p.degree() == 4 && p[1].isZero() && p[3].isZero():
+		// Biquadratic, i.e. a quadratic in x^2
+		for _, square := range solveQuadratic(p[4], p[2], p[0], kt) {
+			r, ok := toRatFunc(square, kt)
+			if !ok {
+				return nil, false
+			}
+			root := ratFuncSqrt(r, kt)
+			roots = append(roots, root.toExpr(kt), root.neg().toExpr(kt))
+		}
+		return roots, true
+	}
+	return nil, false
+}
+
+// The two roots of a*x^2 + b*x + c, which are equal if the
+// discriminant is zero.
+func solveQuadratic(a, b, c ratFunc, kt *kernelTable) []Expr {
+	disc := b.mul(b).sub(constRatFunc(Int(4)).mul(a).mul(c))
+	sqrtDisc := ratFuncSqrt(disc, kt)
+	twoA := constRatFunc(Int(2)).mul(a)
+	plus, _ := b.neg().add(sqrtDisc).div(twoA)
+	minus, _ := b.neg().sub(sqrtDisc).div(twoA)
+	return []Expr{minus.toExpr(kt), plus.toExpr(kt)}
+}
+
+/*
+Returns a square root of r. Exact square roots of the numerator and
+denominator are taken when they exist, otherwise r is written as
+sqrt(num*den)/den so that the radical kernel has a polynomial base.
+Square factors of the base are moved outside the radical, e.g.
+sqrt(8*x^3) = 2*x*sqrt(2*x). For symbolic entries this is done formally,
+i.e. without regard to the sign of the factors.
+*/
+func ratFuncSqrt(r ratFunc, kt *kernelTable) ratFunc {
+	if numRoot, ok := r.num.sqrt(); ok {
+		if denRoot, ok := r.den.sqrt(); ok {
+			return newRatFunc(numRoot, denRoot, kt)
+		}
+	}
+
+	radicand := r.num.mul(r.den)
+	outside := constPoly(Int(1))
+	for _, x := range kernelsIn(radicand) {
+		for ix, f := range squareFreeFactors(radicand, x) {
+			if half := (ix + 1) / 2; half > 0 {
+				square := f.pow(half)
+				outside = outside.mul(square)
+				radicand, _ = radicand.exactQuo(square.mul(square))
+			}
+		}
+	}
+
+	// The leading coefficient c = n/d is split as n*d = k^2*m
+	// so that sqrt(c) = k/d*sqrt(m)
+	c := radicand.leadingTerm().coeff
+	k, m := squareFreeSplit(intAbs(intMul(c.numerator(), c.denominator())).value)
+	if c.numerator().value < 0 {
+		m = -m
+	}
+	radicand = radicand.scale(ratDiv(Int(m), c))
+	outside = outside.scale(Div(Int(k), c.denominator()).(rational).simplifyRational())
+	if radicand.equal(constPoly(Int(1))) {
+		return newRatFunc(outside, r.den, kt)
+	}
+	root, _ := kt.radicalPower(radicand, 1, 2)
+	return newRatFunc(root.mul(outside), r.den, kt)
+}
+
+// Returns k and m with n = k^2*m and m square free.
+func squareFreeSplit(n int64) (int64, int64) {
+	k, m := int64(1), int64(1)
+	for d := int64(2); d*d <= n; d++ {
+		for n%(d*d) == 0 {
+			n /= d * d
+			k *= d
+		}
+		if n%d == 0 {
+			n /= d
+			m *= d
+		}
+	}
+	return k, m * n
+}
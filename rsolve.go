@@ -0,0 +1,388 @@
+package gosymbol
+
+import (
+	"fmt"
+)
+
+// An initial value a(At) = Value for RSolve, e.g.
+// InitialValue{At: Int(0), Value: Int(1)} for a(0) = 1.
+type InitialValue struct {
+	At    Expr
+	Value Expr
+}
+
+/*
+Solves the recurrence relation recurrence = 0 for a, which must be an
+undefined function applied to n alone. The recurrence contains a at
+arguments n + j for integers j, e.g. Function("a", Add(n, Int(1))).
+
+The following kinds of recurrences are solved:
+
+  - linear recurrences with constant coefficients of any order, through
+    the roots of the characteristic polynomial, where the right hand side
+    may be a sum of polynomials times powers b^n and the particular
+    solution is found by undetermined coefficients,
+  - first order linear recurrences c_1(n)*a(n + 1) + c_0(n)*a(n) = g(n),
+    whose solution a(n) = P(n)*(a(n0) + sum_{k = n0}^{n - 1} g(k)/(c_1(k)*P(k + 1)))
+    with P(n) = prod_{k = n0}^{n - 1} -c_0(k)/c_1(k) is found with Sum and Product.
+
+Factorials in the solution are written as binomial coefficients where
+possible. The general solution contains the constants C1, C2, ... which
+are determined by the initial values, if given. Constants that are not
+determined by the initial values are kept.
+
+An UnsupportedRecurrenceError is returned if the recurrence is of none of
+the kinds above, or if the characteristic polynomial has complex roots
+whose arguments are not multiples of π/4 or π/6.
+*/
+func RSolve(recurrence Expr, a FunctionApplication, n variable, values ...InitialValue) (Expr, error) {
+	if len(a.Args) != 1 || !Equal(a.Args[0], n) {
+		return nil, &UnsupportedRecurrenceError{Recurrence: recurrence}
+	}
+	r := &recurrenceProblem{eq: recurrence, a: a, n: n}
+	c, g, ok := r.coefficients()
+	if !ok {
+		return nil, &UnsupportedRecurrenceError{Recurrence: recurrence}
+	}
+
+	var solution Expr
+	var err error
+	constant := true
+	for _, ck := range c {
+		constant = constant && !RecContains(ck, n)
+	}
+	if constant {
+		solution, err = r.solveConstantCoefficients(c, g)
+	} else if len(c) == 2 {
+		solution, err = r.solveFirstOrder(c, g, values)
+	} else {
+		return nil, &UnsupportedRecurrenceError{Recurrence: recurrence}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) > 0 {
+		equations := make([]Expr, len(values))
+		for ix, v := range values {
+			equations[ix] = Sub(Substitute(solution, n, v.At), v.Value).Simplify()
+		}
+		solution, _, err = substituteConstants(solution, equations, r.constants)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return factorialsToBinomials(tidy(solution), n), nil
+}
+
+// A recurrence in a where a(n + j) has been
+// replaced by the variable ys[j].
+type recurrenceProblem struct {
+	eq        Expr
+	a         FunctionApplication
+	n         variable
+	ys        map[int64]variable
+	constants []variable
+}
+
+// Returns the k:th constant of the general solution, counting from one.
+func (r *recurrenceProblem) constant(k int) variable {
+	for len(r.constants) < k {
+		name := VarName(fmt.Sprintf("C%d", len(r.constants)+1))
+		r.constants = append(r.constants, freshVariable(name, r.eq))
+	}
+	return r.constants[k-1]
+}
+
+/*
+Returns the coefficients c_0, ..., c_d and the right hand side g if the
+recurrence is c_d*a(n + d) + ... + c_0*a(n) = g, with c_0 and c_d non-zero,
+after shifting n so that the lowest argument of a is n.
+*/
+func (r *recurrenceProblem) coefficients() ([]Expr, Expr, bool) {
+	r.ys = map[int64]variable{}
+	reduced, ok := r.replaceShifts(r.eq)
+	if !ok || len(r.ys) < 2 {
+		return nil, nil, false
+	}
+	lo, hi := int64(maxIntegerShift), int64(-maxIntegerShift)
+	for j := range r.ys {
+		lo, hi = min(lo, j), max(hi, j)
+	}
+
+	expanded := Expand(expandExp(reduced))
+	c := make([]Expr, hi-lo+1)
+	g := expanded
+	for j := range c {
+		y, ok := r.ys[lo+int64(j)]
+		if !ok {
+			c[j] = Int(0)
+			continue
+		}
+		c[j] = Cancel(differentiate(expanded, y))
+		for _, w := range r.ys {
+			if RecContains(c[j], w) {
+				return nil, nil, false
+			}
+		}
+		g = Substitute(g, y, Int(0))
+	}
+
+	// Shifts n so that the lowest argument is n
+	for j := range c {
+		c[j] = Cancel(shiftVariable(c[j], r.n, Int(-lo)))
+	}
+	return c, Cancel(Neg(shiftVariable(g, r.n, Int(-lo)))), true
+}
+
+// Replaces a(n + j) in expr by variables. False is returned if a
+// is applied to anything else than n plus an integer.
+func (r *recurrenceProblem) replaceShifts(expr Expr) (Expr, bool) {
+	if f, ok := expr.(FunctionApplication); ok && f.Name == r.a.Name {
+		if len(f.Args) != 1 {
+			return nil, false
+		}
+		j, ok := Cancel(Sub(f.Args[0], r.n)).(integer)
+		if !ok || j.value > maxIntegerShift || -j.value > maxIntegerShift {
+			return nil, false
+		}
+		if _, ok := r.ys[j.value]; !ok {
+			name := VarName(fmt.Sprintf("%v%d", r.a.Name, len(r.ys)))
+			r.ys[j.value] = freshVariable(name, r.eq)
+		}
+		return r.ys[j.value], true
+	} else if _, ok := expr.(derivative); ok {
+		return nil, false
+	}
+
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		op, ok := r.replaceShifts(Operand(expr, ix))
+		if !ok {
+			return nil, false
+		}
+		expr = replaceOperand(expr, ix, op)
+	}
+	return expr, true
+}
+
+/* Linear recurrences with constant coefficients */
+
+// The angles θ in (0, π) given by cos(θ) for which complex roots
+// ρ*(cos(θ) ± i*sin(θ)) of characteristic polynomials are supported.
+var characteristicAngles = []struct {
+	cos   Expr
+	angle Expr
+}{
+	{Int(0), Div(PI, Int(2))},
+	{Div(Int(1), Int(2)), Div(PI, Int(3))},
+	{Div(Int(-1), Int(2)), Mul(Div(Int(2), Int(3)), PI)},
+	{Div(Pow(Int(2), Div(Int(1), Int(2))), Int(2)), Div(PI, Int(4))},
+	{Div(Neg(Pow(Int(2), Div(Int(1), Int(2)))), Int(2)), Mul(Div(Int(3), Int(4)), PI)},
+	{Div(Pow(Int(3), Div(Int(1), Int(2))), Int(2)), Div(PI, Int(6))},
+	{Div(Neg(Pow(Int(3), Div(Int(1), Int(2)))), Int(2)), Mul(Div(Int(5), Int(6)), PI)},
+}
+
+/*
+Solves sum_j c_j*a(n + j) = g. Each real root r of multiplicity m of the
+characteristic polynomial sum_j c_j*λ^j gives the solutions n^k*r^n for
+k < m, and each pair of complex roots ρ*(cos(θ) ± i*sin(θ)) gives
+n^k*ρ^n*cos(θ*n) and n^k*ρ^n*sin(θ*n).
+*/
+func (r *recurrenceProblem) solveConstantCoefficients(c []Expr, g Expr) (Expr, error) {
+	lambda := freshVariable("λ", r.eq)
+	terms := make([]Expr, len(c))
+	for j, cj := range c {
+		terms[j] = Mul(cj, Pow(lambda, Int(int64(j))))
+	}
+	roots, err := polynomialRoots(Add(terms...), lambda, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	basis := []Expr{}
+	for _, root := range roots {
+		re, im, ok := splitComplex(root.value)
+		if !ok {
+			return nil, &UnsupportedRecurrenceError{Recurrence: r.eq}
+		}
+		for k := 0; k < root.multiplicity; k++ {
+			power := Pow(r.n, Int(int64(k)))
+			if isZero(im) {
+				basis = append(basis, Mul(power, Pow(root.value, r.n)).Simplify())
+				continue
+			} else if isNegativeForm(Expand(im)) {
+				// Only one root of each conjugate pair is used
+				continue
+			}
+			rho := Cancel(Pow(Add(Pow(re, Int(2)), Pow(im, Int(2))), Div(Int(1), Int(2))))
+			angle, ok := characteristicAngle(Cancel(Div(re, rho)))
+			if !ok {
+				return nil, &UnsupportedRecurrenceError{Recurrence: r.eq}
+			}
+			basis = append(basis,
+				Mul(power, Pow(rho, r.n), Cos(Mul(angle, r.n))).Simplify(),
+				Mul(power, Pow(rho, r.n), Sin(Mul(angle, r.n))).Simplify())
+		}
+	}
+
+	terms = make([]Expr, len(basis))
+	for ix, y := range basis {
+		terms[ix] = Mul(r.constant(ix+1), y)
+	}
+	homogeneous := Add(terms...)
+	if isZero(g) {
+		return homogeneous, nil
+	}
+
+	particular, ok := r.undeterminedCoefficients(c, g, roots)
+	if !ok {
+		return nil, &UnsupportedRecurrenceError{Recurrence: r.eq}
+	}
+	return Add(homogeneous, particular), nil
+}
+
+// Returns the angle θ in (0, π) with cos(θ) = cosine.
+func characteristicAngle(cosine Expr) (Expr, bool) {
+	for _, a := range characteristicAngles {
+		if isZero(Sub(cosine, a.cos)) {
+			return a.angle, true
+		}
+	}
+	return nil, false
+}
+
+/*
+Finds a particular solution of sum_j c_j*a(n + j) = g by the method of
+undetermined coefficients. Every term of g must be a polynomial times a
+power b^n. The terms are grouped by b into q(n)*b^n, for which the trial
+solution is n^s*P(n)*b^n where s is the multiplicity of b as a root of
+the characteristic polynomial and P is a polynomial of the same degree
+as q. Dividing by b^n gives the polynomial identity
+
+	sum_j c_j*b^j*(n + j)^s*P(n + j) = q(n)
+
+from which the coefficients of P are found by equating coefficients.
+*/
+func (r *recurrenceProblem) undeterminedCoefficients(c []Expr, g Expr, roots []polynomialRoot) (Expr, bool) {
+	terms, ok := expandedTerms(expandExp(g))
+	if !ok {
+		return nil, false
+	}
+
+	// The families q(n)*b^n of terms of the right hand side
+	type family struct {
+		base Expr
+		q    []Expr
+	}
+	families := []family{}
+	for _, t := range terms {
+		constant, factors := splitConstantFactors(t, r.n)
+		var base Expr = Int(1)
+		polynomial := []Expr{constant}
+		for _, f := range factors {
+			if _, ok := monomialDegree(f, r.n); ok {
+				polynomial = append(polynomial, f)
+			} else if ratio, ok := factorShiftRatio(f, r.n); ok && !RecContains(ratio, r.n) {
+				// f = f(0)*ratio^n
+				base = Mul(base, ratio)
+				polynomial = append(polynomial, Substitute(f, r.n, Int(0)))
+			} else {
+				return nil, false
+			}
+		}
+		base = Cancel(base)
+
+		found := false
+		for ix, fam := range families {
+			if isZero(Sub(fam.base, base)) {
+				families[ix].q = append(fam.q, Mul(polynomial...))
+				found = true
+				break
+			}
+		}
+		if !found {
+			families = append(families, family{base: base, q: []Expr{Mul(polynomial...)}})
+		}
+	}
+
+	particular := []Expr{}
+	for _, fam := range families {
+		q := Add(fam.q...)
+		qc, err := polynomialCoefficients(q, r.n)
+		if err != nil {
+			return nil, false
+		}
+		s := 0
+		for _, root := range roots {
+			if isZero(Sub(root.value, fam.base)) {
+				s = root.multiplicity
+			}
+		}
+
+		unknowns := make([]variable, len(qc))
+		trial := make([]Expr, len(qc))
+		for ix := range unknowns {
+			unknowns[ix] = freshVariable(VarName(fmt.Sprintf("A%d", ix)), r.eq, g)
+			trial[ix] = Mul(unknowns[ix], Pow(r.n, Int(int64(ix+s))))
+		}
+		var ansatz Expr = Add(trial...)
+
+		lhs := make([]Expr, len(c))
+		for j, cj := range c {
+			lhs[j] = Mul(cj, Pow(fam.base, Int(int64(j))), shiftVariable(ansatz, r.n, Int(int64(j))))
+		}
+		values, ok := solveIdentity(Sub(Add(lhs...), q), unknowns, r.n)
+		if !ok {
+			return nil, false
+		}
+		for ix, u := range unknowns {
+			ansatz = Substitute(ansatz, u, values[ix])
+		}
+		particular = append(particular, Mul(ansatz, Pow(fam.base, r.n)).Simplify())
+	}
+	return Add(particular...), true
+}
+
+/* First order linear recurrences */
+
+/*
+Solves c_1(n)*a(n + 1) + c_0(n)*a(n) = g(n) as
+
+	a(n) = P(n)*(C1 + sum_{k = n0}^{n - 1} g(k)/(c_1(k)*P(k + 1)))
+
+with P(n) = prod_{k = n0}^{n - 1} -c_0(k)/c_1(k), so that C1 = a(n0).
+The starting point n0 is the first initial value if there is one, and
+otherwise 0, or 1 if the product does not have a closed form from 0,
+e.g. when a factor vanishes at k = 0.
+*/
+func (r *recurrenceProblem) solveFirstOrder(c []Expr, g Expr, values []InitialValue) (Expr, error) {
+	k := freshVariable("k", r.eq, g)
+	p := Substitute(Cancel(Div(Neg(c[0]), c[1])), r.n, k)
+	starts := []Expr{Int(0), Int(1)}
+	if len(values) > 0 {
+		starts = []Expr{values[0].At}
+	}
+
+	var start, product Expr
+	var err error
+	for _, start = range starts {
+		product, err = Product(p, k, start, Sub(r.n, Int(1)))
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sum Expr = Int(0)
+	if !isZero(g) {
+		q := Substitute(Cancel(Div(g, c[1])), r.n, k)
+		next := Substitute(product, r.n, Add(k, Int(1)))
+		sum, err = Sum(Div(q, next), k, start, Sub(r.n, Int(1)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return Mul(product, Add(r.constant(1), sum)), nil
+}
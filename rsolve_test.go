@@ -0,0 +1,148 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRSolve(t *testing.T) {
+	n, m := Var("n"), Var("m")
+	an := Function("a", n)
+	a := func(j int64) Expr { return Function("a", Add(n, Int(j))) }
+	value := func(at, v int64) InitialValue { return InitialValue{At: Int(at), Value: Int(v)} }
+	C1, C2 := Var("C1"), Var("C2")
+
+	tests := []struct {
+		name           string
+		recurrence     Expr
+		values         []InitialValue
+		expectedOutput Expr
+	}{
+		{
+			name:           "Geometric",
+			recurrence:     Sub(a(1), Mul(Int(2), an)),
+			values:         []InitialValue{value(0, 3)},
+			expectedOutput: Mul(Int(3), Pow(Int(2), n)),
+		},
+		{
+			name:           "General solution",
+			recurrence:     Sub(a(1), Mul(Int(3), an)),
+			expectedOutput: Mul(C1, Pow(Int(3), n)),
+		},
+		{
+			name:           "Towers of Hanoi",
+			recurrence:     Sub(an, Add(Mul(Int(2), a(-1)), Int(1))),
+			values:         []InitialValue{value(0, 0)},
+			expectedOutput: Sub(Pow(Int(2), n), Int(1)),
+		},
+		{
+			name:           "Repeated root",
+			recurrence:     Add(a(2), Mul(Int(-4), a(1)), Mul(Int(4), an)),
+			values:         []InitialValue{value(0, 1), value(1, 4)},
+			expectedOutput: Mul(Add(n, Int(1)), Pow(Int(2), n)),
+		},
+		{
+			name:           "Polynomial right hand side",
+			recurrence:     Sub(a(1), Add(an, n)),
+			values:         []InitialValue{value(0, 0)},
+			expectedOutput: Div(Mul(n, Sub(n, Int(1))), Int(2)),
+		},
+		{
+			name:           "Resonant exponential right hand side",
+			recurrence:     Sub(a(1), Add(Mul(Int(2), an), Pow(Int(2), n))),
+			values:         []InitialValue{value(0, 0)},
+			expectedOutput: Div(Mul(n, Pow(Int(2), n)), Int(2)),
+		},
+		{
+			name:           "Complex roots",
+			recurrence:     Add(a(2), an),
+			values:         []InitialValue{value(0, 1), value(1, 0)},
+			expectedOutput: Cos(Mul(Div(PI, Int(2)), n)),
+		},
+		{
+			name:           "Complex roots without initial values",
+			recurrence:     Add(a(2), Neg(a(1)), an),
+			expectedOutput: Add(Mul(C1, Cos(Mul(Div(PI, Int(3)), n))), Mul(C2, Sin(Mul(Div(PI, Int(3)), n)))),
+		},
+		{
+			name:           "Factorial",
+			recurrence:     Sub(a(1), Mul(Add(n, Int(1)), an)),
+			values:         []InitialValue{value(0, 1)},
+			expectedOutput: Factorial(n),
+		},
+		{
+			name:           "Variable coefficients with right hand side",
+			recurrence:     Sub(a(1), Add(Mul(Add(n, Int(1)), an), Factorial(Add(n, Int(1))))),
+			values:         []InitialValue{value(0, 0)},
+			expectedOutput: Mul(n, Factorial(n)),
+		},
+		{
+			name:           "Binomial coefficient",
+			recurrence:     Sub(Mul(Add(n, Int(1)), a(1)), Mul(Add(n, m, Int(1)), an)),
+			values:         []InitialValue{value(0, 1)},
+			expectedOutput: Binomial(Add(n, m), n),
+		},
+		{
+			name:           "Product vanishing at zero",
+			recurrence:     Sub(a(1), Mul(n, an)),
+			expectedOutput: Mul(C1, Factorial(Sub(n, Int(1)))),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := RSolve(test.recurrence, an, n, test.values...)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+			if !isZero(Sub(result, test.expectedOutput)) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.recurrence, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestRSolveFibonacci(t *testing.T) {
+	n := Var("n")
+	an := Function("a", n)
+	recurrence := Sub(Function("a", Add(n, Int(2))), Add(Function("a", Add(n, Int(1))), an))
+	result, err := RSolve(recurrence, an, n,
+		InitialValue{At: Int(0), Value: Int(0)}, InitialValue{At: Int(1), Value: Int(1)})
+	if err != nil {
+		t.Fatalf("Following test failed: %s\nUnexpected error: %v", "Fibonacci", err)
+	}
+
+	fib := []int64{0, 1}
+	for len(fib) <= 10 {
+		fib = append(fib, fib[len(fib)-1]+fib[len(fib)-2])
+	}
+	for k, expected := range fib {
+		if got := Substitute(result, n, Int(int64(k))); !isZero(Sub(got, Int(expected))) {
+			t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", "Fibonacci", k, expected, Cancel(got))
+		}
+	}
+}
+
+func TestRSolveErrors(t *testing.T) {
+	n := Var("n")
+	an := Function("a", n)
+	a1, a2 := Function("a", Add(n, Int(1))), Function("a", Add(n, Int(2)))
+	var unsupported *UnsupportedRecurrenceError
+
+	if _, err := RSolve(Sub(a1, Pow(an, Int(2))), an, n); !errors.As(err, &unsupported) {
+		t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", "Non-linear", Sub(a1, Pow(an, Int(2))), unsupported, err)
+	}
+	variable := Sub(a2, Add(Mul(n, a1), an))
+	if _, err := RSolve(variable, an, n); !errors.As(err, &unsupported) {
+		t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", "Second order with variable coefficients", variable, unsupported, err)
+	}
+	if _, err := RSolve(Sub(Function("a", Mul(Int(2), n)), an), an, n); !errors.As(err, &unsupported) {
+		t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", "Divide and conquer", Sub(Function("a", Mul(Int(2), n)), an), unsupported, err)
+	}
+	var inconsistent *InconsistentInitialConditionsError
+	values := []InitialValue{{At: Int(0), Value: Int(1)}, {At: Int(1), Value: Int(1)}}
+	if _, err := RSolve(Sub(a1, Mul(Int(2), an)), an, n, values...); !errors.As(err, &inconsistent) {
+		t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", "Inconsistent initial values", values, inconsistent, err)
+	}
+}
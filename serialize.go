@@ -0,0 +1,613 @@
+package gosymbol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+/*
+Expressions are serialized as trees tagged with their node type, as
+JSON for readability and in a compact binary encoding for caching and
+transport. Both formats carry serializationVersion and reproduce the
+expression exactly, i.e. without simplifying it, so a simplified
+expression is read back simplified. Constrained variables can not be
+serialized since their constraint is a Go function.
+*/
+const serializationVersion = 1
+
+// The node types in the order of their binary tags. The names are the
+// "type" of the nodes in JSON, so neither may change within a version.
+var serializedTypes = []string{
+	"undefined",
+	"integer",
+	"fraction",
+	"variable",
+	"add",
+	"mul",
+	"pow",
+	"exp",
+	"log",
+	"sqrt",
+	"sin",
+	"cos",
+	"function",
+	"derivative",
+}
+
+// The names of the assumptions of a variable in JSON.
+var serializedProperties = []struct {
+	property Property
+	name     string
+}{
+	{IsReal, "real"},
+	{IsInteger, "integer"},
+	{IsPositive, "positive"},
+	{IsNegative, "negative"},
+	{IsNonnegative, "nonnegative"},
+	{IsNonpositive, "nonpositive"},
+	{IsNonzero, "nonzero"},
+}
+
+/*
+Wraps an expression so it can be a field of a struct that is encoded
+with encoding/json or encoding/gob, which can not decode into the
+interface Expr, e.g.
+
+	type cacheEntry struct {
+		Input, Simplified gosymbol.Serializable
+	}
+*/
+type Serializable struct {
+	Expr
+}
+
+func (s Serializable) MarshalJSON() ([]byte, error) {
+	if s.Expr == nil {
+		return []byte("null"), nil
+	}
+	return marshalJSON(s.Expr)
+}
+
+func (s *Serializable) UnmarshalJSON(data []byte) error {
+	if string(bytes.TrimSpace(data)) == "null" {
+		s.Expr = nil
+		return nil
+	}
+	expr, err := UnmarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	s.Expr = expr
+	return nil
+}
+
+func (s Serializable) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(s.Expr)
+}
+
+func (s *Serializable) UnmarshalBinary(data []byte) error {
+	expr, err := UnmarshalBinary(data)
+	if err != nil {
+		return err
+	}
+	s.Expr = expr
+	return nil
+}
+
+/* JSON */
+
+type jsonDocument struct {
+	Version int       `json:"version"`
+	Expr    *jsonNode `json:"expr"`
+}
+
+type jsonNode struct {
+	Type        string      `json:"type"`
+	Value       *int64      `json:"value,omitempty"`
+	Num         *int64      `json:"num,omitempty"`
+	Den         *int64      `json:"den,omitempty"`
+	Name        *VarName    `json:"name,omitempty"`
+	Assumptions []string    `json:"assumptions,omitempty"`
+	Args        []*jsonNode `json:"args,omitempty"`
+}
+
+/*
+The JSON encoding of an expression is a versioned document with the
+tree of its nodes, e.g. 2*x + 1/2 is
+
+	{"version": 1, "expr": {"type": "add", "args": [
+		{"type": "mul", "args": [
+			{"type": "integer", "value": 2},
+			{"type": "variable", "name": "x"}]},
+		{"type": "fraction", "num": 1, "den": 2}]}}
+
+The operands of add, mul, pow, the functions and derivative are in
+args, where a derivative has the differentiated expression and the
+variable. Variables list their assumptions by name, e.g. "positive".
+*/
+func marshalJSON(expr Expr) ([]byte, error) {
+	node, err := toJSONNode(expr)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonDocument{Version: serializationVersion, Expr: node})
+}
+
+func (e undefined) MarshalJSON() ([]byte, error)           { return marshalJSON(e) }
+func (e integer) MarshalJSON() ([]byte, error)             { return marshalJSON(e) }
+func (e fraction) MarshalJSON() ([]byte, error)            { return marshalJSON(e) }
+func (e variable) MarshalJSON() ([]byte, error)            { return marshalJSON(e) }
+func (e constrainedVariable) MarshalJSON() ([]byte, error) { return marshalJSON(e) }
+func (e add) MarshalJSON() ([]byte, error)                 { return marshalJSON(e) }
+func (e mul) MarshalJSON() ([]byte, error)                 { return marshalJSON(e) }
+func (e pow) MarshalJSON() ([]byte, error)                 { return marshalJSON(e) }
+func (e exp) MarshalJSON() ([]byte, error)                 { return marshalJSON(e) }
+func (e log) MarshalJSON() ([]byte, error)                 { return marshalJSON(e) }
+func (e sqrt) MarshalJSON() ([]byte, error)                { return marshalJSON(e) }
+func (e sin) MarshalJSON() ([]byte, error)                 { return marshalJSON(e) }
+func (e cos) MarshalJSON() ([]byte, error)                 { return marshalJSON(e) }
+func (e FunctionApplication) MarshalJSON() ([]byte, error) { return marshalJSON(e) }
+func (e derivative) MarshalJSON() ([]byte, error)          { return marshalJSON(e) }
+
+func toJSONNode(expr Expr) (*jsonNode, error) {
+	node := &jsonNode{}
+	var args []Expr
+	switch e := expr.(type) {
+	case undefined:
+		node.Type = "undefined"
+	case integer:
+		node.Type, node.Value = "integer", &e.value
+	case fraction:
+		node.Type, node.Num, node.Den = "fraction", &e.num.value, &e.den.value
+	case variable:
+		node.Type, node.Name = "variable", &e.Name
+		for _, p := range serializedProperties {
+			if e.assumptions&p.property != 0 {
+				node.Assumptions = append(node.Assumptions, p.name)
+			}
+		}
+	case add:
+		node.Type, args = "add", e.Operands
+	case mul:
+		node.Type, args = "mul", e.Operands
+	case pow:
+		node.Type, args = "pow", []Expr{e.Base, e.Exponent}
+	case exp:
+		node.Type, args = "exp", []Expr{e.Arg}
+	case log:
+		node.Type, args = "log", []Expr{e.Arg}
+	case sqrt:
+		node.Type, args = "sqrt", []Expr{e.Arg}
+	case sin:
+		node.Type, args = "sin", []Expr{e.Arg}
+	case cos:
+		node.Type, args = "cos", []Expr{e.Arg}
+	case FunctionApplication:
+		node.Type, node.Name, args = "function", &e.Name, e.Args
+	case derivative:
+		node.Type, args = "derivative", []Expr{e.Arg, e.Var}
+	default:
+		return nil, &InvalidArgumentError{Function: "serialization", Arg: expr}
+	}
+	for _, arg := range args {
+		child, err := toJSONNode(arg)
+		if err != nil {
+			return nil, err
+		}
+		node.Args = append(node.Args, child)
+	}
+	return node, nil
+}
+
+/*
+Decodes an expression from the JSON written by the MarshalJSON method
+of the expression, see Serializable for decoding struct fields. An
+InvalidNotationError is returned for malformed input or an unknown
+version.
+*/
+func UnmarshalJSON(data []byte) (Expr, error) {
+	var doc jsonDocument
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, jsonError("%v", err)
+	}
+	if doc.Version != serializationVersion {
+		return nil, jsonError("unsupported version %d", doc.Version)
+	}
+	if doc.Expr == nil {
+		return nil, jsonError("missing expr")
+	}
+	return fromJSONNode(doc.Expr)
+}
+
+func jsonError(format string, args ...any) error {
+	return &InvalidNotationError{Format: "expression JSON", Msg: fmt.Sprintf(format, args...)}
+}
+
+func fromJSONNode(node *jsonNode) (Expr, error) {
+	if node == nil {
+		return nil, jsonError("null node")
+	}
+	args := make([]Expr, len(node.Args))
+	for ix, child := range node.Args {
+		var err error
+		if args[ix], err = fromJSONNode(child); err != nil {
+			return nil, err
+		}
+	}
+
+	switch node.Type {
+	case "integer":
+		if node.Value == nil {
+			return nil, jsonError("integer without value")
+		}
+		return Int(*node.Value), nil
+	case "fraction":
+		if node.Num == nil || node.Den == nil || *node.Den == 0 {
+			return nil, jsonError("fraction without numerator or denominator")
+		}
+		return fraction{num: Int(*node.Num), den: Int(*node.Den)}, nil
+	case "variable":
+		if node.Name == nil {
+			return nil, jsonError("variable without name")
+		}
+		v := variable{Name: *node.Name}
+	assumptions:
+		for _, name := range node.Assumptions {
+			for _, p := range serializedProperties {
+				if p.name == name {
+					v.assumptions |= p.property
+					continue assumptions
+				}
+			}
+			return nil, jsonError("unknown assumption %q", name)
+		}
+		if !v.assumptions.consistent() {
+			return nil, jsonError("contradictory assumptions %v", node.Assumptions)
+		}
+		return v, nil
+	case "function":
+		if node.Name == nil {
+			return nil, jsonError("function without name")
+		}
+		if len(args) == 0 {
+			args = nil
+		}
+		return FunctionApplication{Name: *node.Name, Args: args}, nil
+	}
+	return newSerializedNode(node.Type, args, jsonError)
+}
+
+// The number of operands of the node types with a fixed number of them.
+var serializedArity = map[string]int{"undefined": 0, "pow": 2, "exp": 1, "log": 1, "sqrt": 1, "sin": 1, "cos": 1, "derivative": 2}
+
+/*
+Returns the node of the given type with the given operands for the
+types without a payload, checking the number and types of the
+operands.
+*/
+func newSerializedNode(nodeType string, args []Expr, newError func(string, ...any) error) (Expr, error) {
+	n, ok := serializedArity[nodeType]
+	switch {
+	case nodeType == "add" || nodeType == "mul":
+		if len(args) < 2 {
+			return nil, newError("%s with %d operands", nodeType, len(args))
+		}
+	case !ok:
+		return nil, newError("unknown node type %q", nodeType)
+	case len(args) != n:
+		return nil, newError("%s with %d operands", nodeType, len(args))
+	}
+
+	switch nodeType {
+	case "undefined":
+		return Undefined(), nil
+	case "add":
+		return add{Operands: args}, nil
+	case "mul":
+		return mul{Operands: args}, nil
+	case "pow":
+		return pow{Base: args[0], Exponent: args[1]}, nil
+	case "exp":
+		return exp{Arg: args[0]}, nil
+	case "log":
+		return log{Arg: args[0]}, nil
+	case "sqrt":
+		return sqrt{Arg: args[0]}, nil
+	case "sin":
+		return sin{Arg: args[0]}, nil
+	case "cos":
+		return cos{Arg: args[0]}, nil
+	}
+	v, ok := args[1].(variable)
+	if !ok {
+		return nil, newError("derivative w.r.t. %v, which is not a variable", args[1])
+	}
+	return derivative{Arg: args[0], Var: v}, nil
+}
+
+/* Binary */
+
+// The first bytes of the binary encoding, followed by the version.
+const binaryMagic = "GSYM"
+
+/*
+Returns the binary encoding of expr. Every distinct subexpression is
+stored once and referred to by its index wherever it occurs, so e.g.
+the result of Expand or a derivative, which often repeat large
+subexpressions, stay small.
+
+The encoding is binaryMagic, the version byte, the number of nodes as
+a uvarint, the nodes and the index of the root. A node is its tag, the
+index in serializedTypes, followed by
+
+	undefined:  nothing
+	integer:    the value as a varint
+	fraction:   the numerator and denominator as varints
+	variable:   the length of the name, the name and the assumptions
+	function:   the length of the name, the name and the operands
+	otherwise:  the operands
+
+where operands are the number of operands and their indices as
+uvarints, each referring to an earlier node, and the assumptions are
+the Property as a uvarint.
+*/
+func MarshalBinary(expr Expr) ([]byte, error) {
+	enc := binaryEncoder{indices: map[string]uint64{}}
+	root, err := enc.encode(expr)
+	if err != nil {
+		return nil, err
+	}
+	data := append([]byte(binaryMagic), serializationVersion)
+	data = binary.AppendUvarint(data, enc.count)
+	data = append(data, enc.nodes...)
+	return binary.AppendUvarint(data, root), nil
+}
+
+type binaryEncoder struct {
+	nodes   []byte
+	count   uint64
+	indices map[string]uint64
+}
+
+// Appends expr and its operands, unless already encoded, and returns its index.
+func (enc *binaryEncoder) encode(expr Expr) (uint64, error) {
+	var node []byte
+	var args []Expr
+	tag := func(nodeType string) {
+		for ix, t := range serializedTypes {
+			if t == nodeType {
+				node = append(node, byte(ix))
+			}
+		}
+	}
+	switch e := expr.(type) {
+	case undefined:
+		tag("undefined")
+	case integer:
+		tag("integer")
+		node = binary.AppendVarint(node, e.value)
+	case fraction:
+		tag("fraction")
+		node = binary.AppendVarint(node, e.num.value)
+		node = binary.AppendVarint(node, e.den.value)
+	case variable:
+		tag("variable")
+		node = appendBinaryString(node, string(e.Name))
+		node = binary.AppendUvarint(node, uint64(e.assumptions))
+	case add:
+		tag("add")
+		args = e.Operands
+	case mul:
+		tag("mul")
+		args = e.Operands
+	case pow:
+		tag("pow")
+		args = []Expr{e.Base, e.Exponent}
+	case exp:
+		tag("exp")
+		args = []Expr{e.Arg}
+	case log:
+		tag("log")
+		args = []Expr{e.Arg}
+	case sqrt:
+		tag("sqrt")
+		args = []Expr{e.Arg}
+	case sin:
+		tag("sin")
+		args = []Expr{e.Arg}
+	case cos:
+		tag("cos")
+		args = []Expr{e.Arg}
+	case FunctionApplication:
+		tag("function")
+		node = appendBinaryString(node, string(e.Name))
+		args = e.Args
+	case derivative:
+		tag("derivative")
+		args = []Expr{e.Arg, e.Var}
+	default:
+		return 0, &InvalidArgumentError{Function: "serialization", Arg: expr}
+	}
+
+	switch expr.(type) {
+	case add, mul, pow, exp, log, sqrt, sin, cos, FunctionApplication, derivative:
+		node = binary.AppendUvarint(node, uint64(len(args)))
+		for _, arg := range args {
+			ix, err := enc.encode(arg)
+			if err != nil {
+				return 0, err
+			}
+			node = binary.AppendUvarint(node, ix)
+		}
+	}
+
+	// Equal subexpressions have equal encodings since their operands have equal indices
+	if ix, ok := enc.indices[string(node)]; ok {
+		return ix, nil
+	}
+	ix := enc.count
+	enc.indices[string(node)] = ix
+	enc.nodes = append(enc.nodes, node...)
+	enc.count++
+	return ix, nil
+}
+
+func appendBinaryString(data []byte, s string) []byte {
+	return append(binary.AppendUvarint(data, uint64(len(s))), s...)
+}
+
+/*
+Decodes an expression from the output of MarshalBinary. Shared
+subexpressions are decoded once and shared in the result. An
+InvalidNotationError is returned for malformed input or an unknown
+version.
+*/
+func UnmarshalBinary(data []byte) (Expr, error) {
+	if !bytes.HasPrefix(data, []byte(binaryMagic)) || len(data) == len(binaryMagic) {
+		return nil, binaryError("missing header")
+	}
+	if version := data[len(binaryMagic)]; version != serializationVersion {
+		return nil, binaryError("unsupported version %d", version)
+	}
+	dec := binaryDecoder{data: data[len(binaryMagic)+1:]}
+	count := dec.uvarint()
+	for uint64(len(dec.nodes)) < count && dec.err == nil {
+		dec.node()
+	}
+	root := dec.index()
+	if dec.err == nil && len(dec.data) > 0 {
+		dec.fail("%d bytes after the root", len(dec.data))
+	}
+	if dec.err != nil {
+		return nil, dec.err
+	}
+	return root, nil
+}
+
+func binaryError(format string, args ...any) error {
+	return &InvalidNotationError{Format: "binary expression", Msg: fmt.Sprintf(format, args...)}
+}
+
+// Reads nodes from data, keeping the first error.
+type binaryDecoder struct {
+	data  []byte
+	nodes []Expr
+	err   error
+}
+
+func (dec *binaryDecoder) fail(format string, args ...any) {
+	if dec.err == nil {
+		dec.err = binaryError(format, args...)
+	}
+}
+
+func (dec *binaryDecoder) uvarint() uint64 {
+	value, n := binary.Uvarint(dec.data)
+	if n <= 0 {
+		dec.fail("truncated or overflowing number")
+		return 0
+	}
+	dec.data = dec.data[n:]
+	return value
+}
+
+func (dec *binaryDecoder) varint() int64 {
+	value, n := binary.Varint(dec.data)
+	if n <= 0 {
+		dec.fail("truncated or overflowing number")
+		return 0
+	}
+	dec.data = dec.data[n:]
+	return value
+}
+
+func (dec *binaryDecoder) string() string {
+	n := dec.uvarint()
+	if n > uint64(len(dec.data)) {
+		dec.fail("truncated name")
+		return ""
+	}
+	s := string(dec.data[:n])
+	dec.data = dec.data[n:]
+	return s
+}
+
+// Reads the index of an earlier node and returns that node.
+func (dec *binaryDecoder) index() Expr {
+	ix := dec.uvarint()
+	if dec.err != nil {
+		return nil
+	}
+	if ix >= uint64(len(dec.nodes)) {
+		dec.fail("reference to node %d of %d", ix, len(dec.nodes))
+		return nil
+	}
+	return dec.nodes[ix]
+}
+
+func (dec *binaryDecoder) operands() []Expr {
+	n := dec.uvarint()
+	if n > uint64(len(dec.data)) {
+		dec.fail("truncated operands")
+		return nil
+	}
+	var args []Expr
+	for i := uint64(0); i < n && dec.err == nil; i++ {
+		args = append(args, dec.index())
+	}
+	return args
+}
+
+func (dec *binaryDecoder) node() {
+	if len(dec.data) == 0 {
+		dec.fail("truncated node")
+		return
+	}
+	tag := int(dec.data[0])
+	dec.data = dec.data[1:]
+	if tag >= len(serializedTypes) {
+		dec.fail("unknown tag %d", tag)
+		return
+	}
+
+	var expr Expr
+	var err error
+	switch nodeType := serializedTypes[tag]; nodeType {
+	case "integer":
+		expr = Int(dec.varint())
+	case "fraction":
+		num, den := dec.varint(), dec.varint()
+		if den == 0 {
+			dec.fail("fraction with zero denominator")
+		}
+		expr = fraction{num: Int(num), den: Int(den)}
+	case "variable":
+		name, bits := VarName(dec.string()), dec.uvarint()
+		if bits&^uint64(allProperties) != 0 {
+			dec.fail("unknown assumptions %d", bits)
+		}
+		v := variable{Name: name, assumptions: Property(bits)}
+		if !v.assumptions.consistent() {
+			dec.fail("contradictory assumptions %d", v.assumptions)
+		}
+		expr = v
+	case "function":
+		expr = FunctionApplication{Name: VarName(dec.string()), Args: dec.operands()}
+	case "undefined":
+		expr, err = newSerializedNode(nodeType, nil, binaryError)
+	default:
+		args := dec.operands()
+		if dec.err != nil {
+			return
+		}
+		expr, err = newSerializedNode(nodeType, args, binaryError)
+	}
+	if err != nil && dec.err == nil {
+		dec.err = err
+	}
+	dec.nodes = append(dec.nodes, expr)
+}
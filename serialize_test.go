@@ -0,0 +1,172 @@
+package gosymbol
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var serializationTests = []Expr{
+	Int(0),
+	Int(-1 << 63),
+	fraction{num: Int(6), den: Int(-4)},
+	Var("x"),
+	Var("n", Positive, Integer),
+	Undefined(),
+	Add(Mul(Div(Int(3), Int(2)), Pow(Var("x"), Int(2))), Neg(Exp(Neg(Var("x")))), Sqrt(Var("y"))),
+	Log(Cos(Sin(Var("θ_1")))),
+	Mul(I, PI, E),
+	Function("f", Var("x"), Add(Var("y"), Int(1)), Function("g")),
+	Derivative(Function("f", Var("x")), Var("x")),
+	Expand(Pow(Add(Var("x"), Sqrt(Int(2))), Int(3))),
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for ix, test := range serializationTests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			data, err := json.Marshal(test)
+			if err != nil {
+				t.Fatalf("Following test failed: JSON round trip\nInput: %v\nUnexpected error: %v", test, err)
+			}
+			result, err := UnmarshalJSON(data)
+			if err != nil || !Equal(result, test) {
+				t.Errorf("Following test failed: JSON round trip\nInput: %s\nExpected: %v\nGot: %v (error %v)", data, test, result, err)
+			}
+		})
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	for ix, test := range serializationTests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			data, err := MarshalBinary(test)
+			if err != nil {
+				t.Fatalf("Following test failed: binary round trip\nInput: %v\nUnexpected error: %v", test, err)
+			}
+			result, err := UnmarshalBinary(data)
+			if err != nil || !Equal(result, test) {
+				t.Errorf("Following test failed: binary round trip\nInput: %v\nExpected: %v\nGot: %v (error %v)", data, test, result, err)
+			}
+		})
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	x := Var("x", Positive)
+	expected := `{"version":1,"expr":{"type":"add","args":[` +
+		`{"type":"mul","args":[{"type":"integer","value":2},{"type":"variable","name":"x","assumptions":["real","positive","nonnegative","nonzero"]}]},` +
+		`{"type":"fraction","num":1,"den":2}]}}`
+
+	input := Add(Mul(Int(2), x), Div(Int(1), Int(2)))
+	if result, err := json.Marshal(input); err != nil || string(result) != expected {
+		t.Errorf("Following test failed: JSON schema\nInput: %v\nExpected: %v\nGot: %s (error %v)", input, expected, result, err)
+	}
+}
+
+func TestBinarySharing(t *testing.T) {
+	x := Var("x")
+	s := Add(Sin(Mul(Int(2), x)), Pow(x, Div(Int(1), Int(3))), Log(Add(x, Int(1))))
+
+	single, _ := MarshalBinary(s)
+	shared, _ := MarshalBinary(Mul(s, s, Pow(s, Int(2))))
+	// Only the product, the power and the exponent are added
+	if len(shared) > len(single)+12 {
+		t.Errorf("Following test failed: structural sharing\nInput: %v\nExpected: at most %d bytes\nGot: %d bytes", s, len(single)+12, len(shared))
+	}
+}
+
+func TestSerializable(t *testing.T) {
+	type entry struct {
+		Input, Simplified Serializable
+		Missing           Serializable
+	}
+	x := Var("x")
+	input := entry{Input: Serializable{Add(x, x)}, Simplified: Serializable{Add(x, x).Simplify()}}
+
+	data, err := json.Marshal(input)
+	var fromJSON entry
+	if err == nil {
+		err = json.Unmarshal(data, &fromJSON)
+	}
+	if err != nil || !Equal(fromJSON.Input.Expr, input.Input.Expr) || !Equal(fromJSON.Simplified.Expr, input.Simplified.Expr) || fromJSON.Missing.Expr != nil {
+		t.Errorf("Following test failed: JSON struct field\nInput: %v\nExpected: %v\nGot: %v (error %v)", input, input, fromJSON, err)
+	}
+
+	var buf bytes.Buffer
+	var fromGob entry
+	input.Missing = Serializable{Undefined()}
+	err = gob.NewEncoder(&buf).Encode(input)
+	if err == nil {
+		err = gob.NewDecoder(&buf).Decode(&fromGob)
+	}
+	if err != nil || !Equal(fromGob.Input.Expr, input.Input.Expr) || !Equal(fromGob.Simplified.Expr, input.Simplified.Expr) {
+		t.Errorf("Following test failed: gob struct field\nInput: %v\nExpected: %v\nGot: %v (error %v)", input, input, fromGob, err)
+	}
+}
+
+func TestSerializationErrors(t *testing.T) {
+	constrained := Add(Int(1), ConstrVar("c", func(Expr) bool { return true }))
+	if _, err := json.Marshal(constrained); err == nil {
+		t.Errorf("Following test failed: JSON of a constrained variable\nInput: %v\nExpected: error\nGot: nil", constrained)
+	}
+	var argErr *InvalidArgumentError
+	if _, err := MarshalBinary(constrained); !errors.As(err, &argErr) {
+		t.Errorf("Following test failed: binary of a constrained variable\nInput: %v\nExpected: InvalidArgumentError\nGot: %v", constrained, err)
+	}
+
+	jsonTests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Malformed JSON", input: `{"version":1,"expr":`},
+		{name: "Unknown version", input: `{"version":2,"expr":{"type":"undefined"}}`},
+		{name: "Missing expression", input: `{"version":1}`},
+		{name: "Unknown node type", input: `{"version":1,"expr":{"type":"tan","args":[{"type":"undefined"}]}}`},
+		{name: "Unknown field", input: `{"version":1,"expr":{"type":"undefined","value":1,"extra":0}}`},
+		{name: "Wrong number of operands", input: `{"version":1,"expr":{"type":"pow","args":[{"type":"undefined"}]}}`},
+		{name: "Zero denominator", input: `{"version":1,"expr":{"type":"fraction","num":1,"den":0}}`},
+		{name: "Unknown assumption", input: `{"version":1,"expr":{"type":"variable","name":"x","assumptions":["prime"]}}`},
+		{name: "Contradictory assumptions", input: `{"version":1,"expr":{"type":"variable","name":"x","assumptions":["positive","negative"]}}`},
+		{
+			name:  "Derivative w.r.t. a number",
+			input: `{"version":1,"expr":{"type":"derivative","args":[{"type":"undefined"},{"type":"integer","value":1}]}}`,
+		},
+	}
+	for ix, test := range jsonTests {
+		t.Run(fmt.Sprint("JSON ", ix+1), func(t *testing.T) {
+			_, err := UnmarshalJSON([]byte(test.input))
+			var notationErr *InvalidNotationError
+			if !errors.As(err, &notationErr) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: InvalidNotationError\nGot: %v", test.name, test.input, err)
+			}
+		})
+	}
+
+	valid, _ := MarshalBinary(Add(Var("x"), Int(1)))
+	binaryTests := []struct {
+		name  string
+		input []byte
+	}{
+		{name: "Missing header", input: []byte("GSY")},
+		{name: "Unknown version", input: append([]byte("GSYM"), 2, 1, 0, 0)},
+		{name: "Truncated", input: valid[:len(valid)-2]},
+		{name: "Trailing bytes", input: append(append([]byte{}, valid...), 0)},
+		{name: "Forward reference", input: append([]byte("GSYM"), 1, 1, 7, 1, 1, 0)},
+		{name: "Unknown tag", input: append([]byte("GSYM"), 1, 1, 99, 0)},
+		{name: "Contradictory assumptions", input: append([]byte("GSYM"), 1, 1, 3, 1, 'x', byte(IsPositive|IsNegative), 0)},
+		{name: "Unknown assumption", input: append([]byte("GSYM"), 1, 1, 3, 1, 'x', 0x80, 0x01, 0)},
+		{name: "Assumptions beyond 16 bits", input: append([]byte("GSYM"), 1, 1, 3, 1, 'x', 0x81, 0x80, 0x04, 0)},
+	}
+	for ix, test := range binaryTests {
+		t.Run(fmt.Sprint("binary ", ix+1), func(t *testing.T) {
+			_, err := UnmarshalBinary(test.input)
+			var notationErr *InvalidNotationError
+			if !errors.As(err, &notationErr) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: InvalidNotationError\nGot: %v", test.name, test.input, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package gosymbol
 
 import (
+	"math/big"
 	"reflect"
 )
 
@@ -19,6 +20,14 @@ func negOrZeroConstant(expr Expr) bool {
 	return ok && exprTyped.approx() <= Int(0).approx()
 }
 
+// Constants are kept apart from their powers, so that radicals in
+// normal form like 2*2^(1/2) and -I = -1*(-1)^(1/2) are not merged
+// back into 2^(3/2) and (-1)^(3/2).
+func notConstant(expr Expr) bool {
+	_, ok := expr.(rational)
+	return !ok
+}
+
 var sumSimplificationRules []transformationRule = []transformationRule{
 	{ // Addition with only one operand simplify to the operand
 		pattern: Add(patternVar("x")),
@@ -32,6 +41,33 @@ var sumSimplificationRules []transformationRule = []transformationRule{
 			return (Int(0))
 		},
 	},
+	{ // 0 + x_1 + ... + x_n = x_1 + ... + x_n
+		patternFunction: func(expr Expr) bool {
+			exprTyped, ok := expr.(add)
+			if !ok || NumberOfOperands(expr) < 2 {
+				return false
+			}
+
+			// Note: we cannot use RecContains as it would return true
+			// on any 0, e.g, even on x^0.
+			for _, elem := range exprTyped.Operands {
+				if Equal(elem, Int(0)) {
+					return true
+				}
+			}
+			return false
+		},
+		transform: func(expr Expr) Expr {
+			var newTerms []Expr
+			for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+				op := Operand(expr, ix)
+				if !Equal(op, Int(0)) {
+					newTerms = append(newTerms, op)
+				}
+			}
+			return Add(newTerms...)
+		},
+	},
 	{ // x - x = 0. Due to the ordering the negative term will always be first.
 		// Note that this will not work for constants since (-c) is a float
 		// while -x = -1*x.
@@ -49,13 +85,18 @@ var sumSimplificationRules []transformationRule = []transformationRule{
 	{ // x + yx = (1+y)x.
 		pattern: Add(patternVar("x"), Mul(patternVar("y"), patternVar("x"))),
 		transform: func(expr Expr) Expr {
-			return Mul(Int(2), Operand(expr, 1))
+			x := Operand(expr, 1)
+			y := Operand(Operand(expr, 2), 1)
+			return Mul(Add(Int(1), y), x)
 		},
 	},
-	{ // yx + zx = 2x.
+	{ // zx + yx = (z+y)x.
 		pattern: Add(Mul(patternVar("z"), patternVar("x")), Mul(patternVar("y"), patternVar("x"))),
 		transform: func(expr Expr) Expr {
-			return Mul(Int(2), Operand(expr, 1))
+			z := Operand(Operand(expr, 1), 1)
+			y := Operand(Operand(expr, 2), 1)
+			x := Operand(Operand(expr, 1), 2)
+			return Mul(Add(z, y), x)
 		},
 	},
 	{ // Sum of constants is replaced with the constant that the sum evaluates to.
@@ -176,6 +217,16 @@ var productSimplificationRules []transformationRule = []transformationRule{
 			return Mul(newFactors...)
 		},
 	},
+	{ // Numeric radicals with the same base or exponent are merged, e.g. 2^(1/2)*3^(1/2) = 6^(1/2)
+		patternFunction: func(expr Expr) bool {
+			_, ok := mergeRadicalFactors(expr.(mul))
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			result, _ := mergeRadicalFactors(expr.(mul))
+			return result
+		},
+	},
 	{ // x*x = x^2
 		pattern: Mul(patternVar("x"), patternVar("x")),
 		transform: func(expr Expr) Expr {
@@ -184,7 +235,7 @@ var productSimplificationRules []transformationRule = []transformationRule{
 		},
 	},
 	{ // x*x^n = x^(n+1) this applies to positive n due to the ordering of an expression
-		pattern: Mul(patternVar("x"), Pow(patternVar("x"), patternVar("y"))),
+		pattern: Mul(constraPatternVar("x", notConstant), Pow(patternVar("x"), patternVar("y"))),
 		transform: func(expr Expr) Expr {
 			newBase := Operand(expr, 1)
 			oldExponent := Operand(Operand(expr, 2), 2)
@@ -193,7 +244,7 @@ var productSimplificationRules []transformationRule = []transformationRule{
 		},
 	},
 	{ // x^n * x = x^(n+1) this applies to negative n due to the ordering of an expression
-		pattern: Mul(Pow(patternVar("x"), patternVar("y")), patternVar("x")),
+		pattern: Mul(Pow(constraPatternVar("x", notConstant), patternVar("y")), patternVar("x")),
 		transform: func(expr Expr) Expr {
 			newBase := Operand(expr, 2)
 			oldExponent := Operand(Operand(expr, 1), 2)
@@ -252,6 +303,20 @@ var productSimplificationRules []transformationRule = []transformationRule{
 			return Mul(newTerms...)
 		},
 	},
+	{ // (a + b*I)*(c + d*I) = (a*c - b*d) + (a*d + b*c)*I for rationals a, b, c, d
+		patternFunction: func(expr Expr) bool {
+			product, ok := expr.(mul)
+			if !ok {
+				return false
+			}
+			_, _, _, ok = gaussianProduct(product.Operands)
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			re, im, others, _ := gaussianProduct(expr.(mul).Operands)
+			return Mul(append([]Expr{Complex(re, im)}, others...)...)
+		},
+	},
 }
 
 var powerSimplificationRules []transformationRule = []transformationRule{
@@ -285,27 +350,35 @@ var powerSimplificationRules []transformationRule = []transformationRule{
 			return Int(1)
 		},
 	},
-	{ // (v_1 * ... * v_m)^n = v_1^n * ... * v_m^n
+	{ // (v_1 * ... * v_m)^n = v_1^n * ... * v_m^n when n is an integer, otherwise only for v_k >= 0
 		patternFunction: func(expr Expr) bool {
-			if _, ok := expr.(pow); ok {
-				base := Operand(expr, 1)
-				_, ok := base.(mul)
-				return ok
-			}
-			return false
+			_, ok := distributePower(expr)
+			return ok
 		},
 		transform: func(expr Expr) Expr {
-			base := Operand(expr, 1)
-			exponent := Operand(expr, 2)
-			for ix := 1; ix <= NumberOfOperands(base); ix++ {
-				factor := Operand(base, ix)
-				base = replaceOperand(base, ix, Pow(factor, exponent))
-			}
-			return base
+			result, _ := distributePower(expr)
+			return result
 		},
 	},
-	{ // (x^y)^z = x^(y*z)
-		pattern: Pow(Pow(patternVar("x"), patternVar("y")), patternVar("z")),
+	{ // (x^y)^z = x^(y*z) when z is an integer, x >= 0 and y is real, or -1 < y <= 1
+		patternFunction: func(expr Expr) bool {
+			power, ok := expr.(pow)
+			if !ok {
+				return false
+			}
+			inner, ok := power.Base.(pow)
+			if !ok {
+				return false
+			}
+			if Ask(power.Exponent, IsInteger) {
+				return true
+			}
+			if Ask(inner.Base, IsNonnegative) && Ask(inner.Exponent, IsReal) {
+				return true
+			}
+			y, ok := inner.Exponent.(rational)
+			return ok && y.approx() > -1 && y.approx() <= 1
+		},
 		transform: func(expr Expr) Expr {
 			x := Operand(Operand(expr, 1), 1)
 			y := Operand(Operand(expr, 1), 2)
@@ -313,14 +386,112 @@ var powerSimplificationRules []transformationRule = []transformationRule{
 			return Pow(x, Mul(y, z))
 		},
 	},
+	{ // Numeric radicals are written as r*b^(s/q), e.g. 8^(1/2) = 2*2^(1/2)
+		patternFunction: func(expr Expr) bool {
+			_, ok := numericRadicalNormalForm(expr)
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			result, _ := numericRadicalNormalForm(expr)
+			return result
+		},
+	},
+	{ // sqrt(a + b*sqrt(c)) = sqrt((a + d)/2) ± sqrt((a - d)/2) with d = sqrt(a^2 - b^2*c) rational
+		patternFunction: func(expr Expr) bool {
+			if !Equal(Operand(expr, 2), Div(Int(1), Int(2))) {
+				return false
+			}
+			_, ok := denestSquareRoot(Operand(expr, 1))
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			result, _ := denestSquareRoot(Operand(expr, 1))
+			return result
+		},
+	},
+	{ // 1/(a + b*sqrt(c)) = (a - b*sqrt(c))/(a^2 - b^2*c)
+		patternFunction: func(expr Expr) bool {
+			if !Equal(Operand(expr, 2), Int(-1)) {
+				return false
+			}
+			_, ok := rationalizeSurd(Operand(expr, 1))
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			result, _ := rationalizeSurd(Operand(expr, 1))
+			return result
+		},
+	},
+	{ // (-c)^(p/2) = c^(p/2) * I^p for rationals c > 0, with I^p = ±I
+		patternFunction: func(expr Expr) bool {
+			power, ok := expr.(pow)
+			if !ok {
+				return false
+			}
+			base, ok1 := power.Base.(rational)
+			exponent, ok2 := power.Exponent.(fraction)
+			return ok1 && ok2 && base.approx() < 0 && exponent.denominator().value == 2 &&
+				!(Equal(base, Int(-1)) && Equal(exponent, Div(Int(1), Int(2))))
+		},
+		transform: func(expr Expr) Expr {
+			power := expr.(pow)
+			magnitude := Pow(ratMinus(power.Base.(rational)), power.Exponent)
+			if p := power.Exponent.(fraction).numerator().value; (p%4+4)%4 == 1 {
+				return TopOperandSort(Mul(magnitude, I))
+			}
+			return TopOperandSort(Mul(Int(-1), magnitude, I))
+		},
+	},
+	{ // RootOf(q, k)^n is reduced by q = 0 when n is at least the degree of q
+		patternFunction: func(expr Expr) bool {
+			power, ok := expr.(pow)
+			if !ok {
+				return false
+			}
+			n, ok := power.Exponent.(integer)
+			if !ok {
+				return false
+			}
+			_, ok = reduceRootOfPower(power.Base, n)
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			power := expr.(pow)
+			result, _ := reduceRootOfPower(power.Base, power.Exponent.(integer))
+			return result
+		},
+	},
+	{ // (a + b*I)^n = c + d*I for rationals a, b and integers n
+		patternFunction: func(expr Expr) bool {
+			power, ok := expr.(pow)
+			if !ok {
+				return false
+			}
+			_, isSum := power.Base.(add)
+			a, b, ok1 := gaussianParts(power.Base)
+			n, ok2 := power.Exponent.(integer)
+			if !isSum || !ok1 || !ok2 || n.value == 0 || Equal(b, Int(0)) {
+				return false
+			}
+			_, _, ok = gaussianPow(a, b, n)
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			power := expr.(pow)
+			a, b, _ := gaussianParts(power.Base)
+			re, im, _ := gaussianPow(a, b, power.Exponent.(integer))
+			return Complex(re, im)
+		},
+	},
 	{ // Prod of constants is replaced with the constant that the product evaluates to.
 		// Note that product of some constants will replace the constants with their product.
+		// Powers that do not fit in an int64 are left as they are.
 		patternFunction: func(expr Expr) bool {
 			power, ok := expr.(pow)
 			if ok {
-				_, ok1 := power.Base.(rational)
-				_, ok2 := power.Exponent.(integer)
-				return ok1 && ok2
+				base, ok1 := power.Base.(rational)
+				exponent, ok2 := power.Exponent.(integer)
+				return ok1 && ok2 && !ratPowOverflows(base, exponent)
 			}
 			return false
 		},
@@ -336,6 +507,81 @@ var expSimplificationRules []transformationRule = []transformationRule{
 		pattern:   Exp(Int(0)),
 		transform: func(expr Expr) Expr { return Int(1) },
 	},
+	{ // e^log(x) = x
+		pattern:   Exp(Log(patternVar("x"))),
+		transform: func(expr Expr) Expr { return Operand(Operand(expr, 1), 1) },
+	},
+	{ // e^(I*n*π/2) = I^n for integers n
+		patternFunction: func(expr Expr) bool {
+			_, ok := imaginaryHalfIntegerMultipleOfPi(Operand(expr, 1))
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			n, _ := imaginaryHalfIntegerMultipleOfPi(Operand(expr, 1))
+			return Pow(I, Int(n))
+		},
+	},
+}
+
+/*
+Distributes the power expr = (v_1 * ... * v_m)^n over the factors. For a
+non-integer n this only holds for the factors v_k >= 0, so the other
+factors are kept in a power, e.g. (-4*x)^(1/2) = 4^(1/2)*(-x)^(1/2),
+where a negative rational factor c leaves -1 behind and -c is pulled out.
+*/
+func distributePower(expr Expr) (Expr, bool) {
+	power, ok := expr.(pow)
+	if !ok {
+		return nil, false
+	}
+	base, ok := power.Base.(mul)
+	if !ok {
+		return nil, false
+	}
+
+	integerExponent := Ask(power.Exponent, IsInteger)
+	var outside, inside []Expr
+	for _, factor := range base.Operands {
+		c, isRational := factor.(rational)
+		switch {
+		case integerExponent || Ask(factor, IsNonnegative):
+			outside = append(outside, Pow(factor, power.Exponent))
+		case isRational && c.approx() < 0 && !Equal(c, Int(-1)):
+			outside = append(outside, Pow(ratMinus(c), power.Exponent))
+			inside = append(inside, Int(-1))
+		default:
+			inside = append(inside, factor)
+		}
+	}
+	switch {
+	case len(outside) == 0:
+		return nil, false
+	case len(inside) == 1:
+		outside = append(outside, Pow(inside[0], power.Exponent))
+	case len(inside) > 1:
+		outside = append(outside, Pow(Mul(inside...), power.Exponent))
+	}
+	return Mul(outside...), true
+}
+
+// Returns n if expr is I*n*π/2 for some integer n.
+func imaginaryHalfIntegerMultipleOfPi(expr Expr) (int64, bool) {
+	product, ok := expr.(mul)
+	if !ok {
+		return 0, false
+	}
+	rest := []Expr{}
+	for _, factor := range product.Operands {
+		if !Equal(factor, I) {
+			rest = append(rest, factor)
+		}
+	}
+	if len(rest) != len(product.Operands)-1 {
+		return 0, false
+	} else if len(rest) == 1 {
+		return halfIntegerMultipleOfPi(rest[0])
+	}
+	return halfIntegerMultipleOfPi(mul{Operands: rest})
 }
 
 var logSimplificationRules []transformationRule = []transformationRule{
@@ -343,4 +589,192 @@ var logSimplificationRules []transformationRule = []transformationRule{
 		pattern:   Log(Int(1)),
 		transform: func(expr Expr) Expr { return Int(0) },
 	},
+	{ // log(-c) = log(c) + I*π for rationals c > 0
+		patternFunction: func(expr Expr) bool {
+			arg, ok := Operand(expr, 1).(rational)
+			return ok && arg.approx() < 0
+		},
+		transform: func(expr Expr) Expr {
+			return Add(Log(ratMinus(Operand(expr, 1).(rational))), Mul(I, PI))
+		},
+	},
+	{ // log(I) = I*π/2
+		patternFunction: func(expr Expr) bool {
+			return Equal(Operand(expr, 1), I)
+		},
+		transform: func(expr Expr) Expr { return Mul(Div(Int(1), Int(2)), I, PI) },
+	},
+	{ // log(e^x) = x for real x
+		patternFunction: func(expr Expr) bool {
+			arg, ok := Operand(expr, 1).(exp)
+			return ok && Ask(arg.Arg, IsReal)
+		},
+		transform: func(expr Expr) Expr { return Operand(Operand(expr, 1), 1) },
+	},
+}
+
+var sqrtSimplificationRules []transformationRule = []transformationRule{
+	{ // sqrt(x) = x^(1/2), so that roots are handled by the power rules
+		pattern:   Sqrt(patternVar("x")),
+		transform: func(expr Expr) Expr { return Pow(Operand(expr, 1), Div(Int(1), Int(2))) },
+	},
+}
+
+var sinSimplificationRules []transformationRule = []transformationRule{
+	{ // sin(0) = 0
+		pattern:   Sin(Int(0)),
+		transform: func(expr Expr) Expr { return Int(0) },
+	},
+	{ // sin(n*π) = 0 and sin((n + 1/2)*π) = (-1)^n for integers n
+		patternFunction: func(expr Expr) bool {
+			_, ok := halfIntegerMultipleOfPi(Operand(expr, 1))
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			n, _ := halfIntegerMultipleOfPi(Operand(expr, 1))
+			if n%2 == 0 {
+				return Int(0)
+			}
+			return Pow(Int(-1), Int((n-1)/2))
+		},
+	},
+}
+
+var cosSimplificationRules []transformationRule = []transformationRule{
+	{ // cos(0) = 1
+		pattern:   Cos(Int(0)),
+		transform: func(expr Expr) Expr { return Int(1) },
+	},
+	{ // cos(n*π) = (-1)^n and cos((n + 1/2)*π) = 0 for integers n
+		patternFunction: func(expr Expr) bool {
+			_, ok := halfIntegerMultipleOfPi(Operand(expr, 1))
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			n, _ := halfIntegerMultipleOfPi(Operand(expr, 1))
+			if n%2 != 0 {
+				return Int(0)
+			}
+			return Pow(Int(-1), Int(n/2))
+		},
+	},
+}
+
+// Returns the integer n if expr is π or c*π with c = n/2.
+func halfIntegerMultipleOfPi(expr Expr) (int64, bool) {
+	if Equal(expr, PI) {
+		return 2, true
+	}
+	product, ok := expr.(mul)
+	if !ok || len(product.Operands) != 2 || !Equal(product.Operands[1], PI) {
+		return 0, false
+	}
+	switch c := product.Operands[0].(type) {
+	case integer:
+		return 2 * c.value, true
+	case fraction:
+		if c.denominator().value == 2 {
+			return c.numerator().value, true
+		}
+	}
+	return 0, false
+}
+
+var derivativeSimplificationRules []transformationRule = []transformationRule{
+	{ // D(u, x) is evaluated unless u contains an undefined function
+		patternFunction: func(expr Expr) bool {
+			return !containsFunctionApplication(Operand(expr, 1))
+		},
+		transform: func(expr Expr) Expr {
+			d := expr.(derivative)
+			return differentiate(d.Arg, d.Var)
+		},
+	},
+}
+
+var functionSimplificationRules []transformationRule = []transformationRule{
+	{ // n!, Binomial(n, k) and Gamma(n) are evaluated for integers
+		patternFunction: func(expr Expr) bool {
+			_, ok := integerSpecialFunction(expr.(FunctionApplication))
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			value, _ := integerSpecialFunction(expr.(FunctionApplication))
+			return value
+		},
+	},
+	{ // NextPrime, EulerPhi, MoebiusMu, LCM, JacobiSymbol and ModularInverse are evaluated for integers
+		patternFunction: func(expr Expr) bool {
+			_, ok := numberTheoreticFunction(expr.(FunctionApplication))
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			value, _ := numberTheoreticFunction(expr.(FunctionApplication))
+			return value
+		},
+	},
+	{ // Binomial(n, 0) = 1 and Binomial(n, 1) = n
+		patternFunction: func(expr Expr) bool {
+			f := expr.(FunctionApplication)
+			return f.Name == binomialName && len(f.Args) == 2 &&
+				(Equal(f.Args[1], Int(0)) || Equal(f.Args[1], Int(1)))
+		},
+		transform: func(expr Expr) Expr {
+			f := expr.(FunctionApplication)
+			if Equal(f.Args[1], Int(0)) {
+				return Int(1)
+			}
+			return f.Args[0]
+		},
+	},
+	{ // Re, Im, Conjugate, Abs and Arg of numbers with known real and imaginary parts
+		patternFunction: func(expr Expr) bool {
+			_, ok := complexPartFunction(expr.(FunctionApplication))
+			return ok
+		},
+		transform: func(expr Expr) Expr {
+			value, _ := complexPartFunction(expr.(FunctionApplication))
+			return value
+		},
+	},
+	{ // Gamma(1/2) = π^(1/2)
+		patternFunction: func(expr Expr) bool {
+			f := expr.(FunctionApplication)
+			return f.Name == gammaName && len(f.Args) == 1 && Equal(f.Args[0], Div(Int(1), Int(2)).Simplify())
+		},
+		transform: func(expr Expr) Expr {
+			return Pow(PI, Div(Int(1), Int(2)))
+		},
+	},
+}
+
+// Returns the value of Factorial, Binomial or Gamma at integer arguments
+// where it is defined, as long as the value fits in an int64.
+func integerSpecialFunction(f FunctionApplication) (Expr, bool) {
+	args := make([]int64, len(f.Args))
+	for ix, arg := range f.Args {
+		n, ok := arg.(integer)
+		if !ok {
+			return nil, false
+		}
+		args[ix] = n.value
+	}
+
+	value := new(big.Int)
+	switch {
+	case f.Name == factorialName && len(args) == 1 && args[0] >= 0:
+		value.MulRange(1, args[0])
+	case f.Name == gammaName && len(args) == 1 && args[0] >= 1:
+		value.MulRange(1, args[0]-1)
+	case f.Name == binomialName && len(args) == 2 && args[0] >= 0:
+		if args[1] >= 0 && args[1] <= args[0] {
+			value.Binomial(args[0], args[1])
+		}
+	default:
+		return nil, false
+	}
+	if !value.IsInt64() {
+		return nil, false
+	}
+	return Int(value.Int64()), true
 }
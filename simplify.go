@@ -44,6 +44,22 @@ func (expr sqrt) Simplify() Expr {
 	return simplify(expr)
 }
 
+func (expr sin) Simplify() Expr {
+	return simplify(expr)
+}
+
+func (expr cos) Simplify() Expr {
+	return simplify(expr)
+}
+
+func (expr FunctionApplication) Simplify() Expr {
+	return simplify(expr)
+}
+
+func (expr derivative) Simplify() Expr {
+	return simplify(expr)
+}
+
 func simplify(expr Expr) Expr {
 	// Having this here makes it possible
 	// to remove all rules in simplification_rules.go
@@ -71,6 +87,24 @@ func simplify(expr Expr) Expr {
 		expr = replaceOperand(expr, ix, op.Simplify())
 	}
 
+	// Simplifying the operands can give nested sums or products,
+	// e.g. after substituting a sum for a variable, which are
+	// flattened and sorted again. It can also change the order of
+	// the operands, e.g. (2+3)*x^(1/2) becomes 5*x^(1/2), so they
+	// are sorted again to keep the result canonical.
+	switch e := expr.(type) {
+	case add:
+		if flat := Add(e.Operands...); len(flat.Operands) != len(e.Operands) {
+			return simplify(flat)
+		}
+		expr = TopOperandSort(e)
+	case mul:
+		if flat := Mul(e.Operands...); len(flat.Operands) != len(e.Operands) {
+			return simplify(flat)
+		}
+		expr = TopOperandSort(e)
+	}
+
 	// Applies simplification rules depending on the operator type
 	// This will extend as more rules gets added! The base cases
 	// are fully simplified so we just return them.
@@ -92,6 +126,16 @@ func simplify(expr Expr) Expr {
 		expr, appliedRuleIdx = rulesApplicator(expr, expSimplificationRules)
 	case log:
 		expr, appliedRuleIdx = rulesApplicator(expr, logSimplificationRules)
+	case sqrt:
+		expr, appliedRuleIdx = rulesApplicator(expr, sqrtSimplificationRules)
+	case sin:
+		expr, appliedRuleIdx = rulesApplicator(expr, sinSimplificationRules)
+	case cos:
+		expr, appliedRuleIdx = rulesApplicator(expr, cosSimplificationRules)
+	case FunctionApplication:
+		expr, appliedRuleIdx = rulesApplicator(expr, functionSimplificationRules)
+	case derivative:
+		expr, appliedRuleIdx = rulesApplicator(expr, derivativeSimplificationRules)
 	}
 
 	// If the expression has been altered it might be possible to apply some other rule
@@ -125,8 +169,3 @@ func rulesApplicator(expr Expr, ruleSlice []transformationRule) (Expr, int) {
 	// If function did not return above no rule was applied
 	return expr, -1
 }
-
-// TODO: figure this out
-func Expand(expr Expr) Expr {
-	panic("Not implemented yet")
-}
@@ -27,6 +27,26 @@ func TestSimplify(t *testing.T) {
 			input:          Add(Int(2), Real("e"), Int(3)),
 			expectedOutput: Add(Int(5), Real("e")),
 		},
+		{
+			name:           "0 + x + 0 = x",
+			input:          Add(Int(0), Var("x"), Int(0)),
+			expectedOutput: Var("x"),
+		},
+		{
+			name:           "x + 3x = 4x",
+			input:          Add(Var("x"), Mul(Int(3), Var("x"))),
+			expectedOutput: Mul(Int(4), Var("x")),
+		},
+		{
+			name:           "2^(1/2) + 3*2^(1/2) = 4*2^(1/2)",
+			input:          Add(Pow(Int(2), Div(Int(1), Int(2))), Mul(Int(3), Pow(Int(2), Div(Int(1), Int(2))))),
+			expectedOutput: Mul(Int(4), Pow(Int(2), Div(Int(1), Int(2)))),
+		},
+		{
+			name:           "2x^2 + 3x^2 = 5x^2",
+			input:          Add(Mul(Int(2), Pow(Var("x"), Int(2))), Mul(Int(3), Pow(Var("x"), Int(2)))),
+			expectedOutput: Mul(Int(5), Pow(Var("x"), Int(2))),
+		},
 		{
 			name:           "undefined^y = undefined",
 			input:          Pow(Undefined(), Var("y")),
@@ -59,13 +79,33 @@ func TestSimplify(t *testing.T) {
 		},
 		{
 			name:           "(v_1 * ... * v_n)^m = v_1^m * .. * v_n^m (note that the result is also sorted)",
+			input:          Pow(Mul(Var("x"), Int(3), Var("y")), Var("elle", Integer)),
+			expectedOutput: Mul(Pow(Int(3), Var("elle", Integer)), Pow(Var("x"), Var("elle", Integer)), Pow(Var("y"), Var("elle", Integer))),
+		},
+		{
+			name:           "(v_1 * ... * v_n)^m = v_1^m * (v_2 * .. * v_n)^m for v_1 >= 0",
 			input:          Pow(Mul(Var("x"), Int(3), Var("y")), Var("elle")),
-			expectedOutput: Mul(Pow(Int(3), Var("elle")), Pow(Var("x"), Var("elle")), Pow(Var("y"), Var("elle"))),
+			expectedOutput: Mul(Pow(Int(3), Var("elle")), Pow(Mul(Var("x"), Var("y")), Var("elle"))),
+		},
+		{
+			name:           "(-c*x)^m = c^m * (-x)^m for a rational c > 0",
+			input:          Sqrt(Mul(Int(-2), Var("x"))),
+			expectedOutput: Mul(Pow(Int(2), Div(Int(1), Int(2))), Pow(Mul(Int(-1), Var("x")), Div(Int(1), Int(2)))),
+		},
+		{
+			name:           "(i^j)^k = i^(j*k) for i >= 0 and real j",
+			input:          Pow(Pow(Var("i", Positive), Real("j")), Exp(Mul(Int(1), Var("k")))),
+			expectedOutput: Pow(Var("i", Positive), Mul(Real("j"), Exp(Var("k")))),
 		},
 		{
-			name:           "(i^j)^k = i^(j*k)",
-			input:          Pow(Pow(Var("i"), Var("j")), Exp(Mul(Int(1), Var("k")))),
-			expectedOutput: Pow(Var("i"), Mul(Var("j"), Exp(Var("k")))),
+			name:           "(x^y)^3 = x^(3*y)",
+			input:          Pow(Pow(Var("x"), Var("y")), Int(3)),
+			expectedOutput: Pow(Var("x"), Mul(Int(3), Var("y"))),
+		},
+		{
+			name:           "(x^2)^(1/2) is not simplified without assumptions on x",
+			input:          Pow(Pow(Var("x"), Int(2)), Div(Int(1), Int(2))),
+			expectedOutput: Pow(Pow(Var("x"), Int(2)), Div(Int(1), Int(2))),
 		},
 		{
 			name:           "undefined * ... = undefined",
@@ -161,6 +201,33 @@ func TestSimplify(t *testing.T) {
 	}
 }
 
+func TestSimplifyIsIdempotent(t *testing.T) {
+	x, y := Var("x"), Var("y")
+
+	tests := []Expr{
+		Add(Sqrt(Int(12)), Sqrt(Int(27))),
+		Add(Mul(Int(2), Pow(x, Div(Int(1), Int(2)))), Mul(Int(3), Pow(x, Div(Int(1), Int(2))))),
+		Add(Mul(y, x), x),
+		Add(Mul(Int(2), y, x), Mul(Int(3), y, x)),
+		Mul(Add(x, x), Sqrt(Int(8))),
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			once := test.Simplify()
+			if twice := once.Simplify(); !Equal(once, twice) {
+				t.Errorf("Following test failed: simplifying twice\nInput: %v\nExpected: %v\nGot: %v", test, once, twice)
+			}
+		})
+	}
+
+	// The coefficient of the combined like terms is sorted before the radical
+	sum := Add(Sqrt(Int(12)), Sqrt(Int(27))).Simplify()
+	if expected := Mul(Int(5), Pow(Int(3), Div(Int(1), Int(2)))).Simplify(); !Equal(sum, expected) {
+		t.Errorf("Following test failed: canonical order of combined like terms\nInput: %v\nExpected: %v\nGot: %v", Add(Sqrt(Int(12)), Sqrt(Int(27))), expected, sum)
+	}
+}
+
 func TestSimplificationRulesForPatternVariables(t *testing.T) {
 	executeTests := func(t *testing.T, ruleSlice []transformationRule, testName string) {
 		for ix, rule := range ruleSlice {
@@ -0,0 +1,226 @@
+package gosymbol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A format of the String() method of expressions.
+type StringFormat int
+
+const (
+	// Parentheses only where the precedence of the operators
+	// requires them, e.g. x - 3/2*y^2/z.
+	CompactFormat StringFormat = iota
+	// Every operation in parentheses, showing the structure of the
+	// expression, e.g. ( x + ( -1 * 3/2 * ( y^2 ) * ( z^-1 ) ) ).
+	DebugFormat
+)
+
+func (f StringFormat) String() string {
+	switch f {
+	case CompactFormat:
+		return "compact"
+	case DebugFormat:
+		return "debug"
+	default:
+		return fmt.Sprintf("StringFormat(%d)", int(f))
+	}
+}
+
+/*
+Returns expr as a string in the given format. String(), and thereby
+the fmt package, always uses the compact format.
+
+Both formats can be read back by Parse. The debug format gives back an
+equal expression, while the compact one gives back an expression which
+simplifies to the same as expr, since e.g. x - 2*y is parsed as
+x + -1*(2*y).
+*/
+func Format(expr Expr, format StringFormat) string {
+	if format == DebugFormat {
+		return debugString(expr)
+	}
+	str, _ := compactPrinter{}.print(expr)
+	return str
+}
+
+func debugString(expr Expr) string {
+	switch e := expr.(type) {
+	case add:
+		return "( " + joinDebugStrings(e.Operands, " + ") + " )"
+	case mul:
+		return "( " + joinDebugStrings(e.Operands, " * ") + " )"
+	case pow:
+		// A negative base is bracketed so that (-1)^1/2 is not read as -(1^1/2)
+		if r, ok := e.Base.(rational); ok && r.approx() < 0 {
+			return fmt.Sprintf("( (%s)^%s )", debugString(e.Base), debugString(e.Exponent))
+		}
+		return fmt.Sprintf("( %s^%s )", debugString(e.Base), debugString(e.Exponent))
+	case exp:
+		return fmt.Sprintf("exp( %s )", debugString(e.Arg))
+	case log:
+		return fmt.Sprintf("log( %s )", debugString(e.Arg))
+	case sqrt:
+		return fmt.Sprintf("sqrt( %s )", debugString(e.Arg))
+	case sin:
+		return fmt.Sprintf("sin( %s )", debugString(e.Arg))
+	case cos:
+		return fmt.Sprintf("cos( %s )", debugString(e.Arg))
+	case FunctionApplication:
+		if len(e.Args) == 0 {
+			return fmt.Sprintf("%v( )", e.Name)
+		}
+		return fmt.Sprintf("%v( %s )", e.Name, joinDebugStrings(e.Args, ", "))
+	case derivative:
+		return fmt.Sprintf("D( %s, %s )", debugString(e.Arg), debugString(e.Var))
+	}
+	return expr.String()
+}
+
+func joinDebugStrings(exprs []Expr, sep string) string {
+	strs := make([]string, len(exprs))
+	for ix, expr := range exprs {
+		strs[ix] = debugString(expr)
+	}
+	return strings.Join(strs, sep)
+}
+
+// The precedence of the compactly printed expressions, from the weakest binding.
+const (
+	compactSum = iota + 1
+	compactProduct
+	compactUnary
+	compactPower
+	compactAtom
+)
+
+type compactPrinter struct{}
+
+// Returns expr in the compact format together with its precedence.
+func (p compactPrinter) print(expr Expr) (string, int) {
+	switch e := expr.(type) {
+	case integer:
+		if e.value < 0 {
+			return e.String(), compactUnary
+		}
+		return e.String(), compactAtom
+	case fraction:
+		if e.approx() < 0 {
+			return e.String(), compactUnary
+		}
+		return e.String(), compactProduct
+	case add:
+		str, _ := p.print(e.Operands[0])
+		for _, term := range e.Operands[1:] {
+			if negated, ok := negatedTerm(term); ok {
+				str += " - " + p.wrap(negated, compactProduct)
+			} else {
+				str += " + " + p.wrap(term, compactSum)
+			}
+		}
+		return str, compactSum
+	case mul:
+		return p.product(e.Operands)
+	case pow:
+		if Equal(e, I) {
+			return "I", compactAtom
+		}
+		if r, ok := e.Exponent.(rational); ok && r.approx() < 0 {
+			return p.product([]Expr{e})
+		}
+		return p.wrap(e.Base, compactAtom) + "^" + p.wrap(e.Exponent, compactPower), compactPower
+	case exp:
+		if Equal(e.Arg, Int(1)) {
+			return "e", compactAtom
+		}
+		return p.call("exp", e.Arg), compactAtom
+	case log:
+		return p.call("log", e.Arg), compactAtom
+	case sqrt:
+		return p.call("sqrt", e.Arg), compactAtom
+	case sin:
+		return p.call("sin", e.Arg), compactAtom
+	case cos:
+		return p.call("cos", e.Arg), compactAtom
+	case FunctionApplication:
+		if e.Name == factorialName && len(e.Args) == 1 {
+			return p.wrap(e.Args[0], compactAtom) + "!", compactAtom
+		}
+		return p.call(string(e.Name), e.Args...), compactAtom
+	case derivative:
+		return p.call("D", e.Arg, e.Var), compactAtom
+	}
+	return expr.String(), compactAtom
+}
+
+// Prints expr and parenthesizes it if it binds weaker than precedence.
+func (p compactPrinter) wrap(expr Expr, precedence int) string {
+	str, prec := p.print(expr)
+	if prec < precedence {
+		return "(" + str + ")"
+	}
+	return str
+}
+
+func (p compactPrinter) call(name string, args ...Expr) string {
+	strs := make([]string, len(args))
+	for ix, arg := range args {
+		strs[ix], _ = p.print(arg)
+	}
+	return name + "(" + strings.Join(strs, ", ") + ")"
+}
+
+/*
+Prints a product as a quotient of the factors with positive and
+negative exponents, e.g. -3/2*x/y^2. Factors -1 are written as a
+minus sign, where an even number of them cancel.
+*/
+func (p compactPrinter) product(factors []Expr) (string, int) {
+	numerator, denominator := []string{}, []string{}
+	factors, negative := foldNegations(factors)
+	if r, ok := factors[0].(rational); ok && !Equal(r, Undefined()) {
+		if negative && (len(factors) == 1 || r.approx() < 0) {
+			r, negative = ratMinus(r), false
+		}
+		if !Equal(r, Int(1)) || len(factors) == 1 {
+			numerator = append(numerator, r.String())
+		}
+		factors = factors[1:]
+	}
+	for _, factor := range factors {
+		if e, ok := factor.(pow); ok && !Equal(e, I) {
+			if r, ok := e.Exponent.(rational); ok && r.approx() < 0 {
+				if Equal(r, Int(-1)) {
+					denominator = append(denominator, p.wrap(e.Base, compactPower))
+				} else {
+					denominator = append(denominator, p.wrap(pow{Base: e.Base, Exponent: ratMinus(r)}, compactPower))
+				}
+				continue
+			}
+		}
+		numerator = append(numerator, p.wrap(factor, compactPower))
+	}
+
+	str := strings.Join(numerator, "*")
+	if len(numerator) == 0 {
+		str = "1"
+	}
+	prec := compactProduct
+	if len(numerator) == 1 && len(denominator) == 0 && len(factors) == 1 && !negative {
+		// A single factor keeps its own precedence, e.g. x^2
+		str, prec = p.print(factors[0])
+	}
+	if len(denominator) > 0 {
+		den := denominator[0]
+		if len(denominator) > 1 {
+			den = "(" + strings.Join(denominator, "*") + ")"
+		}
+		str += "/" + den
+	}
+	if negative {
+		// -x*y is read as (-x)*y, which is the same
+		return "-" + str, compactUnary
+	}
+	return str, prec
+}
@@ -0,0 +1,731 @@
+package gosymbol
+
+import (
+	"math/big"
+)
+
+// The largest shift s between the terms g(k) and -g(k + s)
+// of a sum that is detected as a telescoping difference.
+const maxTelescopingShift = 8
+
+// The largest integer distance between the roots paired up by
+// Gosper's algorithm, and between the arguments of combined
+// factorials, which bounds the size of the resulting products.
+const maxIntegerShift = 100
+
+/*
+Returns sum_{k = lo}^{hi} expr in closed form. The bounds may be
+symbolic but must be free of k.
+
+Polynomials in k are summed with Faulhaber's formula, and geometric
+terms t, for which t(k + 1)/t(k) is free of k, with the formula for
+geometric series. Other hypergeometric terms, for which t(k + 1)/t(k)
+is a rational function of k, e.g. products of rational functions,
+powers c^k, factorials and binomial coefficients, are summed with
+Gosper's algorithm [1, chapter 5]. The sum is first tried as a whole,
+then term by term, where the terms that are left are paired up into
+telescoping differences g(k) - g(k + s).
+
+A NoClosedFormError is returned if some terms could not be summed.
+
+[1] PETKOVŠEK, Marko; WILF, Herbert S.; ZEILBERGER, Doron. A = B. AK Peters, 1996.
+*/
+func Sum(expr Expr, k variable, lo, hi Expr) (Expr, error) {
+	if !RecContains(expr, k) {
+		return Mul(expr, termCount(lo, hi)).Simplify(), nil
+	}
+	expr = expandExp(expr)
+	if result, ok := sumTerm(expr, k, lo, hi); ok {
+		return tidySum(result), nil
+	}
+
+	terms, ok := expandedTerms(expr)
+	if !ok {
+		return nil, &NoClosedFormError{Operation: "sum", Expr: expr}
+	}
+	result, rest := []Expr{}, []Expr{}
+	for _, term := range terms {
+		if !RecContains(term, k) {
+			result = append(result, Mul(term, termCount(lo, hi)))
+		} else if s, ok := sumTerm(term, k, lo, hi); ok {
+			result = append(result, s)
+		} else {
+			rest = append(rest, term)
+		}
+	}
+	telescoped, rest := telescope(rest, k, lo, hi)
+	result = append(result, telescoped...)
+
+	// The remaining terms might still sum to a hypergeometric
+	// term, e.g. 1/k - 2/(k + 1) + 1/(k + 2)
+	if len(rest) > 0 {
+		s, ok := sumTerm(Cancel(Add(rest...)), k, lo, hi)
+		if !ok {
+			return nil, &NoClosedFormError{Operation: "sum", Expr: Add(rest...).Simplify()}
+		}
+		result = append(result, s)
+	}
+	return tidySum(Add(result...)), nil
+}
+
+// Combines the factorials of a sum, which is cancelled
+// if it is a rational function of its variables.
+func tidySum(expr Expr) Expr {
+	expr = combineFactorials(expr)
+	kt := &kernelTable{}
+	if _, ok := toRatFunc(expr, kt); !ok {
+		return expr
+	}
+	for _, kernel := range kt.kernels {
+		if _, ok := kernel.(variable); !ok {
+			return expr
+		}
+	}
+	return Cancel(expr)
+}
+
+// Returns the number of terms hi - lo + 1 of a sum or product.
+func termCount(lo, hi Expr) Expr {
+	return Add(hi, Neg(lo), Int(1))
+}
+
+// Returns expr with v replaced by v + s.
+func shiftVariable(expr Expr, v variable, s Expr) Expr {
+	// Substitutes through a fresh variable since v + s contains v
+	shifted := freshVariable("σ", expr)
+	return Substitute(Substitute(expr, v, shifted), shifted, Add(v, s))
+}
+
+// Sums the polynomial, geometric or hypergeometric term t.
+func sumTerm(t Expr, k variable, lo, hi Expr) (Expr, bool) {
+	if coeffs, err := polynomialCoefficients(t, k); err == nil {
+		return faulhaber(coeffs, lo, hi), true
+	}
+
+	ratio, ok := shiftRatio(t, k)
+	if !ok {
+		return nil, false
+	}
+	next := Add(hi, Int(1))
+	if !RecContains(ratio, k) {
+		// sum_{k = lo}^{hi} t(k) = (t(hi + 1) - t(lo))/(r - 1) with r = t(k + 1)/t(k)
+		if isZero(Sub(ratio, Int(1))) {
+			return Mul(Substitute(t, k, lo), termCount(lo, hi)), true
+		}
+		return Div(Sub(Substitute(t, k, next), Substitute(t, k, lo)), Sub(ratio, Int(1))), true
+	}
+
+	z, ok := gosper(t, ratio, k)
+	if !ok {
+		return nil, false
+	}
+	result := Sub(Substitute(z, k, next), Substitute(z, k, lo)).Simplify()
+	if RecContains(result, Undefined()) {
+		return nil, false
+	}
+	return result, true
+}
+
+/*
+Returns sum_{k = lo}^{hi} c_0 + c_1*k + ... + c_n*k^n for the
+coefficients c_i, where Faulhaber's formula
+
+	sum_{k = 1}^{m} k^p = 1/(p + 1) * sum_{j = 0}^{p} binomial(p + 1, j) * B_j * m^(p + 1 - j)
+
+gives the sums of powers in terms of the Bernoulli numbers B_j, with B_1 = 1/2.
+*/
+func faulhaber(coeffs []Expr, lo, hi Expr) Expr {
+	bernoulli := bernoulliNumbers(len(coeffs))
+	powerSum := func(p int, m Expr) Expr {
+		terms := make([]Expr, p+1)
+		for j := 0; j <= p; j++ {
+			b := bernoulli[j]
+			if j == 1 {
+				b = Neg(b)
+			}
+			terms[j] = Mul(binomialCoefficient(int64(p+1), int64(j)), b, Pow(m, Int(int64(p+1-j))))
+		}
+		return Div(Add(terms...), Int(int64(p+1)))
+	}
+
+	terms := make([]Expr, len(coeffs))
+	for p, c := range coeffs {
+		terms[p] = Mul(c, Sub(powerSum(p, hi), powerSum(p, Sub(lo, Int(1)))))
+	}
+	return Expand(Add(terms...))
+}
+
+// Returns the Bernoulli numbers B_0, ..., B_(n-1), with B_1 = -1/2,
+// from the recurrence sum_{j = 0}^{m} binomial(m + 1, j) * B_j = 0.
+func bernoulliNumbers(n int) []Expr {
+	b := make([]Expr, n)
+	for m := range b {
+		terms := []Expr{Int(0)}
+		for j := 0; j < m; j++ {
+			terms = append(terms, Mul(binomialCoefficient(int64(m+1), int64(j)), b[j]))
+		}
+		if m == 0 {
+			b[m] = Int(1)
+		} else {
+			b[m] = Div(Neg(Add(terms...)), Int(int64(m+1))).Simplify()
+		}
+	}
+	return b
+}
+
+func binomialCoefficient(n, k int64) integer {
+	return Int(new(big.Int).Binomial(n, k).Int64())
+}
+
+/*
+Returns t(k + 1)/t(k) if it is a rational function of k, i.e. if t is a
+hypergeometric term. The ratio is computed factor by factor, where c^u
+and exp(u) with u = a*k + b give c^a and exp(a), and (a*k + b)! gives
+(a*k + b + 1)*...*(a*k + b + a) for integers a. Gamma functions and
+binomial coefficients are treated as factorials.
+*/
+func shiftRatio(t Expr, k variable) (Expr, bool) {
+	_, factors := splitConstantFactors(t, k)
+	ratios := []Expr{Int(1)}
+	for _, f := range factors {
+		r, ok := factorShiftRatio(f, k)
+		if !ok {
+			return nil, false
+		}
+		ratios = append(ratios, r)
+	}
+	ratio := Cancel(Mul(ratios...))
+	return ratio, isRationalIn(ratio, k)
+}
+
+func factorShiftRatio(f Expr, k variable) (Expr, bool) {
+	if !RecContains(f, k) {
+		return Int(1), true
+	}
+	switch e := f.(type) {
+	case exp:
+		if a, ok := linearCoefficient(e.Arg, k); ok {
+			return Exp(a), true
+		}
+	case pow:
+		if !RecContains(e.Base, k) {
+			if a, ok := linearCoefficient(e.Exponent, k); ok {
+				return Pow(e.Base, a), true
+			}
+		} else if !RecContains(e.Exponent, k) {
+			if r, ok := factorShiftRatio(e.Base, k); ok {
+				return Pow(r, e.Exponent), true
+			}
+		}
+	case FunctionApplication:
+		switch {
+		case (e.Name == factorialName || e.Name == gammaName) && len(e.Args) == 1:
+			a, ok := linearCoefficient(e.Args[0], k)
+			n, isInteger := a.(integer)
+			if !ok || !isInteger || n.value > maxIntegerShift || -n.value > maxIntegerShift {
+				return nil, false
+			}
+			// Gamma(u) = (u - 1)!
+			u := e.Args[0]
+			if e.Name == gammaName {
+				u = Sub(u, Int(1))
+			}
+			return factorialRatio(u, n.value), true
+		case e.Name == binomialName && len(e.Args) == 2:
+			// binomial(n, m) = n!/(m! * (n - m)!)
+			n, m := e.Args[0], e.Args[1]
+			ratios := make([]Expr, 3)
+			for ix, u := range []Expr{n, m, Sub(n, m)} {
+				r, ok := factorShiftRatio(Factorial(u), k)
+				if !ok {
+					return nil, false
+				}
+				ratios[ix] = r
+			}
+			return Div(ratios[0], Mul(ratios[1], ratios[2])), true
+		}
+		return nil, false
+	}
+	if isRationalIn(f, k) {
+		return Cancel(Div(shiftVariable(f, k, Int(1)), f)), true
+	}
+	return nil, false
+}
+
+// Returns (u + a)!/u! for the integer a as a product of factors of u.
+func factorialRatio(u Expr, a int64) Expr {
+	factors := []Expr{Int(1)}
+	for j := int64(1); j <= a; j++ {
+		factors = append(factors, Add(u, Int(j)))
+	}
+	for j := int64(0); j < -a; j++ {
+		factors = append(factors, Pow(Sub(u, Int(j)), Int(-1)))
+	}
+	return Mul(factors...)
+}
+
+/*
+Returns z with t(k) = z(k + 1) - z(k) for the hypergeometric term t with
+ratio t(k + 1)/t(k), or false if there is no hypergeometric such z.
+
+The ratio is written as a(k)/b(k) * c(k + 1)/c(k) where a(k) and b(k + h)
+have no common factor for any non-negative integer h. Then z is given by
+b(k - 1)*x(k)/c(k) * t(k) where the polynomial x solves
+
+	a(k)*x(k + 1) - b(k - 1)*x(k) = c(k).
+*/
+func gosper(t, ratio Expr, k variable) (Expr, bool) {
+	kt := &kernelTable{}
+	r, ok := toRatFunc(ratio, kt)
+	if !ok {
+		return nil, false
+	}
+	a, b, c, ok := gosperForm(r.num.toExpr(kt), r.den.toExpr(kt), k)
+	if !ok {
+		return nil, false
+	}
+	bShifted := shiftVariable(b, k, Int(-1))
+	x, ok := gosperPolynomial(a, bShifted, c, k)
+	if !ok {
+		return nil, false
+	}
+
+	// The rational factors of t are cancelled against the
+	// rational function b(k - 1)*x(k)/c(k), so that z can be
+	// evaluated where t has a zero cancelling a pole of 1/c
+	constant, factors := splitConstantFactors(t, k)
+	rational := []Expr{bShifted, x, Pow(c, Int(-1))}
+	others := []Expr{constant}
+	for _, f := range factors {
+		if isRationalIn(f, k) {
+			rational = append(rational, f)
+		} else {
+			others = append(others, f)
+		}
+	}
+	return Mul(append(others, Cancel(Mul(rational...)))...).Simplify(), true
+}
+
+/*
+Splits num/den into a(k)/b(k) * c(k + 1)/c(k) as in Gosper's algorithm.
+Whenever a root α of num and a root α + h of den differ by a non-negative
+integer h, the factor (k - α)/(k - α - h) is moved into c(k + 1)/c(k)
+with c(k) = (k - α - 1)*...*(k - α - h).
+*/
+func gosperForm(num, den Expr, k variable) (Expr, Expr, Expr, bool) {
+	numRoots, numLeading, ok := linearFactors(num, k)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	denRoots, denLeading, ok := linearFactors(den, k)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	a, c := []Expr{numLeading}, []Expr{Int(1)}
+	paired := make([]bool, len(denRoots))
+	for _, alpha := range numRoots {
+		found := false
+		for jx, beta := range denRoots {
+			h, ok := Cancel(Sub(beta, alpha)).(integer)
+			if paired[jx] || !ok || h.value < 0 || h.value > maxIntegerShift {
+				continue
+			}
+			for j := int64(1); j <= h.value; j++ {
+				c = append(c, Sub(k, Add(alpha, Int(j))))
+			}
+			paired[jx], found = true, true
+			break
+		}
+		if !found {
+			a = append(a, Sub(k, alpha))
+		}
+	}
+	b := []Expr{denLeading}
+	for jx, beta := range denRoots {
+		if !paired[jx] {
+			b = append(b, Sub(k, beta))
+		}
+	}
+	return Mul(a...), Mul(b...), Mul(c...), true
+}
+
+// Returns the roots, repeated according to their multiplicities, and the
+// leading coefficient of the polynomial p in k, or false if p does not
+// split into linear factors.
+func linearFactors(p Expr, k variable) ([]Expr, Expr, bool) {
+	coeffs, err := polynomialCoefficients(p, k)
+	if err != nil {
+		return nil, nil, false
+	}
+	roots, err := polynomialRoots(p, k, nil)
+	if err != nil {
+		return nil, nil, false
+	}
+	values := []Expr{}
+	for _, r := range roots {
+		for ix := 0; ix < r.multiplicity; ix++ {
+			values = append(values, r.value)
+		}
+	}
+	return values, coeffs[len(coeffs)-1], len(values) == len(coeffs)-1
+}
+
+/*
+Returns the polynomial solution x of a(k)*x(k + 1) - b(k)*x(k) = c(k),
+where b is the shifted b(k - 1) of Gosper's algorithm. The degree of x is
+bounded by comparing the leading terms of the left hand side: if they do
+not cancel it is deg(c) - max(deg(a), deg(b)), and otherwise it is at
+most deg(c) - deg(a) + 1, or the integer d for which the coefficients of
+k^(deg(a) + d - 1) cancel as well.
+*/
+func gosperPolynomial(a, b, c Expr, k variable) (Expr, bool) {
+	ac, err := polynomialCoefficients(a, k)
+	if err != nil {
+		return nil, false
+	}
+	bc, err := polynomialCoefficients(b, k)
+	if err != nil {
+		return nil, false
+	}
+	cc, err := polynomialCoefficients(c, k)
+	if err != nil {
+		return nil, false
+	}
+
+	m := max(len(ac), len(bc)) - 1
+	degree := len(cc) - 1 - m
+	if len(ac) == len(bc) && isZero(Sub(ac[m], bc[m])) {
+		degree++
+		if m > 0 {
+			d, ok := Cancel(Div(Sub(bc[m-1], ac[m-1]), ac[m])).(integer)
+			if ok && d.value > int64(degree) && d.value <= maxIntegerShift {
+				degree = int(d.value)
+			}
+		}
+	}
+	if degree < 0 {
+		return nil, false
+	}
+
+	// With x = sum_j u_j*k^j, each unknown u_j contributes
+	// a(k)*(k + 1)^j - b(k)*k^j to the left hand side
+	columns := make([][]Expr, degree+1)
+	rows := len(cc)
+	for j := range columns {
+		lhs := Sub(Mul(a, Pow(Add(k, Int(1)), Int(int64(j)))), Mul(b, Pow(k, Int(int64(j)))))
+		col, err := polynomialCoefficients(lhs, k)
+		if err != nil {
+			return nil, false
+		}
+		columns[j] = col
+		rows = max(rows, len(col))
+	}
+	coefficient := func(coeffs []Expr, ix int) Expr {
+		if ix < len(coeffs) {
+			return coeffs[ix]
+		}
+		return Int(0)
+	}
+	augmented := make([][]Expr, rows)
+	for ix := range augmented {
+		augmented[ix] = make([]Expr, degree+2)
+		for j, col := range columns {
+			augmented[ix][j] = coefficient(col, ix)
+		}
+		augmented[ix][degree+1] = coefficient(cc, ix)
+	}
+	u, ok := solveLinearSystem(augmented, degree+1)
+	if !ok {
+		return nil, false
+	}
+	terms := make([]Expr, len(u))
+	for j, uj := range u {
+		terms[j] = Mul(uj, Pow(k, Int(int64(j))))
+	}
+	return Add(terms...).Simplify(), true
+}
+
+/*
+Pairs up the terms g(k) and -g(k + s) with 0 < s <= maxTelescopingShift,
+whose sum telescopes into
+
+	sum_{k = lo}^{hi} g(k) - g(k + s) = sum_{j = 0}^{s - 1} g(lo + j) - sum_{j = 1}^{s} g(hi + j).
+
+Returns the sums of the pairs and the terms that were not paired up.
+*/
+func telescope(terms []Expr, k variable, lo, hi Expr) ([]Expr, []Expr) {
+	sums := []Expr{}
+	paired := make([]bool, len(terms))
+	for ix, g := range terms {
+		for jx := range terms {
+			if paired[ix] {
+				break
+			} else if ix == jx || paired[jx] {
+				continue
+			}
+			for s := int64(1); s <= maxTelescopingShift; s++ {
+				if !isZero(Add(terms[jx], shiftVariable(g, k, Int(s)))) {
+					continue
+				}
+				for j := int64(0); j < s; j++ {
+					sums = append(sums, Substitute(g, k, Add(lo, Int(j))))
+					sums = append(sums, Neg(Substitute(g, k, Add(hi, Int(j+1)))))
+				}
+				paired[ix], paired[jx] = true, true
+				break
+			}
+		}
+	}
+	rest := []Expr{}
+	for ix, term := range terms {
+		if !paired[ix] {
+			rest = append(rest, term)
+		}
+	}
+	return sums, rest
+}
+
+/*
+Returns prod_{k = lo}^{hi} expr in closed form. The bounds may be
+symbolic but must be free of k.
+
+Factors c free of k give c^(hi - lo + 1), powers c^u and exp(u) give c
+and exp to the power sum_{k = lo}^{hi} u, and powers g^e with e free of
+k give (prod_{k = lo}^{hi} g)^e. Rational functions of k are split into
+linear factors, where
+
+	prod_{k = lo}^{hi} (k - r) = (hi - r)!/(lo - r - 1)!
+
+for integer roots r, and Gamma(hi - r + 1)/Gamma(lo - r) otherwise.
+Factorials of arguments that differ by integers are then combined,
+e.g. (n + 1)!/n! = n + 1.
+
+A NoClosedFormError is returned if some factor could not be handled,
+e.g. if a linear factor k - r with integer r vanishes for some k >= lo.
+*/
+func Product(expr Expr, k variable, lo, hi Expr) (Expr, error) {
+	result, ok := product(expandExp(expr), k, lo, hi)
+	if !ok {
+		return nil, &NoClosedFormError{Operation: "product", Expr: expr}
+	}
+	return combineFactorials(result), nil
+}
+
+func product(expr Expr, k variable, lo, hi Expr) (Expr, bool) {
+	constant, factors := splitConstantFactors(expr, k)
+	result := []Expr{Pow(constant, termCount(lo, hi))}
+	rational := []Expr{Int(1)}
+	for _, f := range factors {
+		switch e := f.(type) {
+		case exp:
+			s, err := Sum(e.Arg, k, lo, hi)
+			if err != nil {
+				return nil, false
+			}
+			result = append(result, Exp(s))
+			continue
+		case pow:
+			if !RecContains(e.Base, k) {
+				s, err := Sum(e.Exponent, k, lo, hi)
+				if err != nil {
+					return nil, false
+				}
+				result = append(result, Pow(e.Base, s))
+				continue
+			} else if !RecContains(e.Exponent, k) && !isRationalIn(e, k) {
+				p, ok := product(e.Base, k, lo, hi)
+				if !ok {
+					return nil, false
+				}
+				result = append(result, Pow(p, e.Exponent))
+				continue
+			}
+		}
+		if !isRationalIn(f, k) {
+			return nil, false
+		}
+		rational = append(rational, f)
+	}
+
+	kt := &kernelTable{}
+	r, ok := toRatFunc(Mul(rational...), kt)
+	if !ok {
+		return nil, false
+	}
+	num, ok := linearProduct(r.num.toExpr(kt), k, lo, hi)
+	if !ok {
+		return nil, false
+	}
+	den, ok := linearProduct(r.den.toExpr(kt), k, lo, hi)
+	if !ok {
+		return nil, false
+	}
+	return Mul(append(result, num, Pow(den, Int(-1)))...), true
+}
+
+// Returns the product over k = lo, ..., hi of the polynomial p in k
+// through its linear factors.
+func linearProduct(p Expr, k variable, lo, hi Expr) (Expr, bool) {
+	roots, leading, ok := linearFactors(p, k)
+	if !ok {
+		return nil, false
+	}
+	factors := []Expr{Pow(leading, termCount(lo, hi))}
+	for _, r := range roots {
+		if _, ok := Cancel(r).(integer); !ok {
+			factors = append(factors, Div(Gamma(Add(Sub(hi, r), Int(1))), Gamma(Sub(lo, r))))
+			continue
+		}
+		// The factor k - r vanishes in the product if lo <= r
+		if first, ok := Cancel(Sub(lo, r)).(integer); ok && first.value <= 0 {
+			return nil, false
+		}
+		factors = append(factors, Div(Factorial(Sub(hi, r)), Factorial(Sub(Sub(lo, r), Int(1)))))
+	}
+	return Mul(factors...), true
+}
+
+/*
+Combines the factorials among the factors of products in expr whose
+arguments differ by integers, by writing them in terms of the one with
+the smallest argument, e.g. (n + 2)!/n! = (n + 1)*(n + 2). Gamma functions
+are combined likewise.
+*/
+func combineFactorials(expr Expr) Expr {
+	expr = expr.Simplify()
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		expr = replaceOperand(expr, ix, combineFactorials(Operand(expr, ix)))
+	}
+	product, ok := expr.(mul)
+	if !ok {
+		return expr.Simplify()
+	}
+
+	type factorialGroup struct {
+		name      VarName
+		arg       Expr
+		offsets   []int64
+		exponents []Expr
+	}
+	groups := []*factorialGroup{}
+	factors := []Expr{}
+	for _, op := range product.Operands {
+		var base, exponent Expr = op, Int(1)
+		if p, ok := op.(pow); ok {
+			base, exponent = p.Base, p.Exponent
+		}
+		f, ok := base.(FunctionApplication)
+		if !ok || len(f.Args) != 1 || (f.Name != factorialName && f.Name != gammaName) {
+			factors = append(factors, op)
+			continue
+		}
+
+		var group *factorialGroup
+		offset := int64(0)
+		for _, g := range groups {
+			d, ok := Cancel(Sub(f.Args[0], g.arg)).(integer)
+			if g.name == f.Name && ok && d.value <= maxIntegerShift && -d.value <= maxIntegerShift {
+				group, offset = g, d.value
+				break
+			}
+		}
+		if group == nil {
+			group = &factorialGroup{name: f.Name, arg: f.Args[0]}
+			groups = append(groups, group)
+		} else if offset < 0 {
+			// The new factor has the smallest argument
+			for ix := range group.offsets {
+				group.offsets[ix] -= offset
+			}
+			group.arg, offset = f.Args[0], 0
+		}
+		group.offsets = append(group.offsets, offset)
+		group.exponents = append(group.exponents, exponent)
+	}
+
+	for _, g := range groups {
+		factors = append(factors, Pow(Function(g.name, g.arg), Add(g.exponents...)))
+		// Gamma(u) = (u - 1)!
+		u := g.arg
+		if g.name == gammaName {
+			u = Sub(u, Int(1))
+		}
+		for ix, offset := range g.offsets {
+			factors = append(factors, Pow(factorialRatio(u, offset), g.exponents[ix]))
+		}
+	}
+	return Mul(factors...).Simplify()
+}
+
+/*
+Writes the products u!/(v! * w!) in expr with u = v + w as binomial
+coefficients Binomial(u, v), where v is the one of v and w containing n
+if only one of them does. Gamma(u) is read as (u - 1)!.
+*/
+func factorialsToBinomials(expr Expr, n variable) Expr {
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		expr = replaceOperand(expr, ix, factorialsToBinomials(Operand(expr, ix), n))
+	}
+	product, ok := expr.(mul)
+	if !ok {
+		return expr
+	}
+
+	// The operand indices and arguments of the
+	// factorials in the numerator and the denominator
+	type factorialFactor struct {
+		index int
+		arg   Expr
+	}
+	numerators, denominators := []factorialFactor{}, []factorialFactor{}
+	for ix, op := range product.Operands {
+		var base, exponent Expr = op, Int(1)
+		if p, ok := op.(pow); ok {
+			base, exponent = p.Base, p.Exponent
+		}
+		f, ok := base.(FunctionApplication)
+		if !ok || len(f.Args) != 1 || (f.Name != factorialName && f.Name != gammaName) {
+			continue
+		}
+		arg := f.Args[0]
+		if f.Name == gammaName {
+			arg = Cancel(Sub(arg, Int(1)))
+		}
+		if Equal(exponent, Int(1)) {
+			numerators = append(numerators, factorialFactor{ix, arg})
+		} else if Equal(exponent, Int(-1)) {
+			denominators = append(denominators, factorialFactor{ix, arg})
+		}
+	}
+
+	used := map[int]bool{}
+	binomials := []Expr{}
+	for _, u := range numerators {
+	search:
+		for _, v := range denominators {
+			for _, w := range denominators {
+				if v.index == w.index || used[v.index] || used[w.index] || !isZero(Sub(u.arg, Add(v.arg, w.arg))) {
+					continue
+				}
+				k := v.arg
+				if RecContains(w.arg, n) && !RecContains(v.arg, n) {
+					k = w.arg
+				}
+				used[u.index], used[v.index], used[w.index] = true, true, true
+				binomials = append(binomials, Binomial(u.arg, k))
+				break search
+			}
+		}
+	}
+	if len(binomials) == 0 {
+		return expr
+	}
+	factors := binomials
+	for ix, op := range product.Operands {
+		if !used[ix] {
+			factors = append(factors, op)
+		}
+	}
+	return Mul(factors...).Simplify()
+}
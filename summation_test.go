@@ -0,0 +1,222 @@
+package gosymbol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSum(t *testing.T) {
+	k, n, x, m := Var("k"), Var("n"), Var("x"), Var("m")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		lo             Expr
+		expectedOutput Expr // nil if the closed form is only checked at n = lo - 1, ..., 5
+	}{
+		{
+			name:           "Sum of integers",
+			input:          k,
+			lo:             Int(1),
+			expectedOutput: Div(Mul(n, Add(n, Int(1))), Int(2)),
+		},
+		{
+			name:           "Sum of cubes",
+			input:          Pow(k, Int(3)),
+			lo:             Int(1),
+			expectedOutput: Pow(Div(Mul(n, Add(n, Int(1))), Int(2)), Int(2)),
+		},
+		{
+			name:           "Constant",
+			input:          x,
+			lo:             Int(2),
+			expectedOutput: Mul(x, Sub(n, Int(1))),
+		},
+		{
+			name:           "Geometric",
+			input:          Pow(Int(2), k),
+			lo:             Int(0),
+			expectedOutput: Sub(Pow(Int(2), Add(n, Int(1))), Int(1)),
+		},
+		{
+			name:           "Symbolic geometric",
+			input:          Pow(x, k),
+			lo:             Int(0),
+			expectedOutput: Div(Sub(Pow(x, Add(n, Int(1))), Int(1)), Sub(x, Int(1))),
+		},
+		{
+			name:           "Polynomial and geometric terms",
+			input:          Add(k, Pow(Int(3), k)),
+			lo:             Int(1),
+			expectedOutput: Add(Div(Mul(n, Add(n, Int(1))), Int(2)), Div(Sub(Pow(Int(3), Add(n, Int(1))), Int(3)), Int(2))),
+		},
+		{
+			name:           "Rational",
+			input:          Div(Int(1), Mul(k, Add(k, Int(1)))),
+			lo:             Int(1),
+			expectedOutput: Div(n, Add(n, Int(1))),
+		},
+		{
+			name:           "Rational terms",
+			input:          Add(Div(Int(1), k), Div(Int(-2), Add(k, Int(1))), Div(Int(1), Add(k, Int(2)))),
+			lo:             Int(1),
+			expectedOutput: Add(Div(Int(1), Int(2)), Div(Int(-1), Add(n, Int(1))), Div(Int(1), Add(n, Int(2)))),
+		},
+		{
+			name:           "Polynomial times geometric",
+			input:          Mul(k, Pow(Int(2), k)),
+			lo:             Int(0),
+			expectedOutput: Add(Mul(Sub(n, Int(1)), Pow(Int(2), Add(n, Int(1)))), Int(2)),
+		},
+		{
+			name:           "Factorial",
+			input:          Mul(k, Factorial(k)),
+			lo:             Int(0),
+			expectedOutput: Sub(Factorial(Add(n, Int(1))), Int(1)),
+		},
+		{
+			name:  "Alternating binomial coefficients",
+			input: Mul(Pow(Int(-1), k), Binomial(m, k)),
+			lo:    Int(0),
+		},
+		{
+			name:           "Telescoping logarithms",
+			input:          Sub(Log(Add(k, Int(1))), Log(k)),
+			lo:             Int(1),
+			expectedOutput: Log(Add(n, Int(1))),
+		},
+	}
+
+	values := func(expr Expr) Expr {
+		return Substitute(Substitute(expr, x, Int(3)), m, Int(7)).Simplify()
+	}
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := Sum(test.input, k, test.lo, n)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+			if test.expectedOutput != nil && !isZero(Sub(result, test.expectedOutput)) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+
+			// Compares with adding up the terms
+			lo := test.lo.(integer).value
+			for hi := lo - 1; hi <= 5; hi++ {
+				terms := []Expr{Int(0)}
+				for j := lo; j <= hi; j++ {
+					terms = append(terms, Substitute(test.input, k, Int(j)))
+				}
+				expected := values(Add(terms...))
+				if got := values(Substitute(result, n, Int(hi))); !isZero(Sub(got, expected)) {
+					t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, hi, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSumNoClosedForm(t *testing.T) {
+	k, n := Var("k"), Var("n")
+	var noClosedForm *NoClosedFormError
+	if _, err := Sum(Div(Int(1), k), k, Int(1), n); !errors.As(err, &noClosedForm) {
+		t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", "Harmonic sum", Div(Int(1), k), noClosedForm, err)
+	}
+	if _, err := Sum(Log(k), k, Int(1), n); !errors.As(err, &noClosedForm) {
+		t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", "Sum of logarithms", Log(k), noClosedForm, err)
+	}
+}
+
+func TestProduct(t *testing.T) {
+	k, n := Var("k"), Var("n")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput Expr
+	}{
+		{
+			name:           "Factorial",
+			input:          k,
+			expectedOutput: Factorial(n),
+		},
+		{
+			name:           "Constant factor",
+			input:          Mul(Int(2), k),
+			expectedOutput: Mul(Pow(Int(2), n), Factorial(n)),
+		},
+		{
+			name:           "Telescoping factorials",
+			input:          Add(Int(1), Div(Int(1), k)),
+			expectedOutput: Add(n, Int(1)),
+		},
+		{
+			name:           "Rational",
+			input:          Div(Add(k, Int(1)), Add(k, Int(2))),
+			expectedOutput: Div(Int(2), Add(n, Int(2))),
+		},
+		{
+			name:           "Exponential",
+			input:          Pow(Int(2), k),
+			expectedOutput: Pow(Int(2), Div(Mul(n, Add(n, Int(1))), Int(2))),
+		},
+		{
+			name:           "Power of a factor",
+			input:          Pow(k, Int(2)),
+			expectedOutput: Pow(Factorial(n), Int(2)),
+		},
+		{
+			name:           "Odd numbers",
+			input:          Sub(Mul(Int(2), k), Int(1)),
+			expectedOutput: Div(Mul(Pow(Int(2), n), Gamma(Add(n, Div(Int(1), Int(2))))), Pow(PI, Div(Int(1), Int(2)))),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := Product(test.input, k, Int(1), n)
+			if err != nil {
+				t.Fatalf("Following test failed: %s\nUnexpected error: %v", test.name, err)
+			}
+			if !isZero(Sub(result, test.expectedOutput)) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestProductNoClosedForm(t *testing.T) {
+	k, n := Var("k"), Var("n")
+	var noClosedForm *NoClosedFormError
+	if _, err := Product(Sub(k, Int(2)), k, Int(1), n); !errors.As(err, &noClosedForm) {
+		t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", "Vanishing factor", Sub(k, Int(2)), noClosedForm, err)
+	}
+}
+
+func TestSpecialFunctionValues(t *testing.T) {
+	n := Var("n")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		expectedOutput Expr
+	}{
+		{name: "Factorial", input: Factorial(Int(5)), expectedOutput: Int(120)},
+		{name: "Factorial of zero", input: Factorial(Int(0)), expectedOutput: Int(1)},
+		{name: "Negative factorial", input: Factorial(Int(-1)), expectedOutput: Factorial(Int(-1))},
+		{name: "Binomial", input: Binomial(Int(6), Int(2)), expectedOutput: Int(15)},
+		{name: "Binomial out of range", input: Binomial(Int(3), Int(5)), expectedOutput: Int(0)},
+		{name: "Symbolic binomial", input: Binomial(n, Int(1)), expectedOutput: n},
+		{name: "Gamma", input: Gamma(Int(5)), expectedOutput: Int(24)},
+		{name: "Gamma of 1/2", input: Gamma(Div(Int(1), Int(2))), expectedOutput: Pow(PI, Div(Int(1), Int(2)))},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			if result := test.input.Simplify(); !Equal(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
@@ -62,6 +62,9 @@ type undefined struct {
 type variable struct {
 	Expr
 	Name VarName
+	// Facts assumed about the value of the variable,
+	// see assumptions.go
+	assumptions Property
 	// Indicating if variable is part of a pattern
 	isPattern bool
 }
@@ -99,6 +102,34 @@ type sqrt struct {
 	Arg Expr
 }
 
+type sin struct {
+	Expr
+	Arg Expr
+}
+
+type cos struct {
+	Expr
+	Arg Expr
+}
+
+/* Undefined functions */
+
+// An application f(x, y, ...) of an undefined function f,
+// e.g. the unknown function of a differential equation.
+type FunctionApplication struct {
+	Expr
+	Name VarName
+	Args []Expr
+}
+
+// The unevaluated derivative of Arg w.r.t. Var. It is only
+// kept unevaluated when Arg contains a FunctionApplication.
+type derivative struct {
+	Expr
+	Arg Expr
+	Var variable
+}
+
 /* Const types */
 
 type integer struct {
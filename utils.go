@@ -16,7 +16,7 @@ func correctnesCheck(t *testing.T, testName string, testInput, testExpectedOutpu
 // Substitutes u for t in expr.
 func Substitute(expr, u, t Expr) Expr {
 	if Equal(u, t) {
-		return u
+		return expr
 	} else if Equal(expr, u) {
 		return t
 	} else if RecContains(expr, u) {
@@ -37,6 +37,10 @@ func Substitute(expr, u, t Expr) Expr {
 Replaces operand number n in t with u and returns the resulting
 expression. The function panics if n is larger than
 the NumberOfOperands(t).
+
+Note: t itself is left untouched, the operands of n-ary
+operators are copied before the replacement so that the
+result does not share its operand slice with t.
 */
 func replaceOperand(t Expr, n int, u Expr) Expr {
 	nop := NumberOfOperands(t)
@@ -61,9 +65,11 @@ func replaceOperand(t Expr, n int, u Expr) Expr {
 	case constrainedVariable:
 		return v
 	case add:
+		v.Operands = append([]Expr{}, v.Operands...)
 		v.Operands[n-1] = u
 		return v
 	case mul:
+		v.Operands = append([]Expr{}, v.Operands...)
 		v.Operands[n-1] = u
 		return v
 	case pow:
@@ -82,6 +88,28 @@ func replaceOperand(t Expr, n int, u Expr) Expr {
 	case sqrt:
 		v.Arg = u
 		return v
+	case sin:
+		v.Arg = u
+		return v
+	case cos:
+		v.Arg = u
+		return v
+	case FunctionApplication:
+		v.Args = append([]Expr{}, v.Args...)
+		v.Args[n-1] = u
+		return v
+	case derivative:
+		if n == 1 {
+			v.Arg = u
+			return v
+		}
+		w, ok := u.(variable)
+		if !ok {
+			errMsg := fmt.Sprintf("ERROR: the variable of differentiation can not be replaced by: %v", u)
+			panic(errMsg)
+		}
+		v.Var = w
+		return v
 	default:
 		errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(v))
 		panic(errMsg)
@@ -162,6 +190,26 @@ func Equal(t, u Expr) bool {
 	case sqrt:
 		_, ok := u.(sqrt)
 		return ok && Equal(Operand(v, 1), Operand(u, 1))
+	case sin:
+		_, ok := u.(sin)
+		return ok && Equal(Operand(v, 1), Operand(u, 1))
+	case cos:
+		_, ok := u.(cos)
+		return ok && Equal(Operand(v, 1), Operand(u, 1))
+	case FunctionApplication:
+		uTyped, ok := u.(FunctionApplication)
+		if !ok || v.Name != uTyped.Name || len(v.Args) != len(uTyped.Args) {
+			return false
+		}
+		for ix := range v.Args {
+			if !Equal(v.Args[ix], uTyped.Args[ix]) {
+				return false
+			}
+		}
+		return true
+	case derivative:
+		uTyped, ok := u.(derivative)
+		return ok && v.Var.Name == uTyped.Var.Name && Equal(v.Arg, uTyped.Arg)
 	default:
 		errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(v))
 		panic(errMsg)
@@ -336,6 +384,14 @@ func NumberOfOperands(expr Expr) int {
 		return 1
 	case sqrt:
 		return 1
+	case sin:
+		return 1
+	case cos:
+		return 1
+	case FunctionApplication:
+		return len(v.Args)
+	case derivative:
+		return 2
 	default:
 		errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(v))
 		panic(errMsg)
@@ -377,12 +433,144 @@ func Operand(expr Expr, n int) Expr {
 		return v.Arg
 	case sqrt:
 		return v.Arg
+	case sin:
+		return v.Arg
+	case cos:
+		return v.Arg
+	case FunctionApplication:
+		return v.Args[n-1]
+	case derivative:
+		if n == 1 {
+			return v.Arg
+		}
+		return v.Var
+	default:
+		errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(v))
+		panic(errMsg)
+	}
+}
+
+/*
+The kind of the top level operation of an expression, see KindOf.
+Together with NumberOfOperands, Operand, RationalValue and NameOf it
+lets other packages traverse expression trees.
+*/
+type Kind int
+
+const (
+	KindUndefined Kind = iota
+	KindInteger
+	KindFraction
+	KindVariable
+	KindAdd
+	KindMul
+	KindPow
+	KindExp
+	KindLog
+	KindSqrt
+	KindSin
+	KindCos
+	KindFunction
+	KindDerivative
+)
+
+// Returns the kind of the top level operation of expr. Constrained
+// variables are of KindVariable.
+func KindOf(expr Expr) Kind {
+	switch v := expr.(type) {
+	case undefined:
+		return KindUndefined
+	case integer:
+		return KindInteger
+	case fraction:
+		return KindFraction
+	case variable:
+		return KindVariable
+	case constrainedVariable:
+		return KindVariable
+	case add:
+		return KindAdd
+	case mul:
+		return KindMul
+	case pow:
+		return KindPow
+	case exp:
+		return KindExp
+	case log:
+		return KindLog
+	case sqrt:
+		return KindSqrt
+	case sin:
+		return KindSin
+	case cos:
+		return KindCos
+	case FunctionApplication:
+		return KindFunction
+	case derivative:
+		return KindDerivative
 	default:
 		errMsg := fmt.Sprintf("ERROR: function is not implemented for type: %v", reflect.TypeOf(v))
 		panic(errMsg)
 	}
 }
 
+// Returns the numerator and denominator of expr if it is
+// an integer or a fraction, and ok false otherwise.
+func RationalValue(expr Expr) (num, den int64, ok bool) {
+	switch v := expr.(type) {
+	case integer:
+		return v.value, 1, true
+	case fraction:
+		return v.num.value, v.den.value, true
+	}
+	return 0, 0, false
+}
+
+// Returns the name of a variable or of the function in a
+// function application, and the empty name otherwise.
+func NameOf(expr Expr) VarName {
+	switch v := expr.(type) {
+	case variable:
+		return v.Name
+	case constrainedVariable:
+		return v.Name
+	case FunctionApplication:
+		return v.Name
+	}
+	return ""
+}
+
+// Returns a variable named name, with primes appended
+// if needed, that does not occur in any of exprs.
+func freshVariable(name VarName, exprs ...Expr) variable {
+	for {
+		occurs := false
+		for _, e := range exprs {
+			if RecContains(e, Var(name)) {
+				occurs = true
+				break
+			}
+		}
+		if !occurs {
+			return Var(name)
+		}
+		name += "'"
+	}
+}
+
+// Returns true if expr contains an application of an undefined function.
+func containsFunctionApplication(expr Expr) bool {
+	if _, ok := expr.(FunctionApplication); ok {
+		return true
+	}
+	for ix := 1; ix <= NumberOfOperands(expr); ix++ {
+		if containsFunctionApplication(Operand(expr, ix)) {
+			return true
+		}
+	}
+	return false
+}
+
 // TODO: see Computer Algebra and Symbolic Computation page 10 to understand this shit
 func Map(F Expr, u ...Expr) Expr { panic("Not implemented yet") }
 
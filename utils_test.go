@@ -169,6 +169,14 @@ func TestSubstitute(t *testing.T) {
 			},
 			expectedOutput: Var("X"),
 		},
+		{ // Test 13: substituting a variable for itself
+			input: inputArgs{
+				expr: Function("x", Var("t")),
+				u:    Var("t"),
+				t:    Var("t"),
+			},
+			expectedOutput: Function("x", Var("t")),
+		},
 	}
 
 	for ix, test := range tests {
@@ -250,3 +258,44 @@ func TestDepth(t *testing.T) {
 		correctnesCheck(t, strconv.Itoa(ix+1), test.input, test.expectedOutput, result)
 	}
 }
+
+func TestKindOf(t *testing.T) {
+	x := Var("x")
+
+	tests := []struct {
+		input        Expr
+		expectedKind Kind
+		expectedName VarName
+	}{
+		{input: Undefined(), expectedKind: KindUndefined},
+		{input: Int(3), expectedKind: KindInteger},
+		{input: Div(Int(3), Int(4)), expectedKind: KindFraction},
+		{input: x, expectedKind: KindVariable, expectedName: "x"},
+		{input: ConstrVar("c", func(Expr) bool { return true }), expectedKind: KindVariable, expectedName: "c"},
+		{input: Add(x, Int(1)), expectedKind: KindAdd},
+		{input: Mul(Int(2), x), expectedKind: KindMul},
+		{input: Pow(x, Int(2)), expectedKind: KindPow},
+		{input: Exp(x), expectedKind: KindExp},
+		{input: Log(x), expectedKind: KindLog},
+		{input: Sqrt(x), expectedKind: KindSqrt},
+		{input: Sin(x), expectedKind: KindSin},
+		{input: Cos(x), expectedKind: KindCos},
+		{input: Function("f", x), expectedKind: KindFunction, expectedName: "f"},
+		{input: Derivative(Function("f", x), x), expectedKind: KindDerivative},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			if kind, name := KindOf(test.input), NameOf(test.input); kind != test.expectedKind || name != test.expectedName {
+				t.Errorf("Following test failed: %d\nInput: %v\nExpected: %v %q\nGot: %v %q", ix+1, test.input, test.expectedKind, test.expectedName, kind, name)
+			}
+		})
+	}
+
+	if num, den, ok := RationalValue(Div(Int(-3), Int(4))); !ok || num != -3 || den != 4 {
+		t.Errorf("Following test failed: RationalValue\nInput: %v\nExpected: -3 4 true\nGot: %v %v %v", Div(Int(-3), Int(4)), num, den, ok)
+	}
+	if _, _, ok := RationalValue(x); ok {
+		t.Errorf("Following test failed: RationalValue\nInput: %v\nExpected: false\nGot: %v", x, ok)
+	}
+}
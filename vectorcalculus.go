@@ -0,0 +1,122 @@
+package gosymbol
+
+/*
+A CoordinateSystem is an orthogonal coordinate system given by its
+coordinate variables and their scale factors (Lamé coefficients), i.e.
+the lengths h_i of the tangent vectors of the coordinate lines. The
+scale factors are all one for Cartesian coordinates.
+
+The vector operators below work in the orthonormal basis of the
+coordinate system, e.g. the components of a vector field in spherical
+coordinates are its components along the unit vectors e_r, e_theta and
+e_phi.
+*/
+type CoordinateSystem struct {
+	Vars         []variable
+	ScaleFactors []Expr
+}
+
+/* Factories */
+
+func Cartesian(vars ...variable) CoordinateSystem {
+	scaleFactors := make([]Expr, len(vars))
+	for ix := range scaleFactors {
+		scaleFactors[ix] = Int(1)
+	}
+	return CoordinateSystem{Vars: vars, ScaleFactors: scaleFactors}
+}
+
+// Cylindrical coordinates (rho, phi, z) where rho is
+// the distance to the z-axis and phi the azimuth.
+func Cylindrical(rho, phi, z variable) CoordinateSystem {
+	return CoordinateSystem{
+		Vars:         []variable{rho, phi, z},
+		ScaleFactors: []Expr{Int(1), rho, Int(1)},
+	}
+}
+
+// Spherical coordinates (r, theta, phi) where theta is the
+// polar angle from the z-axis and phi the azimuth.
+func Spherical(r, theta, phi variable) CoordinateSystem {
+	return CoordinateSystem{
+		Vars:         []variable{r, theta, phi},
+		ScaleFactors: []Expr{Int(1), r, Mul(r, Sin(theta))},
+	}
+}
+
+/* Operators */
+
+// The gradient of the scalar field f, with components (1/h_i)*D(f, q_i).
+func Grad(f Expr, cs CoordinateSystem) []Expr {
+	result := make([]Expr, len(cs.Vars))
+	for ix, v := range cs.Vars {
+		result[ix] = Cancel(Div(differentiate(f, v), cs.ScaleFactors[ix]))
+	}
+	return result
+}
+
+/*
+The divergence of the vector field F, i.e.
+
+	(1/H) * sum_i D(H/h_i * F_i, q_i)
+
+where H is the product of all scale factors. The name Div
+is already taken by division.
+*/
+func Divergence(F []Expr, cs CoordinateSystem) (Expr, error) {
+	if len(F) != len(cs.Vars) {
+		return nil, &DimensionMismatchError{Expected: len(cs.Vars), Got: len(F)}
+	}
+	volume := Mul(cs.ScaleFactors...)
+	terms := make([]Expr, len(F))
+	for ix, v := range cs.Vars {
+		terms[ix] = differentiate(Mul(volume, Pow(cs.ScaleFactors[ix], Int(-1)), F[ix]), v)
+	}
+	return Cancel(Div(Add(terms...), volume)), nil
+}
+
+/*
+The curl of the three dimensional vector field F, where the first
+component is
+
+	(1/(h_2*h_3)) * (D(h_3*F_3, q_2) - D(h_2*F_2, q_3))
+
+and the others follow by cyclic permutation.
+*/
+func Curl(F []Expr, cs CoordinateSystem) ([]Expr, error) {
+	if len(cs.Vars) != 3 {
+		return nil, &DimensionMismatchError{Expected: 3, Got: len(cs.Vars)}
+	} else if len(F) != 3 {
+		return nil, &DimensionMismatchError{Expected: 3, Got: len(F)}
+	}
+	h := cs.ScaleFactors
+	result := make([]Expr, 3)
+	for i := range result {
+		j, k := (i+1)%3, (i+2)%3
+		circulation := Sub(differentiate(Mul(h[k], F[k]), cs.Vars[j]), differentiate(Mul(h[j], F[j]), cs.Vars[k]))
+		result[i] = Cancel(Div(circulation, Mul(h[j], h[k])))
+	}
+	return result, nil
+}
+
+// The Laplacian of the scalar field f, i.e. the divergence of its gradient.
+func Laplacian(f Expr, cs CoordinateSystem) Expr {
+	// The gradient always has the right dimension
+	laplacian, _ := Divergence(Grad(f, cs), cs)
+	return laplacian
+}
+
+// The derivative of the scalar field f in the direction u, i.e.
+// Grad(f)·u. Note that u is not normalised, so the result is
+// the directional derivative scaled by the length of u.
+func DirectionalDerivative(f Expr, u []Expr, cs CoordinateSystem) (Expr, error) {
+	if len(u) != len(cs.Vars) {
+		return nil, &DimensionMismatchError{Expected: len(cs.Vars), Got: len(u)}
+	}
+	grad := Grad(f, cs)
+	terms := make([]Expr, len(u))
+	for ix := range u {
+		terms[ix] = Mul(grad[ix], u[ix])
+	}
+	return Cancel(Add(terms...)), nil
+}
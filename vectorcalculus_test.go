@@ -0,0 +1,225 @@
+package gosymbol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGrad(t *testing.T) {
+	x, y, z := Var("x"), Var("y"), Var("z")
+	r, theta, phi := Var("r"), Var("theta"), Var("phi")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		coordinates    CoordinateSystem
+		expectedOutput []Expr
+	}{
+		{
+			name:           "Cartesian",
+			input:          Add(Mul(Pow(x, Int(2)), y), Sin(z)),
+			coordinates:    Cartesian(x, y, z),
+			expectedOutput: []Expr{Mul(Int(2), x, y), Pow(x, Int(2)), Cos(z)},
+		},
+		{
+			name:           "Cylindrical",
+			input:          Mul(r, Cos(phi)),
+			coordinates:    Cylindrical(r, phi, z),
+			expectedOutput: []Expr{Cos(phi), Neg(Sin(phi)), Int(0)},
+		},
+		{
+			name:           "Spherical",
+			input:          Mul(Pow(r, Int(2)), Cos(theta)),
+			coordinates:    Spherical(r, theta, phi),
+			expectedOutput: []Expr{Mul(Int(2), r, Cos(theta)), Neg(Mul(r, Sin(theta))), Int(0)},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := Grad(test.input, test.coordinates)
+			if !vectorsEqual(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestDivergence(t *testing.T) {
+	x, y, z := Var("x"), Var("y"), Var("z")
+	r, theta, phi := Var("r"), Var("theta"), Var("phi")
+
+	tests := []struct {
+		name           string
+		input          []Expr
+		coordinates    CoordinateSystem
+		expectedOutput Expr
+	}{
+		{
+			name:           "Cartesian position vector",
+			input:          []Expr{x, y, z},
+			coordinates:    Cartesian(x, y, z),
+			expectedOutput: Int(3),
+		},
+		{
+			name:           "Cartesian in two dimensions",
+			input:          []Expr{Mul(x, y), Neg(Pow(y, Int(2)))},
+			coordinates:    Cartesian(x, y),
+			expectedOutput: Neg(y),
+		},
+		{
+			name:           "Cylindrical radial field",
+			input:          []Expr{r, Int(0), Int(0)},
+			coordinates:    Cylindrical(r, phi, z),
+			expectedOutput: Int(2),
+		},
+		{
+			name:           "Spherical radial field",
+			input:          []Expr{r, Int(0), Int(0)},
+			coordinates:    Spherical(r, theta, phi),
+			expectedOutput: Int(3),
+		},
+		{
+			name:           "Spherical polar field",
+			input:          []Expr{Int(0), Sin(theta), Int(0)},
+			coordinates:    Spherical(r, theta, phi),
+			expectedOutput: Div(Mul(Int(2), Cos(theta)), r),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := Divergence(test.input, test.coordinates)
+			if err != nil || !isZero(Sub(result, test.expectedOutput)) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v, error: %v", test.name, test.input, test.expectedOutput, result, err)
+			}
+		})
+	}
+
+	if _, err := Divergence([]Expr{x, y}, Cartesian(x, y, z)); err == nil {
+		t.Errorf("Expected DimensionMismatchError for a two dimensional field in three dimensions")
+	}
+}
+
+func TestCurl(t *testing.T) {
+	x, y, z := Var("x"), Var("y"), Var("z")
+	r, theta, phi := Var("r"), Var("theta"), Var("phi")
+
+	tests := []struct {
+		name           string
+		input          []Expr
+		coordinates    CoordinateSystem
+		expectedOutput []Expr
+	}{
+		{
+			name:           "Cartesian rotation",
+			input:          []Expr{Neg(y), x, Int(0)},
+			coordinates:    Cartesian(x, y, z),
+			expectedOutput: []Expr{Int(0), Int(0), Int(2)},
+		},
+		{
+			name:           "Curl of a gradient",
+			input:          Grad(Mul(x, Pow(y, Int(2)), Exp(z)), Cartesian(x, y, z)),
+			coordinates:    Cartesian(x, y, z),
+			expectedOutput: []Expr{Int(0), Int(0), Int(0)},
+		},
+		{
+			name:           "Cylindrical rotation",
+			input:          []Expr{Int(0), r, Int(0)},
+			coordinates:    Cylindrical(r, phi, z),
+			expectedOutput: []Expr{Int(0), Int(0), Int(2)},
+		},
+		{
+			name:           "Spherical azimuthal field",
+			input:          []Expr{Int(0), Int(0), Mul(r, Sin(theta))},
+			coordinates:    Spherical(r, theta, phi),
+			expectedOutput: []Expr{Mul(Int(2), Cos(theta)), Mul(Int(-2), Sin(theta)), Int(0)},
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result, err := Curl(test.input, test.coordinates)
+			if err != nil || !vectorsEqual(result, test.expectedOutput) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v, error: %v", test.name, test.input, test.expectedOutput, result, err)
+			}
+		})
+	}
+
+	if _, err := Curl([]Expr{x, y}, Cartesian(x, y)); err == nil {
+		t.Errorf("Expected DimensionMismatchError for the curl in two dimensions")
+	}
+}
+
+func TestLaplacian(t *testing.T) {
+	x, y, z := Var("x"), Var("y"), Var("z")
+	r, theta, phi := Var("r"), Var("theta"), Var("phi")
+
+	tests := []struct {
+		name           string
+		input          Expr
+		coordinates    CoordinateSystem
+		expectedOutput Expr
+	}{
+		{
+			name:           "Cartesian",
+			input:          Add(Pow(x, Int(2)), Pow(y, Int(2)), Pow(z, Int(2))),
+			coordinates:    Cartesian(x, y, z),
+			expectedOutput: Int(6),
+		},
+		{
+			name:           "Harmonic function",
+			input:          Mul(Exp(x), Sin(y)),
+			coordinates:    Cartesian(x, y),
+			expectedOutput: Int(0),
+		},
+		{
+			name:           "Cylindrical",
+			input:          Pow(r, Int(2)),
+			coordinates:    Cylindrical(r, phi, z),
+			expectedOutput: Int(4),
+		},
+		{
+			name:           "Spherical Coulomb potential",
+			input:          Pow(r, Int(-1)),
+			coordinates:    Spherical(r, theta, phi),
+			expectedOutput: Int(0),
+		},
+		{
+			name:           "Spherical dipole potential",
+			input:          Mul(Cos(theta), Pow(r, Int(-2))),
+			coordinates:    Spherical(r, theta, phi),
+			expectedOutput: Int(0),
+		},
+	}
+
+	for ix, test := range tests {
+		t.Run(fmt.Sprint(ix+1), func(t *testing.T) {
+			result := Laplacian(test.input, test.coordinates)
+			if !isZero(Sub(result, test.expectedOutput)) {
+				t.Errorf("Following test failed: %s\nInput: %v\nExpected: %v\nGot: %v", test.name, test.input, test.expectedOutput, result)
+			}
+		})
+	}
+}
+
+func TestDirectionalDerivative(t *testing.T) {
+	x, y := Var("x"), Var("y")
+	result, err := DirectionalDerivative(Mul(x, y), []Expr{Int(1), Int(1)}, Cartesian(x, y))
+	expected := Add(x, y)
+	if err != nil || !isZero(Sub(result, expected)) {
+		t.Errorf("Expected %v, got %v, error: %v", expected, result, err)
+	}
+}
+
+func vectorsEqual(u, v []Expr) bool {
+	if len(u) != len(v) {
+		return false
+	}
+	for ix := range u {
+		if !isZero(Sub(u[ix], v[ix])) {
+			return false
+		}
+	}
+	return true
+}